@@ -12,6 +12,7 @@ import (
 	"github.com/CelineWuest/biscepter/pkg/biscepter"
 	"github.com/Anon10214/dinkel/cmd/config"
 	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
 	"github.com/Anon10214/dinkel/scheduler"
 	"github.com/Anon10214/dinkel/seed"
 	"github.com/sirupsen/logrus"
@@ -146,11 +147,17 @@ func runJob(job *biscepter.Job, reports []*config.BugReport, wg *sync.WaitGroup,
 
 				conf.TargetStrategy = reports[commit.ReplicaIndex].StrategyNum
 
+				ast, err := reports[commit.ReplicaIndex].LoadAST(conf.Implementation)
+				if err != nil {
+					logrus.Warnf("Couldn't load the AST of bugreport #%d, the bisected bugreport will only hold its byte string - %v", commit.ReplicaIndex, err)
+				}
+
 				// Write new bugreport
 				scheduler.WriteBugReport(
 					conf,
 					lastRes[commit.ReplicaIndex],
 					reports[commit.ReplicaIndex].Query,
+					ast,
 					commit.Commit,
 					seed.GetPregeneratedByteString(reports[commit.ReplicaIndex].ByteString),
 					reports[commit.ReplicaIndex].ReportName+"_bisected",
@@ -229,7 +236,8 @@ func getQueryResults(report config.BugReport, conf scheduler.Config, replicaInde
 		statementIndex++
 		logrus.Infof("Rerunning statement #%d/%d for index %d", statementIndex, len(report.Query), replicaIndex)
 		logrus.Debugf("Rerunning statement %s for index %d", statement, replicaIndex)
-		res, err := scheduler.RunQuery(conf, statement)
+		// The row order isn't part of the report, so rows are compared as if unordered
+		res, err := scheduler.RunQuery(conf, statement, schema.Unordered, 0)
 		results = append(results, res)
 		return res, err
 	})
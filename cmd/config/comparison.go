@@ -0,0 +1,19 @@
+package config
+
+import (
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/scheduler"
+)
+
+// useComparator makes the config's DB compare results using the passed comparator instead of the driver's.
+//
+// If the comparator tolerates inaccurate floats, float expressions prone to inaccuracies get generated again,
+// even if the implementation disallows inaccurate division.
+func useComparator(conf *scheduler.Config, comparator dbms.Comparator) {
+	conf.DB = dbms.WrapDB(conf.DB, dbms.DBMiddleware{
+		IsEqualResultMiddleware: func(dbms.IsEqualResultHandler) dbms.IsEqualResultHandler {
+			return comparator.EqualResults
+		},
+	})
+	conf.FloatTolerance = comparator.ToleratesFloats()
+}
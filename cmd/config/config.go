@@ -10,23 +10,30 @@ package config
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"regexp"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/Anon10214/dinkel/dbms"
 	"github.com/Anon10214/dinkel/models/apacheage"
+	"github.com/Anon10214/dinkel/models/bolt"
 	"github.com/Anon10214/dinkel/models/falkordb"
+	"github.com/Anon10214/dinkel/models/inmemory"
 	"github.com/Anon10214/dinkel/models/memgraph"
 	"github.com/Anon10214/dinkel/models/neo4j"
 	"github.com/Anon10214/dinkel/models/redisgraph"
 	"github.com/Anon10214/dinkel/scheduler"
 	"github.com/Anon10214/dinkel/scheduler/strategy"
+	"github.com/Anon10214/dinkel/translator"
+	"github.com/Anon10214/dinkel/translator/helperclauses"
 	"github.com/Masterminds/sprig/v3"
 	"gopkg.in/yaml.v3"
 )
@@ -34,7 +41,47 @@ import (
 type targetConfig map[string]struct {
 	IgnoredErrorMessages  []string `yaml:"ignoredErrors"`
 	ReportedErrorMessages []string `yaml:"reportedErrors"`
-	BugReportTemplate     string   `yaml:"bugreportTemplate"`
+	// Status codes of errors, matched in addition to the error messages
+	IgnoredErrorCodes  []string `yaml:"ignoredErrorCodes"`
+	ReportedErrorCodes []string `yaml:"reportedErrorCodes"`
+	BugReportTemplate  string   `yaml:"bugreportTemplate"`
+	// Describes a target speaking the Bolt protocol, only used for targets without a model
+	Bolt *bolt.Config `yaml:"bolt"`
+	// The database or graph to fuzz if none was passed via flags or environment variables
+	Namespace string `yaml:"namespace"`
+	// Credentials used if none were passed via flags or environment variables
+	Auth struct {
+		Username string `yaml:"username"`
+		Password string `yaml:"password"`
+		Token    string `yaml:"token"`
+	} `yaml:"auth"`
+	// TLS options used if TLS wasn't enabled via flags or environment variables
+	TLS struct {
+		Enabled    bool   `yaml:"enabled"`
+		CAFile     string `yaml:"caFile"`
+		SkipVerify bool   `yaml:"skipVerify"`
+	} `yaml:"tls"`
+	// Where to read the server log from to detect bugs the DB only logs, e.g. sanitizer reports
+	LogWatch *struct {
+		File          string        `yaml:"file"`
+		Command       string        `yaml:"command"`
+		BugPatterns   []string      `yaml:"bugPatterns"`
+		CrashPatterns []string      `yaml:"crashPatterns"`
+		ContextLines  int           `yaml:"contextLines"`
+		Delay         time.Duration `yaml:"delay"`
+	} `yaml:"logWatch"`
+	// Whether to restore the DB to a snapshot before every query instead of resetting it, if the target supports snapshots
+	Snapshots bool `yaml:"snapshots"`
+	// Whether to generate returns ordered by all returned elements, whose rows get compared in order
+	OrderedReturns bool `yaml:"orderedReturns"`
+	// How results get compared, e.g. the tolerance for inaccurate floats
+	Comparison *struct {
+		RelativeTolerance float64 `yaml:"relativeTolerance"`
+		AbsoluteTolerance float64 `yaml:"absoluteTolerance"`
+		// Defaults to true
+		NaNEqualsNaN *bool `yaml:"nanEqualsNaN"`
+		SignedZeros  bool  `yaml:"signedZeros"`
+	} `yaml:"comparison"`
 }
 
 var defaultConfig scheduler.Config
@@ -54,8 +101,10 @@ type BugReport struct {
 	TimeFound          string                   `yaml:"time_found"`
 	OffendingCommit    string                   `yaml:"offending_commit"`
 	ByteStringAsString string                   `yaml:"byte_string"`
+	ASTAsString        string                   `yaml:"ast"`
 	Query              []string                 `yaml:"query"`
 	ByteString         []byte
+	AST                []byte // The JSON encoding of the statements' ASTs, nil if the bugreport holds none
 }
 
 // ReadBugreport reads in the bugreport pointed to by the given path
@@ -77,11 +126,50 @@ func ReadBugreport(filePath string) (*BugReport, error) {
 		return nil, errors.Join(errors.New("invalid byte string in bugreport - %v"), err)
 	}
 
+	if curBugreport.ASTAsString != "" {
+		compressedAST, err := base64.StdEncoding.DecodeString(curBugreport.ASTAsString)
+		if err != nil {
+			return nil, errors.Join(errors.New("invalid AST in bugreport - "), err)
+		}
+		r, err := gzip.NewReader(bytes.NewReader(compressedAST))
+		if err != nil {
+			return nil, errors.Join(errors.New("invalid AST in bugreport - "), err)
+		}
+		if curBugreport.AST, err = io.ReadAll(r); err != nil {
+			return nil, errors.Join(errors.New("invalid AST in bugreport - "), err)
+		}
+	}
+
 	curBugreport.Strategy = curBugreport.StrategyNum.ToStrategy()
 
 	return &curBugreport, nil
 }
 
+// LoadAST returns the root clauses of the statements stored in the bugreport's AST,
+// or nil if the bugreport holds no AST, e.g. because it was created by an older version of dinkel.
+//
+// The passed implementation is the one of the bugreport's target, whose drop ins get used when regenerating parts of the AST.
+func (r BugReport) LoadAST(impl translator.Implementation) ([]*helperclauses.ClauseCapturer, error) {
+	if r.AST == nil {
+		return nil, nil
+	}
+	helperclauses.SetImplementation(impl)
+	return helperclauses.UnmarshalAST(r.AST)
+}
+
+// compileErrorMessages compiles the error message regexes into a single regex.
+// Returns nil if no regexes are given, e.g. if a target's errors are only matched by their codes.
+func compileErrorMessages(messages []string) (*regexp.Regexp, error) {
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	expr := ""
+	for _, msg := range messages {
+		expr += fmt.Sprintf("(%s)|", msg)
+	}
+	return regexp.Compile(expr[:len(expr)-1])
+}
+
 // GetConfigForTarget returns the fuzzing config associated with a given fuzzing target
 func GetConfigForTarget(target string, configPath string) (scheduler.Config, error) {
 	conf := defaultConfig
@@ -102,30 +190,22 @@ func GetConfigForTarget(target string, configPath string) (scheduler.Config, err
 
 	// Create the ignored error message regexp
 	curTargetConf := targetConf[target]
-	ignoredErrorMessages := ""
-	for _, msg := range curTargetConf.IgnoredErrorMessages {
-		ignoredErrorMessages += fmt.Sprintf("(%s)|", msg)
-	}
-	ignoredErrorMessages = ignoredErrorMessages[:len(ignoredErrorMessages)-1]
-	ignoredErrorMessagesRegexp, err := regexp.Compile(ignoredErrorMessages)
+	ignoredErrorMessagesRegexp, err := compileErrorMessages(curTargetConf.IgnoredErrorMessages)
 	if err != nil {
 		return scheduler.Config{}, errors.Join(errors.New("failed to read the regexp for ignored error messages - "), err)
 	}
 
 	// Create the reported error message regexp
-	reportedErrorMessages := ""
-	for _, msg := range curTargetConf.ReportedErrorMessages {
-		reportedErrorMessages += fmt.Sprintf("(%s)|", msg)
-	}
-	reportedErrorMessages = reportedErrorMessages[:len(reportedErrorMessages)-1]
-	reportedErrorMessagesRegexp, err := regexp.Compile(reportedErrorMessages)
+	reportedErrorMessagesRegexp, err := compileErrorMessages(curTargetConf.ReportedErrorMessages)
 	if err != nil {
 		return scheduler.Config{}, errors.Join(errors.New("failed to read the regexp for reported error messages - "), err)
 	}
 
 	errorMessageRegex := dbms.ErrorMessageRegex{
-		Ignored:  ignoredErrorMessagesRegexp,
-		Reported: reportedErrorMessagesRegexp,
+		Ignored:       ignoredErrorMessagesRegexp,
+		Reported:      reportedErrorMessagesRegexp,
+		IgnoredCodes:  curTargetConf.IgnoredErrorCodes,
+		ReportedCodes: curTargetConf.ReportedErrorCodes,
 	}
 
 	conf.ErrorMessageRegex = &errorMessageRegex
@@ -140,6 +220,44 @@ func GetConfigForTarget(target string, configPath string) (scheduler.Config, err
 
 	conf.TargetDB = target
 
+	// Options passed via flags or environment variables take precedence over the target config
+	if conf.DBOptions.Namespace == "" {
+		conf.DBOptions.Namespace = curTargetConf.Namespace
+	}
+	if conf.DBOptions.Username == "" {
+		conf.DBOptions.Username = curTargetConf.Auth.Username
+	}
+	if conf.DBOptions.Password == "" {
+		conf.DBOptions.Password = curTargetConf.Auth.Password
+	}
+	if conf.DBOptions.Token == "" {
+		conf.DBOptions.Token = curTargetConf.Auth.Token
+	}
+	if tlsConf := curTargetConf.TLS; conf.DBOptions.TLS == nil && (tlsConf.Enabled || tlsConf.CAFile != "" || tlsConf.SkipVerify) {
+		conf.DBOptions.TLS = &dbms.TLSOptions{
+			CAFile:     tlsConf.CAFile,
+			SkipVerify: tlsConf.SkipVerify,
+		}
+	}
+
+	conf.UseSnapshots = curTargetConf.Snapshots
+	conf.OrderedReturns = curTargetConf.OrderedReturns
+
+	if logWatch := curTargetConf.LogWatch; logWatch != nil {
+		conf.LogWatcher = &dbms.LogWatcher{
+			File:         logWatch.File,
+			Command:      logWatch.Command,
+			ContextLines: logWatch.ContextLines,
+			Delay:        logWatch.Delay,
+		}
+		if conf.LogWatcher.BugPattern, err = compileErrorMessages(logWatch.BugPatterns); err != nil {
+			return scheduler.Config{}, errors.Join(errors.New("failed to read the regexp for bug log patterns - "), err)
+		}
+		if conf.LogWatcher.CrashPattern, err = compileErrorMessages(logWatch.CrashPatterns); err != nil {
+			return scheduler.Config{}, errors.Join(errors.New("failed to read the regexp for crash log patterns - "), err)
+		}
+	}
+
 	// Set the fuzz target
 	switch target {
 	case "neo4j":
@@ -157,8 +275,38 @@ func GetConfigForTarget(target string, configPath string) (scheduler.Config, err
 	case "apache-age":
 		conf.DB = &apacheage.Driver{}
 		conf.Implementation = apacheage.Implementation{}
+	case "inmemory":
+		conf.DB = &inmemory.Driver{}
+		conf.Implementation = inmemory.Implementation{}
+	case "kuzu":
+		if conf.DB, conf.Implementation, err = kuzuTarget(); err != nil {
+			return conf, err
+		}
 	default:
-		return conf, errors.New("invalid target")
+		// Targets without a model can be fuzzed through the generic bolt model
+		if curTargetConf.Bolt == nil {
+			return conf, errors.New("invalid target")
+		}
+		driver, err := bolt.NewDriver(*curTargetConf.Bolt)
+		if err != nil {
+			return conf, errors.Join(errors.New("invalid bolt config - "), err)
+		}
+		conf.DB = driver
+		conf.Implementation = bolt.Implementation{Config: *curTargetConf.Bolt}
+	}
+
+	if comparison := curTargetConf.Comparison; comparison != nil {
+		comparator := dbms.DefaultComparator
+		comparator.RelativeTolerance = comparison.RelativeTolerance
+		comparator.AbsoluteTolerance = comparison.AbsoluteTolerance
+		comparator.SignedZeros = comparison.SignedZeros
+		if comparison.NaNEqualsNaN != nil {
+			comparator.NaNEqualsNaN = *comparison.NaNEqualsNaN
+		}
+		if comparator.RelativeTolerance < 0 || comparator.AbsoluteTolerance < 0 {
+			return conf, errors.New("float tolerances must not be negative")
+		}
+		useComparator(&conf, comparator)
 	}
 	return conf, nil
 }
@@ -0,0 +1,14 @@
+//go:build kuzu
+
+package config
+
+import (
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/kuzu"
+	"github.com/Anon10214/dinkel/translator"
+)
+
+// kuzuTarget returns the driver and implementation of the embedded Kùzu target.
+func kuzuTarget() (dbms.DB, translator.Implementation, error) {
+	return &kuzu.Driver{}, kuzu.Implementation{}, nil
+}
@@ -0,0 +1,15 @@
+//go:build !kuzu
+
+package config
+
+import (
+	"errors"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/translator"
+)
+
+// kuzuTarget returns an error, as the Kùzu driver requires cgo and is only compiled in with the kuzu build tag.
+func kuzuTarget() (dbms.DB, translator.Implementation, error) {
+	return nil, nil, errors.New("the kuzu target is embedded and requires cgo, rebuild dinkel with -tags kuzu to use it")
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/spf13/cobra"
+)
+
+// The prefix of environment variables corresponding to flags
+const envPrefix = "DINKEL_"
+
+// flagOrEnv returns the value of the flag if it was set, else the value of its environment variable.
+//
+// The environment variable's name is the flag's name in upper case, prefixed with [envPrefix],
+// e.g. DINKEL_DB_USER for --db-user.
+func flagOrEnv(cmd *cobra.Command, name string) string {
+	if flag := cmd.Flags().Lookup(name); flag != nil && flag.Changed {
+		return flag.Value.String()
+	}
+	return os.Getenv(envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_")))
+}
+
+// boolFlagOrEnv is like [flagOrEnv] for boolean flags, treating an unset value as false.
+func boolFlagOrEnv(cmd *cobra.Command, name string) (bool, error) {
+	value := flagOrEnv(cmd, name)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value %q for %s - %w", value, name, err)
+	}
+	return b, nil
+}
+
+// setCredentials sets the credentials and TLS options passed via flags or environment variables.
+//
+// Options left unset may still get set by the targets config.
+func setCredentials(cmd *cobra.Command, opts *dbms.DBOptions) error {
+	opts.Username = flagOrEnv(cmd, "db-user")
+	opts.Password = flagOrEnv(cmd, "db-password")
+	opts.Token = flagOrEnv(cmd, "db-token")
+
+	enableTLS, err := boolFlagOrEnv(cmd, "db-tls")
+	if err != nil {
+		return err
+	}
+	skipVerify, err := boolFlagOrEnv(cmd, "db-tls-skip-verify")
+	if err != nil {
+		return err
+	}
+	caFile := flagOrEnv(cmd, "db-tls-ca-file")
+
+	if enableTLS || skipVerify || caFile != "" {
+		opts.TLS = &dbms.TLSOptions{
+			CAFile:     caFile,
+			SkipVerify: skipVerify,
+		}
+	}
+	return nil
+}
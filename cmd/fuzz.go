@@ -30,7 +30,13 @@ Valid targets are:
     falkodb        - default port: 6379
     apache-age     - default port: 5432
     memgraph       - default port: 7687
+    kuzu           - embedded, requires building with -tags kuzu
+                     --db-host sets the database path (default: in-memory)
+    inmemory       - embedded reference engine written in Go, no database needed
     redisgraph     - default port: 6379 (DEPRECATED)
+    bolt           - default port: 7687
+                     Generic Bolt target, configured through its "bolt" entry in the targets config.
+                     Any other target in the targets config with such an entry can be fuzzed the same way.
 
 Valid strategies are:
     0 | NONE             (default)  - Generate random queries, hoping to trigger exceptions or crashes.
@@ -41,13 +47,20 @@ Valid strategies are:
                                       Then, generate a read query (1).
                                       Afterwards, generate a predicate Q. Verify, that the result
                                       of the queries with the predicates Q, NOT Q and Q IS NULL 
-                                      produce the same results as the original query (1).`,
-	Args: cobra.MatchAll(cobra.MinimumNArgs(1), cobra.MaximumNArgs(2), cobra.OnlyValidArgs),
+                                      produce the same results as the original query (1).
+    3 | PROFILE_CARDINALITY         - First, generate write clauses to populate the schema.
+                                      Then, run a read query under PROFILE. Verify, that the root
+                                      operator produced as many rows as were returned and that no
+                                      filter produced more rows than it received.
+                                      Supported by neo4j, memgraph and falkordb.`,
+	// Targets get validated against the targets config, as it may define additional bolt targets
+	Args: cobra.MatchAll(cobra.MinimumNArgs(1), cobra.MaximumNArgs(2)),
 	ValidArgs: []string{
-		"neo4j", "redisgraph", "falkordb", "memgraph", "apache-age",
+		"neo4j", "redisgraph", "falkordb", "memgraph", "apache-age", "kuzu", "inmemory", "bolt",
 		"0", "NONE", "none",
 		"1", "EQUIVALENCE_TRANSFORMATION", "equivalence_transformation",
 		"2", "PREDICATE_PARTITIONING", "predicate_partitioning",
+		"3", "PROFILE_CARDINALITY", "profile_cardinality",
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		conf, err := config.GetConfigForTarget(args[0], targetConfigPath)
@@ -66,6 +79,8 @@ Valid strategies are:
 				conf.TargetStrategy = strategy.EquivalenceTransformation
 			case "2", "predicate_partitioning":
 				conf.TargetStrategy = strategy.PredicatePartitioning
+			case "3", "profile_cardinality":
+				conf.TargetStrategy = strategy.ProfileCardinality
 			default:
 				fmt.Printf("Failed to initialize fuzzer - invalid strategy\n\n%s", cmd.Long)
 				os.Exit(1)
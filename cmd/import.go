@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/Anon10214/dinkel/cmd/config"
+	"github.com/Anon10214/dinkel/models/opencypher/parser"
+	"github.com/Anon10214/dinkel/scheduler"
+	"github.com/Anon10214/dinkel/scheduler/strategy"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var importNoBugreport bool
+
+var importCmd = &cobra.Command{
+	Use:   "import target file",
+	Short: "Run Cypher queries from a file against a target",
+	Args:  cobra.ExactArgs(2),
+	Long: `Parse the Cypher queries in a file, separated by semicolons, and run them against a target.
+
+This allows running queries which weren't generated by dinkel, such as ones from bug trackers or regression suites.
+If the queries trigger a bug, a bugreport holding their AST gets created, which can then be reduced
+using dinkel reduce or regenerated using dinkel regenerate, just like bugreports of generated queries.
+
+The queries are run without a strategy, so only exceptions and crashes are detected.
+Schema commands, such as CREATE INDEX, are run as is but cannot be reduced.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		query, err := os.ReadFile(args[1])
+		if err != nil {
+			logrus.Errorf("Failed to read queries - %v", err)
+			os.Exit(1)
+		}
+		ast, err := parser.Parse(string(query))
+		if err != nil {
+			logrus.Errorf("Failed to parse queries in %s - %v", args[1], err)
+			os.Exit(1)
+		}
+
+		conf, err := config.GetConfigForTarget(args[0], targetConfigPath)
+		if err != nil {
+			logrus.Errorf("Failed to get config for target %s - %v", args[0], err)
+			os.Exit(1)
+		}
+		conf.TargetStrategy = strategy.None
+		conf.Strategy = conf.TargetStrategy.ToStrategy()
+		conf.QueryLimit = 1
+		conf.AST = ast
+		conf.SuppressBugreport = importNoBugreport
+		conf.DisableKeybinds = true
+
+		logrus.Infof("Running %d imported statements from %s against target %s", len(ast), args[1], args[0])
+
+		if err := scheduler.Run(conf); err != nil {
+			logrus.Errorf("Scheduler failed with: %v", err)
+		} else {
+			logrus.Infoln("Scheduler terminated without error")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().BoolVarP(&importNoBugreport, "no-bugreport", "n", false, "Don't create a bugreport if a bug is triggered")
+}
@@ -19,7 +19,7 @@ var reduceCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	Long: `Reduce a generated bugreport's queries.
 
-The associated byte string has to generate the associated query.
+The bugreport's AST, or the associated byte string if the bugreport holds no AST, has to generate the associated query.
 This command then reduces the queries according to their strategy.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		bugreport, err := config.ReadBugreport(args[0])
@@ -36,6 +36,9 @@ This command then reduces the queries according to their strategy.`,
 		conf.Strategy = bugreport.Strategy
 		conf.TargetStrategy = bugreport.StrategyNum
 		conf.ByteString = bugreport.ByteString
+		if conf.AST, err = bugreport.LoadAST(conf.Implementation); err != nil {
+			logrus.Warnf("Couldn't load the bugreport's AST, regenerating its query from the byte string instead - %v", err)
+		}
 		conf.BugReportsDirectory, _ = path.Split(bugreport.FilePath)
 
 		reducedReportName := bugreport.ReportName + "_reduced"
@@ -20,6 +20,7 @@ var regenerateCmd = &cobra.Command{
 	Long: `This command allows you to regenerate a query from a bug report.
 
 By passing a generated bug report, this command reads in the associated byte string, uses it to regenerates the query and runs it.
+If the bug report holds the AST of its query, the query gets regenerated from the AST instead, which still works after changes to dinkel's query generation.
 This is useful for debugging dinkel, for rerunning just the query from a bugreport, check dinkel rerun.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		bugreport, err := config.ReadBugreport(args[0])
@@ -37,6 +38,9 @@ This is useful for debugging dinkel, for rerunning just the query from a bugrepo
 		conf.TargetStrategy = bugreport.StrategyNum
 		conf.QueryLimit = 1
 		conf.ByteString = bugreport.ByteString
+		if conf.AST, err = bugreport.LoadAST(conf.Implementation); err != nil {
+			logrus.Warnf("Couldn't load the bugreport's AST, regenerating its query from the byte string instead - %v", err)
+		}
 		conf.BugReportsDirectory, _ = path.Split(bugreport.FilePath)
 		conf.SuppressBugreport = !regenerateBugreport
 		conf.DisableKeybinds = true
@@ -6,6 +6,7 @@ import (
 
 	"github.com/Anon10214/dinkel/cmd/config"
 	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
 	"github.com/Anon10214/dinkel/scheduler"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -90,7 +91,8 @@ func Rerun(bugreport *config.BugReport, conf scheduler.Config) (dbms.QueryResult
 		statementIndex++
 		logrus.Infof("Rerunning statement #%d/%d", statementIndex, len(bugreport.Query))
 		logrus.Debugf("Rerunning statement %s", statement)
-		res, err := scheduler.RunQuery(conf, statement)
+		// Bug reports don't record how the rows of their statements are ordered, compare them regardless of their order
+		res, err := scheduler.RunQuery(conf, statement, schema.Unordered, 0)
 		lastRes = res
 		return res, err
 	})
@@ -30,12 +30,20 @@ var rootCmd = &cobra.Command{
 			dbPort = &port
 		}
 
+		dbOptions := dbms.DBOptions{
+			Host:    connectionString,
+			Port:    dbPort,
+			Timeout: time.Duration(dbTimeoutSeconds * float64(time.Second)),
+			// Left empty, the target's default namespace or the one in the targets config is used
+			Namespace: flagOrEnv(cmd, "db-namespace"),
+		}
+		if err := setCredentials(cmd, &dbOptions); err != nil {
+			logrus.Errorf("Failed to set DB credentials %v", err)
+			os.Exit(1)
+		}
+
 		config.SetDefaultConfig(scheduler.Config{
-			DBOptions: dbms.DBOptions{
-				Host:    connectionString,
-				Port:    dbPort,
-				Timeout: time.Duration(dbTimeoutSeconds * float64(time.Second)),
-			},
+			DBOptions:                 dbOptions,
 			BugReportsDirectory:       bugreportsDirectory,
 			DBConnectionRetries:       dbConnectionRetries,
 			DBConnectionRetryInterval: time.Duration(dbConnectionRetryInterval) * time.Second,
@@ -123,6 +131,13 @@ func init() {
 	rootCmd.PersistentFlags().Float64VarP(&dbTimeoutSeconds, "timeout", "t", 15, "How long until DB requests are considered timed out in seconds")
 	rootCmd.PersistentFlags().IntVar(&dbConnectionRetries, "db-connection-retries", 3, "How many times to retry connecting to DB before giving up, or -1 if infinite")
 	rootCmd.PersistentFlags().IntVar(&dbConnectionRetryInterval, "db-connection-interval", 15, "How many seconds to wait before retrying to connect to DB")
+	rootCmd.PersistentFlags().String("db-namespace", "", "The database or graph to fuzz, only this namespace gets reset (env: "+envPrefix+"DB_NAMESPACE)")
+	rootCmd.PersistentFlags().String("db-user", "", "The username to authenticate with (env: "+envPrefix+"DB_USER)")
+	rootCmd.PersistentFlags().String("db-password", "", "The password to authenticate with (env: "+envPrefix+"DB_PASSWORD)")
+	rootCmd.PersistentFlags().String("db-token", "", "The token to authenticate with, takes precedence over the username and password (env: "+envPrefix+"DB_TOKEN)")
+	rootCmd.PersistentFlags().Bool("db-tls", false, "Connect to the database using TLS (env: "+envPrefix+"DB_TLS)")
+	rootCmd.PersistentFlags().String("db-tls-ca-file", "", "The PEM file holding the CA certificates to trust, implies --db-tls (env: "+envPrefix+"DB_TLS_CA_FILE)")
+	rootCmd.PersistentFlags().Bool("db-tls-skip-verify", false, "Don't verify the database's certificate, implies --db-tls (env: "+envPrefix+"DB_TLS_SKIP_VERIFY)")
 	rootCmd.PersistentFlags().StringVar(&bugreportsDirectory, "bugreports", "bugreports", "Where bugreports are to be stored")
 	rootCmd.PersistentFlags().StringVarP(&targetConfigPath, "target-config", "c", "targets-config.yml", "The path to the target config")
 }
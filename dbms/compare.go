@@ -0,0 +1,373 @@
+package dbms
+
+import (
+	"math"
+	"slices"
+
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/sirupsen/logrus"
+)
+
+// A Comparator compares the values returned by queries.
+//
+// Node and relationship IDs are never compared, as they aren't stable between runs.
+// Values of different types never match, e.g. the integer 1 doesn't match the float 1.0.
+//
+// Floats match if they differ by at most the absolute or the relative tolerance.
+// Infinities only match infinities of the same sign, NaN only matches NaN if NaNEqualsNaN is set.
+type Comparator struct {
+	// The maximum difference between two floats relative to the larger one for them to match
+	RelativeTolerance float64
+	// The maximum absolute difference between two floats for them to match, catching results close to zero
+	AbsoluteTolerance float64
+	// Whether NaN matches NaN
+	NaNEqualsNaN bool
+	// Whether -0.0 doesn't match 0.0
+	SignedZeros bool
+	// Whether lists match regardless of the order of their elements
+	UnorderedLists bool
+}
+
+// DefaultComparator is the comparator used by the drivers.
+// Floats must be equal, NaN matches NaN, -0.0 matches 0.0 and lists must hold their elements in the same order.
+var DefaultComparator = Comparator{NaNEqualsNaN: true}
+
+// EqualResults returns true if the two passed query results hold the same information, else false.
+//
+// The results match if their rows, update counters, graphs and produced errors match,
+// graphs are compared up to isomorphism. Update counters are only compared if both results hold them.
+// Rows are compared in order if both results are totally ordered, otherwise regardless of their order.
+// If the comparator tolerates inaccurate floats, rows holding floats are always compared regardless of their order.
+// If either result's rows got cut by SKIP or LIMIT after being ordered by ties, see [Comparator.EqualTruncatedTies].
+// Mismatches get logged.
+func (c Comparator) EqualResults(a, b QueryResult) bool {
+	if len(a.Rows) != len(b.Rows) {
+		logrus.Warn("Encountered mismatching results")
+		logrus.Infof("\n\t%v\nvs\n\t%v", a.Rows, b.Rows)
+		return false
+	}
+
+	inOrder := a.Order == schema.TotallyOrdered && b.Order == schema.TotallyOrdered
+	if inOrder && c.ToleratesFloats() && (holdFloats(a.Rows) || holdFloats(b.Rows)) {
+		// Rows ordered by floats within the tolerance of each other may be swapped
+		inOrder = false
+	}
+
+	// Check that result rows match
+	switch {
+	case a.Order == schema.TruncatedTies || b.Order == schema.TruncatedTies:
+		if !c.EqualTruncatedTies(a.Rows, b.Rows, truncatedSortColumns(a, b)) {
+			logrus.Warn("Encountered mismatching rows cut through ties")
+			logrus.Infof("\n\t%v\nvs\n\t%v", a.Rows, b.Rows)
+			return false
+		}
+	case inOrder:
+		if !slices.EqualFunc(a.Rows, b.Rows, c.equalRows) {
+			logrus.Warn("Encountered mismatching or misordered rows")
+			logrus.Infof("\n\t%v\nvs\n\t%v", a.Rows, b.Rows)
+			return false
+		}
+	default:
+		if !c.EqualRows(a.Rows, b.Rows) {
+			logrus.Warn("Encountered mismatching rows")
+			logrus.Infof("\n\t%v\nvs\n\t%v", a.Rows, b.Rows)
+			return false
+		}
+	}
+
+	// Check that both queries changed the graph alike
+	if a.Counters != nil && b.Counters != nil && *a.Counters != *b.Counters {
+		logrus.Warn("Encountered mismatching update counters")
+		logrus.Infof("\n\t%v\nvs\n\t%v", a.Counters, b.Counters)
+		return false
+	}
+
+	// Check if the graphs match
+	if !c.EqualGraphs(a.Graph, b.Graph) {
+		logrus.Warnf("Mismatching graphs")
+		logrus.Infof("Graphs:\n\t%+v\nvs\n\t%+v", a.Graph, b.Graph)
+		return false
+	}
+
+	if a.ProducedError != nil || b.ProducedError != nil {
+		if a.ProducedError == nil || b.ProducedError == nil {
+			return false
+		}
+		if a.ProducedError.Error() != b.ProducedError.Error() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// EqualRows returns true if both passed slices hold matching rows, regardless of their order.
+func (c Comparator) EqualRows(a, b []Row) bool {
+	if (a == nil) != (b == nil) {
+		return false
+	}
+	return matchUnordered(a, b, c.equalRows, !c.ToleratesFloats())
+}
+
+// EqualTruncatedTies returns true if both passed slices hold matching rows ordered by the passed amount of leading columns,
+// which got cut by SKIP or LIMIT.
+//
+// Rows tying in their sort columns form a group. Groups have to appear in the same order and hold matching rows,
+// except the last one, which may be cut through and is only compared by its sort columns.
+// If the amount of sort columns is unknown, passed as 0, only the amount of rows is compared.
+func (c Comparator) EqualTruncatedTies(a, b []Row, sortColumns int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	if sortColumns == 0 {
+		return true
+	}
+	sortKey := func(row Row) Row { return row[:min(sortColumns, len(row))] }
+	for start := 0; start < len(a); {
+		end := start + 1
+		for end < len(a) && c.equalRows(sortKey(a[start]), sortKey(a[end])) {
+			end++
+		}
+		for _, row := range b[start:end] {
+			if !c.equalRows(sortKey(a[start]), sortKey(row)) {
+				return false
+			}
+		}
+		if end < len(a) && !c.EqualRows(a[start:end], b[start:end]) {
+			return false
+		}
+		start = end
+	}
+	return true
+}
+
+// truncatedSortColumns returns the amount of sort columns of the rows cut through ties, 0 if unknown.
+func truncatedSortColumns(a, b QueryResult) int {
+	switch {
+	case a.Order != schema.TruncatedTies:
+		return b.SortColumns
+	case b.Order != schema.TruncatedTies:
+		return a.SortColumns
+	}
+	return min(a.SortColumns, b.SortColumns)
+}
+
+// Equal returns true if the two passed values match, else false.
+func (c Comparator) Equal(a, b Value) bool {
+	switch a := a.(type) {
+	case nil, Null:
+		switch b.(type) {
+		case nil, Null:
+			return true
+		}
+		return false
+	case Float:
+		b, ok := b.(Float)
+		return ok && c.equalFloats(float64(a), float64(b))
+	case List:
+		b, ok := b.(List)
+		if !ok {
+			return false
+		}
+		if c.UnorderedLists {
+			return matchUnordered(a, b, c.Equal, !c.ToleratesFloats())
+		}
+		return c.equalSlices(a, b)
+	case Map:
+		b, ok := b.(Map)
+		return ok && c.equalMaps(a, b)
+	case Node:
+		b, ok := b.(Node)
+		return ok && c.equalNodes(a, b)
+	case Relationship:
+		b, ok := b.(Relationship)
+		return ok && c.equalRelationships(a, b)
+	case Path:
+		b, ok := b.(Path)
+		return ok && slices.EqualFunc(a.Nodes, b.Nodes, c.equalNodes) &&
+			slices.EqualFunc(a.Relationships, b.Relationships, c.equalRelationships)
+	case Date:
+		b, ok := b.(Date)
+		return ok && a.Equal(b.Time)
+	case LocalTime:
+		b, ok := b.(LocalTime)
+		return ok && a.Equal(b.Time)
+	case Time:
+		b, ok := b.(Time)
+		return ok && a.Equal(b.Time)
+	case LocalDateTime:
+		b, ok := b.(LocalDateTime)
+		return ok && a.Equal(b.Time)
+	case DateTime:
+		b, ok := b.(DateTime)
+		return ok && a.Equal(b.Time)
+	case Point:
+		b, ok := b.(Point)
+		return ok && a.SRID == b.SRID && slices.EqualFunc(a.Coordinates, b.Coordinates, c.equalFloats)
+	default:
+		// Bool, Int, String and Duration are comparable
+		return a == b
+	}
+}
+
+// ToleratesFloats returns true if the comparator matches floats that aren't equal.
+func (c Comparator) ToleratesFloats() bool {
+	return c.RelativeTolerance > 0 || c.AbsoluteTolerance > 0
+}
+
+func (c Comparator) equalFloats(a, b float64) bool {
+	switch {
+	case math.IsNaN(a) || math.IsNaN(b):
+		return c.NaNEqualsNaN && math.IsNaN(a) && math.IsNaN(b)
+	case math.IsInf(a, 0) || math.IsInf(b, 0):
+		// No finite float is close to an infinity, no matter the tolerance
+		return a == b
+	case a == 0 && b == 0:
+		return !c.SignedZeros || math.Signbit(a) == math.Signbit(b)
+	}
+	// The difference of two large floats may overflow to infinity, which is never within tolerance
+	diff := math.Abs(a - b)
+	return diff <= c.AbsoluteTolerance || diff <= c.RelativeTolerance*math.Max(math.Abs(a), math.Abs(b))
+}
+
+func (c Comparator) equalSlices(a, b []Value) bool {
+	return slices.EqualFunc(a, b, c.Equal)
+}
+
+func (c Comparator) equalRows(a, b Row) bool {
+	return c.equalSlices(a, b)
+}
+
+func (c Comparator) equalMaps(a, b Map) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		other, found := b[k]
+		if !found || !c.Equal(v, other) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Comparator) equalNodes(a, b Node) bool {
+	// Labels are a set, their order carries no meaning
+	if len(a.Labels) != len(b.Labels) {
+		return false
+	}
+	aLabels, bLabels := slices.Clone(a.Labels), slices.Clone(b.Labels)
+	slices.Sort(aLabels)
+	slices.Sort(bLabels)
+	return slices.Equal(aLabels, bLabels) && c.equalMaps(a.Properties, b.Properties)
+}
+
+func (c Comparator) equalRelationships(a, b Relationship) bool {
+	return a.Type == b.Type && c.equalMaps(a.Properties, b.Properties)
+}
+
+// holdFloats returns true if any of the passed rows holds a float, possibly nested in another value.
+func holdFloats(rows []Row) bool {
+	return slices.ContainsFunc(rows, func(row Row) bool { return slices.ContainsFunc(row, holdsFloat) })
+}
+
+func holdsFloat(v Value) bool {
+	switch v := v.(type) {
+	case Float, Point:
+		return true
+	case List:
+		return slices.ContainsFunc(v, holdsFloat)
+	case Map:
+		for _, value := range v {
+			if holdsFloat(value) {
+				return true
+			}
+		}
+	case Node:
+		return holdsFloat(v.Properties)
+	case Relationship:
+		return holdsFloat(v.Properties)
+	case Path:
+		for _, node := range v.Nodes {
+			if holdsFloat(node) {
+				return true
+			}
+		}
+		for _, relationship := range v.Relationships {
+			if holdsFloat(relationship) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchUnordered returns true if every element of a matches a distinct element of b.
+//
+// If equal is transitive, matching each element of a to the first unmatched element of b it equals suffices.
+// Otherwise, as is the case when tolerating inaccurate floats, an element of a may take the only match of another one,
+// so a maximum bipartite matching is searched for.
+func matchUnordered[T any](a, b []T, equal func(T, T) bool, transitive bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	// Try matching greedily first, succeeding is conclusive either way
+	remaining := slices.Clone(b)
+	greedy := true
+	for _, x := range a {
+		matchedIndex := slices.IndexFunc(remaining, func(y T) bool { return equal(x, y) })
+		if matchedIndex == -1 {
+			greedy = false
+			break
+		}
+		remaining = slices.Delete(remaining, matchedIndex, matchedIndex+1)
+	}
+	if greedy || transitive {
+		return greedy
+	}
+	return matchBipartite(a, b, equal)
+}
+
+// matchBipartite returns true if there is a perfect matching between a and b, using augmenting paths.
+func matchBipartite[T any](a, b []T, equal func(T, T) bool) bool {
+	// Memoize comparisons, 0 if not compared yet, 1 if equal and -1 if not
+	comparisons := make([][]int8, len(a))
+	for i := range comparisons {
+		comparisons[i] = make([]int8, len(b))
+	}
+	isEqual := func(i, j int) bool {
+		if comparisons[i][j] == 0 {
+			comparisons[i][j] = -1
+			if equal(a[i], b[j]) {
+				comparisons[i][j] = 1
+			}
+		}
+		return comparisons[i][j] == 1
+	}
+
+	// The index of the element of a each element of b is matched to, -1 if unmatched
+	matchedTo := make([]int, len(b))
+	for j := range matchedTo {
+		matchedTo[j] = -1
+	}
+	var augment func(i int, visited []bool) bool
+	augment = func(i int, visited []bool) bool {
+		for j := range b {
+			if visited[j] || !isEqual(i, j) {
+				continue
+			}
+			visited[j] = true
+			if matchedTo[j] == -1 || augment(matchedTo[j], visited) {
+				matchedTo[j] = i
+				return true
+			}
+		}
+		return false
+	}
+	for i := range a {
+		if !augment(i, make([]bool, len(b))) {
+			return false
+		}
+	}
+	return true
+}
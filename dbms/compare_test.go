@@ -0,0 +1,165 @@
+package dbms
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEqual(t *testing.T) {
+	nan := Float(math.NaN())
+	testCases := []struct {
+		name       string
+		comparator Comparator
+		a, b       Value
+		expected   bool
+	}{
+		{"Null matches nil", DefaultComparator, Null{}, nil, true},
+		{"Null doesn't match false", DefaultComparator, Null{}, Bool(false), false},
+		{"Int doesn't match Float", DefaultComparator, Int(1), Float(1), false},
+		{"Equal strings", DefaultComparator, String("a"), String("a"), true},
+		{"NaN matches NaN", DefaultComparator, nan, nan, true},
+		{"NaN doesn't match NaN", Comparator{}, nan, nan, false},
+		{"NaN doesn't match number", DefaultComparator, nan, Float(1), false},
+		{"Floats outside of tolerance", DefaultComparator, Float(1), Float(1 + 1e-12), false},
+		{"Floats within tolerance", Comparator{RelativeTolerance: 1e-9}, Float(1), Float(1 + 1e-12), true},
+		{"Tolerance is relative", Comparator{RelativeTolerance: 1e-9}, Float(1e-12), Float(2e-12), false},
+		{"Floats within absolute tolerance", Comparator{AbsoluteTolerance: 1e-9}, Float(1e-12), Float(2e-12), true},
+		{"Absolute tolerance doesn't scale", Comparator{AbsoluteTolerance: 1e-9}, Float(1e12), Float(1e12 + 1), false},
+		{"Infinity matches infinity", Comparator{RelativeTolerance: 1e-9}, Float(math.Inf(1)), Float(math.Inf(1)), true},
+		{"Infinities of different signs", Comparator{RelativeTolerance: 1}, Float(math.Inf(1)), Float(math.Inf(-1)), false},
+		{"Infinity isn't within tolerance", Comparator{RelativeTolerance: 1e-9}, Float(math.Inf(1)), Float(math.MaxFloat64), false},
+		{"Overflowing difference", Comparator{RelativeTolerance: 1e-9}, Float(math.MaxFloat64), Float(-math.MaxFloat64), false},
+		{"Negative zero matches zero", DefaultComparator, Float(math.Copysign(0, -1)), Float(0), true},
+		{"Signed zeros", Comparator{SignedZeros: true}, Float(math.Copysign(0, -1)), Float(0), false},
+		{"Signed negative zeros", Comparator{SignedZeros: true}, Float(math.Copysign(0, -1)), Float(math.Copysign(0, -1)), true},
+		{"Ordered lists", DefaultComparator, List{Int(1), Int(2)}, List{Int(2), Int(1)}, false},
+		{"Unordered lists", Comparator{UnorderedLists: true}, List{Int(1), Int(2)}, List{Int(2), Int(1)}, true},
+		{"Unordered lists with duplicates", Comparator{UnorderedLists: true}, List{Int(1), Int(1)}, List{Int(1), Int(2)}, false},
+		{"Maps", DefaultComparator, Map{"a": Int(1), "b": nan}, Map{"b": nan, "a": Int(1)}, true},
+		{"Maps with different keys", DefaultComparator, Map{"a": Int(1)}, Map{"b": Int(1)}, false},
+		{
+			"Node labels are unordered", DefaultComparator,
+			Node{Labels: []string{"A", "B"}, Properties: Map{"p": Int(1)}},
+			Node{Labels: []string{"B", "A"}, Properties: Map{"p": Int(1)}},
+			true,
+		},
+		{
+			"Nodes with different properties", DefaultComparator,
+			Node{Labels: []string{"A"}, Properties: Map{"p": Int(1)}},
+			Node{Labels: []string{"A"}, Properties: Map{"p": Int(2)}},
+			false,
+		},
+		{
+			"Relationships with different types", DefaultComparator,
+			Relationship{Type: "A"}, Relationship{Type: "B"},
+			false,
+		},
+		{
+			"Paths", DefaultComparator,
+			Path{Nodes: []Node{{Labels: []string{"A"}}, {}}, Relationships: []Relationship{{Type: "T"}}},
+			Path{Nodes: []Node{{Labels: []string{"A"}}, {}}, Relationships: []Relationship{{Type: "T"}}},
+			true,
+		},
+		{"Durations", DefaultComparator, Duration{Months: 1}, Duration{Days: 30}, false},
+		{"Points", DefaultComparator, Point{SRID: 7203, Coordinates: []float64{1, 2}}, Point{SRID: 7203, Coordinates: []float64{1, 2}}, true},
+		{"Points of different SRIDs", DefaultComparator, Point{SRID: 7203, Coordinates: []float64{1, 2}}, Point{SRID: 4326, Coordinates: []float64{1, 2}}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.comparator.Equal(tc.a, tc.b))
+			assert.Equal(t, tc.expected, tc.comparator.Equal(tc.b, tc.a), "Comparison not symmetric")
+		})
+	}
+}
+
+func TestEqualRows(t *testing.T) {
+	rows := []Row{{Int(1), String("a")}, {Int(2), String("b")}}
+
+	assert.True(t, DefaultComparator.EqualRows(rows, []Row{rows[1], rows[0]}), "Order of rows matters")
+	assert.False(t, DefaultComparator.EqualRows(rows, []Row{rows[0], rows[0]}), "Duplicate rows match distinct rows")
+	assert.False(t, DefaultComparator.EqualRows(rows, nil), "Rows match no rows")
+	assert.True(t, DefaultComparator.EqualRows(nil, nil))
+
+	// Greedily matching 1 with 1.25 fails, although 1 matches 1 and 1.5 matches 1.25
+	tolerant := Comparator{AbsoluteTolerance: 0.25}
+	a := []Row{{Float(1)}, {Float(1.5)}}
+	b := []Row{{Float(1.25)}, {Float(1)}}
+	assert.True(t, tolerant.EqualRows(a, b), "Rows matching within tolerance don't match")
+	assert.True(t, tolerant.EqualRows(b, a), "Rows matching within tolerance don't match")
+	assert.False(t, tolerant.EqualRows(a, []Row{{Float(1.25)}, {Float(2)}}))
+}
+
+func TestEqualResults(t *testing.T) {
+	res := QueryResult{Rows: []Row{{Int(1)}}, Graph: NewGraph()}
+	graph := NewGraph()
+	graph.AddNode("0", Node{})
+
+	assert.True(t, DefaultComparator.EqualResults(res, res))
+	assert.False(t, DefaultComparator.EqualResults(res, QueryResult{Rows: []Row{{Int(1)}}, Graph: NewGraph(), ProducedError: errors.New("error")}))
+	assert.False(t, DefaultComparator.EqualResults(res, QueryResult{Rows: []Row{{Int(1)}}, Graph: graph}))
+
+	// Update counters are only compared if both results hold them
+	created := res
+	created.Counters = &UpdateCounters{NodesCreated: 1}
+	assert.True(t, DefaultComparator.EqualResults(res, created))
+	assert.True(t, DefaultComparator.EqualResults(created, created))
+	assert.False(t, DefaultComparator.EqualResults(created, QueryResult{Rows: []Row{{Int(1)}}, Graph: NewGraph(), Counters: &UpdateCounters{}}))
+}
+
+func TestEqualResults_Order(t *testing.T) {
+	ascending := []Row{{Int(1)}, {Int(2)}}
+	descending := []Row{{Int(2)}, {Int(1)}}
+	otherRows := []Row{{Int(1)}, {Int(3)}}
+
+	testCases := []struct {
+		name     string
+		a, b     QueryResult
+		expected bool
+	}{
+		{"Unordered rows in different order", QueryResult{Rows: ascending}, QueryResult{Rows: descending}, true},
+		{"Ordered rows in different order", QueryResult{Rows: ascending, Order: schema.TotallyOrdered}, QueryResult{Rows: descending, Order: schema.TotallyOrdered}, false},
+		{"Ordered rows in same order", QueryResult{Rows: ascending, Order: schema.TotallyOrdered}, QueryResult{Rows: ascending, Order: schema.TotallyOrdered}, true},
+		{"Only one result ordered", QueryResult{Rows: ascending, Order: schema.TotallyOrdered}, QueryResult{Rows: descending}, true},
+		{"Truncated ties with different rows", QueryResult{Rows: ascending, Order: schema.TruncatedTies, SortColumns: 1}, QueryResult{Rows: otherRows, Order: schema.TruncatedTies, SortColumns: 1}, false},
+		{"Truncated ties with different amount of rows", QueryResult{Rows: ascending, Order: schema.TruncatedTies, SortColumns: 1}, QueryResult{Rows: ascending[:1], Order: schema.TruncatedTies, SortColumns: 1}, false},
+		{"Truncated ties by unknown sort keys", QueryResult{Rows: ascending, Order: schema.TruncatedTies}, QueryResult{Rows: otherRows, Order: schema.TruncatedTies}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, DefaultComparator.EqualResults(tc.a, tc.b))
+		})
+	}
+
+	// Rows tying before the cut have to be returned, in any order, only the last group of ties may differ
+	cut := []Row{{Int(1), String("a")}, {Int(1), String("b")}, {Int(2), String("c")}}
+	tied := QueryResult{Rows: cut, Order: schema.TruncatedTies, SortColumns: 1}
+	for _, tc := range []struct {
+		name     string
+		rows     []Row
+		expected bool
+	}{
+		{"Same rows", cut, true},
+		{"Swapped ties before the cut", []Row{{Int(1), String("b")}, {Int(1), String("a")}, {Int(2), String("c")}}, true},
+		{"Other row of the last group", []Row{{Int(1), String("a")}, {Int(1), String("b")}, {Int(2), String("x")}}, true},
+		{"Wrong row before the cut", []Row{{Int(1), String("a")}, {Int(1), String("x")}, {Int(2), String("c")}}, false},
+		{"Misordered groups", []Row{{Int(2), String("c")}, {Int(1), String("a")}, {Int(1), String("b")}}, false},
+		{"Wrong sort key in the last group", []Row{{Int(1), String("a")}, {Int(1), String("b")}, {Int(3), String("c")}}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, DefaultComparator.EqualResults(tied, QueryResult{Rows: tc.rows, Order: schema.TruncatedTies, SortColumns: 1}))
+		})
+	}
+
+	// Rows ordered by floats within the tolerance of each other may be swapped
+	tolerant := Comparator{AbsoluteTolerance: 1e-6}
+	a := QueryResult{Rows: []Row{{Float(1), String("b")}, {Float(1 + 1e-7), String("a")}}, Order: schema.TotallyOrdered}
+	b := QueryResult{Rows: []Row{{Float(1), String("a")}, {Float(1 + 1e-7), String("b")}}, Order: schema.TotallyOrdered}
+	assert.True(t, tolerant.EqualResults(a, b), "Rows ordered by tolerated floats must be compared regardless of their order")
+	assert.False(t, DefaultComparator.EqualResults(a, b))
+}
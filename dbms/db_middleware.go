@@ -2,6 +2,8 @@
 package dbms
 
 import (
+	"context"
+
 	"github.com/Anon10214/dinkel/models/opencypher/schema"
 	"github.com/Anon10214/dinkel/seed"
 )
@@ -32,7 +34,7 @@ type GetSchemaHandler func(DBOptions) (*schema.Schema, error)
 type InitHandler func(DBOptions) error
 type IsEqualResultHandler func(QueryResult, QueryResult) bool
 type ResetHandler func(DBOptions) error
-type RunQueryHandler func(DBOptions, string) QueryResult
+type RunQueryHandler func(context.Context, DBOptions, string) QueryResult
 type VerifyConnectivityHandler func(DBOptions) (bool, error)
 
 func (d *DBMiddleware) DiscardQuery(a0 QueryResult, a1 *seed.Seed) bool {
@@ -83,12 +85,12 @@ func (d *DBMiddleware) Reset(a0 DBOptions) error {
 	return fun(a0)
 }
 
-func (d *DBMiddleware) RunQuery(a0 DBOptions, a1 string) QueryResult {
+func (d *DBMiddleware) RunQuery(a0 context.Context, a1 DBOptions, a2 string) QueryResult {
 	fun := d.wrapped.RunQuery
 	if d.RunQueryMiddleware != nil {
 		fun = d.RunQueryMiddleware(fun)
 	}
-	return fun(a0, a1)
+	return fun(a0, a1, a2)
 }
 
 func (d *DBMiddleware) VerifyConnectivity(a0 DBOptions) (bool, error) {
@@ -2,7 +2,15 @@
 package dbms
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
 	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/Anon10214/dinkel/models/opencypher/schema"
@@ -14,13 +22,86 @@ type QueryResult struct {
 	// The type this query result indicates
 	Type QueryResultType
 	// Returned rows
-	Rows []any
+	Rows []Row
+	// The names of the returned columns, nil if the target doesn't report them
+	Columns []string
+	// The changes the query made to the graph, nil if the target doesn't report them
+	Counters *UpdateCounters
+	// How the returned rows are ordered, set by the scheduler from the generated statement
+	Order schema.RowOrder
+	// The amount of leading columns the rows are ordered by if they are ordered by [schema.TruncatedTies], 0 if unknown
+	SortColumns int
 	// The error as returned by the driver
 	ProducedError error
 	// The fingerprint of the graph, used for comparing if results changed
 	Fingerprint string
-	// The DB schema
-	Schema any
+	// The DB's graph after running the query, compared by strategies fuzzing for logic bugs
+	Graph *Graph
+	// The profiled query plan.
+	// Only set if the query was prefixed with [ProfilePrefix] and the target supports profiling.
+	Profile *ProfiledOperator
+	// An excerpt of the DB's server log, captured after the query crashed the DB
+	// or if the log indicated a bug, see [LogWatcher].
+	// Only set by drivers with access to the server log or if a log watcher is configured.
+	LogExcerpt string
+}
+
+// ProfilePrefix is prepended to a query to request its profiled plan from the target.
+const ProfilePrefix = "PROFILE "
+
+// A ProfiledOperator is an operator of a profiled query plan.
+type ProfiledOperator struct {
+	// The name of the operator, stripped of any target specific details
+	Name string
+	// The amount of rows the operator produced
+	Rows int64
+	// The operators this operator receives its rows from
+	Children []*ProfiledOperator
+	// The amount of rows returned by the profiled query, only set on the root operator.
+	// Targets only returning the plan of profiled queries count them by running the query again without profiling it.
+	ReturnedRows int64
+}
+
+// UpdateCounters count the changes a query made to the graph, as reported by the target.
+//
+// Changes to indexes and constraints aren't counted, as they don't affect the graph.
+type UpdateCounters struct {
+	NodesCreated         int
+	NodesDeleted         int
+	RelationshipsCreated int
+	RelationshipsDeleted int
+	PropertiesSet        int
+	LabelsAdded          int
+	LabelsRemoved        int
+}
+
+// String returns the non-zero counters, e.g. "1 node created, 2 properties set".
+func (c UpdateCounters) String() string {
+	var counts []string
+	for _, count := range []struct {
+		n                int
+		singular, plural string
+	}{
+		{c.NodesCreated, "node created", "nodes created"},
+		{c.NodesDeleted, "node deleted", "nodes deleted"},
+		{c.RelationshipsCreated, "relationship created", "relationships created"},
+		{c.RelationshipsDeleted, "relationship deleted", "relationships deleted"},
+		{c.PropertiesSet, "property set", "properties set"},
+		{c.LabelsAdded, "label added", "labels added"},
+		{c.LabelsRemoved, "label removed", "labels removed"},
+	} {
+		switch count.n {
+		case 0:
+		case 1:
+			counts = append(counts, "1 "+count.singular)
+		default:
+			counts = append(counts, fmt.Sprintf("%d %s", count.n, count.plural))
+		}
+	}
+	if len(counts) == 0 {
+		return "no updates"
+	}
+	return strings.Join(counts, ", ")
 }
 
 // A QueryResultType specifies what a query's result indicates to dictate how to classify the query
@@ -76,14 +157,98 @@ type DBOptions struct {
 	// Whether the driver should run in backwards compatible mode.
 	// This is used during bisection, where older versions may be tested and some features either disabled or adjusted
 	BackwardsCompatibleMode bool
+
+	// The username to authenticate with.
+	//
+	// If the username, password and token are empty, the driver should connect without authenticating.
+	Username string
+	// The password to authenticate with.
+	Password string
+	// The token to authenticate with.
+	// If set, it takes precedence over the username and password for DBMSs supporting tokens,
+	// others should use it as the password.
+	Token string
+	// How to secure the connection to the DB, TLS is disabled if nil.
+	TLS *TLSOptions
+
+	// The database or graph to run queries in.
+	// Resetting the DB must only affect this namespace, allowing multiple fuzzers to share a server.
+	//
+	// If empty, the driver uses its default namespace.
+	Namespace string
+}
+
+// TLSOptions specify how the connection to the DB is secured.
+type TLSOptions struct {
+	// The path to a PEM file holding the certificates of the CAs to trust.
+	// If empty, the system's certificate pool is used.
+	CAFile string
+	// Whether to skip verifying the DB's certificate.
+	SkipVerify bool
 }
 
-// ErrorMessageRegex holds regular expressions, matching different types of error messages
+// Config returns the TLS config to connect to the DB with.
+//
+// Returns nil if TLS is disabled, i.e. the options are nil.
+func (o *TLSOptions) Config() (*tls.Config, error) {
+	if o == nil {
+		return nil, nil
+	}
+	config := &tls.Config{InsecureSkipVerify: o.SkipVerify}
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, errors.Join(errors.New("failed to read the CA file - "), err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in the CA file %s", o.CAFile)
+		}
+	}
+	return config, nil
+}
+
+// ErrorMessageRegex holds regular expressions, matching different types of error messages,
+// as well as the status codes of these types of errors.
+//
+// Codes are robust against reworded messages, but only some targets return them.
 type ErrorMessageRegex struct {
-	// Ignored matches error messages that should be ignored
+	// Ignored matches error messages that should be ignored, nil if no messages should be ignored
 	Ignored *regexp.Regexp
-	// Reported matches error messages that indicate an already reported bug
+	// Reported matches error messages that indicate an already reported bug, nil if there are none
 	Reported *regexp.Regexp
+	// IgnoredCodes holds the status codes of errors that should be ignored
+	IgnoredCodes []string
+	// ReportedCodes holds the status codes of errors that indicate an already reported bug
+	ReportedCodes []string
+}
+
+// Classify returns the result type indicated by an error with the passed message and status codes,
+// e.g. an error's vendor code and its GQLSTATUS.
+//
+// Errors with an ignored message or code are [Invalid], errors with a reported message or code are [ReportedBug].
+// Returns false if the error is neither ignored nor reported. Empty codes are skipped.
+func (r *ErrorMessageRegex) Classify(msg string, codes ...string) (QueryResultType, bool) {
+	if matchesError(r.Ignored, r.IgnoredCodes, msg, codes) {
+		return Invalid, true
+	}
+	if matchesError(r.Reported, r.ReportedCodes, msg, codes) {
+		return ReportedBug, true
+	}
+	return None, false
+}
+
+// matchesError returns true if the message matches the regex or if one of the codes is part of the known codes.
+func matchesError(regex *regexp.Regexp, knownCodes []string, msg string, codes []string) bool {
+	if regex != nil && regex.MatchString(msg) {
+		return true
+	}
+	for _, code := range codes {
+		if code != "" && slices.Contains(knownCodes, code) {
+			return true
+		}
+	}
+	return false
 }
 
 // A DB is a database driver implementation.
@@ -98,8 +263,12 @@ type DB interface {
 	Reset(DBOptions) error
 	// Fetch the DB's schema
 	GetSchema(DBOptions) (*schema.Schema, error)
-	// Runs a given query against the database
-	RunQuery(DBOptions, string) QueryResult
+	// Runs a given query against the database.
+	//
+	// Once the context is done, the driver has to abort the query, killing it on the server if necessary,
+	// and return promptly, so the next query doesn't share its connection with a query still running.
+	// Drivers are never used concurrently, drivers failing to abort a query only get reinitialised once it returns.
+	RunQuery(context.Context, DBOptions, string) QueryResult
 	// Verifies that the database can still be reached.
 	//
 	// Returns true if connection is successful and an optional error describing the connection error.
@@ -0,0 +1,52 @@
+package dbms
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	regex := &ErrorMessageRegex{
+		Ignored:       regexp.MustCompile("^ignored$"),
+		Reported:      regexp.MustCompile("^reported$"),
+		IgnoredCodes:  []string{"22003"},
+		ReportedCodes: []string{"Neo.DatabaseError.Statement.ExecutionFailed"},
+	}
+
+	testCases := []struct {
+		name             string
+		msg              string
+		codes            []string
+		expected         QueryResultType
+		expectedMatching bool
+	}{
+		{"Ignored message", "ignored", nil, Invalid, true},
+		{"Reported message", "reported", nil, ReportedBug, true},
+		{"Ignored code", "reworded", []string{"Neo.ClientError.Statement.ArithmeticError", "22003"}, Invalid, true},
+		{"Reported code", "reworded", []string{"Neo.DatabaseError.Statement.ExecutionFailed", ""}, ReportedBug, true},
+		{"Ignored message with reported code", "ignored", []string{"Neo.DatabaseError.Statement.ExecutionFailed"}, Invalid, true},
+		{"Unknown error", "unknown", []string{"42000"}, None, false},
+		{"Empty code", "unknown", []string{""}, None, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resultType, matching := regex.Classify(tc.msg, tc.codes...)
+			assert.Equal(t, tc.expected, resultType)
+			assert.Equal(t, tc.expectedMatching, matching)
+		})
+	}
+
+	t.Run("Only codes configured", func(t *testing.T) {
+		_, matching := (&ErrorMessageRegex{IgnoredCodes: []string{"22003"}}).Classify("any message")
+		assert.False(t, matching)
+	})
+}
+
+func TestUpdateCountersString(t *testing.T) {
+	assert.Equal(t, "no updates", UpdateCounters{}.String())
+	assert.Equal(t, "1 node created, 2 properties set", UpdateCounters{NodesCreated: 1, PropertiesSet: 2}.String())
+	assert.Equal(t, "3 relationships deleted, 1 label removed", UpdateCounters{RelationshipsDeleted: 3, LabelsRemoved: 1}.String())
+}
@@ -0,0 +1,372 @@
+package dbms
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// A Graph is the state of the DB's graph, captured after running a query.
+//
+// Nodes and relationships are keyed by the IDs the DB assigned them, which are only used to connect them.
+// As IDs aren't stable between runs, a [Comparator] compares graphs up to isomorphism.
+type Graph struct {
+	Nodes         map[string]Node
+	Relationships map[string]GraphRelationship
+}
+
+// A GraphRelationship is a relationship of a [Graph], along with the IDs of the nodes it connects.
+type GraphRelationship struct {
+	Relationship
+	Start string
+	End   string
+}
+
+// NewGraph returns an empty graph.
+func NewGraph() *Graph {
+	return &Graph{Nodes: map[string]Node{}, Relationships: map[string]GraphRelationship{}}
+}
+
+// AddNode adds the node with the passed ID to the graph, replacing any node with the same ID.
+func (g *Graph) AddNode(id string, node Node) {
+	g.Nodes[id] = node
+}
+
+// AddRelationship adds the relationship with the passed ID, going from the start to the end node, to the graph.
+// Replaces any relationship with the same ID.
+func (g *Graph) AddRelationship(id, start, end string, relationship Relationship) {
+	g.Relationships[id] = GraphRelationship{Relationship: relationship, Start: start, End: end}
+}
+
+// The maximum amount of candidates tried when searching an isomorphism between two graphs.
+// Only reached by graphs with many alike nodes, whose nodes and relationships are then compared as multisets instead.
+var maxIsomorphismCandidates = 100_000
+
+// EqualGraphs returns true if the two passed graphs are isomorphic, else false.
+//
+// Nodes are matched to each other regardless of their IDs, matching nodes and the relationships between them
+// have to match according to the comparator. Returns true if both graphs are nil.
+func (c Comparator) EqualGraphs(a, b *Graph) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	ga, gb := newIndexedGraph(a), newIndexedGraph(b)
+	if len(ga.nodes) != len(gb.nodes) || len(a.Relationships) != len(b.Relationships) {
+		return false
+	}
+
+	// Refine the nodes' colors until they stop getting split up further.
+	// Isomorphic graphs have the same colors, as they are computed from hashes independent of IDs.
+	colorsA, colorsB := ga.initialColors(), gb.initialColors()
+	for {
+		if !slices.Equal(sortedColors(colorsA), sortedColors(colorsB)) {
+			return false
+		}
+		refinedA, refinedB := ga.refineColors(colorsA), gb.refineColors(colorsB)
+		if countColors(refinedA) == countColors(colorsA) && countColors(refinedB) == countColors(colorsB) {
+			break
+		}
+		colorsA, colorsB = refinedA, refinedB
+	}
+
+	m := isomorphismMatcher{
+		comparator: c,
+		a:          ga,
+		b:          gb,
+		colorsA:    colorsA,
+		colorsB:    colorsB,
+		mapping:    make([]int, len(ga.nodes)),
+		used:       make([]bool, len(gb.nodes)),
+	}
+	for i := range m.mapping {
+		m.mapping[i] = -1
+	}
+	// Match nodes of rare colors first, as they have the fewest candidates
+	colorCounts := map[uint64]int{}
+	for _, color := range colorsA {
+		colorCounts[color]++
+	}
+	m.order = make([]int, len(ga.nodes))
+	for i := range m.order {
+		m.order[i] = i
+	}
+	sort.SliceStable(m.order, func(i, j int) bool {
+		return colorCounts[colorsA[m.order[i]]] < colorCounts[colorsA[m.order[j]]]
+	})
+
+	equal := m.match(0)
+	if m.exhausted {
+		logrus.Warnf("Gave up searching an isomorphism between graphs after trying %d candidates, comparing their nodes and relationships instead", maxIsomorphismCandidates)
+		return m.equalMultisets()
+	}
+	return equal
+}
+
+// equalMultisets returns true if the nodes and relationships of both graphs match as multisets.
+//
+// Relationships are compared along with the colors of the nodes they connect, so only the way
+// alike nodes are connected to each other goes unchecked.
+func (m *isomorphismMatcher) equalMultisets() bool {
+	type coloredNode struct {
+		color uint64
+		node  Node
+	}
+	type coloredRelationship struct {
+		start, end   uint64
+		relationship Relationship
+	}
+	transitive := !m.comparator.ToleratesFloats()
+
+	nodes := func(g indexedGraph, colors []uint64) []coloredNode {
+		res := make([]coloredNode, len(g.nodes))
+		for i, node := range g.nodes {
+			res[i] = coloredNode{colors[i], node}
+		}
+		return res
+	}
+	equalNodes := func(x, y coloredNode) bool {
+		return x.color == y.color && m.comparator.equalNodes(x.node, y.node)
+	}
+	if !matchUnordered(nodes(m.a, m.colorsA), nodes(m.b, m.colorsB), equalNodes, transitive) {
+		return false
+	}
+
+	relationships := func(g indexedGraph, colors []uint64) []coloredRelationship {
+		var res []coloredRelationship
+		for key, rels := range g.between {
+			for _, rel := range rels {
+				res = append(res, coloredRelationship{colors[key[0]], colors[key[1]], rel})
+			}
+		}
+		return res
+	}
+	equalRelationships := func(x, y coloredRelationship) bool {
+		return x.start == y.start && x.end == y.end && m.comparator.equalRelationships(x.relationship, y.relationship)
+	}
+	return matchUnordered(relationships(m.a, m.colorsA), relationships(m.b, m.colorsB), equalRelationships, transitive)
+}
+
+// An indexedGraph holds a graph's nodes in a slice and its relationships grouped by the nodes they connect.
+type indexedGraph struct {
+	nodes []Node
+	// The relationships from one node to another, keyed by the nodes' indices
+	between map[[2]int][]Relationship
+	// The neighbors of every node, including the node itself for self-loops
+	neighbors [][]int
+}
+
+func newIndexedGraph(g *Graph) indexedGraph {
+	res := indexedGraph{between: map[[2]int][]Relationship{}}
+	indices := map[string]int{}
+	index := func(id string) int {
+		if i, ok := indices[id]; ok {
+			return i
+		}
+		indices[id] = len(res.nodes)
+		// Relationships may reference nodes the graph doesn't hold, e.g. if the DB captured them separately
+		res.nodes = append(res.nodes, g.Nodes[id])
+		res.neighbors = append(res.neighbors, nil)
+		return indices[id]
+	}
+
+	// Iterate in a deterministic order, so the matcher's work doesn't vary between runs
+	for _, id := range sortedKeys(g.Nodes) {
+		index(id)
+	}
+	for _, id := range sortedKeys(g.Relationships) {
+		rel := g.Relationships[id]
+		start, end := index(rel.Start), index(rel.End)
+		key := [2]int{start, end}
+		if len(res.between[key]) == 0 && len(res.between[[2]int{end, start}]) == 0 {
+			res.neighbors[start] = append(res.neighbors[start], end)
+			if start != end {
+				res.neighbors[end] = append(res.neighbors[end], start)
+			}
+		}
+		res.between[key] = append(res.between[key], rel.Relationship)
+	}
+	return res
+}
+
+// initialColors returns the hashes of the nodes' labels and properties.
+func (g indexedGraph) initialColors() []uint64 {
+	colors := make([]uint64, len(g.nodes))
+	for i, node := range g.nodes {
+		colors[i] = hashNode(node)
+	}
+	return colors
+}
+
+// refineColors returns the nodes' new colors, combining their color with the colors of their neighbors
+// and the relationships to them.
+func (g indexedGraph) refineColors(colors []uint64) []uint64 {
+	refined := make([]uint64, len(g.nodes))
+	for i := range g.nodes {
+		var signature []uint64
+		for _, j := range g.neighbors[i] {
+			for _, rel := range g.between[[2]int{i, j}] {
+				signature = append(signature, combineHashes(0, hashRelationship(rel), colors[j]))
+			}
+			if i == j {
+				continue
+			}
+			for _, rel := range g.between[[2]int{j, i}] {
+				signature = append(signature, combineHashes(1, hashRelationship(rel), colors[j]))
+			}
+		}
+		slices.Sort(signature)
+		refined[i] = combineHashes(append([]uint64{colors[i]}, signature...)...)
+	}
+	return refined
+}
+
+// isomorphismMatcher searches a mapping from the nodes of graph a to the nodes of graph b,
+// under which both graphs match.
+type isomorphismMatcher struct {
+	comparator       Comparator
+	a, b             indexedGraph
+	colorsA, colorsB []uint64
+	// The order in which the nodes of a are mapped
+	order []int
+	// The node of b each node of a is mapped to, -1 if it isn't mapped yet
+	mapping []int
+	// Whether a node of b was mapped to already
+	used       []bool
+	candidates int
+	exhausted  bool
+}
+
+// match maps the nodes starting at the passed position of the order, returning true if a mapping was found.
+func (m *isomorphismMatcher) match(pos int) bool {
+	if pos == len(m.order) {
+		return true
+	}
+	x := m.order[pos]
+	for y := range m.b.nodes {
+		if m.used[y] || m.colorsA[x] != m.colorsB[y] {
+			continue
+		}
+		if m.candidates++; m.candidates > maxIsomorphismCandidates {
+			m.exhausted = true
+			return false
+		}
+		if !m.comparator.equalNodes(m.a.nodes[x], m.b.nodes[y]) || !m.consistent(x, y) {
+			continue
+		}
+		m.mapping[x], m.used[y] = y, true
+		if m.match(pos + 1) {
+			return true
+		}
+		if m.exhausted {
+			return false
+		}
+		m.mapping[x], m.used[y] = -1, false
+	}
+	return false
+}
+
+// consistent returns true if the relationships between x and the mapped nodes of a
+// match the relationships between y and the nodes they are mapped to.
+func (m *isomorphismMatcher) consistent(x, y int) bool {
+	equal, transitive := m.comparator.equalRelationships, !m.comparator.ToleratesFloats()
+	if !matchUnordered(m.a.between[[2]int{x, x}], m.b.between[[2]int{y, y}], equal, transitive) {
+		return false
+	}
+	for _, neighbor := range m.a.neighbors[x] {
+		mapped := m.mapping[neighbor]
+		if mapped == -1 || neighbor == x {
+			continue
+		}
+		if !matchUnordered(m.a.between[[2]int{x, neighbor}], m.b.between[[2]int{y, mapped}], equal, transitive) ||
+			!matchUnordered(m.a.between[[2]int{neighbor, x}], m.b.between[[2]int{mapped, y}], equal, transitive) {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedColors(colors []uint64) []uint64 {
+	sorted := slices.Clone(colors)
+	slices.Sort(sorted)
+	return sorted
+}
+
+func countColors(colors []uint64) int {
+	return len(slices.Compact(sortedColors(colors)))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func combineHashes(hashes ...uint64) uint64 {
+	h := fnv.New64a()
+	for _, x := range hashes {
+		h.Write(binary.LittleEndian.AppendUint64(nil, x))
+	}
+	return h.Sum64()
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+func hashNode(n Node) uint64 {
+	labels := slices.Clone(n.Labels)
+	slices.Sort(labels)
+	hashes := []uint64{uint64(len(labels))}
+	for _, label := range labels {
+		hashes = append(hashes, hashString(label))
+	}
+	return combineHashes(append(hashes, hashValue(n.Properties))...)
+}
+
+func hashRelationship(r Relationship) uint64 {
+	return combineHashes(hashString(r.Type), hashValue(r.Properties))
+}
+
+// hashValue hashes the passed value, such that matching values have the same hash under any [Comparator].
+//
+// Floats and the contents of lists thus only contribute their type, as they may match despite differing.
+func hashValue(v Value) uint64 {
+	switch v := v.(type) {
+	case nil, Null:
+		return hashString("null")
+	case Bool:
+		if v {
+			return hashString("true")
+		}
+		return hashString("false")
+	case Int:
+		return combineHashes(hashString("int"), uint64(v))
+	case Float:
+		return hashString("float")
+	case String:
+		return combineHashes(hashString("string"), hashString(string(v)))
+	case List:
+		return combineHashes(hashString("list"), uint64(len(v)))
+	case Map:
+		hashes := []uint64{hashString("map")}
+		for _, key := range sortedKeys(v) {
+			hashes = append(hashes, hashString(key), hashValue(v[key]))
+		}
+		return combineHashes(hashes...)
+	case Point:
+		return combineHashes(hashString("point"), uint64(v.SRID), uint64(len(v.Coordinates)))
+	case Duration:
+		return combineHashes(hashString("duration"), uint64(v.Months), uint64(v.Days), uint64(v.Seconds), uint64(v.Nanos))
+	}
+	// Remaining values, such as temporal values, only contribute their type
+	return hashString(fmt.Sprintf("%T", v))
+}
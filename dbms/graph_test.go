@@ -0,0 +1,103 @@
+package dbms
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pathGraph returns a graph of nodes with the passed labels, each connected to the next one by a relationship.
+// The IDs of the nodes start at the passed offset.
+func pathGraph(offset int, labels ...string) *Graph {
+	g := NewGraph()
+	for i, label := range labels {
+		id := fmt.Sprint(offset + i)
+		g.AddNode(id, Node{Labels: []string{label}, Properties: Map{}})
+		if i > 0 {
+			g.AddRelationship(id, fmt.Sprint(offset+i-1), id, Relationship{Type: "T", Properties: Map{}})
+		}
+	}
+	return g
+}
+
+func TestEqualGraphs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		a, b     *Graph
+		expected bool
+	}{
+		{"Empty graphs", NewGraph(), NewGraph(), true},
+		{"Nil graphs", nil, nil, true},
+		{"Nil and empty graph", nil, NewGraph(), false},
+		{"Different IDs", pathGraph(0, "A", "B", "C"), pathGraph(10, "A", "B", "C"), true},
+		{"Reversed path", pathGraph(0, "A", "B", "C"), pathGraph(0, "C", "B", "A"), false},
+		{"Different labels", pathGraph(0, "A", "B", "C"), pathGraph(0, "A", "B", "B"), false},
+		{"Different sizes", pathGraph(0, "A", "B"), pathGraph(0, "A", "B", "C"), false},
+		{"Alike nodes", pathGraph(0, "A", "A", "A", "A"), pathGraph(5, "A", "A", "A", "A"), true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, DefaultComparator.EqualGraphs(tc.a, tc.b))
+		})
+	}
+}
+
+func TestEqualGraphs_Relationships(t *testing.T) {
+	// Two nodes connected by two relationships, either both pointing the same way or opposite ways
+	newGraph := func(secondStart, secondEnd string, weight Value) *Graph {
+		g := NewGraph()
+		g.AddNode("a", Node{Labels: []string{"N"}, Properties: Map{}})
+		g.AddNode("b", Node{Labels: []string{"N"}, Properties: Map{}})
+		g.AddRelationship("r1", "a", "b", Relationship{Type: "T", Properties: Map{}})
+		g.AddRelationship("r2", secondStart, secondEnd, Relationship{Type: "T", Properties: Map{"w": weight}})
+		return g
+	}
+
+	assert.True(t, DefaultComparator.EqualGraphs(newGraph("b", "a", Int(1)), newGraph("b", "a", Int(1)).swapIDs("a", "b")))
+	assert.False(t, DefaultComparator.EqualGraphs(newGraph("a", "b", Int(1)), newGraph("b", "a", Int(1))), "Direction of relationships is ignored")
+	assert.False(t, DefaultComparator.EqualGraphs(newGraph("a", "b", Int(1)), newGraph("a", "b", Int(2))), "Properties of relationships are ignored")
+	assert.False(t, DefaultComparator.EqualGraphs(newGraph("a", "a", Int(1)), newGraph("b", "b", Int(1))), "Self-loops match regardless of their node")
+
+	// Floats only match within the comparator's tolerance, they thus mustn't affect the graph's hashes
+	a, b := 0.1, 0.2
+	tolerant := Comparator{RelativeTolerance: 1e-9}
+	assert.True(t, tolerant.EqualGraphs(newGraph("a", "b", Float(0.3)), newGraph("a", "b", Float(a+b))))
+	assert.False(t, DefaultComparator.EqualGraphs(newGraph("a", "b", Float(0.3)), newGraph("a", "b", Float(a+b))))
+}
+
+func TestEqualGraphs_ExhaustedCandidates(t *testing.T) {
+	defer func(max int) { maxIsomorphismCandidates = max }(maxIsomorphismCandidates)
+	maxIsomorphismCandidates = 0
+
+	// Floats don't affect the nodes' colors, so only comparing the nodes tells these graphs apart
+	withWeight := func(g *Graph, id string, weight Value) *Graph {
+		g.Nodes[id] = Node{Labels: g.Nodes[id].Labels, Properties: Map{"w": weight}}
+		return g
+	}
+	assert.True(t, DefaultComparator.EqualGraphs(pathGraph(0, "A", "A", "A"), pathGraph(5, "A", "A", "A")))
+	assert.False(t, DefaultComparator.EqualGraphs(withWeight(pathGraph(0, "A", "A", "A"), "1", Float(1)), withWeight(pathGraph(0, "A", "A", "A"), "1", Float(2))))
+	assert.False(t, DefaultComparator.EqualGraphs(pathGraph(0, "A", "B", "C"), pathGraph(0, "C", "B", "A")))
+}
+
+// swapIDs returns the graph with the two node IDs swapped.
+func (g *Graph) swapIDs(x, y string) *Graph {
+	swap := func(id string) string {
+		switch id {
+		case x:
+			return y
+		case y:
+			return x
+		}
+		return id
+	}
+	res := NewGraph()
+	for id, node := range g.Nodes {
+		res.AddNode(swap(id), node)
+	}
+	for id, rel := range g.Relationships {
+		res.AddRelationship(id, swap(rel.Start), swap(rel.End), rel.Relationship)
+	}
+	return res
+}
@@ -0,0 +1,213 @@
+package dbms
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// A LogWatcher tails the DB's server log, detecting bugs the DB only logs,
+// such as sanitizer reports not killing the process or failed internal assertions.
+//
+// The log is read either from a file or from the output of a command, e.g. `docker logs -f`.
+// Watching starts on first use, only lines logged afterwards are scanned.
+// If the command exits, e.g. because the container got restarted, it gets restarted on the next use.
+type LogWatcher struct {
+	// The path of the log file to tail
+	File string
+	// The shell command printing the log, only used if File is empty.
+	// Both stdout and stderr of the command are scanned.
+	Command string
+	// Matches lines indicating a bug, nil if no lines indicate a bug
+	BugPattern *regexp.Regexp
+	// Matches lines indicating a crash, nil if no lines indicate a crash
+	CrashPattern *regexp.Regexp
+	// How many lines before the first and after the last matching line to include in the excerpt
+	ContextLines int
+	// How long to wait for the DB to write its log after a statement before scanning it
+	Delay time.Duration
+
+	mu      sync.Mutex
+	started bool
+	// The offset up to which the log file was read
+	offset int64
+	// Lines the command printed which weren't scanned yet
+	pending []string
+	cmd     *exec.Cmd
+	// Set once the command exited and all of its output got read, holding the error it exited with
+	exited  bool
+	exitErr error
+}
+
+// Skip discards all lines logged up until now, e.g. while resetting the DB.
+func (w *LogWatcher) Skip() error {
+	_, err := w.newLines()
+	return err
+}
+
+// Scan scans the lines logged since the last call to [LogWatcher.Scan] or [LogWatcher.Skip].
+//
+// Returns [Crash] if a line matches the crash pattern, else [Bug] if a line matches the bug pattern,
+// together with an excerpt of the log around the matching lines.
+// Returns [None] and an empty excerpt if no line matches.
+func (w *LogWatcher) Scan() (QueryResultType, string, error) {
+	time.Sleep(w.Delay)
+	lines, err := w.newLines()
+	if err != nil {
+		return None, "", err
+	}
+
+	resType := None
+	first, last := -1, -1
+	for i, line := range lines {
+		switch {
+		case w.CrashPattern != nil && w.CrashPattern.MatchString(line):
+			resType = Crash
+		case w.BugPattern != nil && w.BugPattern.MatchString(line):
+			if resType != Crash {
+				resType = Bug
+			}
+		default:
+			continue
+		}
+		if first == -1 {
+			first = i
+		}
+		last = i
+	}
+	if resType == None {
+		return None, "", nil
+	}
+
+	start := max(first-w.ContextLines, 0)
+	end := min(last+w.ContextLines+1, len(lines))
+	return resType, strings.Join(lines[start:end], "\n"), nil
+}
+
+// Close stops watching the log, terminating the command if one was started.
+func (w *LogWatcher) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.started = false
+	if w.cmd == nil {
+		return nil
+	}
+	err := w.cmd.Process.Kill()
+	w.cmd = nil
+	return err
+}
+
+// newLines returns the lines logged since it was last called, starting to watch the log if necessary.
+func (w *LogWatcher) newLines() ([]string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.started && w.exited {
+		logrus.Warnf("The log command exited, restarting it. Lines logged in the meantime weren't scanned - %v", w.exitErr)
+		w.started = false
+	}
+	if !w.started {
+		if err := w.start(); err != nil {
+			return nil, err
+		}
+		w.started = true
+	}
+
+	if w.File == "" {
+		lines := w.pending
+		w.pending = nil
+		return lines, nil
+	}
+	return w.readFile()
+}
+
+// start starts watching the log, skipping everything logged so far.
+func (w *LogWatcher) start() error {
+	if w.File != "" {
+		stat, err := os.Stat(w.File)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			w.offset = 0
+		case err != nil:
+			return errors.Join(errors.New("failed to stat the log file - "), err)
+		default:
+			w.offset = stat.Size()
+		}
+		return nil
+	}
+
+	if w.Command == "" {
+		return errors.New("neither a log file nor a log command is set")
+	}
+
+	r, pw := io.Pipe()
+	w.cmd = exec.Command("sh", "-c", w.Command)
+	w.cmd.Stdout = pw
+	w.cmd.Stderr = pw
+	if err := w.cmd.Start(); err != nil {
+		w.cmd = nil
+		return errors.Join(errors.New("failed to start the log command - "), err)
+	}
+	w.exited, w.exitErr = false, nil
+	go func(cmd *exec.Cmd) {
+		pw.CloseWithError(cmd.Wait())
+	}(w.cmd)
+	go func(cmd *exec.Cmd) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, 1024*1024)
+		for scanner.Scan() {
+			w.mu.Lock()
+			w.pending = append(w.pending, scanner.Text())
+			w.mu.Unlock()
+		}
+
+		// The pipe gets closed with the error the command exited with once it exited
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if w.cmd == cmd {
+			w.exited, w.exitErr = true, scanner.Err()
+		}
+	}(w.cmd)
+	return nil
+}
+
+// readFile reads the complete lines appended to the log file since it was last read.
+// Starts over if the file got truncated, e.g. because the DB got restarted.
+func (w *LogWatcher) readFile() ([]string, error) {
+	file, err := os.Open(w.File)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Join(errors.New("failed to open the log file - "), err)
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, errors.Join(errors.New("failed to stat the log file - "), err)
+	}
+	if stat.Size() < w.offset {
+		w.offset = 0
+	}
+
+	data, err := io.ReadAll(io.NewSectionReader(file, w.offset, stat.Size()-w.offset))
+	if err != nil {
+		return nil, errors.Join(errors.New("failed to read the log file - "), err)
+	}
+
+	// Leave incomplete lines for the next read
+	end := strings.LastIndexByte(string(data), '\n')
+	if end == -1 {
+		return nil, nil
+	}
+	w.offset += int64(end + 1)
+	return strings.Split(string(data[:end]), "\n"), nil
+}
@@ -0,0 +1,135 @@
+package dbms
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogWatcherFile(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "server.log")
+	require.NoError(t, os.WriteFile(logFile, []byte("==1==ERROR: AddressSanitizer: old report\n"), 0o644))
+
+	watcher := &LogWatcher{
+		File:         logFile,
+		BugPattern:   regexp.MustCompile("AddressSanitizer|Assertion .* failed"),
+		CrashPattern: regexp.MustCompile("Redis .* crashed by signal"),
+		ContextLines: 1,
+	}
+	defer watcher.Close()
+
+	appendLog := func(text string) {
+		file, err := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0o644)
+		require.NoError(t, err)
+		defer file.Close()
+		_, err = file.WriteString(text)
+		require.NoError(t, err)
+	}
+
+	// Lines logged before the watcher started are skipped
+	require.NoError(t, watcher.Skip())
+	resType, excerpt, err := watcher.Scan()
+	require.NoError(t, err)
+	assert.Equal(t, None, resType)
+	assert.Empty(t, excerpt)
+
+	t.Run("Bug", func(t *testing.T) {
+		appendLog("ready\nquery started\nAssertion `x' failed\nquery done\nidle\n")
+		resType, excerpt, err := watcher.Scan()
+		require.NoError(t, err)
+		assert.Equal(t, Bug, resType)
+		assert.Equal(t, "query started\nAssertion `x' failed\nquery done", excerpt)
+	})
+
+	t.Run("Crash takes precedence", func(t *testing.T) {
+		appendLog("==1==ERROR: AddressSanitizer: heap-use-after-free\n=== REDIS BUG REPORT START ===\nRedis 7.2.3 crashed by signal: 11\n")
+		resType, excerpt, err := watcher.Scan()
+		require.NoError(t, err)
+		assert.Equal(t, Crash, resType)
+		assert.Equal(t, "==1==ERROR: AddressSanitizer: heap-use-after-free\n=== REDIS BUG REPORT START ===\nRedis 7.2.3 crashed by signal: 11", excerpt)
+	})
+
+	t.Run("Skipped lines", func(t *testing.T) {
+		appendLog("Assertion `y' failed\n")
+		require.NoError(t, watcher.Skip())
+		resType, _, err := watcher.Scan()
+		require.NoError(t, err)
+		assert.Equal(t, None, resType)
+	})
+
+	t.Run("Incomplete line", func(t *testing.T) {
+		appendLog("Assertion `z'")
+		resType, _, err := watcher.Scan()
+		require.NoError(t, err)
+		assert.Equal(t, None, resType)
+
+		appendLog(" failed\n")
+		resType, excerpt, err := watcher.Scan()
+		require.NoError(t, err)
+		assert.Equal(t, Bug, resType)
+		assert.Equal(t, "Assertion `z' failed", excerpt)
+	})
+
+	t.Run("Truncated log", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(logFile, []byte("Assertion `w' failed\n"), 0o644))
+		resType, _, err := watcher.Scan()
+		require.NoError(t, err)
+		assert.Equal(t, Bug, resType)
+	})
+}
+
+func TestLogWatcherCommand(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "server.log")
+	require.NoError(t, os.WriteFile(logFile, nil, 0o644))
+
+	watcher := &LogWatcher{
+		Command:    "tail -n 0 -f " + logFile + " >&2",
+		BugPattern: regexp.MustCompile("unexpected error"),
+	}
+	defer watcher.Close()
+	require.NoError(t, watcher.Skip())
+
+	// tail may not be watching the file yet, so keep logging until the line shows up
+	assert.Eventually(t, func() bool {
+		file, err := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0o644)
+		require.NoError(t, err)
+		defer file.Close()
+		_, err = file.WriteString("unexpected error in query\n")
+		require.NoError(t, err)
+
+		resType, excerpt, err := watcher.Scan()
+		require.NoError(t, err)
+		return resType == Bug && excerpt != ""
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestLogWatcherCommandRestart(t *testing.T) {
+	startedFile := filepath.Join(t.TempDir(), "started")
+	// Each run of the command prints a line and exits
+	watcher := &LogWatcher{
+		Command:    "echo started >> " + startedFile + "; echo unexpected error",
+		BugPattern: regexp.MustCompile("unexpected error"),
+	}
+	defer watcher.Close()
+	require.NoError(t, watcher.Skip())
+
+	// Once the command exited, the next scan restarts it and the following one sees its output
+	assert.Eventually(t, func() bool {
+		_, _, err := watcher.Scan()
+		require.NoError(t, err)
+		started, err := os.ReadFile(startedFile)
+		require.NoError(t, err)
+		return strings.Count(string(started), "started") >= 2
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		resType, _, err := watcher.Scan()
+		require.NoError(t, err)
+		return resType == Bug
+	}, 5*time.Second, 50*time.Millisecond)
+}
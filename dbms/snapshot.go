@@ -0,0 +1,53 @@
+package dbms
+
+import "errors"
+
+// A Snapshotter is a DB able to snapshot its freshly reset state and restore it,
+// which is faster than resetting the DB before every query.
+//
+// DBs implementing this interface are restored instead of reset by the scheduler.
+// If restoring fails, the scheduler falls back to [DB.Reset] and takes a new snapshot.
+type Snapshotter interface {
+	// Snapshot the DB's current state, which was just reset
+	Snapshot(DBOptions) error
+	// Restore the DB to the state of its snapshot, keeping the snapshot for further restores.
+	//
+	// Returns [ErrNoSnapshot] if no snapshot was taken, e.g. because the DB got reset or reinitialised since.
+	Restore(DBOptions) error
+}
+
+// ErrNoSnapshot is returned when restoring a DB without a snapshot.
+var ErrNoSnapshot = errors.New("no snapshot was taken")
+
+// AsSnapshotter returns the passed DB as a [Snapshotter] if it supports snapshots.
+//
+// DBs wrapped in middleware are unwrapped, as the middleware doesn't implement [Snapshotter] itself.
+// Restoring a DB resets it, so restores still pass through the ResetMiddleware of the middleware wrapping the DB.
+func AsSnapshotter(db DB) (Snapshotter, bool) {
+	switch db := db.(type) {
+	case Snapshotter:
+		return db, true
+	case *DBMiddleware:
+		snapshotter, ok := AsSnapshotter(db.wrapped)
+		if !ok {
+			return nil, false
+		}
+		return middlewareSnapshotter{snapshotter, db}, true
+	}
+	return nil, false
+}
+
+// A middlewareSnapshotter is the [Snapshotter] of a DB wrapped in middleware.
+type middlewareSnapshotter struct {
+	Snapshotter
+	middleware *DBMiddleware
+}
+
+// Restore the wrapped DB, passing the restore through the middleware's ResetMiddleware.
+func (s middlewareSnapshotter) Restore(opts DBOptions) error {
+	fun := ResetHandler(s.Snapshotter.Restore)
+	if s.middleware.ResetMiddleware != nil {
+		fun = s.middleware.ResetMiddleware(fun)
+	}
+	return fun(opts)
+}
@@ -0,0 +1,51 @@
+package dbms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type plainDB struct{ DB }
+
+type snapshotDB struct{ DB }
+
+func (snapshotDB) Snapshot(DBOptions) error { return nil }
+func (snapshotDB) Restore(DBOptions) error  { return nil }
+
+func TestAsSnapshotter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		db       DB
+		expected bool
+	}{
+		{"Plain DB", plainDB{}, false},
+		{"Snapshot DB", snapshotDB{}, true},
+		{"Wrapped plain DB", WrapDB(plainDB{}, DBMiddleware{}), false},
+		{"Wrapped snapshot DB", WrapDB(WrapDB(snapshotDB{}, DBMiddleware{}), DBMiddleware{}), true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			snapshotter, ok := AsSnapshotter(tc.db)
+			assert.Equal(t, tc.expected, ok)
+			assert.Equal(t, tc.expected, snapshotter != nil)
+		})
+	}
+}
+
+func TestAsSnapshotter_Middleware(t *testing.T) {
+	var resets int
+	countResets := DBMiddleware{ResetMiddleware: func(next ResetHandler) ResetHandler {
+		return func(opts DBOptions) error {
+			resets++
+			return next(opts)
+		}
+	}}
+	snapshotter, ok := AsSnapshotter(WrapDB(WrapDB(snapshotDB{}, countResets), countResets))
+	if assert.True(t, ok) {
+		assert.NoError(t, snapshotter.Snapshot(DBOptions{}))
+		assert.NoError(t, snapshotter.Restore(DBOptions{}))
+		assert.Equal(t, 2, resets, "restores should pass through the reset middleware of every wrapper")
+	}
+}
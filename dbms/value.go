@@ -0,0 +1,159 @@
+package dbms
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// A Value is a value returned by a query.
+//
+// Drivers convert the values returned by their client libraries into Values,
+// allowing results to be compared by a single [Comparator], even across targets.
+type Value interface {
+	isValue()
+}
+
+// A Row is a single row returned by a query, holding one value per column.
+type Row []Value
+
+// Null is the null value
+type Null struct{}
+
+// Bool is a boolean value
+type Bool bool
+
+// Int is an integer value
+type Int int64
+
+// Float is a floating point value
+type Float float64
+
+// String is a string value
+type String string
+
+// List is a list of values
+type List []Value
+
+// Map maps keys to values
+type Map map[string]Value
+
+// Node is a graph node.
+// Its ID is not part of the value, as IDs aren't stable between runs.
+type Node struct {
+	Labels     []string
+	Properties Map
+}
+
+// Relationship is a graph relationship.
+// Its ID and the IDs of its start and end nodes are not part of the value, as IDs aren't stable between runs.
+type Relationship struct {
+	Type       string
+	Properties Map
+}
+
+// Path is a path through the graph, alternating between nodes and relationships.
+type Path struct {
+	Nodes         []Node
+	Relationships []Relationship
+}
+
+// Date is a calendar date, its time is midnight in UTC
+type Date struct{ time.Time }
+
+// LocalTime is a time of day without a time zone, its date is January 1st of year 0 in UTC
+type LocalTime struct{ time.Time }
+
+// Time is a time of day with a time zone offset, its date is January 1st of year 0
+type Time struct{ time.Time }
+
+// LocalDateTime is a date and time without a time zone, its location is UTC
+type LocalDateTime struct{ time.Time }
+
+// DateTime is a date and time with a time zone
+type DateTime struct{ time.Time }
+
+// Duration is a temporal amount, kept in its components as they aren't convertible into each other,
+// e.g. a month doesn't have a fixed amount of days.
+type Duration struct {
+	Months  int64
+	Days    int64
+	Seconds int64
+	Nanos   int64
+}
+
+// Point is a spatial point in the coordinate reference system identified by the SRID
+type Point struct {
+	SRID        uint32
+	Coordinates []float64
+}
+
+func (Null) isValue()          {}
+func (Bool) isValue()          {}
+func (Int) isValue()           {}
+func (Float) isValue()         {}
+func (String) isValue()        {}
+func (List) isValue()          {}
+func (Map) isValue()           {}
+func (Node) isValue()          {}
+func (Relationship) isValue()  {}
+func (Path) isValue()          {}
+func (Date) isValue()          {}
+func (LocalTime) isValue()     {}
+func (Time) isValue()          {}
+func (LocalDateTime) isValue() {}
+func (DateTime) isValue()      {}
+func (Duration) isValue()      {}
+func (Point) isValue()         {}
+
+// ScalarValue converts Go's representation of a scalar, as returned by most client libraries, into a Value.
+//
+// Returns false if the passed value isn't a nil, boolean, numeric, string or [time.Duration] value.
+func ScalarValue(v any) (Value, bool) {
+	switch v := v.(type) {
+	case nil:
+		return Null{}, true
+	case time.Duration:
+		return Duration{Seconds: int64(v / time.Second), Nanos: int64(v % time.Second)}, true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Bool:
+		return Bool(rv.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Int(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Int(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return Float(rv.Float()), true
+	case reflect.String:
+		return String(rv.String()), true
+	}
+	return nil, false
+}
+
+// UnknownValue represents a value of a type without a Value equivalent by its string representation.
+//
+// Drivers use it as a fallback, so values unknown to them are still compared.
+func UnknownValue(v any) Value {
+	return String(fmt.Sprintf("%T(%v)", v, v))
+}
+
+// NewDate returns the date of the passed time, disregarding its time of day and location.
+func NewDate(t time.Time) Date {
+	return Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+// NewLocalTime returns the time of day of the passed time, disregarding its date and location.
+func NewLocalTime(t time.Time) LocalTime {
+	return LocalTime{time.Date(0, time.January, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)}
+}
+
+// NewTime returns the time of day and location of the passed time, disregarding its date.
+func NewTime(t time.Time) Time {
+	return Time{time.Date(0, time.January, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())}
+}
+
+// NewLocalDateTime returns the date and time of day of the passed time, disregarding its location.
+func NewLocalDateTime(t time.Time) LocalDateTime {
+	return LocalDateTime{time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)}
+}
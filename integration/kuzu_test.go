@@ -0,0 +1,81 @@
+//go:build integration && kuzu
+
+package integration_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Anon10214/dinkel/cmd/config"
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/scheduler"
+	"github.com/stretchr/testify/assert"
+)
+
+// Kùzu is embedded, so unlike the other models it doesn't need a container
+func TestIntegrationKuzu(t *testing.T) {
+	dbOptions := dbms.DBOptions{
+		// Use an in-memory database
+		Host:    "localhost",
+		Timeout: 5 * time.Second,
+	}
+
+	conf, err := config.GetConfigForTarget("kuzu", "../targets-config.yml")
+	if !assert.NoError(t, err, "Getting config for target failed") {
+		t.FailNow()
+	}
+	assert.NoError(t, conf.BugReportTemplate.Execute(&bytes.Buffer{}, scheduler.BugreportMarkdownData{}), "Failed to execute bug report template")
+
+	driver := conf.DB
+	if !assert.NoError(t, driver.Init(dbOptions), "Failed to init DB") {
+		t.FailNow()
+	}
+	if ok, err := driver.VerifyConnectivity(dbOptions); !assert.True(t, ok, "Failed to connect to DB: %v", err) {
+		t.FailNow()
+	}
+
+	t.Run("GetSchema works", func(t *testing.T) {
+		if !assert.NoError(t, driver.Reset(dbOptions), "Failed to reset DB") {
+			return
+		}
+
+		res := driver.RunQuery(context.Background(), dbOptions, "CREATE (:N0{p_integer: 1})-[:R1{p_string: 'a'}]->(:N2)")
+		assert.Equal(t, dbms.Valid, driver.GetQueryResultType(res, conf.ErrorMessageRegex), "Simple query causes non-valid result type")
+
+		s, err := driver.GetSchema(dbOptions)
+		if !assert.NoError(t, err, "Failed to get schema") {
+			return
+		}
+
+		assert.Equal(t, []string{"N0", "N1", "N2"}, s.Labels[schema.NODE], "Fetched node labels differ from the created tables")
+		assert.Equal(t, []string{"R0", "R1"}, s.Labels[schema.RELATIONSHIP], "Fetched relationship labels differ from the created tables")
+		assert.Len(t, s.Labels[schema.ANY], 5, "More or less than five labels fetched for ANY type")
+		assert.NotEmpty(t, s.Properties[schema.Integer], "No integer properties fetched")
+	})
+
+	t.Run("Reset clears the database", func(t *testing.T) {
+		if !assert.NoError(t, driver.Reset(dbOptions), "Failed to reset DB") {
+			return
+		}
+		res := driver.RunQuery(context.Background(), dbOptions, "MATCH (n) RETURN n")
+		assert.Equal(t, dbms.Valid, driver.GetQueryResultType(res, conf.ErrorMessageRegex), "Simple query causes non-valid result type")
+		assert.Empty(t, res.Rows, "Nodes remain after resetting the database")
+	})
+
+	t.Run("Cancelling interrupts the query", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		res := driver.RunQuery(ctx, dbOptions, "UNWIND range(1, 1000000) AS a UNWIND range(1, 1000000) AS b WITH a + b AS c WHERE c % 7 = 100 RETURN count(*)")
+		assert.Error(t, res.ProducedError, "Cancelled query didn't produce an error")
+		assert.Less(t, time.Since(start), dbOptions.Timeout, "Query wasn't interrupted before timing out")
+	})
+
+	t.Run("Gibberish does not return valid", func(t *testing.T) {
+		res := driver.RunQuery(context.Background(), dbOptions, "GIBBERISH")
+		assert.NotEqual(t, dbms.Valid, driver.GetQueryResultType(res, conf.ErrorMessageRegex), "Gibberish resulted in a query type of VALID")
+	})
+}
@@ -4,6 +4,7 @@ package integration_test
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -25,10 +26,12 @@ func TestIntegrationModels(t *testing.T) {
 	for _, model := range []struct {
 		name string
 		port int
+		// Whether the community edition supports multiple databases or graphs
+		namespaces bool
 	}{
-		{"neo4j", 1000},
-		{"memgraph", 1001},
-		{"falkordb", 1002},
+		{"neo4j", 1000, false},
+		{"memgraph", 1001, false},
+		{"falkordb", 1002, true},
 	} {
 		model := model // Capture model in loop
 
@@ -79,7 +82,7 @@ func TestIntegrationModels(t *testing.T) {
 						label := "NODE_LABEL"
 
 						// Check that labels get read out of the DB
-						res := driver.RunQuery(dbOptions, fmt.Sprintf("CREATE (:%s)", label))
+						res := driver.RunQuery(context.Background(), dbOptions, fmt.Sprintf("CREATE (:%s)", label))
 						assert.Equal(t, driver.GetQueryResultType(res, conf.ErrorMessageRegex), dbms.Valid, "Simple query causes non-valid result type")
 
 						s, err := driver.GetSchema(dbOptions)
@@ -104,7 +107,7 @@ func TestIntegrationModels(t *testing.T) {
 						label := "RELATIONSHIP_LABEL"
 
 						// Check that labels get read out of the DB
-						res := driver.RunQuery(dbOptions, fmt.Sprintf("CREATE ()-[:%s]->()", label))
+						res := driver.RunQuery(context.Background(), dbOptions, fmt.Sprintf("CREATE ()-[:%s]->()", label))
 						assert.Equal(t, driver.GetQueryResultType(res, conf.ErrorMessageRegex), dbms.Valid, "Simple query causes non-valid result type")
 
 						s, err := driver.GetSchema(dbOptions)
@@ -125,8 +128,52 @@ func TestIntegrationModels(t *testing.T) {
 
 				})
 
+				t.Run("Reset only affects the namespace", func(t *testing.T) {
+					if !model.namespaces {
+						t.Skipf("%s doesn't support multiple namespaces", model.name)
+					}
+
+					otherOptions := dbOptions
+					otherOptions.Namespace = "other"
+					otherConf, err := config.GetConfigForTarget(model.name, "../targets-config.yml")
+					if !assert.NoError(t, err, "Getting config for target failed") {
+						return
+					}
+					other := otherConf.DB
+					if !assert.NoError(t, other.Init(otherOptions), "Failed to init DB for other namespace") {
+						return
+					}
+
+					if !assert.NoError(t, other.Reset(otherOptions), "Failed to reset other namespace") {
+						return
+					}
+					res := other.RunQuery(context.Background(), otherOptions, "CREATE ()")
+					assert.Equal(t, dbms.Valid, other.GetQueryResultType(res, conf.ErrorMessageRegex), "Simple query causes non-valid result type")
+
+					if !assert.NoError(t, driver.Reset(dbOptions), "Failed to reset DB") {
+						return
+					}
+					res = driver.RunQuery(context.Background(), dbOptions, "MATCH (n) RETURN n")
+					assert.Empty(t, res.Rows, "Nodes of other namespace visible")
+
+					res = other.RunQuery(context.Background(), otherOptions, "MATCH (n) RETURN n")
+					assert.Len(t, res.Rows, 1, "Resetting the default namespace affected the other namespace")
+				})
+
+				t.Run("Cancelling kills the query", func(t *testing.T) {
+					ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+					defer cancel()
+					start := time.Now()
+					res := driver.RunQuery(ctx, dbOptions, "UNWIND range(1, 1000000) AS a UNWIND range(1, 1000000) AS b WITH a + b AS c WHERE c % 7 = 100 RETURN count(*)")
+					assert.Error(t, res.ProducedError, "Cancelled query didn't produce an error")
+					assert.Less(t, time.Since(start), dbOptions.Timeout, "Query wasn't killed before timing out")
+
+					ok, err := driver.VerifyConnectivity(dbOptions)
+					assert.True(t, ok, "Lost connection after cancelling query: %v", err)
+				})
+
 				t.Run("Gibberish does not return valid", func(t *testing.T) {
-					res := driver.RunQuery(dbOptions, "GIBBERISH")
+					res := driver.RunQuery(context.Background(), dbOptions, "GIBBERISH")
 					assert.NotEqual(t, dbms.Valid, driver.GetQueryResultType(res, conf.ErrorMessageRegex), "Gibberish resulted in a query type of VALID")
 				})
 			}
@@ -1,6 +1,7 @@
 package prometheus
 
 import (
+	"context"
 	"time"
 
 	"github.com/Anon10214/dinkel/dbms"
@@ -22,10 +23,10 @@ func (e *dinkelExporter) handleRunQuery(next dbms.RunQueryHandler) dbms.RunQuery
 	generationLatency := time.Since(e.queryGenerationStart)
 	e.generationLatencies.Observe(generationLatency.Seconds())
 
-	return func(opts dbms.DBOptions, query string) dbms.QueryResult {
+	return func(ctx context.Context, opts dbms.DBOptions, query string) dbms.QueryResult {
 		startTime := time.Now()
 
-		res := next(opts, query)
+		res := next(ctx, opts, query)
 
 		latency := time.Since(startTime)
 		e.queryLatencies.Observe(latency.Seconds())
@@ -80,9 +81,9 @@ func getFullDBHooks(exporter *fullDinkelExporter) dbms.DBMiddleware {
 }
 
 func (e *fullDinkelExporter) handleRunQuery(next dbms.RunQueryHandler) dbms.RunQueryHandler {
-	return func(opts dbms.DBOptions, query string) dbms.QueryResult {
+	return func(ctx context.Context, opts dbms.DBOptions, query string) dbms.QueryResult {
 		e.lastQueryString = query
-		res := next(opts, query)
+		res := next(ctx, opts, query)
 		return res
 	}
 }
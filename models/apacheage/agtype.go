@@ -0,0 +1,281 @@
+package apacheage
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+)
+
+// A Vertex is a vertex as returned by apache age.
+type Vertex struct {
+	ID         int64
+	Label      string
+	Properties map[string]any
+}
+
+// An Edge is an edge as returned by apache age.
+type Edge struct {
+	ID         int64
+	StartID    int64
+	EndID      int64
+	Label      string
+	Properties map[string]any
+}
+
+// A Path is a path as returned by apache age, alternating between [Vertex] and [Edge] elements.
+type Path []any
+
+// parseAgtype parses the textual representation of an agtype value.
+//
+// Scalars get converted to nil, bool, int64, float64 or string, lists to []any and maps to map[string]any.
+// Vertices, edges and paths get converted to [Vertex], [Edge] and [Path] respectively.
+func parseAgtype(text string) (any, error) {
+	p := &agtypeParser{text: text}
+	val, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	p.skipWhitespace()
+	if p.pos != len(p.text) {
+		return nil, fmt.Errorf("unexpected trailing characters %q in agtype %q", p.text[p.pos:], text)
+	}
+	return val, nil
+}
+
+type agtypeParser struct {
+	text string
+	pos  int
+}
+
+func (p *agtypeParser) skipWhitespace() {
+	for p.pos < len(p.text) && strings.ContainsRune(" \t\n\r", rune(p.text[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *agtypeParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("couldn't parse agtype %q at position %d: %s", p.text, p.pos, fmt.Sprintf(format, args...))
+}
+
+// consume advances past the passed prefix and returns true if the remaining text starts with it.
+func (p *agtypeParser) consume(prefix string) bool {
+	if strings.HasPrefix(p.text[p.pos:], prefix) {
+		p.pos += len(prefix)
+		return true
+	}
+	return false
+}
+
+func (p *agtypeParser) parseValue() (any, error) {
+	p.skipWhitespace()
+	if p.pos == len(p.text) {
+		return nil, p.errorf("unexpected end of input")
+	}
+
+	var val any
+	var err error
+	switch c := p.text[p.pos]; {
+	case c == '{':
+		val, err = p.parseMap()
+	case c == '[':
+		val, err = p.parseList()
+	case c == '"':
+		val, err = p.parseString()
+	case p.consume("null"):
+		return nil, nil
+	case p.consume("true"):
+		return true, nil
+	case p.consume("false"):
+		return false, nil
+	default:
+		val, err = p.parseNumber()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle type annotations
+	if !p.consume("::") {
+		return val, nil
+	}
+	switch {
+	case p.consume("vertex"):
+		return toVertex(val)
+	case p.consume("edge"):
+		return toEdge(val)
+	case p.consume("path"):
+		list, ok := val.([]any)
+		if !ok {
+			return nil, p.errorf("path is of type %T instead of a list", val)
+		}
+		return Path(list), nil
+	case p.consume("numeric"):
+		return val, nil
+	}
+	return nil, p.errorf("unknown type annotation")
+}
+
+func (p *agtypeParser) parseMap() (map[string]any, error) {
+	p.pos++ // Skip the opening brace
+	res := map[string]any{}
+	p.skipWhitespace()
+	if p.consume("}") {
+		return res, nil
+	}
+	for {
+		p.skipWhitespace()
+		key, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		p.skipWhitespace()
+		if !p.consume(":") {
+			return nil, p.errorf("expected colon after map key %q", key)
+		}
+		if res[key], err = p.parseValue(); err != nil {
+			return nil, err
+		}
+		p.skipWhitespace()
+		if p.consume("}") {
+			return res, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected comma or closing brace in map")
+		}
+	}
+}
+
+func (p *agtypeParser) parseList() ([]any, error) {
+	p.pos++ // Skip the opening bracket
+	res := []any{}
+	p.skipWhitespace()
+	if p.consume("]") {
+		return res, nil
+	}
+	for {
+		val, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, val)
+		p.skipWhitespace()
+		if p.consume("]") {
+			return res, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected comma or closing bracket in list")
+		}
+	}
+}
+
+// parseString parses a JSON encoded string.
+func (p *agtypeParser) parseString() (string, error) {
+	if p.pos == len(p.text) || p.text[p.pos] != '"' {
+		return "", p.errorf("expected string")
+	}
+	end := p.pos + 1
+	for ; end < len(p.text) && p.text[end] != '"'; end++ {
+		if p.text[end] == '\\' {
+			end++
+		}
+	}
+	if end >= len(p.text) {
+		return "", p.errorf("unterminated string")
+	}
+	var res string
+	if err := json.Unmarshal([]byte(p.text[p.pos:end+1]), &res); err != nil {
+		return "", p.errorf("invalid string - %v", err)
+	}
+	p.pos = end + 1
+	return res, nil
+}
+
+func (p *agtypeParser) parseNumber() (any, error) {
+	switch {
+	case p.consume("NaN"):
+		return math.NaN(), nil
+	case p.consume("Infinity"):
+		return math.Inf(1), nil
+	case p.consume("-Infinity"):
+		return math.Inf(-1), nil
+	}
+
+	end := p.pos
+	for end < len(p.text) && strings.ContainsRune("0123456789+-.eE", rune(p.text[end])) {
+		end++
+	}
+	literal := p.text[p.pos:end]
+	if literal == "" {
+		return nil, p.errorf("unexpected character %q", p.text[p.pos])
+	}
+	p.pos = end
+
+	// Floats always contain a decimal point or an exponent
+	if !strings.ContainsAny(literal, ".eE") {
+		if val, err := strconv.ParseInt(literal, 10, 64); err == nil {
+			return val, nil
+		}
+	}
+	val, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return nil, p.errorf("invalid number %q", literal)
+	}
+	return val, nil
+}
+
+func toVertex(val any) (Vertex, error) {
+	m, ok := val.(map[string]any)
+	if !ok {
+		return Vertex{}, fmt.Errorf("vertex is of type %T instead of a map", val)
+	}
+	id, _ := m["id"].(int64)
+	label, _ := m["label"].(string)
+	props, _ := m["properties"].(map[string]any)
+	return Vertex{ID: id, Label: label, Properties: props}, nil
+}
+
+func toEdge(val any) (Edge, error) {
+	m, ok := val.(map[string]any)
+	if !ok {
+		return Edge{}, fmt.Errorf("edge is of type %T instead of a map", val)
+	}
+	id, _ := m["id"].(int64)
+	startID, _ := m["start_id"].(int64)
+	endID, _ := m["end_id"].(int64)
+	label, _ := m["label"].(string)
+	props, _ := m["properties"].(map[string]any)
+	return Edge{ID: id, StartID: startID, EndID: endID, Label: label, Properties: props}, nil
+}
+
+// agtypeToPropertyType returns the [schema.PropertyType] of a parsed agtype property value.
+// Returns false if the value doesn't correspond to any property type.
+//
+// Lists whose elements don't share a single type are of the type [schema.AnyType] with the list mask set.
+func agtypeToPropertyType(val any) (schema.PropertyType, bool) {
+	switch val := val.(type) {
+	case bool:
+		return schema.Boolean, true
+	case int64:
+		return schema.Integer, true
+	case float64:
+		return schema.Float, true
+	case string:
+		return schema.String, true
+	case []any:
+		elemType := schema.AnyType
+		for i, elem := range val {
+			t, ok := agtypeToPropertyType(elem)
+			if !ok || t&schema.PropertyType(schema.ListMask) != 0 || (i != 0 && t != elemType) {
+				elemType = schema.AnyType
+				break
+			}
+			elemType = t
+		}
+		return elemType | schema.PropertyType(schema.ListMask), true
+	}
+	return schema.AnyType, false
+}
@@ -0,0 +1,82 @@
+package apacheage
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAgtype_Scalars(t *testing.T) {
+	for text, expected := range map[string]any{
+		"null":            nil,
+		"true":            true,
+		"false":           false,
+		"42":              int64(42),
+		"-7":              int64(-7),
+		"1.0":             1.0,
+		"-2.5e-05":        -2.5e-05,
+		"Infinity":        math.Inf(1),
+		"-Infinity":       math.Inf(-1),
+		"1.5::numeric":    1.5,
+		`"a\"bä"`:         "a\"bä",
+		`[1, "x", []]`:    []any{int64(1), "x", []any{}},
+		`{"k": {"j": 1}}`: map[string]any{"k": map[string]any{"j": int64(1)}},
+	} {
+		val, err := parseAgtype(text)
+		assert.NoError(t, err, text)
+		assert.Equal(t, expected, val, text)
+	}
+
+	val, err := parseAgtype("NaN")
+	assert.NoError(t, err)
+	assert.True(t, math.IsNaN(val.(float64)), "NaN not parsed")
+}
+
+func TestParseAgtype_Entities(t *testing.T) {
+	vertex := `{"id": 844424930131969, "label": "L", "properties": {"a": 1}}::vertex`
+	edge := `{"id": 1125899906842625, "label": "R", "end_id": 844424930131970, "start_id": 844424930131969, "properties": {}}::edge`
+
+	val, err := parseAgtype(vertex)
+	assert.NoError(t, err)
+	assert.Equal(t, Vertex{ID: 844424930131969, Label: "L", Properties: map[string]any{"a": int64(1)}}, val)
+
+	val, err = parseAgtype(edge)
+	assert.NoError(t, err)
+	assert.Equal(t, Edge{ID: 1125899906842625, StartID: 844424930131969, EndID: 844424930131970, Label: "R", Properties: map[string]any{}}, val)
+
+	val, err = parseAgtype("[" + vertex + ", " + edge + ", " + vertex + "]::path")
+	assert.NoError(t, err)
+	assert.Len(t, val, 3)
+	assert.IsType(t, Path{}, val)
+}
+
+func TestParseAgtype_Malformed(t *testing.T) {
+	for _, text := range []string{"", "[1, 2", `{"a" 1}`, `"abc`, "1 2", "{}::unknown", "abc"} {
+		_, err := parseAgtype(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestAgtypeToPropertyType(t *testing.T) {
+	for _, tc := range []struct {
+		val      any
+		expected schema.PropertyType
+	}{
+		{true, schema.Boolean},
+		{int64(1), schema.Integer},
+		{1.5, schema.Float},
+		{"a", schema.String},
+		{[]any{int64(1), int64(2)}, schema.Integer | schema.PropertyType(schema.ListMask)},
+		{[]any{int64(1), "a"}, schema.AnyType | schema.PropertyType(schema.ListMask)},
+		{[]any{}, schema.AnyType | schema.PropertyType(schema.ListMask)},
+	} {
+		propType, ok := agtypeToPropertyType(tc.val)
+		assert.True(t, ok, tc.val)
+		assert.Equal(t, tc.expected, propType, tc.val)
+	}
+
+	_, ok := agtypeToPropertyType(map[string]any{})
+	assert.False(t, ok, "Map identified as property type")
+}
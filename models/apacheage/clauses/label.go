@@ -9,8 +9,8 @@ import (
 
 // ExistingLabel drop-in for apache age
 type ExistingLabel struct {
-	LabelType schema.StructuralType
-	name      string
+	LabelType schema.StructuralType `ast:"labelType"`
+	name      string                `ast:"name"`
 }
 
 // Generate the ExistingLabel subclauses.
@@ -9,7 +9,7 @@ import (
 
 // Merge drop-in for apache age
 type Merge struct {
-	oldAllowOnlyNonNullPropertyExpressions bool
+	oldAllowOnlyNonNullPropertyExpressions bool `ast:"oldAllowOnlyNonNullPropertyExpressions"`
 }
 
 // Generate the merge subclauses.
@@ -0,0 +1,11 @@
+package clauses
+
+import "github.com/Anon10214/dinkel/translator/helperclauses"
+
+// Register the Apache AGE specific clauses, allowing ASTs containing them to be loaded from bug reports.
+func init() {
+	helperclauses.RegisterClauses(
+		&ExistingLabel{},
+		&Merge{},
+	)
+}
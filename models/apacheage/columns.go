@@ -0,0 +1,58 @@
+package apacheage
+
+import (
+	"strings"
+	"unicode"
+)
+
+// returnColumnCount returns the amount of columns returned by the passed query.
+//
+// Apache AGE requires the columns of a cypher query to be declared in the surrounding SQL statement,
+// so they are derived from the projections of the query's last RETURN clause which isn't nested in any
+// parentheses, brackets or braces. Queries without a RETURN clause return no columns.
+//
+// RETURN * is not supported, as its columns can't be derived from the query alone.
+func returnColumnCount(query string) int {
+	var columns int
+	var isCounting bool
+	var depth int
+
+	for i := 0; i < len(query); i++ {
+		switch c := rune(query[i]); {
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == '\'' || c == '"' || c == '`':
+			// Skip string literals and escaped names
+			for i++; i < len(query) && rune(query[i]) != c; i++ {
+				if query[i] == '\\' {
+					i++
+				}
+			}
+		case c == ',' && depth == 0 && isCounting:
+			columns++
+		case isWordCharacter(c):
+			start := i
+			for i+1 < len(query) && isWordCharacter(rune(query[i+1])) {
+				i++
+			}
+			if depth != 0 {
+				continue
+			}
+			switch strings.ToUpper(query[start : i+1]) {
+			case "RETURN":
+				isCounting = true
+				columns = 1
+			case "ORDER", "SKIP", "LIMIT", "UNION":
+				isCounting = false
+			}
+		}
+	}
+
+	return columns
+}
+
+func isWordCharacter(c rune) bool {
+	return c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
@@ -0,0 +1,23 @@
+package apacheage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReturnColumnCount(t *testing.T) {
+	for query, expected := range map[string]int{
+		"CREATE (n)":              0,
+		"MATCH (n) RETURN n AS a": 1,
+		"MATCH (n) RETURN DISTINCT n AS a, n.x AS b":                     2,
+		"RETURN {a: 1, b: [1, 2]} AS a, f(1, 2) AS b":                    2,
+		"RETURN 'a, b' AS a, \"RETURN\" AS b, `x,y` AS c":                3,
+		"RETURN 1 AS a, 2 AS b ORDER BY a, b SKIP 1":                     2,
+		"WITH 1 AS x RETURN x AS a UNION RETURN 2 AS a":                  1,
+		"MATCH (n) WHERE EXISTS { MATCH (m) RETURN m, n } RETURN n AS a": 1,
+		"return 1 as a, 2 as b":                                          2,
+	} {
+		assert.Equal(t, expected, returnColumnCount(query), query)
+	}
+}
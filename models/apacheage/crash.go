@@ -0,0 +1,83 @@
+package apacheage
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// The maximum amount of bytes read from the end of the server log when a crash is detected
+const logExcerptSize = 8192
+
+// isBackendTermination returns true if the passed error indicates that the backend serving the connection got terminated.
+//
+// If a backend segfaults, the postmaster restarts all backends, so the database will usually be reachable
+// again by the time the connectivity gets verified. The crash thus has to be detected through the error itself.
+func isBackendTermination(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "57P01", // admin_shutdown
+			"57P02", // crash_shutdown
+			"57P03": // cannot_connect_now, the database is in crash recovery
+			return true
+		}
+		return false
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	msg := err.Error()
+	for _, substr := range []string{
+		"server closed the connection unexpectedly",
+		"connection reset by peer",
+		"broken pipe",
+	} {
+		if strings.Contains(msg, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// serverLogExcerpt returns the last lines of the server's current log file.
+//
+// As the server restarts after a backend crashed, reading the log is retried until the passed timeout expires.
+// Returns the empty string if the log couldn't be read, e.g. because the logging collector is disabled.
+func (d Driver) serverLogExcerpt(timeout time.Duration) string {
+	deadline := time.Now().Add(timeout)
+	for {
+		var excerpt sql.NullString
+		err := d.driver.QueryRow(`SELECT pg_read_file(pg_current_logfile(), GREATEST(size - $1, 0), $1)
+	FROM pg_stat_file(pg_current_logfile());`, logExcerptSize).Scan(&excerpt)
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			// Only keep complete lines
+			text := excerpt.String
+			if len(text) == logExcerptSize {
+				if _, rest, found := strings.Cut(text, "\n"); found {
+					text = rest
+				}
+			}
+			return text
+		}
+		if time.Now().After(deadline) {
+			logrus.Warnf("Couldn't read server log after crash - %v", err)
+			return ""
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
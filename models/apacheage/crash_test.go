@@ -0,0 +1,36 @@
+package apacheage
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsBackendTermination(t *testing.T) {
+	for _, err := range []error{
+		driver.ErrBadConn,
+		&pq.Error{Code: "57P02", Message: "terminating connection because of crash of another server process"},
+		&pq.Error{Code: "57P03", Message: "the database system is in recovery mode"},
+		&net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)},
+		fmt.Errorf("wrapped - %w", driver.ErrBadConn),
+		errors.New("server closed the connection unexpectedly"),
+	} {
+		assert.True(t, isBackendTermination(err), err.Error())
+	}
+
+	for _, err := range []error{
+		nil,
+		&pq.Error{Code: "22012", Message: "division by zero"},
+		&pq.Error{Code: "57014", Message: "canceling statement due to user request"},
+		errors.New("couldn't parse agtype"),
+	} {
+		assert.False(t, isBackendTermination(err), err)
+	}
+}
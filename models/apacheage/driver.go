@@ -4,13 +4,16 @@ Package apacheage provides the model for Apache AGE, a postgres extension.
 package apacheage
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/Anon10214/dinkel/dbms"
 	"github.com/Anon10214/dinkel/models/opencypher/schema"
 	"github.com/Anon10214/dinkel/seed"
-	"github.com/apache/age/drivers/golang/age"
 	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 )
@@ -18,6 +21,10 @@ import (
 // Driver for apache age
 type Driver struct {
 	driver *sql.DB
+
+	// The transaction all statements run in while a snapshot is taken, rolling it back restores the snapshot.
+	// Nil if no snapshot was taken.
+	snapshot *sql.Tx
 }
 
 // Init the DB driver
@@ -27,8 +34,10 @@ func (d *Driver) Init(opts dbms.DBOptions) error {
 		connPort = *opts.Port
 	}
 
+	d.discardSnapshot()
+
 	var err error
-	if d.driver, err = sql.Open("postgres", fmt.Sprintf("host=%s port=%d user=postgres sslmode=disable", opts.Host, connPort)); err != nil {
+	if d.driver, err = sql.Open("postgres", connectionString(opts, connPort)); err != nil {
 		return err
 	}
 
@@ -36,6 +45,55 @@ func (d *Driver) Init(opts dbms.DBOptions) error {
 	return nil
 }
 
+// connectionString returns the libpq connection string for the passed options.
+//
+// Connects as the postgres user if no username is passed. Tokens are passed as the password.
+func connectionString(opts dbms.DBOptions, port int) string {
+	user := opts.Username
+	if user == "" {
+		user = "postgres"
+	}
+	password := opts.Password
+	if opts.Token != "" {
+		password = opts.Token
+	}
+
+	params := [][2]string{{"host", opts.Host}, {"port", fmt.Sprint(port)}, {"user", user}}
+	if password != "" {
+		params = append(params, [2]string{"password", password})
+	}
+	switch {
+	case opts.TLS == nil:
+		params = append(params, [2]string{"sslmode", "disable"})
+	case opts.TLS.SkipVerify:
+		params = append(params, [2]string{"sslmode", "require"})
+	default:
+		params = append(params, [2]string{"sslmode", "verify-full"})
+		if opts.TLS.CAFile != "" {
+			params = append(params, [2]string{"sslrootcert", opts.TLS.CAFile})
+		}
+	}
+
+	var parts []string
+	for _, param := range params {
+		// Quote values, as they may contain spaces or quotes
+		value := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(param[1])
+		parts = append(parts, fmt.Sprintf("%s='%s'", param[0], value))
+	}
+	return strings.Join(parts, " ")
+}
+
+// The graph used if no namespace was passed
+const defaultGraph = "graph"
+
+// graphName returns the name of the graph to fuzz, which is the namespace if one was passed.
+func graphName(opts dbms.DBOptions) string {
+	if opts.Namespace != "" {
+		return opts.Namespace
+	}
+	return defaultGraph
+}
+
 // initAgeTransaction runs the boilerplate statements for initializing an apache age transaction.
 func (d *Driver) initAgeTransaction() (*sql.Tx, error) {
 	tx, err := d.driver.Begin()
@@ -55,9 +113,97 @@ func (d *Driver) initAgeTransaction() (*sql.Tx, error) {
 	return tx, nil
 }
 
-// Reset the database
+// beginStatement returns the transaction to run a statement in, along with the function ending the statement.
+// The statement's changes are kept if the function gets passed true, else they are rolled back.
+//
+// If a snapshot was taken, the statement runs in a savepoint of the snapshot's transaction,
+// so an erroring statement doesn't abort the transaction. Else, a new transaction is started.
+func (d Driver) beginStatement() (*sql.Tx, func(commit bool) error, error) {
+	if d.snapshot == nil {
+		tx, err := d.initAgeTransaction()
+		if err != nil {
+			return nil, nil, err
+		}
+		return tx, func(commit bool) error {
+			if commit {
+				return tx.Commit()
+			}
+			return tx.Rollback()
+		}, nil
+	}
+
+	tx := d.snapshot
+	if _, err := tx.Exec(`SAVEPOINT statement;`); err != nil {
+		return nil, nil, err
+	}
+	ended := false
+	return tx, func(commit bool) error {
+		// Ending a statement twice would fail and thereby abort the snapshot's transaction
+		if ended {
+			return nil
+		}
+		ended = true
+		if commit {
+			_, err := tx.Exec(`RELEASE SAVEPOINT statement;`)
+			if err == nil {
+				return nil
+			}
+			// Releasing fails if the transaction got aborted, e.g. by cancelling the statement while releasing it.
+			// Roll back the statement, else the snapshot's transaction remains aborted and all further statements fail.
+			if _, rollbackErr := tx.Exec(`ROLLBACK TO SAVEPOINT statement;`); rollbackErr != nil {
+				return errors.Join(err, rollbackErr)
+			}
+			return err
+		}
+		_, err := tx.Exec(`ROLLBACK TO SAVEPOINT statement;`)
+		return err
+	}, nil
+}
+
+// Snapshot the freshly reset graph by starting the transaction all further statements run in.
+//
+// As DDL statements are transactional in postgres, rolling back the transaction restores the graph entirely.
+func (d *Driver) Snapshot(opts dbms.DBOptions) error {
+	d.discardSnapshot()
+	tx, err := d.initAgeTransaction()
+	if err != nil {
+		return err
+	}
+	d.snapshot = tx
+	return nil
+}
+
+// Restore the graph to its snapshot by rolling back the snapshot's transaction and starting a new one.
+func (d *Driver) Restore(opts dbms.DBOptions) error {
+	if d.snapshot == nil {
+		return dbms.ErrNoSnapshot
+	}
+	tx := d.snapshot
+	d.snapshot = nil
+	if err := tx.Rollback(); err != nil {
+		return err
+	}
+	return d.Snapshot(opts)
+}
+
+// discardSnapshot rolls back the snapshot's transaction, if a snapshot was taken.
+func (d *Driver) discardSnapshot() {
+	if d.snapshot != nil {
+		// The transaction may have been aborted already, e.g. if the backend crashed
+		_ = d.snapshot.Rollback()
+		d.snapshot = nil
+	}
+}
+
+// Reset the database by recreating the graph.
+//
+// Other graphs in the database are left untouched. Discards the snapshot, if one was taken.
 func (d *Driver) Reset(opts dbms.DBOptions) error {
 	logrus.Debug("Resetting Database")
+	graph := graphName(opts)
+
+	// The snapshot's transaction would block dropping the graph
+	d.discardSnapshot()
 
 	// Drop the graph in a separate transaction, as when this functions is called for
 	// the first time, it will error as no graph exists yet.
@@ -65,9 +211,9 @@ func (d *Driver) Reset(opts dbms.DBOptions) error {
 	if err != nil {
 		return err
 	}
-	if _, err := tx.Exec(`SELECT drop_graph('graph', true);`); err != nil {
-		if err.Error() != `pq: graph "graph" does not exist` {
-			tx.Rollback()
+	if _, err := tx.Exec(fmt.Sprintf(`SELECT drop_graph(%s, true);`, pq.QuoteLiteral(graph))); err != nil {
+		tx.Rollback()
+		if err.Error() != fmt.Sprintf(`pq: graph "%s" does not exist`, graph) {
 			return err
 		}
 	} else if err := tx.Commit(); err != nil {
@@ -79,7 +225,7 @@ func (d *Driver) Reset(opts dbms.DBOptions) error {
 	if err != nil {
 		return err
 	}
-	if _, err := tx.Exec(`SELECT create_graph('graph');`); err != nil {
+	if _, err := tx.Exec(fmt.Sprintf(`SELECT create_graph(%s);`, pq.QuoteLiteral(graph))); err != nil {
 		tx.Rollback()
 		return err
 	}
@@ -88,48 +234,204 @@ func (d *Driver) Reset(opts dbms.DBOptions) error {
 }
 
 // RunQuery runs the query against the apache age DB and returns its result.
-func (d Driver) RunQuery(opts dbms.DBOptions, query string) dbms.QueryResult {
+//
+// Once the context is done, the query gets cancelled using pg_cancel_backend.
+func (d Driver) RunQuery(ctx context.Context, opts dbms.DBOptions, query string) dbms.QueryResult {
 	res := dbms.QueryResult{}
 
-	tx, err := d.initAgeTransaction()
+	tx, end, err := d.beginStatement()
 	if err != nil {
 		res.ProducedError = err
-		tx.Rollback()
 		return res
 	}
+	defer end(false)
 
-	if _, err := tx.Exec(fmt.Sprintf(`SELECT * FROM cypher('graph',$$
-	%s
-$$) as (v agtype);`, query)); err != nil {
+	var pid int
+	if err := tx.QueryRow(`SELECT pg_backend_pid();`).Scan(&pid); err != nil {
 		res.ProducedError = err
-		tx.Rollback()
+		return res
+	}
+	stop := context.AfterFunc(ctx, func() {
+		if err := d.cancelBackend(opts, pid); err != nil {
+			logrus.Warnf("Couldn't cancel query - %v", err)
+		}
+	})
+	defer stop()
+
+	rows, err := runCypher(tx, graphName(opts), query, returnColumnCount(query))
+	if err != nil {
+		res.ProducedError = err
+		if isBackendTermination(err) {
+			res.LogExcerpt = d.serverLogExcerpt(opts.Timeout)
+		}
 		return res
 	}
 
-	res.ProducedError = tx.Commit()
+	// Apache AGE reports neither the names of the columns nor how the query changed the graph
+	res.Rows = toRows(rows)
 
 	// Get schema
-	cursor, err := age.ExecCypher(tx, "graph", 1, "MATCH (n) RETURN n AS x UNION MATCH ()-[m]-() RETURN m AS x")
+	graph, err := runCypher(tx, graphName(opts), "MATCH (n) RETURN n AS x UNION MATCH ()-[m]->() RETURN m AS x", 1)
 	if err != nil {
 		res.ProducedError = err
 		logrus.Debugf("Error %v produced when trying to get schema", err)
 		return res
 	}
+	res.Graph = toGraph(graph)
 
-	for cursor.Next() {
-		fmt.Println(cursor.GetRow())
+	res.ProducedError = end(true)
+	return res
+}
+
+// cancelBackend cancels the query currently run by the backend with the passed process ID.
+func (d Driver) cancelBackend(opts dbms.DBOptions, pid int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	_, err := d.driver.ExecContext(ctx, `SELECT pg_cancel_backend($1);`, pid)
+	return err
+}
+
+// runCypher runs the cypher query against the graph in the passed transaction and returns the rows it produced.
+// Every row holds the parsed agtype values of the query's columns.
+func runCypher(tx *sql.Tx, graph, query string, columnCount int) ([]any, error) {
+	// Queries without any return values still need to declare a column
+	columns := make([]string, max(columnCount, 1))
+	for i := range columns {
+		columns[i] = fmt.Sprintf("c%d agtype", i)
 	}
 
-	return res
+	rows, err := tx.Query(fmt.Sprintf(`SELECT * FROM cypher(%s,$$
+	%s
+$$) as (%s);`, pq.QuoteLiteral(graph), query, strings.Join(columns, ", ")))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []any
+	for rows.Next() {
+		rawValues := make([]sql.NullString, len(columns))
+		scanTargets := make([]any, len(columns))
+		for i := range rawValues {
+			scanTargets[i] = &rawValues[i]
+		}
+		if err := rows.Scan(scanTargets...); err != nil {
+			return nil, err
+		}
+
+		row := make([]any, len(columns))
+		for i, raw := range rawValues {
+			if !raw.Valid {
+				continue
+			}
+			if row[i], err = parseAgtype(raw.String); err != nil {
+				return nil, err
+			}
+		}
+		res = append(res, row)
+	}
+	return res, rows.Err()
 }
 
 // GetSchema returns the database's current schema
-func (d Driver) GetSchema(dbms.DBOptions) (*schema.Schema, error) {
+func (d Driver) GetSchema(opts dbms.DBOptions) (*schema.Schema, error) {
 	s := &schema.Schema{}
 	s.Reset()
+
+	tx, end, err := d.beginStatement()
+	if err != nil {
+		return nil, err
+	}
+	defer end(false)
+
+	if err := populateLabels(tx, graphName(opts), s); err != nil {
+		logrus.Errorf("Error while populating labels of schema: %v", err)
+		return nil, err
+	}
+	logrus.Tracef("Populated schema with labels %v", s.Labels)
+	if err := populateProperties(tx, graphName(opts), s); err != nil {
+		logrus.Errorf("Error while populating properties of schema: %v", err)
+		return nil, err
+	}
+	logrus.Tracef("Populated schema with properties %v", s.Properties)
+
 	return s, nil
 }
 
+// populateLabels fetches all vertex and edge labels of the graph from the catalog and inserts them into the schema.
+func populateLabels(tx *sql.Tx, graph string, s *schema.Schema) error {
+	// Skip the default labels of unlabeled vertices and edges
+	rows, err := tx.Query(`SELECT l.name, l.kind FROM ag_catalog.ag_label l
+	JOIN ag_catalog.ag_graph g ON l.graph = g.graphid
+	WHERE g.name = $1 AND l.name NOT LIKE '\_ag\_label\_%'
+	ORDER BY l.name;`, graph)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, kind string
+		if err := rows.Scan(&name, &kind); err != nil {
+			return err
+		}
+		switch kind {
+		case "v":
+			s.Labels[schema.NODE] = append(s.Labels[schema.NODE], name)
+		case "e":
+			s.Labels[schema.RELATIONSHIP] = append(s.Labels[schema.RELATIONSHIP], name)
+		default:
+			logrus.Errorf("Couldn't identify kind %q of label %s while populating labels", kind, name)
+		}
+	}
+
+	s.Labels[schema.ANY] = append(s.Labels[schema.RELATIONSHIP], s.Labels[schema.NODE]...)
+
+	return rows.Err()
+}
+
+// The amount of vertices and edges sampled each for populating the schema's properties
+const propertySampleSize = 1000
+
+// populateProperties samples the properties of vertices and edges in the graph and inserts them into the schema.
+func populateProperties(tx *sql.Tx, graph string, s *schema.Schema) error {
+	var sampled []any
+	for _, query := range []string{
+		fmt.Sprintf("MATCH (n) RETURN properties(n) ORDER BY id(n) LIMIT %d", propertySampleSize),
+		fmt.Sprintf("MATCH ()-[m]->() RETURN properties(m) ORDER BY id(m) LIMIT %d", propertySampleSize),
+	} {
+		rows, err := runCypher(tx, graph, query, 1)
+		if err != nil {
+			return err
+		}
+		sampled = append(sampled, rows...)
+	}
+
+	// Collect the distinct properties, sorted by name, type and value for the schema to be deterministic
+	found := map[schema.Property]bool{}
+	for _, row := range sampled {
+		props, _ := row.([]any)[0].(map[string]any)
+		for key, val := range props {
+			propType, ok := agtypeToPropertyType(val)
+			if !ok {
+				logrus.Debugf("Couldn't identify type %T of property %s while populating properties", val, key)
+				continue
+			}
+			found[schema.Property{Name: key, Type: propType, Value: schema.Literal(val, nil)}] = true
+		}
+	}
+	properties := make([]schema.Property, 0, len(found))
+	for property := range found {
+		properties = append(properties, property)
+	}
+	slices.SortFunc(properties, schema.CompareProperties)
+
+	for _, property := range properties {
+		s.AddProperty(property)
+	}
+	return nil
+}
+
 // GetQueryResultType evaluates the produced result and returns the type the result indicates.
 func (d Driver) GetQueryResultType(res dbms.QueryResult, errorMessageRegex *dbms.ErrorMessageRegex) dbms.QueryResultType {
 	err := res.ProducedError
@@ -137,14 +439,21 @@ func (d Driver) GetQueryResultType(res dbms.QueryResult, errorMessageRegex *dbms
 		return dbms.Valid
 	}
 
+	if isBackendTermination(err) {
+		logrus.Warnf("Backend got terminated while running query - %v", err)
+		return dbms.Crash
+	}
+
 	switch err := err.(type) {
 	case *pq.Error:
-		if errorMessageRegex.Ignored.MatchString(err.Message) {
-			return dbms.Invalid
+		// query_canceled, raised when cancelling a query after its timeout
+		if err.Code == "57014" {
+			return dbms.Timeout
 		}
 
-		if errorMessageRegex.Reported.MatchString(err.Message) {
-			return dbms.ReportedBug
+		// Codes may be given as a full SQLSTATE or as its class, e.g. 22 for all data exceptions
+		if resultType, ok := errorMessageRegex.Classify(err.Message, string(err.Code), string(err.Code.Class())); ok {
+			return resultType
 		}
 
 		logrus.Warnf("Encountered pqError with error code %s and msg %s", err.Code, err.Message)
@@ -171,7 +480,7 @@ func (d Driver) VerifyConnectivity(dbms.DBOptions) (bool, error) {
 	return err == nil, err
 }
 
-// IsEqualResult panics for apache age, as it hasn't been implemented yet
-func (d Driver) IsEqualResult(dbms.QueryResult, dbms.QueryResult) bool {
-	panic("IsEqualResult is unimplemented for apache AGE")
+// IsEqualResult returns true if the two passed query results hold the same information, else false.
+func (d Driver) IsEqualResult(a, b dbms.QueryResult) bool {
+	return dbms.DefaultComparator.EqualResults(a, b)
 }
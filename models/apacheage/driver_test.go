@@ -0,0 +1,242 @@
+package apacheage
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/fakeserver"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionString(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		opts     dbms.DBOptions
+		expected string
+	}{
+		{"Defaults", dbms.DBOptions{Host: "localhost"},
+			"host='localhost' port='5432' user='postgres' sslmode='disable'"},
+		{"Credentials", dbms.DBOptions{Host: "localhost", Username: "fuzzer", Password: `it's a \\secret`},
+			`host='localhost' port='5432' user='fuzzer' password='it\'s a \\\\secret' sslmode='disable'`},
+		{"Token takes precedence", dbms.DBOptions{Host: "localhost", Password: "password", Token: "token"},
+			"host='localhost' port='5432' user='postgres' password='token' sslmode='disable'"},
+		{"TLS without verification", dbms.DBOptions{Host: "localhost", TLS: &dbms.TLSOptions{SkipVerify: true}},
+			"host='localhost' port='5432' user='postgres' sslmode='require'"},
+		{"TLS with CA file", dbms.DBOptions{Host: "localhost", TLS: &dbms.TLSOptions{CAFile: "/ca.pem"}},
+			"host='localhost' port='5432' user='postgres' sslmode='verify-full' sslrootcert='/ca.pem'"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, connectionString(tc.opts, 5432))
+		})
+	}
+}
+
+func TestGraphName(t *testing.T) {
+	assert.Equal(t, "graph", graphName(dbms.DBOptions{}), "Default graph not used without namespace")
+	assert.Equal(t, "fuzzer_1", graphName(dbms.DBOptions{Namespace: "fuzzer_1"}), "Namespace not used as graph name")
+}
+
+func TestGetQueryResultType(t *testing.T) {
+	regex := &dbms.ErrorMessageRegex{
+		Ignored:       regexp.MustCompile("^ignored$"),
+		IgnoredCodes:  []string{"22"},
+		ReportedCodes: []string{"XX000"},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		err      error
+		expected dbms.QueryResultType
+	}{
+		{"No error", nil, dbms.Valid},
+		{"Cancelled query", &pq.Error{Code: "57014"}, dbms.Timeout},
+		{"Ignored message", &pq.Error{Code: "42601", Message: "ignored"}, dbms.Invalid},
+		{"Ignored class", &pq.Error{Code: "22003", Message: "integer out of range"}, dbms.Invalid},
+		{"Reported code", &pq.Error{Code: "XX000", Message: "internal error"}, dbms.ReportedBug},
+		{"Unknown error", &pq.Error{Code: "42601", Message: "syntax error"}, dbms.Bug},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Driver{}.GetQueryResultType(dbms.QueryResult{ProducedError: tc.err}, regex))
+		})
+	}
+}
+
+// The agtype values of the vertices and the edge returned by the fake servers
+const (
+	vertexA = `{"id": 844424930131969, "label": "A", "properties": {"p": 1}}::vertex`
+	vertexB = `{"id": 844424930131970, "label": "", "properties": {}}::vertex`
+	edgeT   = `{"id": 1125899906842625, "label": "T", "end_id": 844424930131970, "start_id": 844424930131969, "properties": {}}::edge`
+)
+
+// startDriver starts a fake postgres answering statements using the passed handler and returns a driver connected to it.
+//
+// The handler only gets passed statements other than the boilerplate run by every transaction and the queries of backend PIDs.
+func startDriver(t *testing.T, handler fakeserver.PostgresHandler) (*Driver, *fakeserver.PostgresServer, dbms.DBOptions) {
+	server := fakeserver.StartPostgres(t, func(statement string) fakeserver.PostgresResult {
+		switch {
+		case statement == `SELECT pg_backend_pid();`:
+			return fakeserver.PostgresResult{Columns: []string{"pg_backend_pid"}, Rows: [][]any{{42}}}
+		case strings.HasPrefix(statement, "LOAD "), strings.HasPrefix(statement, "SET "):
+			return fakeserver.PostgresResult{}
+		case handler != nil:
+			return handler(statement)
+		}
+		return fakeserver.PostgresResult{}
+	})
+	d, opts, err := fakeserver.InitDriver(server, &Driver{})
+	require.NoError(t, err)
+	t.Cleanup(func() { d.driver.Close() })
+	return d, server, opts
+}
+
+func TestReset(t *testing.T) {
+	graphExists := false
+	d, server, opts := startDriver(t, func(statement string) fakeserver.PostgresResult {
+		if strings.HasPrefix(statement, "SELECT drop_graph(") && !graphExists {
+			return fakeserver.PostgresResult{Code: "3F000", Message: `graph "graph" does not exist`}
+		}
+		return fakeserver.PostgresResult{Columns: []string{"result"}, Rows: [][]any{{""}}}
+	})
+
+	assert.NoError(t, d.Reset(opts), "nonexistent graphs shouldn't fail the reset")
+	assert.Equal(t, []string{
+		"BEGIN READ WRITE", `LOAD 'age';`, `SET search_path = ag_catalog, "$user", public;`, `SELECT drop_graph('graph', true);`, "ROLLBACK",
+		"BEGIN READ WRITE", `LOAD 'age';`, `SET search_path = ag_catalog, "$user", public;`, `SELECT create_graph('graph');`, "COMMIT",
+	}, server.Received())
+
+	graphExists = true
+	opts.Namespace = "fuzzer_1"
+	assert.NoError(t, d.Reset(opts))
+	assert.Contains(t, server.Received(), `SELECT drop_graph('fuzzer_1', true);`)
+	assert.Contains(t, server.Received(), `SELECT create_graph('fuzzer_1');`)
+}
+
+func TestRunQuery(t *testing.T) {
+	d, server, opts := startDriver(t, func(statement string) fakeserver.PostgresResult {
+		switch {
+		case strings.Contains(statement, "RETURN a, 1.5, null"):
+			return fakeserver.PostgresResult{Columns: []string{"c0", "c1", "c2"}, Rows: [][]any{{vertexA, "1.5", nil}}}
+		case strings.Contains(statement, "MATCH (n) RETURN n AS x UNION MATCH ()-[m]->() RETURN m AS x"):
+			return fakeserver.PostgresResult{Columns: []string{"c0"}, Rows: [][]any{{vertexA}, {vertexB}, {edgeT}}}
+		case strings.Contains(statement, "RETURN 1 / 0"):
+			return fakeserver.PostgresResult{Code: "22012", Message: "division by zero"}
+		}
+		return fakeserver.PostgresResult{}
+	})
+
+	res := d.RunQuery(context.Background(), opts, "MATCH (a:A) RETURN a, 1.5, null")
+	assert.NoError(t, res.ProducedError)
+	nodeA := dbms.Node{Labels: []string{"A"}, Properties: dbms.Map{"p": dbms.Int(1)}}
+	nodeB := dbms.Node{Properties: dbms.Map{}}
+	assert.Equal(t, []dbms.Row{{nodeA, dbms.Float(1.5), dbms.Null{}}}, res.Rows)
+	assert.Contains(t, server.Received(), "SELECT * FROM cypher('graph',$$\n\tMATCH (a:A) RETURN a, 1.5, null\n$$) as (c0 agtype, c1 agtype, c2 agtype);")
+
+	expectedGraph := dbms.NewGraph()
+	expectedGraph.AddNode("844424930131969", nodeA)
+	expectedGraph.AddNode("844424930131970", nodeB)
+	expectedGraph.AddRelationship("1125899906842625", "844424930131969", "844424930131970", dbms.Relationship{Type: "T", Properties: dbms.Map{}})
+	assert.Equal(t, expectedGraph, res.Graph)
+
+	regex := &dbms.ErrorMessageRegex{IgnoredCodes: []string{"22"}}
+	res = d.RunQuery(context.Background(), opts, "RETURN 1 / 0")
+	assert.EqualError(t, res.ProducedError, "pq: division by zero")
+	assert.Equal(t, dbms.Invalid, d.GetQueryResultType(res, regex))
+}
+
+func TestRunQuery_Snapshot(t *testing.T) {
+	d, server, opts := startDriver(t, func(statement string) fakeserver.PostgresResult {
+		if strings.Contains(statement, "RETURN 1 / 0") {
+			return fakeserver.PostgresResult{Code: "22012", Message: "division by zero"}
+		}
+		return fakeserver.PostgresResult{}
+	})
+	assert.NoError(t, d.Snapshot(opts))
+
+	res := d.RunQuery(context.Background(), opts, "RETURN 1 / 0")
+	assert.Error(t, res.ProducedError)
+	res = d.RunQuery(context.Background(), opts, "CREATE ()")
+	assert.NoError(t, res.ProducedError, "failed statements shouldn't abort the snapshot's transaction")
+	assert.NoError(t, d.Restore(opts))
+
+	received := server.Received()
+	assert.Contains(t, received, "ROLLBACK TO SAVEPOINT statement;")
+	assert.Contains(t, received, "RELEASE SAVEPOINT statement;")
+	assert.Equal(t, 2, countOf(received, "BEGIN READ WRITE"), "statements should run in the snapshot's transaction")
+}
+
+func TestRunQuery_SnapshotReleaseFailure(t *testing.T) {
+	failRelease := true
+	d, server, opts := startDriver(t, func(statement string) fakeserver.PostgresResult {
+		if statement == "RELEASE SAVEPOINT statement;" && failRelease {
+			failRelease = false
+			return fakeserver.PostgresResult{Code: "57014", Message: "canceling statement due to user request"}
+		}
+		return fakeserver.PostgresResult{}
+	})
+	assert.NoError(t, d.Snapshot(opts))
+
+	res := d.RunQuery(context.Background(), opts, "CREATE ()")
+	assert.EqualError(t, res.ProducedError, "pq: canceling statement due to user request")
+	received := server.Received()
+	assert.Equal(t, "ROLLBACK TO SAVEPOINT statement;", received[len(received)-1], "the statement should be rolled back if releasing it fails")
+
+	res = d.RunQuery(context.Background(), opts, "CREATE ()")
+	assert.NoError(t, res.ProducedError)
+}
+
+func TestRunQuery_BackendTermination(t *testing.T) {
+	d, _, opts := startDriver(t, func(statement string) fakeserver.PostgresResult {
+		if strings.Contains(statement, "RETURN 'crash'") {
+			return fakeserver.PostgresResult{Code: "57P01", Message: "terminating connection due to administrator command", Terminate: true}
+		}
+		return fakeserver.PostgresResult{}
+	})
+
+	res := d.RunQuery(context.Background(), opts, "RETURN 'crash'")
+	assert.Equal(t, dbms.Crash, d.GetQueryResultType(res, &dbms.ErrorMessageRegex{}))
+
+	ok, err := d.VerifyConnectivity(opts)
+	assert.True(t, ok, "a new connection should be established")
+	assert.NoError(t, err)
+}
+
+func TestGetSchema(t *testing.T) {
+	d, _, opts := startDriver(t, func(statement string) fakeserver.PostgresResult {
+		switch {
+		case strings.Contains(statement, "ag_catalog.ag_label"):
+			return fakeserver.PostgresResult{Columns: []string{"name", "kind"}, Rows: [][]any{{"A", "v"}, {"B", "v"}, {"T", "e"}}}
+		case strings.Contains(statement, "MATCH (n) RETURN properties(n)"):
+			return fakeserver.PostgresResult{Columns: []string{"c0"}, Rows: [][]any{{`{"p": 1, "l": ["a"]}`}, {`{"m": {"k": 1}}`}}}
+		}
+		return fakeserver.PostgresResult{}
+	})
+
+	s, err := d.GetSchema(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"A", "B"}, s.Labels[schema.NODE])
+	assert.Equal(t, []string{"T"}, s.Labels[schema.RELATIONSHIP])
+	assert.Equal(t, []string{"T", "A", "B"}, s.Labels[schema.ANY])
+
+	expected := &schema.Schema{}
+	expected.Reset()
+	expected.AddProperty(schema.Property{Name: "l", Type: schema.String | schema.PropertyType(schema.ListMask), Value: `["a"]`})
+	expected.AddProperty(schema.Property{Name: "p", Type: schema.Integer, Value: "1"})
+	assert.Equal(t, expected.Properties, s.Properties, "maps should be skipped")
+}
+
+// countOf returns how often the statement got received.
+func countOf(received []string, statement string) int {
+	count := 0
+	for _, r := range received {
+		if r == statement {
+			count++
+		}
+	}
+	return count
+}
@@ -116,9 +116,10 @@ func (Implementation) GetDropIns() translator.DropIns {
 			return &clauses.SetPropertyExpression{}
 		},
 
-		// Can only return a single column at once with the SQL foundation given
-		reflect.TypeOf(&clauses.ReturnElementChain{}): func(c translator.Clause, s1 *seed.Seed, s2 *schema.Schema) translator.Clause {
-			return &clauses.ReturnElement{}
+		// The returned columns have to be declared in the SQL statement, which isn't possible for RETURN *
+		reflect.TypeOf(&clauses.Return{}): func(c translator.Clause, s1 *seed.Seed, s2 *schema.Schema) translator.Clause {
+			s2.DisallowReturnAll = true
+			return c
 		},
 	}
 }
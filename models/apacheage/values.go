@@ -0,0 +1,105 @@
+package apacheage
+
+import (
+	"fmt"
+
+	"github.com/Anon10214/dinkel/dbms"
+)
+
+// toRows converts the rows returned by [runCypher] into rows of [dbms.Value].
+func toRows(rows []any) []dbms.Row {
+	res := make([]dbms.Row, len(rows))
+	for i, row := range rows {
+		values := row.([]any)
+		res[i] = make(dbms.Row, len(values))
+		for j, v := range values {
+			res[i][j] = toValue(v)
+		}
+	}
+	return res
+}
+
+// toValue converts a parsed agtype value into its [dbms.Value].
+func toValue(v any) dbms.Value {
+	switch v := v.(type) {
+	case []any:
+		list := make(dbms.List, len(v))
+		for i, elem := range v {
+			list[i] = toValue(elem)
+		}
+		return list
+	case map[string]any:
+		return toMap(v)
+	case Vertex:
+		return toNode(v)
+	case Edge:
+		return toRelationship(v)
+	case Path:
+		path := dbms.Path{}
+		for _, elem := range v {
+			switch elem := elem.(type) {
+			case Vertex:
+				path.Nodes = append(path.Nodes, toNode(elem))
+			case Edge:
+				path.Relationships = append(path.Relationships, toRelationship(elem))
+			}
+		}
+		return path
+	}
+	if value, ok := dbms.ScalarValue(v); ok {
+		return value
+	}
+	return dbms.UnknownValue(v)
+}
+
+// toGraph collects the nodes and relationships found in the rows returned by [runCypher] into a graph.
+// Nodes and relationships may be nested in lists, maps and paths.
+func toGraph(rows []any) *dbms.Graph {
+	graph := dbms.NewGraph()
+	for _, row := range rows {
+		addToGraph(graph, row)
+	}
+	return graph
+}
+
+func addToGraph(graph *dbms.Graph, v any) {
+	switch v := v.(type) {
+	case []any:
+		for _, elem := range v {
+			addToGraph(graph, elem)
+		}
+	case map[string]any:
+		for _, elem := range v {
+			addToGraph(graph, elem)
+		}
+	case Vertex:
+		graph.AddNode(fmt.Sprint(v.ID), toNode(v))
+	case Edge:
+		graph.AddRelationship(fmt.Sprint(v.ID), fmt.Sprint(v.StartID), fmt.Sprint(v.EndID), toRelationship(v))
+	case Path:
+		for _, elem := range v {
+			addToGraph(graph, elem)
+		}
+	}
+}
+
+func toMap(m map[string]any) dbms.Map {
+	res := make(dbms.Map, len(m))
+	for k, v := range m {
+		res[k] = toValue(v)
+	}
+	return res
+}
+
+func toNode(v Vertex) dbms.Node {
+	// Apache age vertices have a single label, unlabeled ones have none
+	var labels []string
+	if v.Label != "" {
+		labels = []string{v.Label}
+	}
+	return dbms.Node{Labels: labels, Properties: toMap(v.Properties)}
+}
+
+func toRelationship(e Edge) dbms.Relationship {
+	return dbms.Relationship{Type: e.Label, Properties: toMap(e.Properties)}
+}
@@ -0,0 +1,148 @@
+package bolt
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"text/template"
+
+	"github.com/Anon10214/dinkel/dbms"
+	neo4jimpl "github.com/Anon10214/dinkel/models/neo4j"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+)
+
+// Config describes a Bolt-speaking target.
+// It is read from the `bolt` entry of the target in the targets config.
+type Config struct {
+	// The URI scheme used to connect to the target, bolt if unset
+	Scheme string `yaml:"scheme"`
+	// The port used if none was passed, 7687 if unset
+	DefaultPort int `yaml:"defaultPort"`
+
+	// Statements run after connecting, e.g. to configure the server-side query timeout.
+	// They are templates which get passed the [dbms.DBOptions], e.g. `{{ .Timeout.Seconds }}`.
+	SetupStatements []string `yaml:"setupStatements"`
+	// Statements run to reset the database, deletes all nodes and relationships if unset
+	ResetStatements []string `yaml:"resetStatements"`
+
+	// Query returning the distinct node labels in its first column, ordered
+	NodeLabelsQuery string `yaml:"nodeLabelsQuery"`
+	// Query returning the distinct relationship types in its first column, ordered
+	RelationshipLabelsQuery string `yaml:"relationshipLabelsQuery"`
+	// Query returning property names in its first column and the properties' values in its second
+	PropertiesQuery string `yaml:"propertiesQuery"`
+	// Query returning all nodes and relationships of the graph, compared by strategies fuzzing for logic bugs.
+	// They may be returned in any column and nested in lists, maps or paths.
+	GraphQuery string `yaml:"graphQuery"`
+
+	// Maps the titles of errors returned by the target, such as SyntaxError for Neo.ClientError.Statement.SyntaxError,
+	// to the result type they indicate, such as INVALID or TIMEOUT.
+	// The messages of errors with unmapped titles are matched against the ignored and reported errors.
+	ErrorTitles map[string]string `yaml:"errorTitles"`
+	// Regexes matching messages of errors caused by a query timing out
+	TimeoutErrors []string `yaml:"timeoutErrors"`
+	// Don't send the timeout along with the queries, for targets rejecting transaction timeouts
+	DisableTransactionTimeout bool `yaml:"disableTransactionTimeout"`
+
+	// Query returning the IDs of a cancelled query's transactions in its first column.
+	// It gets passed the ID the transactions' metadata got tagged with under the key dinkelQueryId as $id.
+	// Neo4j's SHOW TRANSACTIONS query if unset.
+	TransactionsQuery string `yaml:"transactionsQuery"`
+	// Statement terminating the transactions whose IDs get passed as $ids, Neo4j's TERMINATE TRANSACTIONS if unset
+	TerminateStatement string `yaml:"terminateStatement"`
+	// Don't terminate the transactions of cancelled queries, for targets not supporting it.
+	// Cancelled queries then only stop once the target's own timeout aborts them.
+	DisableTermination bool `yaml:"disableTermination"`
+
+	// Functions the target doesn't implement
+	DisallowedFunctions []string `yaml:"disallowedFunctions"`
+	// Property types the target doesn't support, named as in [propertyTypesByName]
+	DisallowedPropertyTypes []string `yaml:"disallowedPropertyTypes"`
+	// The generation config's flags of the same name
+	OnlyVariablesAsWriteTarget      bool `yaml:"onlyVariablesAsWriteTarget"`
+	AsteriskNeedsTargets            bool `yaml:"asteriskNeedsTargets"`
+	DisallowMatchAfterOptionalMatch bool `yaml:"disallowMatchAfterOptionalMatch"`
+}
+
+// The names of property types as used in the config
+var propertyTypesByName = map[string]schema.PropertyType{
+	"BOOLEAN":        schema.Boolean,
+	"DATE":           schema.Date,
+	"DATETIME":       schema.Datetime,
+	"DURATION":       schema.Duration,
+	"FLOAT":          schema.Float,
+	"INTEGER":        schema.Integer,
+	"LOCAL_DATETIME": schema.LocalDateTime,
+	"LOCAL_TIME":     schema.LocalTime,
+	"POINT":          schema.Point,
+	"STRING":         schema.String,
+	"TIME":           schema.Time,
+}
+
+// withDefaults returns the config with unset fields set to values suitable for Neo4j-like targets.
+func (c Config) withDefaults() Config {
+	if c.Scheme == "" {
+		c.Scheme = "bolt"
+	}
+	if c.DefaultPort == 0 {
+		c.DefaultPort = 7687
+	}
+	if len(c.ResetStatements) == 0 {
+		c.ResetStatements = []string{"MATCH (n) DETACH DELETE n"}
+	}
+	if c.NodeLabelsQuery == "" {
+		c.NodeLabelsQuery = "MATCH (n) UNWIND labels(n) AS label RETURN DISTINCT label ORDER BY label"
+	}
+	if c.RelationshipLabelsQuery == "" {
+		c.RelationshipLabelsQuery = "MATCH ()-[m]->() RETURN DISTINCT type(m) AS label ORDER BY label"
+	}
+	if c.PropertiesQuery == "" {
+		c.PropertiesQuery = "MATCH (n) UNWIND keys(n) AS key RETURN key, n[key] AS value UNION MATCH ()-[m]->() UNWIND keys(m) AS key RETURN key, m[key] AS value"
+	}
+	if c.GraphQuery == "" {
+		c.GraphQuery = "MATCH (n) RETURN n AS x UNION MATCH ()-[m]->() RETURN m AS x"
+	}
+	if c.TransactionsQuery == "" {
+		c.TransactionsQuery = neo4jimpl.TransactionsQuery
+	}
+	if c.TerminateStatement == "" {
+		c.TerminateStatement = neo4jimpl.TerminateStatement
+	}
+	return c
+}
+
+// parseResultType returns the query result type whose string representation matches the passed name.
+func parseResultType(name string) (dbms.QueryResultType, error) {
+	for t := dbms.Valid; t <= dbms.Timeout; t++ {
+		if t.ToString() == name {
+			return t, nil
+		}
+	}
+	return dbms.None, fmt.Errorf("invalid query result type %q", name)
+}
+
+// compileTimeoutErrors compiles the timeout error regexes into a single regex.
+// Returns nil if no regexes are configured.
+func compileTimeoutErrors(timeoutErrors []string) (*regexp.Regexp, error) {
+	if len(timeoutErrors) == 0 {
+		return nil, nil
+	}
+	expr := ""
+	for _, msg := range timeoutErrors {
+		expr += fmt.Sprintf("(%s)|", msg)
+	}
+	return regexp.Compile(expr[:len(expr)-1])
+}
+
+// renderStatements executes the passed statement templates with the DB options.
+func renderStatements(statements []*template.Template, opts dbms.DBOptions) ([]string, error) {
+	var rendered []string
+	for _, statement := range statements {
+		var buf bytes.Buffer
+		if err := statement.Execute(&buf, opts); err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, buf.String())
+	}
+	return rendered, nil
+}
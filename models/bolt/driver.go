@@ -0,0 +1,333 @@
+/*
+Package bolt provides a model for targets speaking the Bolt protocol.
+
+Unlike the other models, the queries used to reset and introspect the target,
+the mapping of errors to result types and the functions used during generation
+are read from the targets config, allowing new Bolt-speaking targets to be
+fuzzed without writing a model for them.
+*/
+package bolt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"text/template"
+
+	"github.com/Anon10214/dinkel/dbms"
+	neo4jimpl "github.com/Anon10214/dinkel/models/neo4j"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/seed"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/sirupsen/logrus"
+)
+
+// Driver for Bolt-speaking targets
+type Driver struct {
+	conf Config
+
+	setupStatements []*template.Template
+	errorTitles     map[string]dbms.QueryResultType
+	timeoutErrors   *regexp.Regexp
+
+	driver  neo4j.DriverWithContext
+	session neo4j.SessionWithContext
+}
+
+// NewDriver returns a driver for the target described by the passed config.
+//
+// Returns an error if the config is invalid.
+func NewDriver(conf Config) (*Driver, error) {
+	d := &Driver{
+		conf:        conf.withDefaults(),
+		errorTitles: make(map[string]dbms.QueryResultType),
+	}
+
+	for _, statement := range d.conf.SetupStatements {
+		tmpl, err := template.New("setup-statement").Parse(statement)
+		if err != nil {
+			return nil, errors.Join(errors.New("failed to parse setup statement - "), err)
+		}
+		d.setupStatements = append(d.setupStatements, tmpl)
+	}
+
+	for title, resultType := range d.conf.ErrorTitles {
+		t, err := parseResultType(resultType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid result type for error title %s - %w", title, err)
+		}
+		d.errorTitles[title] = t
+	}
+
+	var err error
+	if d.timeoutErrors, err = compileTimeoutErrors(d.conf.TimeoutErrors); err != nil {
+		return nil, errors.Join(errors.New("failed to read the regexp for timeout errors - "), err)
+	}
+
+	for _, name := range d.conf.DisallowedPropertyTypes {
+		if _, ok := propertyTypesByName[name]; !ok {
+			return nil, fmt.Errorf("invalid disallowed property type %q", name)
+		}
+	}
+
+	return d, nil
+}
+
+// txConfig returns the transaction config to run queries with.
+func (d *Driver) txConfig(opts dbms.DBOptions) []func(*neo4j.TransactionConfig) {
+	if d.conf.DisableTransactionTimeout {
+		return nil
+	}
+	return []func(*neo4j.TransactionConfig){neo4j.WithTxTimeout(opts.Timeout)}
+}
+
+// Init the DB driver and run the setup statements
+func (d *Driver) Init(opts dbms.DBOptions) error {
+	connPort := d.conf.DefaultPort
+	if opts.Port != nil {
+		connPort = *opts.Port
+	}
+	tlsConfig, err := opts.TLS.Config()
+	if err != nil {
+		return err
+	}
+	driver, err := neo4j.NewDriverWithContext(neo4jimpl.TargetURI(d.conf.Scheme, connPort, opts), neo4jimpl.AuthToken(opts), func(c *neo4j.Config) {
+		c.ConnectionAcquisitionTimeout = opts.Timeout
+		c.MaxTransactionRetryTime = 0
+		c.TlsConfig = tlsConfig
+	})
+	if err != nil {
+		return err
+	}
+	d.driver = driver
+
+	statements, err := renderStatements(d.setupStatements, opts)
+	if err != nil {
+		return errors.Join(errors.New("failed to render setup statements - "), err)
+	}
+	session := driver.NewSession(context.Background(), neo4j.SessionConfig{DatabaseName: opts.Namespace})
+	defer session.Close(context.Background())
+	for _, statement := range statements {
+		if _, err := session.Run(context.Background(), statement, nil, d.txConfig(opts)...); err != nil {
+			logrus.Errorf("couldn't run setup statement %q - %v", statement, err)
+			return err
+		}
+	}
+
+	logrus.Debug("Setting up connection to the database")
+	return nil
+}
+
+// Reset the database by running the reset statements
+func (d *Driver) Reset(opts dbms.DBOptions) error {
+	logrus.Debug("Resetting Database")
+	ctx := context.Background()
+	d.session = d.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite, DatabaseName: opts.Namespace})
+
+	for _, statement := range d.conf.ResetStatements {
+		res, err := d.session.Run(ctx, statement, nil, d.txConfig(opts)...)
+		if err == nil {
+			_, err = res.Consume(ctx)
+		}
+		if err != nil {
+			logrus.Errorf("couldn't reset database - %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// collect runs the query and returns the values of all returned records.
+func (d *Driver) collect(ctx context.Context, opts dbms.DBOptions, query string, configurers ...func(*neo4j.TransactionConfig)) ([][]any, error) {
+	res, err := d.session.Run(ctx, query, nil, append(d.txConfig(opts), configurers...)...)
+	if err != nil {
+		return nil, err
+	}
+	rows := [][]any{}
+	for res.Next(ctx) {
+		rows = append(rows, res.Record().Values)
+	}
+	return rows, res.Err()
+}
+
+// RunQuery runs the query against the DB and returns its result.
+//
+// Once the context is done, the query's transactions get terminated using the configured statements,
+// unless termination is disabled.
+func (d *Driver) RunQuery(ctx context.Context, opts dbms.DBOptions, query string) dbms.QueryResult {
+	logrus.Debug("Sending query to database")
+	queryID := neo4jimpl.NewQueryID()
+	if !d.conf.DisableTermination {
+		terminated := make(chan struct{})
+		stop := context.AfterFunc(ctx, func() {
+			defer close(terminated)
+			if err := neo4jimpl.TerminateTransactions(d.driver, opts, queryID, d.conf.TransactionsQuery, d.conf.TerminateStatement); err != nil {
+				logrus.Warnf("Couldn't terminate cancelled query - %v", err)
+			}
+		})
+		defer func() {
+			// The driver mustn't be used anymore once the query returned
+			if !stop() {
+				<-terminated
+			}
+		}()
+	}
+
+	res, err := d.session.Run(ctx, query, nil, append(d.txConfig(opts), neo4jimpl.WithQueryID(queryID))...)
+	if err != nil {
+		logrus.Debugf("Error %v produced when running query %s", err, query)
+		return dbms.QueryResult{ProducedError: err}
+	}
+	queryResult, _ := neo4jimpl.CollectResult(ctx, res)
+	if queryResult.ProducedError != nil {
+		logrus.Debugf("Error %v produced when running query %s", queryResult.ProducedError, query)
+		return queryResult
+	}
+
+	// Get the graph to compare it when fuzzing for logic bugs
+	graph, err := d.collect(ctx, opts, d.conf.GraphQuery, neo4jimpl.WithQueryID(queryID))
+	if err != nil {
+		queryResult.ProducedError = err
+		logrus.Debugf("Error %v produced when trying to get schema", err)
+		return queryResult
+	}
+	queryResult.Graph = neo4jimpl.ToGraph(graph)
+
+	logrus.Debug("Query finished")
+	return queryResult
+}
+
+// GetSchema returns the database's current schema
+func (d *Driver) GetSchema(opts dbms.DBOptions) (*schema.Schema, error) {
+	s := &schema.Schema{}
+	s.Reset()
+
+	for _, labels := range []struct {
+		query string
+		t     schema.StructuralType
+	}{
+		{d.conf.NodeLabelsQuery, schema.NODE},
+		{d.conf.RelationshipLabelsQuery, schema.RELATIONSHIP},
+	} {
+		rows, err := d.collect(context.Background(), opts, labels.query)
+		if err != nil {
+			logrus.Errorf("Error while populating labels of schema: %v", err)
+			return nil, err
+		}
+		for _, values := range rows {
+			if len(values) > 0 {
+				if label, ok := values[0].(string); ok {
+					s.Labels[labels.t] = append(s.Labels[labels.t], label)
+				}
+			}
+		}
+	}
+	s.Labels[schema.ANY] = append(s.Labels[schema.RELATIONSHIP], s.Labels[schema.NODE]...)
+	logrus.Tracef("Populated schema with labels %v", s.Labels)
+
+	if err := d.populateProperties(opts, s); err != nil {
+		logrus.Errorf("Error while populating properties of schema: %v", err)
+		return nil, err
+	}
+	logrus.Tracef("Populated schema with properties %v", s.Properties)
+
+	return s, nil
+}
+
+// populateProperties fetches all properties in the DB and inserts them into the schema.
+//
+// The properties get sorted before being added, as the order of the returned rows isn't necessarily deterministic.
+func (d *Driver) populateProperties(opts dbms.DBOptions, s *schema.Schema) error {
+	rows, err := d.collect(context.Background(), opts, d.conf.PropertiesQuery)
+	if err != nil {
+		return err
+	}
+
+	var properties []schema.Property
+	for _, values := range rows {
+		if len(values) < 2 {
+			continue
+		}
+		name, ok := values[0].(string)
+		if !ok {
+			continue
+		}
+		propType, ok := neo4jimpl.PropertyTypeOf(values[1])
+		if !ok {
+			logrus.Debugf("Skipping property %s of unsupported value %v", name, values[1])
+			continue
+		}
+		property := schema.Property{
+			Name:  name,
+			Type:  propType,
+			Value: schema.Literal(values[1], neo4jimpl.ValueExpression),
+		}
+		if !slices.Contains(properties, property) {
+			properties = append(properties, property)
+		}
+	}
+
+	slices.SortFunc(properties, schema.CompareProperties)
+	for _, property := range properties {
+		s.AddProperty(property)
+	}
+	return nil
+}
+
+// GetQueryResultType evaluates the produced result and returns the type the result indicates.
+//
+// Errors are classified by their title if it is mapped in the config, and by their message otherwise.
+func (d *Driver) GetQueryResultType(res dbms.QueryResult, errorMessageRegex *dbms.ErrorMessageRegex) dbms.QueryResultType {
+	err := res.ProducedError
+	if err == nil {
+		return dbms.Valid
+	}
+
+	switch err := err.(type) {
+	case *neo4j.ConnectivityError:
+		return dbms.Crash
+	case *neo4j.Neo4jError:
+		if d.timeoutErrors != nil && d.timeoutErrors.MatchString(err.Msg) {
+			return dbms.Timeout
+		}
+
+		if resultType, ok := d.errorTitles[err.Title()]; ok {
+			return resultType
+		}
+
+		if resultType, ok := errorMessageRegex.Classify(err.Msg, err.Code, err.GqlStatus); ok {
+			return resultType
+		}
+
+		logrus.Warnf("Encountered Neo4jError with error title %q and msg %s", err.Title(), err.Msg)
+		return dbms.Bug
+	default:
+		return dbms.Bug
+	}
+}
+
+// DiscardQuery returns true with probability 1/10 or if the query produced a non-nil error,
+// else it returns false. Thereby causing queries to have an expected amount of 11 statements
+// if they don't produce an error.
+func (d *Driver) DiscardQuery(res dbms.QueryResult, seed *seed.Seed) bool {
+	if res.ProducedError != nil {
+		return true
+	}
+
+	return seed.BooleanWithProbability(0.1)
+}
+
+// VerifyConnectivity checks whether the DB is still reachable and hasn't crashed.
+func (d *Driver) VerifyConnectivity(opts dbms.DBOptions) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	err := d.driver.VerifyConnectivity(ctx)
+	return err == nil, err
+}
+
+// IsEqualResult returns true if the two passed query results hold the same information, else false.
+func (d *Driver) IsEqualResult(a, b dbms.QueryResult) bool {
+	return dbms.DefaultComparator.EqualResults(a, b)
+}
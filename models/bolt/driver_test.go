@@ -0,0 +1,211 @@
+package bolt
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/fakeserver"
+	neo4jimpl "github.com/Anon10214/dinkel/models/neo4j"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDriver(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		conf  Config
+		valid bool
+	}{
+		{"Empty config", Config{}, true},
+		{"Valid error titles", Config{ErrorTitles: map[string]string{"SyntaxError": "INVALID", "Timeout": "TIMEOUT"}}, true},
+		{"Invalid error title result type", Config{ErrorTitles: map[string]string{"SyntaxError": "IGNORED"}}, false},
+		{"Invalid timeout regex", Config{TimeoutErrors: []string{"("}}, false},
+		{"Invalid setup statement template", Config{SetupStatements: []string{"{{ .Timeout"}}, false},
+		{"Valid disallowed property types", Config{DisallowedPropertyTypes: []string{"POINT", "LOCAL_DATETIME"}}, true},
+		{"Invalid disallowed property type", Config{DisallowedPropertyTypes: []string{"MAP"}}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewDriver(tc.conf)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestConfigDefaults(t *testing.T) {
+	d, err := NewDriver(Config{DefaultPort: 7688})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "bolt", d.conf.Scheme)
+	assert.Equal(t, 7688, d.conf.DefaultPort, "Configured value got overwritten")
+	assert.Equal(t, []string{"MATCH (n) DETACH DELETE n"}, d.conf.ResetStatements)
+	assert.NotEmpty(t, d.conf.NodeLabelsQuery)
+	assert.NotEmpty(t, d.conf.RelationshipLabelsQuery)
+	assert.NotEmpty(t, d.conf.PropertiesQuery)
+	assert.NotEmpty(t, d.conf.GraphQuery)
+	assert.NotEmpty(t, d.conf.TransactionsQuery)
+	assert.NotEmpty(t, d.conf.TerminateStatement)
+}
+
+func TestRenderStatements(t *testing.T) {
+	d, err := NewDriver(Config{SetupStatements: []string{`SET DATABASE SETTING "query.timeout" TO "{{ .Timeout.Seconds }}"`}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	statements, err := renderStatements(d.setupStatements, dbms.DBOptions{Timeout: 1500 * time.Millisecond})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{`SET DATABASE SETTING "query.timeout" TO "1.5"`}, statements)
+	}
+}
+
+func TestGetQueryResultType(t *testing.T) {
+	d, err := NewDriver(Config{
+		ErrorTitles:   map[string]string{"SyntaxError": "INVALID", "DatabaseError": "BUG"},
+		TimeoutErrors: []string{"^Query timed out$"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	regex := &dbms.ErrorMessageRegex{
+		Ignored:       regexp.MustCompile("^ignored$"),
+		Reported:      regexp.MustCompile("^reported$"),
+		IgnoredCodes:  []string{"Neo.ClientError.Statement.TypeError"},
+		ReportedCodes: []string{"50N42"},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		err      error
+		expected dbms.QueryResultType
+	}{
+		{"No error", nil, dbms.Valid},
+		{"Mapped title", &neo4j.Neo4jError{Code: "Neo.ClientError.Statement.SyntaxError", Msg: "reported"}, dbms.Invalid},
+		{"Mapped title overrides ignored message", &neo4j.Neo4jError{Code: "Neo.DatabaseError.General.DatabaseError", Msg: "ignored"}, dbms.Bug},
+		{"Timeout message", &neo4j.Neo4jError{Code: "Neo.ClientError.Statement.SyntaxError", Msg: "Query timed out"}, dbms.Timeout},
+		{"Ignored message", &neo4j.Neo4jError{Code: "Neo.ClientError.Statement.ArithmeticError", Msg: "ignored"}, dbms.Invalid},
+		{"Reported message", &neo4j.Neo4jError{Code: "Neo.ClientError.Statement.ArithmeticError", Msg: "reported"}, dbms.ReportedBug},
+		{"Ignored code", &neo4j.Neo4jError{Code: "Neo.ClientError.Statement.TypeError", Msg: "unknown"}, dbms.Invalid},
+		{"Reported GQLSTATUS", &neo4j.Neo4jError{Code: "Neo.ClientError.Statement.ArithmeticError", GqlStatus: "50N42", Msg: "unknown"}, dbms.ReportedBug},
+		{"Unknown error", &neo4j.Neo4jError{Code: "Neo.ClientError.Statement.ArithmeticError", Msg: "unknown"}, dbms.Bug},
+		{"Connectivity error", &neo4j.ConnectivityError{}, dbms.Crash},
+		{"Other error", errors.New("other"), dbms.Bug},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, d.GetQueryResultType(dbms.QueryResult{ProducedError: tc.err}, regex))
+		})
+	}
+}
+
+func TestGetOpenCypherConfig(t *testing.T) {
+	conf := Implementation{Config: Config{
+		DisallowedFunctions:        []string{"range"},
+		DisallowedPropertyTypes:    []string{"POINT", "DURATION"},
+		OnlyVariablesAsWriteTarget: true,
+	}}.GetOpenCypherConfig()
+
+	assert.Equal(t, []string{"range"}, conf.DisallowedFunctions)
+	assert.Equal(t, []schema.PropertyType{schema.Point, schema.Duration}, conf.DisallowedPropertyTypes)
+	assert.True(t, conf.OnlyVariablesAsWriteTarget)
+	assert.False(t, conf.AsteriskNeedsTargets)
+}
+
+func TestStatementsAgainstServer(t *testing.T) {
+	server := fakeserver.StartBolt(t, func(query string, params map[string]any) fakeserver.BoltResult {
+		switch query {
+		case "RETURN 1 / 0":
+			return fakeserver.BoltResult{Code: "Vendor.ClientError.Statement.ArithmeticError", Message: "/ by zero"}
+		case "RETURN 'slow'":
+			return fakeserver.BoltResult{Code: "Vendor.ClientError.General.Unknown", Message: "query took too long"}
+		}
+		return fakeserver.BoltResult{Columns: []string{"x"}, Rows: [][]any{{int64(1)}}}
+	})
+	d, err := NewDriver(Config{
+		SetupStatements: []string{`SET TIMEOUT {{ .Timeout.Seconds }}`},
+		ResetStatements: []string{"DROP GRAPH", "CREATE GRAPH"},
+		ErrorTitles:     map[string]string{"ArithmeticError": "INVALID"},
+		TimeoutErrors:   []string{"took too long"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	opts := server.Options()
+	if !assert.NoError(t, d.Init(opts)) {
+		return
+	}
+	defer d.driver.Close(context.Background())
+	assert.NoError(t, d.Reset(opts))
+	assert.Equal(t, []string{"SET TIMEOUT 5", "DROP GRAPH", "CREATE GRAPH"}, server.Received())
+
+	regex := &dbms.ErrorMessageRegex{}
+	res := d.RunQuery(context.Background(), opts, "RETURN 1 AS x")
+	assert.NoError(t, res.ProducedError)
+	assert.Equal(t, []dbms.Row{{dbms.Int(1)}}, res.Rows)
+	assert.Equal(t, dbms.Invalid, d.GetQueryResultType(d.RunQuery(context.Background(), opts, "RETURN 1 / 0"), regex))
+	assert.Equal(t, dbms.Timeout, d.GetQueryResultType(d.RunQuery(context.Background(), opts, "RETURN 'slow'"), regex))
+}
+
+func TestRunQuery_Termination(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		conf     Config
+		expected []string
+	}{
+		{"Configured statements", Config{TransactionsQuery: "CALL transactions($id)", TerminateStatement: "CALL terminate($ids)"}, []string{"CALL transactions($id)", "CALL terminate($ids)"}},
+		{"Termination disabled", Config{DisableTermination: true}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			terminated := make(chan struct{})
+			var terminatedIDs []any
+			server := fakeserver.StartBolt(t, func(query string, params map[string]any) fakeserver.BoltResult {
+				switch query {
+				case "RETURN 'hang'":
+					select {
+					case <-terminated:
+					case <-time.After(200 * time.Millisecond):
+					}
+					return fakeserver.BoltResult{Code: "Vendor.TransientError.Transaction.Terminated", Message: "terminated"}
+				case "CALL transactions($id)":
+					return fakeserver.BoltResult{Columns: []string{"id"}, Rows: [][]any{{"tx-1"}}}
+				case "CALL terminate($ids)":
+					terminatedIDs = params["ids"].([]any)
+					close(terminated)
+				}
+				return fakeserver.BoltResult{}
+			})
+			d, err := NewDriver(tc.conf)
+			if !assert.NoError(t, err) {
+				return
+			}
+			opts := server.Options()
+			if !assert.NoError(t, d.Init(opts)) {
+				return
+			}
+			defer d.driver.Close(context.Background())
+			assert.NoError(t, d.Reset(opts))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			d.RunQuery(ctx, opts, "RETURN 'hang'")
+
+			var received []string
+			for _, query := range server.Received() {
+				if query == "CALL transactions($id)" || query == "CALL terminate($ids)" || query == neo4jimpl.TransactionsQuery {
+					received = append(received, query)
+				}
+			}
+			assert.Equal(t, tc.expected, received)
+			if tc.expected != nil {
+				assert.Equal(t, []any{"tx-1"}, terminatedIDs)
+			}
+		})
+	}
+}
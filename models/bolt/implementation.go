@@ -0,0 +1,36 @@
+package bolt
+
+import (
+	"github.com/Anon10214/dinkel/models/opencypher/config"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/translator"
+)
+
+// Implementation for Bolt-speaking targets
+type Implementation struct {
+	Config Config
+}
+
+// GetDropIns returns no drop-ins, queries get generated as for plain OpenCypher
+func (Implementation) GetDropIns() translator.DropIns {
+	return translator.DropIns{}
+}
+
+// GetOpenCypherConfig returns the generation config described by the target's config
+func (i Implementation) GetOpenCypherConfig() config.Config {
+	var disallowedPropertyTypes []schema.PropertyType
+	for _, name := range i.Config.DisallowedPropertyTypes {
+		// Invalid names were already rejected by NewDriver
+		if propType, ok := propertyTypesByName[name]; ok {
+			disallowedPropertyTypes = append(disallowedPropertyTypes, propType)
+		}
+	}
+
+	return config.Config{
+		OnlyVariablesAsWriteTarget:      i.Config.OnlyVariablesAsWriteTarget,
+		AsteriskNeedsTargets:            i.Config.AsteriskNeedsTargets,
+		DisallowMatchAfterOptionalMatch: i.Config.DisallowMatchAfterOptionalMatch,
+		DisallowedPropertyTypes:         disallowedPropertyTypes,
+		DisallowedFunctions:             i.Config.DisallowedFunctions,
+	}
+}
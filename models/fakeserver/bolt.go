@@ -0,0 +1,230 @@
+package fakeserver
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sync/atomic"
+	"testing"
+)
+
+// A BoltResult is the result of a query run on a [BoltServer].
+type BoltResult struct {
+	Columns []string
+	// The rows' values, which may be nil, booleans, integers, floats, strings, slices, maps with string keys,
+	// [Node], [Relationship] and [Path] values
+	Rows [][]any
+	// Metadata sent once the result got consumed, e.g. the update counters under the key "stats"
+	Metadata map[string]any
+	// The code and message of the error the query fails with, the query succeeds if the code is empty.
+	//
+	// The code is classified like Neo4j's status codes, e.g. "Neo.ClientError.Statement.SyntaxError".
+	Code    string
+	Message string
+}
+
+// A BoltHandler answers a query sent to a [BoltServer], getting passed the query and its parameters.
+//
+// Handlers may get called concurrently.
+type BoltHandler func(query string, params map[string]any) BoltResult
+
+// A BoltServer speaks version 4.4 of Bolt, the protocol of Neo4j and Memgraph.
+//
+// It accepts any credentials and acknowledges transactions without isolating them,
+// all queries get answered by its handler.
+type BoltServer struct {
+	*server
+	handler BoltHandler
+	nextID  atomic.Int64
+}
+
+// StartBolt starts a Bolt server answering queries using the passed handler, an empty result is returned if it is nil.
+//
+// Received queries are recorded, other messages are not.
+func StartBolt(t testing.TB, handler BoltHandler) *BoltServer {
+	t.Helper()
+	s := &BoltServer{handler: handler}
+	s.server = start(t, s.serveConn)
+	return s
+}
+
+// Bolt message tags
+const (
+	boltHello    = 0x01
+	boltGoodbye  = 0x02
+	boltReset    = 0x0F
+	boltRun      = 0x10
+	boltBegin    = 0x11
+	boltCommit   = 0x12
+	boltRollback = 0x13
+	boltDiscard  = 0x2F
+	boltPull     = 0x3F
+	boltLogon    = 0x6A
+	boltLogoff   = 0x6B
+	boltSuccess  = 0x70
+	boltRecord   = 0x71
+	boltIgnored  = 0x7E
+	boltFailure  = 0x7F
+)
+
+// boltMagic is sent by clients before proposing the versions they support.
+var boltMagic = []byte{0x60, 0x60, 0xB0, 0x17}
+
+func (s *BoltServer) serveConn(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+
+	handshake := make([]byte, 20)
+	if _, err := io.ReadFull(r, handshake); err != nil || string(handshake[:4]) != string(boltMagic) {
+		return
+	}
+	// The proposed versions aren't checked, as all drivers used support 4.4
+	if _, err := conn.Write([]byte{0, 0, 4, 4}); err != nil {
+		return
+	}
+
+	connectionID := fmt.Sprintf("bolt-%d", s.nextID.Add(1))
+	// Whether a failure occurred since the last reset, in which case all other messages are ignored
+	failed := false
+	// The result of the last query, until it got consumed
+	var pending *BoltResult
+	for {
+		msg, err := readBoltMessage(r)
+		if err != nil {
+			return
+		}
+
+		var replies []structure
+		switch {
+		case msg.tag == boltGoodbye:
+			return
+		case msg.tag == boltReset:
+			failed, pending = false, nil
+			replies = append(replies, boltSucceed(nil))
+		case failed:
+			replies = append(replies, structure{boltIgnored, nil})
+		case msg.tag == boltHello:
+			replies = append(replies, boltSucceed(map[string]any{"server": "Neo4j/4.4.0", "connection_id": connectionID}))
+		case msg.tag == boltLogon, msg.tag == boltLogoff, msg.tag == boltBegin, msg.tag == boltRollback:
+			replies = append(replies, boltSucceed(nil))
+		case msg.tag == boltCommit:
+			replies = append(replies, boltSucceed(map[string]any{"bookmark": "fake:" + connectionID}))
+		case msg.tag == boltRun && len(msg.fields) >= 2:
+			query, _ := msg.fields[0].(string)
+			params, _ := msg.fields[1].(map[string]any)
+			s.record(query)
+			res := BoltResult{}
+			if s.handler != nil {
+				res = s.handler(query, params)
+			}
+			if res.Code != "" {
+				failed = true
+				replies = append(replies, boltFail(res.Code, res.Message))
+				break
+			}
+			pending = &res
+			replies = append(replies, boltSucceed(map[string]any{"fields": res.Columns, "t_first": int64(0)}))
+		case msg.tag == boltPull || msg.tag == boltDiscard:
+			if pending == nil {
+				failed = true
+				replies = append(replies, boltFail("Neo.ClientError.Request.Invalid", "there is no result to consume"))
+				break
+			}
+			n := int64(math.MaxInt64)
+			if len(msg.fields) > 0 {
+				extra, _ := msg.fields[0].(map[string]any)
+				if limit, ok := extra["n"].(int64); ok && limit >= 0 {
+					n = limit
+				}
+			}
+			for len(pending.Rows) > 0 && n > 0 {
+				if msg.tag == boltPull {
+					replies = append(replies, structure{boltRecord, []any{pending.Rows[0]}})
+				}
+				pending.Rows = pending.Rows[1:]
+				n--
+			}
+			if len(pending.Rows) > 0 {
+				replies = append(replies, boltSucceed(map[string]any{"has_more": true}))
+				break
+			}
+			metadata := map[string]any{"type": "rw", "db": "neo4j", "t_last": int64(0)}
+			for key, value := range pending.Metadata {
+				metadata[key] = value
+			}
+			pending = nil
+			replies = append(replies, boltSucceed(metadata))
+		default:
+			failed = true
+			replies = append(replies, boltFail("Neo.ClientError.Request.Invalid", fmt.Sprintf("fake server can't handle message 0x%X", msg.tag)))
+		}
+
+		for _, reply := range replies {
+			writeBoltMessage(w, reply)
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func boltSucceed(metadata map[string]any) structure {
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	return structure{boltSuccess, []any{metadata}}
+}
+
+func boltFail(code, message string) structure {
+	return structure{boltFailure, []any{map[string]any{"code": code, "message": message}}}
+}
+
+// readBoltMessage reads a message, sent in chunks prefixed by their size and terminated by an empty chunk.
+func readBoltMessage(r *bufio.Reader) (structure, error) {
+	var buf []byte
+	for {
+		header := make([]byte, 2)
+		if _, err := io.ReadFull(r, header); err != nil {
+			return structure{}, err
+		}
+		size := binary.BigEndian.Uint16(header)
+		if size == 0 {
+			if len(buf) == 0 {
+				// A NOOP sent to keep the connection alive
+				continue
+			}
+			break
+		}
+		chunk := make([]byte, size)
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return structure{}, err
+		}
+		buf = append(buf, chunk...)
+	}
+
+	u := unpacker{buf}
+	v, err := u.unpack()
+	if err != nil {
+		return structure{}, err
+	}
+	msg, ok := v.(structure)
+	if !ok {
+		return structure{}, fmt.Errorf("message %v is of type %T instead of a structure", v, v)
+	}
+	return msg, nil
+}
+
+// writeBoltMessage writes the message in chunks.
+func writeBoltMessage(w *bufio.Writer, msg structure) {
+	buf := pack(nil, msg)
+	for len(buf) > 0 {
+		size := min(len(buf), math.MaxUint16)
+		w.Write(binary.BigEndian.AppendUint16(nil, uint16(size)))
+		w.Write(buf[:size])
+		buf = buf[size:]
+	}
+	w.Write([]byte{0, 0})
+}
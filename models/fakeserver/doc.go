@@ -0,0 +1,13 @@
+/*
+Package fakeserver provides in-process servers speaking just enough of the protocols of the supported databases
+to unit test their drivers without running the databases.
+
+  - [RESPServer] speaks RESP, answering FalkorDB's and RedisGraph's GRAPH.* commands through a [GraphHandler].
+  - [BoltServer] speaks Bolt, as used by Neo4j and Memgraph.
+  - [PostgresServer] speaks the PostgreSQL wire protocol, as used by Apache AGE.
+
+The servers answer statements with canned results returned by a handler and record every statement they receive,
+which allows testing result parsing, error mapping, schema extraction and reset statements.
+Each server listens on a random local port until the test it got started in finishes.
+*/
+package fakeserver
@@ -0,0 +1,254 @@
+package fakeserver
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A GraphResult is the result of a query run using GRAPH.QUERY.
+type GraphResult struct {
+	Columns []string
+	// The rows' values, which may be nil, booleans, integers, floats, strings, slices, maps with string keys,
+	// [Node], [Relationship] and [Path] values
+	Rows [][]any
+	// Statistics such as "Nodes created: 1", the execution time gets appended to them
+	Statistics []string
+	// The query fails with this error message if it is non-empty
+	Error string
+}
+
+// A GraphHandler answers the GRAPH.* commands of FalkorDB and RedisGraph, replying in their compact format.
+//
+// Its [GraphHandler.Handle] method is used as the [RESPHandler] of a [RESPServer].
+// Labels, relationship types and property keys are numbered in the order they are returned,
+// and the procedures clients call to resolve their numbers are answered accordingly.
+type GraphHandler struct {
+	// Returns the result of the query run against the graph, an empty result if nil
+	Query func(graph, query string) GraphResult
+	// Returns the plan of the query profiled using GRAPH.PROFILE, which fails if nil
+	Profile func(graph, query string) []string
+
+	mu sync.Mutex
+	// The graphs queried or copied to and not deleted since
+	graphs                   map[string]bool
+	labels, types, propNames []string
+}
+
+// Handle answers the command, failing for commands other than GRAPH.QUERY, GRAPH.RO_QUERY, GRAPH.PROFILE, GRAPH.DELETE, GRAPH.COPY and EXISTS.
+//
+// Graphs hold no data, copying a graph only makes the copy exist.
+func (h *GraphHandler) Handle(args []string) any {
+	command := strings.ToUpper(args[0])
+	if command == "EXISTS" {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		existing := int64(0)
+		for _, key := range args[1:] {
+			if h.graphs[key] {
+				existing++
+			}
+		}
+		return existing
+	}
+	if len(args) < 2 {
+		return RESPError(fmt.Sprintf("ERR wrong number of arguments for '%s' command", args[0]))
+	}
+
+	graph := args[1]
+	switch command {
+	case "GRAPH.QUERY", "GRAPH.RO_QUERY":
+		if len(args) < 3 || !slices.Contains(args[3:], "--compact") {
+			return RESPError("ERR fake server only supports compact replies")
+		}
+		return h.query(graph, args[2])
+	case "GRAPH.PROFILE":
+		if len(args) < 3 || h.Profile == nil {
+			return RESPError("ERR fake server can't profile the query")
+		}
+		return h.Profile(graph, args[2])
+	case "GRAPH.DELETE":
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if !h.graphs[graph] {
+			return RESPError("ERR Invalid graph operation on empty key")
+		}
+		delete(h.graphs, graph)
+		h.labels, h.types, h.propNames = nil, nil, nil
+		return "Graph removed, internal execution time: 0.100000 milliseconds"
+	case "GRAPH.COPY":
+		if len(args) != 3 {
+			return RESPError("ERR wrong number of arguments for 'GRAPH.COPY' command")
+		}
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if !h.graphs[graph] {
+			return RESPError("ERR Invalid graph operation on empty key")
+		}
+		if h.graphs[args[2]] {
+			return RESPError("ERR destination key already exists")
+		}
+		h.graphs[args[2]] = true
+		return "OK"
+	}
+	return RESPError(fmt.Sprintf("ERR unknown command '%s'", args[0]))
+}
+
+// query returns the compact reply to the query.
+func (h *GraphHandler) query(graph, query string) any {
+	h.mu.Lock()
+	if h.graphs == nil {
+		h.graphs = map[string]bool{}
+	}
+	h.graphs[graph] = true
+	// The procedures clients call to resolve the numbers of labels, relationship types and property keys
+	procedures := map[string][]string{
+		"CALL db.labels()":            h.labels,
+		"CALL db.relationshipTypes()": h.types,
+		"CALL db.propertyKeys()":      h.propNames,
+	}
+	names, isProcedure := procedures[strings.TrimSpace(query)]
+	h.mu.Unlock()
+
+	res := GraphResult{}
+	if isProcedure {
+		res.Columns = []string{"name"}
+		for _, name := range names {
+			res.Rows = append(res.Rows, []any{name})
+		}
+	} else if h.Query != nil {
+		res = h.Query(graph, query)
+	}
+	if res.Error != "" {
+		return RESPError(res.Error)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	statistics := []any{}
+	for _, stat := range res.Statistics {
+		statistics = append(statistics, stat)
+	}
+	statistics = append(statistics, "Query internal execution time: 0.100000 milliseconds")
+	if len(res.Columns) == 0 {
+		return []any{statistics}
+	}
+
+	header := make([]any, len(res.Columns))
+	for i, column := range res.Columns {
+		// All columns are scalar columns, as is the case for current versions of FalkorDB
+		header[i] = []any{int64(1), column}
+	}
+	rows := make([]any, len(res.Rows))
+	for i, row := range res.Rows {
+		cells := make([]any, len(row))
+		for j, v := range row {
+			cells[j] = h.compact(v)
+		}
+		rows[i] = cells
+	}
+	return []any{header, rows, statistics}
+}
+
+// The types of values in compact replies
+const (
+	compactNull int64 = iota + 1
+	compactString
+	compactInteger
+	compactBoolean
+	compactDouble
+	compactArray
+	compactEdge
+	compactNode
+	compactPath
+	compactMap
+)
+
+// compact returns the compact encoding of the value, consisting of its type and its encoded value.
+//
+// The caller has to hold the lock, as new labels, relationship types and property keys get numbered.
+func (h *GraphHandler) compact(v any) []any {
+	switch v := v.(type) {
+	case nil:
+		return []any{compactNull, nil}
+	case string:
+		return []any{compactString, v}
+	case int:
+		return []any{compactInteger, int64(v)}
+	case int64:
+		return []any{compactInteger, v}
+	case bool:
+		return []any{compactBoolean, strconv.FormatBool(v)}
+	case float64:
+		return []any{compactDouble, strconv.FormatFloat(v, 'g', -1, 64)}
+	case []any:
+		elems := make([]any, len(v))
+		for i, elem := range v {
+			elems[i] = h.compact(elem)
+		}
+		return []any{compactArray, elems}
+	case map[string]any:
+		var entries []any
+		for _, key := range sortedKeys(v) {
+			entries = append(entries, key, h.compact(v[key]))
+		}
+		return []any{compactMap, entries}
+	case Node:
+		return []any{compactNode, h.compactNode(v)}
+	case Relationship:
+		return []any{compactEdge, h.compactRelationship(v)}
+	case Path:
+		nodes := make([]any, len(v.Nodes))
+		for i, n := range v.Nodes {
+			nodes[i] = []any{compactNode, h.compactNode(n)}
+		}
+		relationships := make([]any, len(v.Relationships))
+		for i, r := range v.Relationships {
+			relationships[i] = []any{compactEdge, h.compactRelationship(r)}
+		}
+		return []any{compactPath, []any{[]any{compactArray, nodes}, []any{compactArray, relationships}}}
+	}
+	panic(fmt.Sprintf("fake server can't encode value of type %T", v))
+}
+
+func (h *GraphHandler) compactNode(n Node) []any {
+	labels := make([]any, len(n.Labels))
+	for i, label := range n.Labels {
+		labels[i] = number(&h.labels, label)
+	}
+	return []any{n.ID, labels, h.compactProperties(n.Properties)}
+}
+
+func (h *GraphHandler) compactRelationship(r Relationship) []any {
+	return []any{r.ID, number(&h.types, r.Type), r.StartID, r.EndID, h.compactProperties(r.Properties)}
+}
+
+// compactProperties returns the properties, each encoded as its key's number followed by its compact value.
+func (h *GraphHandler) compactProperties(properties map[string]any) []any {
+	res := []any{}
+	for _, key := range sortedKeys(properties) {
+		res = append(res, append([]any{number(&h.propNames, key)}, h.compact(properties[key])...))
+	}
+	return res
+}
+
+// number returns the number of the name, appending it to the names if it isn't numbered yet.
+func number(names *[]string, name string) int64 {
+	i := slices.Index(*names, name)
+	if i == -1 {
+		i = len(*names)
+		*names = append(*names, name)
+	}
+	return int64(i)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,314 @@
+package fakeserver
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+// A structure is a PackStream structure, e.g. a Bolt message or a node.
+type structure struct {
+	tag    byte
+	fields []any
+}
+
+// PackStream markers, the markers of tiny values hold their size in their lower nibble
+const (
+	markerTinyString = 0x80
+	markerTinyList   = 0x90
+	markerTinyMap    = 0xA0
+	markerTinyStruct = 0xB0
+	markerNull       = 0xC0
+	markerFloat      = 0xC1
+	markerFalse      = 0xC2
+	markerTrue       = 0xC3
+	markerInt8       = 0xC8
+	markerInt16      = 0xC9
+	markerInt32      = 0xCA
+	markerInt64      = 0xCB
+	markerBytes8     = 0xCC
+	markerBytes16    = 0xCD
+	markerBytes32    = 0xCE
+	markerString8    = 0xD0
+	markerString16   = 0xD1
+	markerString32   = 0xD2
+	markerList8      = 0xD4
+	markerList16     = 0xD5
+	markerList32     = 0xD6
+	markerMap8       = 0xD8
+	markerMap16      = 0xD9
+	markerMap32      = 0xDA
+)
+
+// pack appends the PackStream encoding of the value to the buffer.
+//
+// Nodes, relationships and paths are encoded as the structures of Bolt 4.
+func pack(buf []byte, v any) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(buf, markerNull)
+	case bool:
+		if v {
+			return append(buf, markerTrue)
+		}
+		return append(buf, markerFalse)
+	case int:
+		return packInt(buf, int64(v))
+	case int64:
+		return packInt(buf, v)
+	case float64:
+		return binary.BigEndian.AppendUint64(append(buf, markerFloat), math.Float64bits(v))
+	case string:
+		buf = packSize(buf, len(v), markerTinyString, markerString8)
+		return append(buf, v...)
+	case []byte:
+		buf = packSize(buf, len(v), -1, markerBytes8)
+		return append(buf, v...)
+	case []string:
+		buf = packSize(buf, len(v), markerTinyList, markerList8)
+		for _, elem := range v {
+			buf = pack(buf, elem)
+		}
+		return buf
+	case []any:
+		buf = packSize(buf, len(v), markerTinyList, markerList8)
+		for _, elem := range v {
+			buf = pack(buf, elem)
+		}
+		return buf
+	case map[string]any:
+		buf = packSize(buf, len(v), markerTinyMap, markerMap8)
+		for _, key := range sortedKeys(v) {
+			buf = pack(pack(buf, key), v[key])
+		}
+		return buf
+	case structure:
+		buf = append(buf, markerTinyStruct|byte(len(v.fields)), v.tag)
+		for _, field := range v.fields {
+			buf = pack(buf, field)
+		}
+		return buf
+	case Node:
+		return pack(buf, boltNode(v))
+	case Relationship:
+		return pack(buf, structure{'R', []any{v.ID, v.StartID, v.EndID, v.Type, properties(v.Properties)}})
+	case Path:
+		nodes := make([]any, len(v.Nodes))
+		for i, n := range v.Nodes {
+			nodes[i] = boltNode(n)
+		}
+		relationships := make([]any, len(v.Relationships))
+		// The indices alternate between a relationship and the node it leads to,
+		// relationships traversed against their direction have negative indices
+		indices := []any{}
+		for i, r := range v.Relationships {
+			relationships[i] = structure{'r', []any{r.ID, r.Type, properties(r.Properties)}}
+			index := int64(i + 1)
+			if i+1 < len(v.Nodes) && r.EndID != v.Nodes[i+1].ID {
+				index = -index
+			}
+			indices = append(indices, index, int64(i+1))
+		}
+		return pack(buf, structure{'P', []any{nodes, relationships, indices}})
+	}
+	panic(fmt.Sprintf("fake server can't encode value of type %T", v))
+}
+
+func boltNode(n Node) structure {
+	labels := make([]any, len(n.Labels))
+	for i, label := range n.Labels {
+		labels[i] = label
+	}
+	return structure{'N', []any{n.ID, labels, properties(n.Properties)}}
+}
+
+// properties returns the properties, or an empty map if they are nil.
+func properties(props map[string]any) map[string]any {
+	if props == nil {
+		return map[string]any{}
+	}
+	return props
+}
+
+func packInt(buf []byte, v int64) []byte {
+	switch {
+	case v >= -16 && v <= 127:
+		return append(buf, byte(v))
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		return append(buf, markerInt8, byte(v))
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		return binary.BigEndian.AppendUint16(append(buf, markerInt16), uint16(v))
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		return binary.BigEndian.AppendUint32(append(buf, markerInt32), uint32(v))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, markerInt64), uint64(v))
+}
+
+// packSize appends the marker of a value with the passed size.
+//
+// The tiny marker is used for sizes below 16 if it isn't negative,
+// else the 8, 16 or 32 bit marker, which must follow each other.
+func packSize(buf []byte, size int, tinyMarker int, marker8 byte) []byte {
+	switch {
+	case tinyMarker >= 0 && size < 16:
+		return append(buf, byte(tinyMarker|size))
+	case size <= math.MaxUint8:
+		return append(buf, marker8, byte(size))
+	case size <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, marker8+1), uint16(size))
+	}
+	return binary.BigEndian.AppendUint32(append(buf, marker8+2), uint32(size))
+}
+
+// unpacker decodes PackStream values.
+type unpacker struct {
+	buf []byte
+}
+
+// unpack decodes the next value.
+//
+// Integers are decoded as int64, lists as []any, maps as map[string]any and structures as [structure].
+func (u *unpacker) unpack() (any, error) {
+	marker, err := u.read(1)
+	if err != nil {
+		return nil, err
+	}
+	m := marker[0]
+	switch {
+	case m < 0x80 || m >= 0xF0:
+		return int64(int8(m)), nil
+	case m&0xF0 == markerTinyString:
+		return u.string(int(m & 0x0F))
+	case m&0xF0 == markerTinyList:
+		return u.list(int(m & 0x0F))
+	case m&0xF0 == markerTinyMap:
+		return u.dict(int(m & 0x0F))
+	case m&0xF0 == markerTinyStruct:
+		tag, err := u.read(1)
+		if err != nil {
+			return nil, err
+		}
+		fields, err := u.list(int(m & 0x0F))
+		return structure{tag[0], fields}, err
+	}
+
+	switch m {
+	case markerNull:
+		return nil, nil
+	case markerTrue:
+		return true, nil
+	case markerFalse:
+		return false, nil
+	case markerFloat:
+		b, err := u.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case markerInt8, markerInt16, markerInt32, markerInt64:
+		return u.int(1 << (m - markerInt8))
+	case markerBytes8, markerBytes16, markerBytes32:
+		size, err := u.size(m - markerBytes8)
+		if err != nil {
+			return nil, err
+		}
+		b, err := u.read(size)
+		return slices.Clone(b), err
+	case markerString8, markerString16, markerString32:
+		size, err := u.size(m - markerString8)
+		if err != nil {
+			return nil, err
+		}
+		return u.string(size)
+	case markerList8, markerList16, markerList32:
+		size, err := u.size(m - markerList8)
+		if err != nil {
+			return nil, err
+		}
+		return u.list(size)
+	case markerMap8, markerMap16, markerMap32:
+		size, err := u.size(m - markerMap8)
+		if err != nil {
+			return nil, err
+		}
+		return u.dict(size)
+	}
+	return nil, fmt.Errorf("unknown PackStream marker 0x%X", m)
+}
+
+func (u *unpacker) read(n int) ([]byte, error) {
+	if len(u.buf) < n {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := u.buf[:n]
+	u.buf = u.buf[n:]
+	return b, nil
+}
+
+// int decodes a big-endian integer of the passed width in bytes.
+func (u *unpacker) int(width int) (int64, error) {
+	b, err := u.read(width)
+	if err != nil {
+		return 0, err
+	}
+	switch width {
+	case 1:
+		return int64(int8(b[0])), nil
+	case 2:
+		return int64(int16(binary.BigEndian.Uint16(b))), nil
+	case 4:
+		return int64(int32(binary.BigEndian.Uint32(b))), nil
+	}
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
+
+// size decodes an unsigned size of 8, 16 or 32 bits, depending on whether the passed width class is 0, 1 or 2.
+func (u *unpacker) size(widthClass byte) (int, error) {
+	b, err := u.read(1 << widthClass)
+	if err != nil {
+		return 0, err
+	}
+	switch widthClass {
+	case 0:
+		return int(b[0]), nil
+	case 1:
+		return int(binary.BigEndian.Uint16(b)), nil
+	}
+	return int(binary.BigEndian.Uint32(b)), nil
+}
+
+func (u *unpacker) string(size int) (string, error) {
+	b, err := u.read(size)
+	return string(b), err
+}
+
+func (u *unpacker) list(size int) ([]any, error) {
+	list := make([]any, size)
+	for i := range list {
+		var err error
+		if list[i], err = u.unpack(); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+func (u *unpacker) dict(size int) (map[string]any, error) {
+	dict := make(map[string]any, size)
+	for range size {
+		key, err := u.unpack()
+		if err != nil {
+			return nil, err
+		}
+		keyString, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("map key %v is of type %T instead of string", key, key)
+		}
+		if dict[keyString], err = u.unpack(); err != nil {
+			return nil, err
+		}
+	}
+	return dict, nil
+}
@@ -0,0 +1,46 @@
+package fakeserver
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackUnpack(t *testing.T) {
+	for _, v := range []any{
+		nil, true, false,
+		int64(0), int64(-16), int64(127), int64(-17), int64(128), int64(math.MinInt16), int64(math.MaxInt32), int64(math.MinInt64),
+		1.5, math.Inf(-1),
+		"", "abc", strings.Repeat("x", 300), strings.Repeat("x", 70000),
+		[]byte{1, 2},
+		[]any{int64(1), "a", []any{}},
+		map[string]any{"a": int64(1), "b": map[string]any{}},
+		structure{0x10, []any{"RETURN 1", map[string]any{}, map[string]any{}}},
+	} {
+		u := unpacker{pack(nil, v)}
+		unpacked, err := u.unpack()
+		assert.NoError(t, err)
+		assert.Equal(t, v, unpacked)
+		assert.Empty(t, u.buf, "the whole value should be consumed")
+	}
+
+	u := unpacker{pack(nil, "abc")[:2]}
+	_, err := u.unpack()
+	assert.Error(t, err, "truncated values should fail")
+}
+
+func TestPackPath(t *testing.T) {
+	a := Node{ID: 1, Labels: []string{"A"}}
+	b := Node{ID: 2}
+	forward := Relationship{ID: 3, StartID: 1, EndID: 2, Type: "T"}
+	backward := Relationship{ID: 4, StartID: 1, EndID: 2, Type: "T"}
+
+	u := unpacker{pack(nil, Path{Nodes: []Node{a, b, a}, Relationships: []Relationship{forward, backward}})}
+	unpacked, err := u.unpack()
+	assert.NoError(t, err)
+	path := unpacked.(structure)
+	assert.Equal(t, byte('P'), path.tag)
+	assert.Equal(t, []any{int64(1), int64(1), int64(-2), int64(2)}, path.fields[2], "relationships traversed backwards should have negative indices")
+}
@@ -0,0 +1,440 @@
+package fakeserver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// A PostgresResult is the result of a statement run on a [PostgresServer].
+type PostgresResult struct {
+	// The columns, all of which are of type text
+	Columns []string
+	// The rows' values, nil values are sent as NULL and all others as their default formatting
+	Rows [][]any
+	// The command tag, defaults to "SELECT n" for results with columns and the statement's first word otherwise
+	Tag string
+	// The SQLSTATE code and message of the error the statement fails with, the statement succeeds if the code is empty
+	Code    string
+	Message string
+	// If true, the connection gets closed after the error was sent, like a terminated backend
+	Terminate bool
+}
+
+// A PostgresHandler answers a statement sent to a [PostgresServer].
+//
+// Statements sent using the extended protocol are passed with their placeholders, e.g. $1, left in place.
+// Transaction control statements get passed as well, their results are only used if they fail.
+// Handlers may get called concurrently.
+type PostgresHandler func(statement string) PostgresResult
+
+// A PostgresServer speaks the PostgreSQL wire protocol, as used by Apache AGE.
+//
+// It accepts any credentials, rejects TLS and tracks the transaction status clients expect.
+// Transaction control statements such as BEGIN, COMMIT and SAVEPOINT are answered on their own unless the handler fails them,
+// statements failing in a transaction abort it until it gets rolled back.
+// Empty statements are answered on their own too, all other statements get answered by its handler.
+type PostgresServer struct {
+	*server
+	handler PostgresHandler
+	nextPID atomic.Int32
+}
+
+// StartPostgres starts a PostgreSQL server answering statements using the passed handler,
+// an empty result is returned if it is nil.
+//
+// Received statements are recorded, including transaction control statements.
+func StartPostgres(t testing.TB, handler PostgresHandler) *PostgresServer {
+	t.Helper()
+	s := &PostgresServer{handler: handler}
+	s.server = start(t, s.serveConn)
+	return s
+}
+
+// Codes of the requests sent instead of a startup message
+const (
+	postgresSSLRequest    = 80877103
+	postgresCancelRequest = 80877102
+)
+
+// The transaction statuses reported when the server is ready for a query
+const (
+	postgresIdle          = 'I'
+	postgresInTransaction = 'T'
+	postgresFailed        = 'E'
+)
+
+// The OID of the text type, the type of all columns
+const postgresText = 25
+
+// A postgresConn holds the state of a connection to a [PostgresServer].
+type postgresConn struct {
+	s *PostgresServer
+	w *bufio.Writer
+
+	status byte
+	// The statements prepared using the extended protocol by their name
+	statements map[string]*preparedStatement
+	// The statement bound to the unnamed portal
+	portal *preparedStatement
+	// Whether an error occurred in the extended protocol, in which case messages are discarded until the next sync
+	discarding bool
+	// Whether an error was sent in response to the current message
+	failed bool
+	// Whether the connection should be closed
+	closed bool
+}
+
+type preparedStatement struct {
+	query  string
+	result *PostgresResult
+}
+
+func (s *PostgresServer) serveConn(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	c := &postgresConn{s: s, w: bufio.NewWriter(conn), status: postgresIdle, statements: map[string]*preparedStatement{}}
+
+	if !c.startup(r, conn) {
+		return
+	}
+	for !c.closed {
+		msgType, body, err := readPostgresMessage(r)
+		if err != nil {
+			return
+		}
+		c.handle(msgType, body)
+		if err := c.w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// startup answers the startup message, returning whether the connection got established.
+func (c *postgresConn) startup(r *bufio.Reader, conn net.Conn) bool {
+	for {
+		body, err := readPostgresBody(r)
+		if err != nil || len(body) < 4 {
+			return false
+		}
+		switch binary.BigEndian.Uint32(body) {
+		case postgresSSLRequest:
+			if _, err := conn.Write([]byte{'N'}); err != nil {
+				return false
+			}
+			continue
+		case postgresCancelRequest:
+			return false
+		}
+
+		// Authentication succeeded
+		c.send('R', binary.BigEndian.AppendUint32(nil, 0))
+		for _, param := range [][2]string{
+			{"server_version", "16.0"},
+			{"server_encoding", "UTF8"},
+			{"client_encoding", "UTF8"},
+			{"DateStyle", "ISO, MDY"},
+			{"TimeZone", "UTC"},
+			{"integer_datetimes", "on"},
+			{"standard_conforming_strings", "on"},
+		} {
+			c.send('S', cstrings(param[0], param[1]))
+		}
+		keyData := binary.BigEndian.AppendUint32(nil, uint32(c.s.nextPID.Add(1)))
+		c.send('K', binary.BigEndian.AppendUint32(keyData, 0))
+		c.send('Z', []byte{c.status})
+		return c.w.Flush() == nil
+	}
+}
+
+// handle answers the message.
+func (c *postgresConn) handle(msgType byte, body []byte) {
+	fields := bytes.Split(body, []byte{0})
+	switch msgType {
+	case 'X':
+		c.closed = true
+		return
+	case 'S':
+		c.discarding = false
+		c.send('Z', []byte{c.status})
+		return
+	case 'H':
+		return
+	}
+	if c.discarding {
+		return
+	}
+	// Errors in the extended protocol cause all messages to be discarded until the next sync
+	c.failed = false
+	defer func() {
+		c.discarding = c.failed && msgType != 'Q'
+	}()
+
+	switch msgType {
+	case 'Q':
+		c.run(string(fields[0]), nil)
+		if !c.closed {
+			c.send('Z', []byte{c.status})
+		}
+	case 'P':
+		// Parameter types declared by the client are ignored, all parameters are of type text
+		c.statements[string(fields[0])] = &preparedStatement{query: string(fields[1])}
+		c.send('1', nil)
+	case 'B':
+		portal, name := string(fields[0]), string(fields[1])
+		stmt, ok := c.statements[name]
+		if !ok || portal != "" {
+			c.fail("26000", fmt.Sprintf("prepared statement %q does not exist or portal is named", name))
+			return
+		}
+		c.portal = stmt
+		c.send('2', nil)
+	case 'D':
+		stmt := c.portal
+		if body[0] == 'S' {
+			stmt = c.statements[string(fields[0][1:])]
+		}
+		if stmt == nil {
+			c.fail("26000", "prepared statement or portal does not exist")
+			return
+		}
+		if body[0] == 'S' {
+			params := make([]byte, 2)
+			count := parameterCount(stmt.query)
+			binary.BigEndian.PutUint16(params, uint16(count))
+			for range count {
+				params = binary.BigEndian.AppendUint32(params, postgresText)
+			}
+			c.send('t', params)
+		}
+		res := c.prepare(stmt)
+		if len(res.Columns) == 0 {
+			c.send('n', nil)
+		} else {
+			c.sendRowDescription(res.Columns)
+		}
+	case 'E':
+		if c.portal == nil {
+			c.fail("34000", "portal does not exist")
+			return
+		}
+		stmt := c.portal
+		c.portal = nil
+		c.run(stmt.query, c.prepare(stmt))
+		stmt.result = nil
+	case 'C':
+		if body[0] == 'S' {
+			delete(c.statements, string(fields[0][1:]))
+		}
+		c.send('3', nil)
+	default:
+		c.fail("08P01", fmt.Sprintf("fake server can't handle message %q", msgType))
+	}
+}
+
+// prepare returns the result of the statement, getting it from the handler if it wasn't yet.
+//
+// The result is only sent once the statement gets executed, but its columns have to be described beforehand.
+func (c *postgresConn) prepare(stmt *preparedStatement) *PostgresResult {
+	if stmt.result == nil {
+		res := c.result(stmt.query)
+		stmt.result = &res
+	}
+	return stmt.result
+}
+
+// The statements controlling transactions, answered without the handler
+var (
+	beginStatement      = regexp.MustCompile(`(?i)^\s*(BEGIN|START\s+TRANSACTION)\b`)
+	commitStatement     = regexp.MustCompile(`(?i)^\s*(COMMIT|END)\b`)
+	rollbackToStatement = regexp.MustCompile(`(?i)^\s*ROLLBACK\s+TO\b`)
+	rollbackStatement   = regexp.MustCompile(`(?i)^\s*(ROLLBACK|ABORT)\b`)
+	savepointStatement  = regexp.MustCompile(`(?i)^\s*(SAVEPOINT|RELEASE)\b`)
+)
+
+// result returns the result of the statement, answering transaction control statements on its own unless the handler fails them.
+func (c *postgresConn) result(statement string) PostgresResult {
+	var res PostgresResult
+	if c.s.handler != nil {
+		res = c.s.handler(statement)
+	}
+	if res.Code != "" {
+		return res
+	}
+	switch {
+	case beginStatement.MatchString(statement):
+		return PostgresResult{Tag: "BEGIN"}
+	case commitStatement.MatchString(statement):
+		return PostgresResult{Tag: "COMMIT"}
+	case rollbackToStatement.MatchString(statement):
+		return PostgresResult{Tag: "ROLLBACK"}
+	case rollbackStatement.MatchString(statement):
+		return PostgresResult{Tag: "ROLLBACK"}
+	case savepointStatement.MatchString(statement):
+		return PostgresResult{Tag: strings.ToUpper(strings.Fields(statement)[0])}
+	}
+	return res
+}
+
+// run runs the statement, sending its result and updating the transaction status.
+//
+// The statement's result is taken from the handler if nil, as is the case for the simple protocol,
+// in which case its columns get described as well.
+func (c *postgresConn) run(statement string, res *PostgresResult) {
+	c.s.record(statement)
+
+	if strings.Trim(statement, " \t\r\n;") == "" {
+		c.send('I', nil)
+		return
+	}
+
+	// Only ending the transaction or rolling back to a savepoint is allowed once it failed
+	if c.status == postgresFailed && !commitStatement.MatchString(statement) && !rollbackStatement.MatchString(statement) {
+		c.fail("25P02", "current transaction is aborted, commands ignored until end of transaction block")
+		return
+	}
+	describe := res == nil
+	if res == nil {
+		r := c.result(statement)
+		res = &r
+	}
+	if res.Code != "" {
+		c.fail(res.Code, res.Message)
+		if res.Terminate {
+			c.closed = true
+		}
+		return
+	}
+
+	tag := res.Tag
+	switch {
+	case beginStatement.MatchString(statement):
+		c.status = postgresInTransaction
+	case rollbackToStatement.MatchString(statement):
+		c.status = postgresInTransaction
+	case commitStatement.MatchString(statement):
+		if c.status == postgresFailed {
+			tag = "ROLLBACK"
+		}
+		c.status = postgresIdle
+	case rollbackStatement.MatchString(statement):
+		c.status = postgresIdle
+	}
+
+	if tag == "" {
+		tag = strings.ToUpper(strings.Fields(statement)[0])
+		if len(res.Columns) > 0 {
+			tag = fmt.Sprintf("SELECT %d", len(res.Rows))
+		}
+	}
+	if describe && len(res.Columns) > 0 {
+		c.sendRowDescription(res.Columns)
+	}
+	for _, row := range res.Rows {
+		data := binary.BigEndian.AppendUint16(nil, uint16(len(row)))
+		for _, v := range row {
+			if v == nil {
+				data = binary.BigEndian.AppendUint32(data, 0xFFFFFFFF)
+				continue
+			}
+			value := fmt.Sprint(v)
+			data = binary.BigEndian.AppendUint32(data, uint32(len(value)))
+			data = append(data, value...)
+		}
+		c.send('D', data)
+	}
+	c.send('C', cstrings(tag))
+}
+
+// fail sends an error, aborting the current transaction.
+func (c *postgresConn) fail(code, message string) {
+	severity := "ERROR"
+	if strings.HasPrefix(code, "57P") {
+		severity = "FATAL"
+	}
+	c.send('E', append(cstrings("S"+severity, "V"+severity, "C"+code, "M"+message), 0))
+	c.failed = true
+	if c.status == postgresInTransaction {
+		c.status = postgresFailed
+	}
+}
+
+func (c *postgresConn) sendRowDescription(columns []string) {
+	desc := binary.BigEndian.AppendUint16(nil, uint16(len(columns)))
+	for _, column := range columns {
+		desc = append(desc, cstrings(column)...)
+		// The table's OID and the column's attribute number
+		desc = binary.BigEndian.AppendUint32(desc, 0)
+		desc = binary.BigEndian.AppendUint16(desc, 0)
+		// The type's OID, size and modifier
+		desc = binary.BigEndian.AppendUint32(desc, postgresText)
+		desc = binary.BigEndian.AppendUint16(desc, 0xFFFF)
+		desc = binary.BigEndian.AppendUint32(desc, 0xFFFFFFFF)
+		// The text format
+		desc = binary.BigEndian.AppendUint16(desc, 0)
+	}
+	c.send('T', desc)
+}
+
+// send writes a message consisting of its type, its length and its body.
+func (c *postgresConn) send(msgType byte, body []byte) {
+	c.w.WriteByte(msgType)
+	c.w.Write(binary.BigEndian.AppendUint32(nil, uint32(len(body)+4)))
+	c.w.Write(body)
+}
+
+// cstrings returns the null-terminated strings.
+func cstrings(strs ...string) []byte {
+	var b []byte
+	for _, str := range strs {
+		b = append(append(b, str...), 0)
+	}
+	return b
+}
+
+var placeholder = regexp.MustCompile(`\$(\d+)`)
+
+// parameterCount returns the number of parameters of the statement, which is the highest placeholder's number.
+func parameterCount(statement string) int {
+	count := 0
+	for _, match := range placeholder.FindAllStringSubmatch(statement, -1) {
+		n, _ := strconv.Atoi(match[1])
+		count = max(count, n)
+	}
+	return count
+}
+
+// readPostgresMessage reads a message sent by a client after the startup, consisting of its type and its body.
+func readPostgresMessage(r *bufio.Reader) (byte, []byte, error) {
+	msgType, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	body, err := readPostgresBody(r)
+	if err == nil && len(body) == 0 && msgType != 'S' && msgType != 'H' && msgType != 'X' {
+		err = fmt.Errorf("message %q has no body", msgType)
+	}
+	return msgType, body, err
+}
+
+// readPostgresBody reads a message's body, prefixed by its length including the prefix.
+func readPostgresBody(r *bufio.Reader) ([]byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header)
+	if length < 4 {
+		return nil, fmt.Errorf("invalid message length %d", length)
+	}
+	body := make([]byte, length-4)
+	_, err := io.ReadFull(r, body)
+	return body, err
+}
@@ -0,0 +1,192 @@
+package fakeserver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// A RESPHandler answers a command sent to a [RESPServer], getting passed the command's arguments,
+// the first one being the command's name.
+//
+// The reply is encoded as follows:
+//   - strings as bulk strings, nil as the null bulk string
+//   - integers as integers, booleans as the integers 1 and 0, floats as bulk strings
+//   - slices as arrays
+//   - a [RESPStatus] as a simple string and a [RESPError] or error as an error
+//
+// Handlers may get called concurrently.
+type RESPHandler func(args []string) any
+
+// RESPStatus is a reply encoded as a simple string, e.g. "OK".
+type RESPStatus string
+
+// RESPError is a reply encoded as an error, e.g. "ERR unknown command".
+type RESPError string
+
+// A RESPServer speaks RESP2, the protocol of Redis and its modules.
+//
+// It answers the commands go-redis and redigo send when connecting on its own,
+// as well as PING, CLIENT ID and CLIENT KILL ID. All other commands get passed to its handler.
+type RESPServer struct {
+	*server
+	handler RESPHandler
+
+	mu sync.Mutex
+	// The open connections by their client ID
+	clients map[int64]net.Conn
+	nextID  int64
+}
+
+// StartRESP starts a RESP server answering commands using the passed handler.
+//
+// Received commands are recorded with their arguments joined by spaces.
+func StartRESP(t testing.TB, handler RESPHandler) *RESPServer {
+	t.Helper()
+	s := &RESPServer{handler: handler, clients: map[int64]net.Conn{}}
+	s.server = start(t, s.serveConn)
+	return s
+}
+
+func (s *RESPServer) serveConn(conn net.Conn) {
+	s.mu.Lock()
+	s.nextID++
+	id := s.nextID
+	s.clients[id] = conn
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, id)
+		s.mu.Unlock()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		s.record(strings.Join(args, " "))
+		writeReply(w, s.reply(id, args))
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// reply returns the reply to the command, sent by the client with the passed ID.
+func (s *RESPServer) reply(id int64, args []string) any {
+	if len(args) == 0 {
+		return RESPError("ERR empty command")
+	}
+	command := strings.ToUpper(args[0])
+	subcommand := ""
+	if len(args) > 1 {
+		subcommand = strings.ToUpper(args[1])
+	}
+	switch {
+	case command == "HELLO":
+		// Clients fall back to RESP2 if the server doesn't know HELLO
+		return RESPError("ERR unknown command 'HELLO'")
+	case command == "PING":
+		return RESPStatus("PONG")
+	case command == "CLIENT" && subcommand == "SETINFO":
+		return RESPStatus("OK")
+	case command == "CLIENT" && subcommand == "ID":
+		return id
+	case command == "CLIENT" && subcommand == "KILL" && len(args) == 4 && strings.ToUpper(args[2]) == "ID":
+		killed, _ := strconv.ParseInt(args[3], 10, 64)
+		s.mu.Lock()
+		conn, ok := s.clients[killed]
+		s.mu.Unlock()
+		if !ok {
+			return RESPError("ERR No such client")
+		}
+		conn.Close()
+		return int64(1)
+	}
+	if s.handler == nil {
+		return RESPError(fmt.Sprintf("ERR unknown command '%s'", args[0]))
+	}
+	return s.handler(args)
+}
+
+// readCommand reads a command, sent as an array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	n, err := readPrefixedLength(r, '*')
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		length, err := readPrefixedLength(r, '$')
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, length+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:length])
+	}
+	return args, nil
+}
+
+// readPrefixedLength reads a line consisting of the prefix followed by a length.
+func readPrefixedLength(r *bufio.Reader, prefix byte) (int, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if len(line) == 0 || line[0] != prefix {
+		return 0, fmt.Errorf("expected %q, got line %q", prefix, line)
+	}
+	return strconv.Atoi(line[1:])
+}
+
+// writeReply writes the RESP encoding of the reply, see [RESPHandler].
+func writeReply(w *bufio.Writer, reply any) {
+	switch reply := reply.(type) {
+	case nil:
+		w.WriteString("$-1\r\n")
+	case RESPStatus:
+		fmt.Fprintf(w, "+%s\r\n", reply)
+	case RESPError:
+		fmt.Fprintf(w, "-%s\r\n", reply)
+	case error:
+		fmt.Fprintf(w, "-%s\r\n", reply)
+	case string:
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(reply), reply)
+	case int:
+		fmt.Fprintf(w, ":%d\r\n", reply)
+	case int64:
+		fmt.Fprintf(w, ":%d\r\n", reply)
+	case bool:
+		if reply {
+			w.WriteString(":1\r\n")
+		} else {
+			w.WriteString(":0\r\n")
+		}
+	case float64:
+		writeReply(w, strconv.FormatFloat(reply, 'g', -1, 64))
+	case []string:
+		fmt.Fprintf(w, "*%d\r\n", len(reply))
+		for _, elem := range reply {
+			writeReply(w, elem)
+		}
+	case []any:
+		fmt.Fprintf(w, "*%d\r\n", len(reply))
+		for _, elem := range reply {
+			writeReply(w, elem)
+		}
+	default:
+		writeReply(w, RESPError(fmt.Sprintf("ERR fake server can't encode reply of type %T", reply)))
+	}
+}
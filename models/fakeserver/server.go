@@ -0,0 +1,110 @@
+package fakeserver
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Anon10214/dinkel/dbms"
+)
+
+// A server accepts connections on a random local port, serving each connection on its own goroutine.
+type server struct {
+	listener net.Listener
+	serve    func(net.Conn)
+
+	mu    sync.Mutex
+	conns map[net.Conn]bool
+	// The statements received, in order
+	received []string
+	wg       sync.WaitGroup
+}
+
+// start starts a server serving its connections using the passed function.
+//
+// The server gets closed once the test finishes.
+func start(t testing.TB, serve func(net.Conn)) *server {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("couldn't start fake server - %v", err)
+	}
+	s := &server{listener: listener, serve: serve, conns: map[net.Conn]bool{}}
+	s.wg.Add(1)
+	go s.accept()
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *server) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = true
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.closeConn(conn)
+			s.serve(conn)
+		}()
+	}
+}
+
+func (s *server) closeConn(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conn.Close()
+	delete(s.conns, conn)
+}
+
+// Close stops the server and closes all its connections, e.g. to simulate a crash.
+func (s *server) Close() {
+	s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// Port returns the port the server listens on.
+func (s *server) Port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// Options returns the options for connecting to the server.
+func (s *server) Options() dbms.DBOptions {
+	port := s.Port()
+	return dbms.DBOptions{Host: "127.0.0.1", Port: &port, Timeout: 5 * time.Second}
+}
+
+// record records a received statement.
+func (s *server) record(statement string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.received = append(s.received, statement)
+}
+
+// Received returns the statements received so far, in order.
+func (s *server) Received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.received...)
+}
+
+// InitDriver initialises the passed driver to connect to the passed server.
+// Returns the driver and the options it got initialised with, or the error initialising it.
+//
+// The caller has to close the driver's connections once done.
+func InitDriver[D dbms.DB](server interface{ Options() dbms.DBOptions }, d D) (D, dbms.DBOptions, error) {
+	opts := server.Options()
+	return d, opts, d.Init(opts)
+}
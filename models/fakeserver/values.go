@@ -0,0 +1,24 @@
+package fakeserver
+
+// A Node returned by a server.
+type Node struct {
+	ID         int64
+	Labels     []string
+	Properties map[string]any
+}
+
+// A Relationship returned by a server.
+type Relationship struct {
+	ID      int64
+	StartID int64
+	EndID   int64
+	Type    string
+
+	Properties map[string]any
+}
+
+// A Path returned by a server, holding its nodes in order and the relationships between them.
+type Path struct {
+	Nodes         []Node
+	Relationships []Relationship
+}
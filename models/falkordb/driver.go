@@ -6,16 +6,14 @@ package falkordb
 import (
 	"context"
 	"fmt"
-	"math"
 	"net"
-	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/Anon10214/dinkel/dbms"
 	"github.com/Anon10214/dinkel/models/opencypher/schema"
 	"github.com/Anon10214/dinkel/seed"
 	"github.com/FalkorDB/falkordb-go"
-	"github.com/google/go-cmp/cmp"
-	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 )
@@ -26,12 +24,17 @@ type Driver struct {
 	fdbConn *falkordb.FalkorDB
 	graph   *falkordb.Graph
 
+	// The connection fuzzed queries are run on, separate from the pool so it can be killed when cancelling a query.
+	// Opened lazily and discarded after being killed.
+	queryConn   *redis.Conn
+	queryConnID int64
+
 	// Due to a bug in the FalkorDB driver, it sometimes returns nil record values that falsely indicate a logic bug.
 	// If this happens, treat the query as invalid.
 	returnedNil bool
 
-	// The amount of queries run, due to redis not killing long running processes if they write data, we just shutdown the server every once in a while
-	ranQueries int
+	// Whether a snapshot of the graph was taken since the last reset
+	hasSnapshot bool
 }
 
 // Init the DB driver
@@ -43,9 +46,23 @@ func (d *Driver) Init(opts dbms.DBOptions) error {
 		port = *opts.Port
 	}
 
-	var err error
+	tlsConfig, err := opts.TLS.Config()
+	if err != nil {
+		return err
+	}
+	// Redis has no notion of tokens, they are passed as the password instead
+	password := opts.Password
+	if opts.Token != "" {
+		password = opts.Token
+	}
+
+	d.closeQueryConn()
+	d.hasSnapshot = false
 	d.fdbConn, err = falkordb.FalkorDBNew(&falkordb.ConnectionOption{
 		Addr:         fmt.Sprintf("%s:%d", opts.Host, port),
+		Username:     opts.Username,
+		Password:     password,
+		TLSConfig:    tlsConfig,
 		DialTimeout:  opts.Timeout,
 		ReadTimeout:  opts.Timeout,
 		WriteTimeout: opts.Timeout,
@@ -57,73 +74,228 @@ func (d *Driver) Init(opts dbms.DBOptions) error {
 		return err
 	}
 
-	d.graph = d.fdbConn.SelectGraph("")
+	d.graph = d.fdbConn.SelectGraph(graphName(opts))
 	d.conn = d.graph.Conn
 
 	return d.conn.Ping(context.Background()).Err()
 }
 
-// Reset the database
+// The graph used if no namespace was passed
+const defaultGraph = "graph"
+
+// graphName returns the key of the graph to fuzz, which is the namespace if one was passed.
+func graphName(opts dbms.DBOptions) string {
+	if opts.Namespace != "" {
+		return opts.Namespace
+	}
+	return defaultGraph
+}
+
+// snapshotName returns the key of the graph holding the snapshot of the fuzzed graph.
+func snapshotName(opts dbms.DBOptions) string {
+	return graphName(opts) + "_snapshot"
+}
+
+// Reset the database by deleting the graph and its snapshot.
+//
+// Other keys of the redis instance are left untouched.
 func (d *Driver) Reset(opts dbms.DBOptions) error {
-	if d.ranQueries >= 10 {
-		logrus.Debugf("Ran %d queries, shutting down redis to restart", d.ranQueries)
-		d.ranQueries = 0
-		d.conn.ShutdownNoSave(context.Background())
-		for i := 0; ; i++ {
-			if err := d.Init(opts); err == nil {
-				break
-			}
-			if i == 100 {
-				return fmt.Errorf("failed to reestablish connection to redis after shutting it down within %d tries - make sure it restarts on exit", i)
-			}
-		}
+	d.hasSnapshot = false
+	if err := d.deleteGraph(snapshotName(opts)); err != nil {
+		return err
 	}
-	d.graph = d.fdbConn.SelectGraph("")
+	return d.recreateGraph(opts, "")
+}
+
+// Snapshot the freshly reset graph by copying it using GRAPH.COPY.
+//
+// A reset graph only exists if it was set up after resetting it, e.g. by creating indexes.
+// If it doesn't exist, there is nothing to copy and restoring the snapshot only deletes the graph.
+func (d *Driver) Snapshot(opts dbms.DBOptions) error {
+	d.hasSnapshot = false
+	snapshot := snapshotName(opts)
+	if err := d.deleteGraph(snapshot); err != nil {
+		return err
+	}
+	if err := d.copyGraph(graphName(opts), snapshot); err != nil {
+		return err
+	}
+	d.hasSnapshot = true
+	return nil
+}
+
+// Restore the graph to its snapshot by replacing it with a copy of the snapshot.
+func (d *Driver) Restore(opts dbms.DBOptions) error {
+	if !d.hasSnapshot {
+		return dbms.ErrNoSnapshot
+	}
+	return d.recreateGraph(opts, snapshotName(opts))
+}
+
+// recreateGraph deletes the fuzzed graph and replaces it with a copy of the passed graph, if it isn't empty.
+func (d *Driver) recreateGraph(opts dbms.DBOptions, source string) error {
+	// Selecting the graph again clears the labels, relationship types and property keys the client cached for it
+	d.graph = d.fdbConn.SelectGraph(graphName(opts))
 	d.conn = d.graph.Conn
 
 	d.returnedNil = false
-	// Call flushall on the underlying redis instance
-	return d.conn.FlushAll(context.Background()).Err()
+	if err := d.deleteGraph(d.graph.Id); err != nil {
+		return err
+	}
+	if source == "" {
+		return nil
+	}
+	return d.copyGraph(source, d.graph.Id)
+}
+
+// deleteGraph deletes the graph with the passed key, if it exists.
+func (d *Driver) deleteGraph(key string) error {
+	// Deleting a nonexistent graph fails, so check whether it exists first
+	exists, err := d.conn.Exists(context.Background(), key).Result()
+	if err != nil || exists == 0 {
+		return err
+	}
+	return d.conn.Do(context.Background(), "GRAPH.DELETE", key).Err()
+}
+
+// copyGraph copies the graph with the source key to the destination key, if the source graph exists.
+func (d *Driver) copyGraph(source, destination string) error {
+	exists, err := d.conn.Exists(context.Background(), source).Result()
+	if err != nil || exists == 0 {
+		return err
+	}
+	return d.conn.Do(context.Background(), "GRAPH.COPY", source, destination).Err()
+}
+
+// queryOptions returns the options for queries run with the passed DB options.
+func queryOptions(opts dbms.DBOptions) *falkordb.QueryOptions {
+	// The timeout is passed in milliseconds
+	return falkordb.NewQueryOptions().SetTimeout(int(opts.Timeout.Milliseconds()))
+}
+
+// openQueryConn returns the connection to run fuzzed queries on, opening it if necessary.
+func (d *Driver) openQueryConn(ctx context.Context) (*redis.Conn, error) {
+	if d.queryConn != nil {
+		return d.queryConn, nil
+	}
+	conn := d.conn.Conn()
+	id, err := conn.ClientID(ctx).Result()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	d.queryConn, d.queryConnID = conn, id
+	return conn, nil
+}
+
+// closeQueryConn closes the connection fuzzed queries are run on, if it is open.
+func (d *Driver) closeQueryConn() {
+	if d.queryConn != nil {
+		d.queryConn.Close()
+		d.queryConn = nil
+	}
+}
+
+// do runs the command on the connection, as [redis.Conn] doesn't provide a Do method.
+func do(ctx context.Context, conn *redis.Conn, args ...any) *redis.Cmd {
+	cmd := redis.NewCmd(ctx, args...)
+	_ = conn.Process(ctx, cmd)
+	return cmd
+}
+
+// killClient kills the client with the passed ID, aborting the command it is running.
+func (d *Driver) killClient(opts dbms.DBOptions, id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	return d.conn.ClientKillByFilter(ctx, "ID", strconv.FormatInt(id, 10)).Err()
 }
 
 // RunQuery runs the query against the FlakorDB DB and returns its result.
-func (d *Driver) RunQuery(opts dbms.DBOptions, query string) dbms.QueryResult {
+//
+// Queries are run with FalkorDB's TIMEOUT argument.
+// As older versions ignore it for write queries, the query's connection gets killed once the context is done.
+func (d *Driver) RunQuery(ctx context.Context, opts dbms.DBOptions, query string) dbms.QueryResult {
 	res := dbms.QueryResult{}
 
-	queryOpts := falkordb.NewQueryOptions().SetTimeout(int(opts.Timeout.Seconds()))
+	conn, err := d.openQueryConn(ctx)
+	if err != nil {
+		res.ProducedError = err
+		return res
+	}
+	connID := d.queryConnID
+	stop := context.AfterFunc(ctx, func() {
+		if err := d.killClient(opts, connID); err != nil {
+			logrus.Warnf("Couldn't kill connection of cancelled query - %v", err)
+		}
+	})
+	defer func() {
+		// Discard the connection if it got killed
+		if !stop() {
+			d.closeQueryConn()
+		}
+	}()
+
 	// Older versions only allow timeouts on read queries
+	timeoutArgs := []any{"timeout", queryOptions(opts).GetTimeout()}
 	if opts.BackwardsCompatibleMode {
-		queryOpts = nil
+		timeoutArgs = nil
+	}
+
+	unprofiledQuery, isProfiled := strings.CutPrefix(query, dbms.ProfilePrefix)
+	if isProfiled {
+		// GRAPH.PROFILE only returns the plan, the returned rows are counted by running the query again without profiling it.
+		// Profiled queries only read from the graph, so running them twice leaves it unchanged.
+		plan, err := do(ctx, conn, append([]any{"GRAPH.PROFILE", d.graph.Id, unprofiledQuery}, timeoutArgs...)...).StringSlice()
+		if err != nil {
+			logrus.Debugf("Profiling query produced error - %v", err)
+			res.ProducedError = err
+			return res
+		}
+		if res.Profile, err = parseProfile(plan); err != nil {
+			logrus.Warnf("Couldn't parse profiled plan - %v", err)
+		}
 	}
 
-	returned, err := d.graph.Query(query, nil, queryOpts)
+	raw, err := do(ctx, conn, append([]any{"GRAPH.QUERY", d.graph.Id, unprofiledQuery, "--compact"}, timeoutArgs...)...).Result()
+	var returned *falkordb.QueryResult
+	if err == nil {
+		returned, err = falkordb.QueryResultNew(d.graph, raw)
+	}
 	if err != nil {
 		logrus.Debugf("Query produced error - %v", err)
 		res.ProducedError = err
 		return res
 	}
 
+	res.Columns, res.Counters = toMetadata(raw)
+	var returnedRows int64
 	for returned.Next() {
+		returnedRows++
 		val := returned.Record()
 		if val != nil {
-			res.Rows = append(res.Rows, val.Values())
+			res.Rows = append(res.Rows, toRow(val.Values()))
 		} else {
 			d.returnedNil = true
 			logrus.Debugf("nil record after query, this is a bug with the FalkorDB driver %s", query)
 		}
 	}
+	if res.Profile != nil {
+		res.Profile.ReturnedRows = returnedRows
+	}
 
-	res.Schema = []any{}
-	schemaRes, err := d.graph.Query("MATCH (n) RETURN n AS x UNION MATCH ()-[m]-() RETURN m AS x", nil, falkordb.NewQueryOptions().SetTimeout(int(opts.Timeout.Seconds())))
+	schemaRes, err := d.graph.Query("MATCH (n) RETURN n AS x UNION MATCH ()-[m]->() RETURN m AS x", nil, queryOptions(opts))
 	if err != nil {
 		logrus.Debugf("Couldn't get schema - %v", err)
 		res.ProducedError = err
 		return res
 	}
+	res.Graph = dbms.NewGraph()
 	for schemaRes.Next() {
 		val := schemaRes.Record()
 		if val != nil {
-			res.Schema = append(res.Schema.([]any), val.Values())
+			for _, v := range val.Values() {
+				addToGraph(res.Graph, v)
+			}
 		} else {
 			d.returnedNil = true
 			logrus.Debugf("nil record after fetching schema after query, this is a bug with the FalkorDB driver %s", query)
@@ -137,7 +309,7 @@ func (d *Driver) RunQuery(opts dbms.DBOptions, query string) dbms.QueryResult {
 func (d *Driver) GetSchema(opts dbms.DBOptions) (*schema.Schema, error) {
 	s := &schema.Schema{}
 	s.Reset()
-	nodes, err := d.graph.Query("MATCH (n) UNWIND labels(n) AS i RETURN DISTINCT i", nil, falkordb.NewQueryOptions().SetTimeout(int(opts.Timeout.Seconds())))
+	nodes, err := d.graph.Query("MATCH (n) UNWIND labels(n) AS i RETURN DISTINCT i", nil, queryOptions(opts))
 	if err != nil {
 		logrus.Errorf("Couldn't get nodes for schema - %v", err)
 		return nil, err
@@ -147,7 +319,7 @@ func (d *Driver) GetSchema(opts dbms.DBOptions) (*schema.Schema, error) {
 		s.Labels[schema.NODE] = append(s.Labels[schema.NODE], label)
 	}
 
-	relationships, err := d.graph.Query("MATCH ()-[n]-() RETURN DISTINCT type(n)", nil, falkordb.NewQueryOptions().SetTimeout(int(opts.Timeout.Seconds())))
+	relationships, err := d.graph.Query("MATCH ()-[n]-() RETURN DISTINCT type(n)", nil, queryOptions(opts))
 	if err != nil {
 		logrus.Errorf("Couldn't get relationships for schema - %v", err)
 		return nil, err
@@ -159,6 +331,12 @@ func (d *Driver) GetSchema(opts dbms.DBOptions) (*schema.Schema, error) {
 
 	s.Labels[schema.ANY] = append(s.Labels[schema.RELATIONSHIP], s.Labels[schema.NODE]...)
 
+	if err := d.populateProperties(opts, s); err != nil {
+		logrus.Errorf("Couldn't get properties for schema - %v", err)
+		return nil, err
+	}
+	logrus.Tracef("Populated schema with properties %v", s.Properties)
+
 	return s, nil
 }
 
@@ -189,12 +367,9 @@ func (d *Driver) GetQueryResultType(res dbms.QueryResult, errorMessageRegex *dbm
 		return dbms.Bug
 	}
 
-	if errorMessageRegex.Ignored.MatchString(err.Error()) {
-		return dbms.Invalid
-	}
-
-	if errorMessageRegex.Reported.MatchString(err.Error()) {
-		return dbms.ReportedBug
+	// Redis errors carry no codes, they can only be told apart by their message
+	if resultType, ok := errorMessageRegex.Classify(err.Error()); ok {
+		return resultType
 	}
 
 	if err.Error() == "Query timed out" {
@@ -210,15 +385,10 @@ func (d *Driver) GetQueryResultType(res dbms.QueryResult, errorMessageRegex *dbm
 // if they don't produce an error.
 func (d *Driver) DiscardQuery(res dbms.QueryResult, seed *seed.Seed) bool {
 	if res.ProducedError != nil {
-		d.ranQueries++
 		return true
 	}
 
-	discard := seed.BooleanWithProbability(0.1)
-	if discard {
-		d.ranQueries++
-	}
-	return discard
+	return seed.BooleanWithProbability(0.1)
 }
 
 // VerifyConnectivity checks whether the DB is still reachable and hasn't crashed.
@@ -232,150 +402,5 @@ func (d *Driver) VerifyConnectivity(opts dbms.DBOptions) (bool, error) {
 
 // IsEqualResult returns whether the two passed results equal
 func (d *Driver) IsEqualResult(a dbms.QueryResult, b dbms.QueryResult) bool {
-	if len(a.Rows) != len(b.Rows) {
-		logrus.Warn("Encountered mismatching results")
-		logrus.Infof("\n\t%v\nvs\n\t%v", a.Rows, b.Rows)
-		return false
-	}
-
-	// Check that result rows match
-	if !d.IsMatchingRows(a.Rows, b.Rows) {
-		logrus.Warn("Encountered mismatching rows")
-		logrus.Infof("\n\t%v\nvs\n\t%v", a.Rows, b.Rows)
-		return false
-	}
-
-	// Check if the schemas match
-	if !d.IsMatchingRows(a.Schema, b.Schema) {
-		logrus.Warnf("Mismatching Schemas")
-		logrus.Infof("Schemas:\n\t%+v\nvs\n\t%+v", a.Schema, b.Schema)
-		return false
-	}
-
-	if a.ProducedError != nil || b.ProducedError != nil {
-		if a.ProducedError == nil || b.ProducedError == nil {
-			return false
-		}
-		if a.ProducedError.Error() != b.ProducedError.Error() {
-			return false
-		}
-	}
-
-	return true
-}
-
-// IsMatchingRows compares two redisgraph result rows.
-// It returns true if they match, else false.
-func (d *Driver) IsMatchingRows(first, second any) bool {
-	if first == nil || second == nil {
-		return first == nil && second == nil
-	}
-
-	firstRow := first.([]any)
-	secondRow := second.([]any)
-	// Copy second row since it gets manipulated during the comparison
-	secondRowCopy := make([]any, len(secondRow))
-	copy(secondRowCopy, secondRow)
-
-	// If only one row is nil
-	if (firstRow == nil || secondRow == nil) && (firstRow != nil || secondRow != nil) {
-		return false
-	}
-	if len(firstRow) != len(secondRow) {
-		return false
-	}
-
-	for i := range firstRow {
-		matchedIndex := -1
-		for j := range secondRowCopy {
-			if isMatchingRedisGraphElement(firstRow[i], secondRowCopy[j]) {
-				matchedIndex = j
-				break
-			}
-		}
-		if matchedIndex == -1 {
-			return false
-		}
-		secondRowCopy = append(secondRowCopy[:matchedIndex], secondRowCopy[matchedIndex+1:]...)
-	}
-	return true
-}
-
-// isMatchingRedisGraphElement returns true if the two passed elements
-// evaluate to the same redisgraph elements, else it returns false.
-func isMatchingRedisGraphElement(a, b any) bool {
-	secondElement := b
-	switch a := a.(type) {
-	case nil:
-		return b == nil
-	case []any:
-		if b, ok := secondElement.([]any); ok {
-			if len(a) != len(b) {
-				return false
-			}
-			for i := range a {
-				if !isMatchingRedisGraphElement(a[i], b[i]) {
-					return false
-				}
-			}
-			return true
-		}
-		return false
-	case int:
-		b, ok := secondElement.(int)
-		return ok && (b == a)
-	case float64:
-		b, ok := secondElement.(float64)
-		return ok && (b == a || (math.IsNaN(a) && math.IsNaN(b)))
-	case *falkordb.Node:
-		b, ok := secondElement.(*falkordb.Node)
-		return ok && (isMatchingNode(*a, *b))
-	case *falkordb.Edge:
-		b, ok := secondElement.(*falkordb.Edge)
-		return ok && (isMatchingEdge(*a, *b))
-	default:
-		if !reflect.DeepEqual(a, b) {
-			return false
-		}
-	}
-	return true
-}
-
-func isMatchingNode(a, b falkordb.Node) bool {
-	if len(a.Labels) != len(b.Labels) {
-		return false
-	}
-	// Mismatching labels
-	if cmp.Diff(a.Labels, b.Labels, cmpopts.SortSlices(func(a, b string) bool { return a < b })) != "" {
-		return false
-	}
-
-	if len(a.Properties) != len(b.Properties) {
-		return false
-	}
-
-	for k, v := range a.Properties {
-		if !isMatchingRedisGraphElement(v, b.Properties[k]) {
-			return false
-		}
-	}
-	return true
-}
-
-func isMatchingEdge(a, b falkordb.Edge) bool {
-	if a.Relation != b.Relation {
-		return false
-	}
-
-	if len(a.Properties) != len(b.Properties) {
-		return false
-	}
-
-	for k, v := range a.Properties {
-		if !isMatchingRedisGraphElement(v, b.Properties[k]) {
-			return false
-		}
-	}
-
-	return true
+	return dbms.DefaultComparator.EqualResults(a, b)
 }
@@ -0,0 +1,239 @@
+package falkordb
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/fakeserver"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The query fetching the graph after every fuzzed query
+const graphQuery = "MATCH (n) RETURN n AS x UNION MATCH ()-[m]->() RETURN m AS x"
+
+// startDriver starts a fake FalkorDB answering queries using the passed function and returns a driver connected to it.
+func startDriver(t *testing.T, query func(graph, query string) fakeserver.GraphResult) (*Driver, *fakeserver.RESPServer, dbms.DBOptions) {
+	server := fakeserver.StartRESP(t, (&fakeserver.GraphHandler{Query: query}).Handle)
+	d, opts, err := fakeserver.InitDriver(server, &Driver{})
+	require.NoError(t, err)
+	t.Cleanup(d.closeQueryConn)
+	return d, server, opts
+}
+
+func TestRunQuery(t *testing.T) {
+	a := fakeserver.Node{ID: 0, Labels: []string{"A"}, Properties: map[string]any{"p": int64(1)}}
+	b := fakeserver.Node{ID: 1, Labels: []string{"B"}}
+	r := fakeserver.Relationship{ID: 0, StartID: 0, EndID: 1, Type: "T", Properties: map[string]any{"q": "x"}}
+
+	d, server, opts := startDriver(t, func(graph, query string) fakeserver.GraphResult {
+		switch query {
+		case "CREATE (a:A {p: 1})-[:T {q: 'x'}]->(:B) RETURN a, 1.5 AS f, [1, 'a'] AS l, {k: true} AS m":
+			return fakeserver.GraphResult{
+				Columns:    []string{"a", "f", "l", "m"},
+				Rows:       [][]any{{a, 1.5, []any{int64(1), "a"}, map[string]any{"k": true}}},
+				Statistics: []string{"Labels added: 2", "Nodes created: 2", "Relationships created: 1", "Properties set: 2"},
+			}
+		case "MATCH p = ()-->() RETURN p":
+			return fakeserver.GraphResult{Columns: []string{"p"}, Rows: [][]any{{fakeserver.Path{Nodes: []fakeserver.Node{a, b}, Relationships: []fakeserver.Relationship{r}}}}}
+		case graphQuery:
+			return fakeserver.GraphResult{Columns: []string{"x"}, Rows: [][]any{{a}, {b}, {r}}}
+		}
+		return fakeserver.GraphResult{Error: "Invalid input"}
+	})
+
+	res := d.RunQuery(context.Background(), opts, "CREATE (a:A {p: 1})-[:T {q: 'x'}]->(:B) RETURN a, 1.5 AS f, [1, 'a'] AS l, {k: true} AS m")
+	assert.NoError(t, res.ProducedError)
+	assert.Equal(t, []string{"a", "f", "l", "m"}, res.Columns)
+	assert.Equal(t, []dbms.Row{{
+		dbms.Node{Labels: []string{"A"}, Properties: dbms.Map{"p": dbms.Int(1)}},
+		dbms.Float(1.5),
+		dbms.List{dbms.Int(1), dbms.String("a")},
+		dbms.Map{"k": dbms.Bool(true)},
+	}}, res.Rows)
+	assert.Equal(t, &dbms.UpdateCounters{LabelsAdded: 2, NodesCreated: 2, RelationshipsCreated: 1, PropertiesSet: 2}, res.Counters)
+
+	expectedGraph := dbms.NewGraph()
+	expectedGraph.AddNode("0", dbms.Node{Labels: []string{"A"}, Properties: dbms.Map{"p": dbms.Int(1)}})
+	expectedGraph.AddNode("1", dbms.Node{Labels: []string{"B"}, Properties: dbms.Map{}})
+	expectedGraph.AddRelationship("0", "0", "1", dbms.Relationship{Type: "T", Properties: dbms.Map{"q": dbms.String("x")}})
+	assert.Equal(t, expectedGraph, res.Graph)
+
+	res = d.RunQuery(context.Background(), opts, "MATCH p = ()-->() RETURN p")
+	assert.NoError(t, res.ProducedError)
+	assert.Equal(t, []dbms.Row{{dbms.Path{
+		Nodes: []dbms.Node{
+			{Labels: []string{"A"}, Properties: dbms.Map{"p": dbms.Int(1)}},
+			{Labels: []string{"B"}, Properties: dbms.Map{}},
+		},
+		Relationships: []dbms.Relationship{{Type: "T", Properties: dbms.Map{"q": dbms.String("x")}}},
+	}}}, res.Rows)
+
+	assert.Contains(t, server.Received(), "GRAPH.QUERY graph MATCH p = ()-->() RETURN p --compact timeout 5000")
+
+	// Older versions don't support timeouts for write queries
+	opts.BackwardsCompatibleMode = true
+	d.RunQuery(context.Background(), opts, "MATCH p = ()-->() RETURN p")
+	assert.Contains(t, server.Received(), "GRAPH.QUERY graph MATCH p = ()-->() RETURN p --compact")
+}
+
+func TestRunQuery_Profile(t *testing.T) {
+	handler := &fakeserver.GraphHandler{
+		Query: func(graph, query string) fakeserver.GraphResult {
+			if query == "MATCH (n) RETURN n.p AS p" {
+				return fakeserver.GraphResult{Columns: []string{"p"}, Rows: [][]any{{int64(1)}, {int64(2)}}}
+			}
+			return fakeserver.GraphResult{}
+		},
+		Profile: func(graph, query string) []string {
+			return []string{
+				"Results | Records produced: 3, Execution time: 0.002 ms",
+				"    All Node Scan | (n) | Records produced: 3, Execution time: 0.003 ms",
+			}
+		},
+	}
+	server := fakeserver.StartRESP(t, handler.Handle)
+	d, opts, err := fakeserver.InitDriver(server, &Driver{})
+	require.NoError(t, err)
+	t.Cleanup(d.closeQueryConn)
+
+	// The returned rows are counted by running the query again without profiling it
+	res := d.RunQuery(context.Background(), opts, dbms.ProfilePrefix+"MATCH (n) RETURN n.p AS p")
+	assert.NoError(t, res.ProducedError)
+	assert.Equal(t, []dbms.Row{{dbms.Int(1)}, {dbms.Int(2)}}, res.Rows)
+	if assert.NotNil(t, res.Profile) {
+		assert.Equal(t, int64(3), res.Profile.Rows)
+		assert.Equal(t, int64(2), res.Profile.ReturnedRows)
+	}
+	assert.Contains(t, server.Received(), "GRAPH.PROFILE graph MATCH (n) RETURN n.p AS p timeout 5000")
+	assert.Contains(t, server.Received(), "GRAPH.QUERY graph MATCH (n) RETURN n.p AS p --compact timeout 5000")
+}
+
+func TestRunQuery_Cancellation(t *testing.T) {
+	release := make(chan struct{})
+	d, server, opts := startDriver(t, func(graph, query string) fakeserver.GraphResult {
+		if query == "RETURN 'slow'" {
+			<-release
+		}
+		return fakeserver.GraphResult{}
+	})
+	// Let the handler return before the server gets closed
+	t.Cleanup(func() { close(release) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	res := d.RunQuery(ctx, opts, "RETURN 'slow'")
+	assert.Error(t, res.ProducedError)
+	assert.True(t, slices.ContainsFunc(server.Received(), func(command string) bool {
+		return strings.HasPrefix(strings.ToUpper(command), "CLIENT KILL ID")
+	}), "the query's connection should be killed")
+
+	res = d.RunQuery(context.Background(), opts, "RETURN 1")
+	assert.NoError(t, res.ProducedError, "a new connection should be opened for the next query")
+}
+
+func TestReset(t *testing.T) {
+	d, server, opts := startDriver(t, nil)
+
+	assert.NoError(t, d.Reset(opts))
+	assert.NotContains(t, server.Received(), "GRAPH.DELETE graph", "nonexistent graphs shouldn't be deleted")
+
+	d.RunQuery(context.Background(), opts, "CREATE ()")
+	assert.NoError(t, d.Reset(opts))
+	assert.Contains(t, server.Received(), "GRAPH.DELETE graph")
+
+	opts.Namespace = "fuzzer_1"
+	assert.NoError(t, d.Reset(opts))
+	d.RunQuery(context.Background(), opts, "CREATE ()")
+	assert.NoError(t, d.Reset(opts))
+	assert.Contains(t, server.Received(), "GRAPH.DELETE fuzzer_1")
+}
+
+func TestSnapshot(t *testing.T) {
+	d, server, opts := startDriver(t, nil)
+
+	assert.ErrorIs(t, d.Restore(opts), dbms.ErrNoSnapshot)
+
+	assert.NoError(t, d.Snapshot(opts))
+	assert.NotContains(t, server.Received(), "GRAPH.COPY graph graph_snapshot", "nonexistent graphs shouldn't be copied")
+	d.RunQuery(context.Background(), opts, "CREATE ()")
+	assert.NoError(t, d.Restore(opts))
+	assert.Contains(t, server.Received(), "GRAPH.DELETE graph", "restoring an empty snapshot should delete the graph")
+
+	d.RunQuery(context.Background(), opts, "CREATE INDEX FOR (n:A) ON (n.p)")
+	assert.NoError(t, d.Snapshot(opts))
+	assert.Contains(t, server.Received(), "GRAPH.COPY graph graph_snapshot")
+	d.RunQuery(context.Background(), opts, "CREATE ()")
+	assert.NoError(t, d.Restore(opts))
+	assert.Equal(t, []string{"exists graph", "GRAPH.DELETE graph", "exists graph_snapshot", "GRAPH.COPY graph_snapshot graph"}, server.Received()[len(server.Received())-4:])
+	assert.NoError(t, d.Restore(opts), "the snapshot should be kept for further restores")
+
+	assert.NoError(t, d.Snapshot(opts), "taking a new snapshot should replace the old one")
+	assert.Contains(t, server.Received(), "GRAPH.DELETE graph_snapshot")
+
+	assert.NoError(t, d.Reset(opts))
+	assert.ErrorIs(t, d.Restore(opts), dbms.ErrNoSnapshot, "resetting should discard the snapshot")
+}
+
+func TestGetSchema(t *testing.T) {
+	d, _, opts := startDriver(t, func(graph, query string) fakeserver.GraphResult {
+		switch query {
+		case "MATCH (n) UNWIND labels(n) AS i RETURN DISTINCT i":
+			return fakeserver.GraphResult{Columns: []string{"i"}, Rows: [][]any{{"A"}, {"B"}}}
+		case "MATCH ()-[n]-() RETURN DISTINCT type(n)":
+			return fakeserver.GraphResult{Columns: []string{"type(n)"}, Rows: [][]any{{"T"}}}
+		case propertiesQuery:
+			return fakeserver.GraphResult{Columns: []string{"key", "type", "value"}, Rows: [][]any{
+				{"p", "Integer", int64(1)},
+				{"l", "List", []any{"a", "b"}},
+				{"m", "Map", nil},
+			}}
+		}
+		return fakeserver.GraphResult{}
+	})
+	assert.NoError(t, d.Reset(opts))
+
+	s, err := d.GetSchema(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"A", "B"}, s.Labels[schema.NODE])
+	assert.Equal(t, []string{"T"}, s.Labels[schema.RELATIONSHIP])
+	assert.Equal(t, []string{"T", "A", "B"}, s.Labels[schema.ANY])
+
+	expected := &schema.Schema{}
+	expected.Reset()
+	expected.AddProperty(schema.Property{Name: "l", Type: schema.String | schema.PropertyType(schema.ListMask), Value: `["a", "b"]`})
+	expected.AddProperty(schema.Property{Name: "p", Type: schema.Integer, Value: "1"})
+	assert.Equal(t, expected.Properties, s.Properties, "unsupported types should be skipped")
+}
+
+func TestGetQueryResultType(t *testing.T) {
+	d, _, opts := startDriver(t, func(graph, query string) fakeserver.GraphResult {
+		return fakeserver.GraphResult{Error: query}
+	})
+	regex := &dbms.ErrorMessageRegex{
+		Ignored:  regexp.MustCompile("^Division by zero$"),
+		Reported: regexp.MustCompile("^Reported$"),
+	}
+
+	for _, tc := range []struct {
+		message  string
+		expected dbms.QueryResultType
+	}{
+		{"Division by zero", dbms.Invalid},
+		{"Reported", dbms.ReportedBug},
+		{"Query timed out", dbms.Timeout},
+		{"Unexpected", dbms.Bug},
+	} {
+		t.Run(tc.message, func(t *testing.T) {
+			res := d.RunQuery(context.Background(), opts, tc.message)
+			assert.EqualError(t, res.ProducedError, tc.message)
+			assert.Equal(t, tc.expected, d.GetQueryResultType(res, regex))
+		})
+	}
+}
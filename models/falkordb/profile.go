@@ -0,0 +1,64 @@
+package falkordb
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Anon10214/dinkel/dbms"
+)
+
+var recordsProducedRegex = regexp.MustCompile(`Records produced: (\d+)`)
+
+// parseProfile builds the operator tree from the lines returned by GRAPH.PROFILE.
+//
+// Every line describes one operator, indented by four spaces per level of nesting, e.g.
+//
+//	Results | Records produced: 2, Execution time: 0.002 ms
+//	    Filter | Records produced: 2, Execution time: 0.007 ms
+//	        Node By Label Scan | (n:L) | Records produced: 3, Execution time: 0.004 ms
+func parseProfile(lines []string) (*dbms.ProfiledOperator, error) {
+	var root *dbms.ProfiledOperator
+	// The last operator encountered at each depth
+	var last []*dbms.ProfiledOperator
+
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		indentation := len(line) - len(trimmed)
+		if indentation%4 != 0 {
+			return nil, fmt.Errorf("line %d has an indentation of %d", i, indentation)
+		}
+		depth := indentation / 4
+
+		name, _, _ := strings.Cut(trimmed, " | ")
+		match := recordsProducedRegex.FindStringSubmatch(trimmed)
+		if match == nil {
+			return nil, fmt.Errorf("couldn't find produced records of operator %q in line %d", name, i)
+		}
+		rows, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		op := &dbms.ProfiledOperator{Name: name, Rows: rows}
+
+		if root == nil {
+			if depth != 0 {
+				return nil, fmt.Errorf("first operator %q is nested", name)
+			}
+			root = op
+		} else {
+			if depth == 0 || depth > len(last) {
+				return nil, fmt.Errorf("operator %q in line %d has no parent", name, i)
+			}
+			last[depth-1].Children = append(last[depth-1].Children, op)
+		}
+
+		last = append(last[:depth], op)
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("no operators in profiled plan")
+	}
+	return root, nil
+}
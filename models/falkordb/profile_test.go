@@ -0,0 +1,42 @@
+package falkordb
+
+import (
+	"testing"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProfile(t *testing.T) {
+	profile, err := parseProfile([]string{
+		"Results | Records produced: 2, Execution time: 0.002 ms",
+		"    Cartesian Product | Records produced: 2, Execution time: 0.010 ms",
+		"        Filter | Records produced: 1, Execution time: 0.007 ms",
+		"            Node By Label Scan | (n:L) | Records produced: 3, Execution time: 0.004 ms",
+		"        All Node Scan | (m) | Records produced: 2, Execution time: 0.003 ms",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &dbms.ProfiledOperator{Name: "Results", Rows: 2, Children: []*dbms.ProfiledOperator{
+		{Name: "Cartesian Product", Rows: 2, Children: []*dbms.ProfiledOperator{
+			{Name: "Filter", Rows: 1, Children: []*dbms.ProfiledOperator{
+				{Name: "Node By Label Scan", Rows: 3},
+			}},
+			{Name: "All Node Scan", Rows: 2},
+		}},
+	}}, profile)
+}
+
+func TestParseProfile_Malformed(t *testing.T) {
+	_, err := parseProfile(nil)
+	assert.Error(t, err, "Empty plan parsed")
+
+	_, err = parseProfile([]string{"Results | Execution time: 0.002 ms"})
+	assert.Error(t, err, "Operator without produced records parsed")
+
+	_, err = parseProfile([]string{
+		"Results | Records produced: 2, Execution time: 0.002 ms",
+		"        Filter | Records produced: 1, Execution time: 0.007 ms",
+	})
+	assert.Error(t, err, "Operator skipping a level parsed")
+}
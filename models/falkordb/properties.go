@@ -0,0 +1,105 @@
+package falkordb
+
+import (
+	"slices"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/sirupsen/logrus"
+)
+
+// The query fetching all properties' keys, types and values.
+//
+// The falkordb driver fails to parse some values, such as points or temporal values.
+// Because of this, only values of types the driver can parse get returned.
+const propertiesQuery = `
+MATCH (n) UNWIND keys(n) AS key WITH key, typeOf(n[key]) AS type, n[key] AS value
+RETURN key, type, CASE
+	WHEN type IN ['Boolean', 'Float', 'Integer', 'String'] THEN value
+	WHEN type = 'List' THEN CASE WHEN all(x IN value WHERE typeOf(x) IN ['Boolean', 'Float', 'Integer', 'String']) THEN value END
+END AS value
+	UNION
+MATCH ()-[m]->() UNWIND keys(m) AS key WITH key, typeOf(m[key]) AS type, m[key] AS value
+RETURN key, type, CASE
+	WHEN type IN ['Boolean', 'Float', 'Integer', 'String'] THEN value
+	WHEN type = 'List' THEN CASE WHEN all(x IN value WHERE typeOf(x) IN ['Boolean', 'Float', 'Integer', 'String']) THEN value END
+END AS value
+`
+
+// populateProperties fetches all properties in the DB and inserts them into the schema.
+//
+// The properties get sorted before being added, as the order of the returned rows isn't deterministic.
+func (d *Driver) populateProperties(opts dbms.DBOptions, s *schema.Schema) error {
+	res, err := d.graph.Query(propertiesQuery, nil, queryOptions(opts))
+	if err != nil {
+		return err
+	}
+
+	var properties []schema.Property
+	for res.Next() {
+		values := res.Record().Values()
+		propType, ok := typeOfToPropertyType(values[1].(string), values[2])
+		if !ok {
+			logrus.Debugf("Skipping property %v of unsupported type %v", values[0], values[1])
+			continue
+		}
+		property := schema.Property{
+			Name:  values[0].(string),
+			Type:  propType,
+			Value: schema.Literal(values[2], nil),
+		}
+		if !slices.Contains(properties, property) {
+			properties = append(properties, property)
+		}
+	}
+
+	slices.SortFunc(properties, schema.CompareProperties)
+	for _, property := range properties {
+		s.AddProperty(property)
+	}
+	return nil
+}
+
+// typeOfToPropertyType maps a type as returned by FalkorDB's typeOf function to its equivalent [schema.PropertyType].
+//
+// As typeOf doesn't reveal the type of a list's elements, the element type is derived from the value itself.
+// Lists with elements of differing or unknown types are of type [schema.AnyType] with the list mask set.
+// Returns false if the type has no equivalent, as is the case for maps and vectors.
+func typeOfToPropertyType(typeOf string, value any) (schema.PropertyType, bool) {
+	if typeOf != "List" {
+		propType, found := map[string]schema.PropertyType{
+			"Boolean":  schema.Boolean,
+			"Date":     schema.Date,
+			"Datetime": schema.Datetime,
+			"Duration": schema.Duration,
+			"Float":    schema.Float,
+			"Integer":  schema.Integer,
+			"Point":    schema.Point,
+			"String":   schema.String,
+			"Time":     schema.Time,
+		}[typeOf]
+		return propType, found
+	}
+
+	elems, _ := value.([]any)
+	var elemType schema.PropertyType
+	for i, elem := range elems {
+		var t schema.PropertyType
+		switch elem.(type) {
+		case bool:
+			t = schema.Boolean
+		case int64:
+			t = schema.Integer
+		case float64:
+			t = schema.Float
+		case string:
+			t = schema.String
+		}
+		if t == schema.AnyType || (i > 0 && t != elemType) {
+			elemType = schema.AnyType
+			break
+		}
+		elemType = t
+	}
+	return elemType | schema.PropertyType(schema.ListMask), true
+}
@@ -0,0 +1,34 @@
+package falkordb
+
+import (
+	"testing"
+
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypeOfToPropertyType(t *testing.T) {
+	listMask := schema.PropertyType(schema.ListMask)
+	for _, tc := range []struct {
+		typeOf   string
+		value    any
+		expected schema.PropertyType
+	}{
+		{"Integer", int64(1), schema.Integer},
+		{"Boolean", true, schema.Boolean},
+		{"Point", nil, schema.Point},
+		{"List", []any{"a", "b"}, schema.String | listMask},
+		{"List", []any{1.5, int64(1)}, schema.AnyType | listMask},
+		// Values the driver can't parse aren't returned
+		{"List", nil, schema.AnyType | listMask},
+	} {
+		propType, ok := typeOfToPropertyType(tc.typeOf, tc.value)
+		assert.True(t, ok, tc.typeOf)
+		assert.Equal(t, tc.expected, propType, tc.value)
+	}
+
+	for _, typeOf := range []string{"Map", "Vectorf32", "Node"} {
+		_, ok := typeOfToPropertyType(typeOf, nil)
+		assert.False(t, ok, typeOf)
+	}
+}
@@ -0,0 +1,148 @@
+package falkordb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/FalkorDB/falkordb-go"
+)
+
+// toRow converts the values of a record returned by the FalkorDB driver into a row.
+func toRow(values []any) dbms.Row {
+	row := make(dbms.Row, len(values))
+	for i, v := range values {
+		row[i] = toValue(v)
+	}
+	return row
+}
+
+// toValue converts a value returned by the FalkorDB driver into its [dbms.Value].
+func toValue(v any) dbms.Value {
+	switch v := v.(type) {
+	case []any:
+		list := make(dbms.List, len(v))
+		for i, elem := range v {
+			list[i] = toValue(elem)
+		}
+		return list
+	case map[string]any:
+		return toMap(v)
+	case *falkordb.Node:
+		return toNode(v)
+	case *falkordb.Edge:
+		return toRelationship(v)
+	case falkordb.Path:
+		path := dbms.Path{}
+		for _, node := range v.Nodes {
+			path.Nodes = append(path.Nodes, toNode(node))
+		}
+		for _, edge := range v.Edges {
+			path.Relationships = append(path.Relationships, toRelationship(edge))
+		}
+		return path
+	}
+	if value, ok := dbms.ScalarValue(v); ok {
+		return value
+	}
+	return dbms.UnknownValue(v)
+}
+
+// addToGraph adds the nodes and relationships found in a value returned by the FalkorDB driver to the graph.
+// Nodes and relationships may be nested in lists, maps and paths.
+func addToGraph(graph *dbms.Graph, v any) {
+	switch v := v.(type) {
+	case []any:
+		for _, elem := range v {
+			addToGraph(graph, elem)
+		}
+	case map[string]any:
+		for _, elem := range v {
+			addToGraph(graph, elem)
+		}
+	case *falkordb.Node:
+		graph.AddNode(fmt.Sprint(v.ID), toNode(v))
+	case *falkordb.Edge:
+		graph.AddRelationship(fmt.Sprint(v.ID), fmt.Sprint(v.SourceNodeID()), fmt.Sprint(v.DestNodeID()), toRelationship(v))
+	case falkordb.Path:
+		for _, node := range v.Nodes {
+			addToGraph(graph, node)
+		}
+		for _, edge := range v.Edges {
+			addToGraph(graph, edge)
+		}
+	}
+}
+
+func toMap(m map[string]any) dbms.Map {
+	res := make(dbms.Map, len(m))
+	for k, v := range m {
+		res[k] = toValue(v)
+	}
+	return res
+}
+
+func toNode(n *falkordb.Node) dbms.Node {
+	return dbms.Node{Labels: n.Labels, Properties: toMap(n.Properties)}
+}
+
+func toRelationship(e *falkordb.Edge) dbms.Relationship {
+	return dbms.Relationship{Type: e.Relation, Properties: toMap(e.Properties)}
+}
+
+// toMetadata returns the column names and update counters of a raw reply to GRAPH.QUERY.
+//
+// The reply holds the header, the records and the statistics, or only the statistics if the query returns nothing.
+func toMetadata(raw any) (columns []string, counters *dbms.UpdateCounters) {
+	reply, ok := raw.([]any)
+	if !ok || len(reply) == 0 {
+		return nil, nil
+	}
+	if len(reply) == 3 {
+		header, _ := reply[0].([]any)
+		for _, column := range header {
+			// Each column is described by its type and name
+			if column, ok := column.([]any); ok && len(column) == 2 {
+				name, _ := column[1].(string)
+				columns = append(columns, name)
+			}
+		}
+	}
+	statistics, _ := reply[len(reply)-1].([]any)
+	return columns, toUpdateCounters(statistics)
+}
+
+// toUpdateCounters parses the statistics of a reply to GRAPH.QUERY, such as "Nodes created: 1".
+func toUpdateCounters(statistics []any) *dbms.UpdateCounters {
+	counters := &dbms.UpdateCounters{}
+	for _, stat := range statistics {
+		stat, _ := stat.(string)
+		name, value, found := strings.Cut(stat, ": ")
+		if !found {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			// Only counters are integers, e.g. the execution time is suffixed by its unit
+			continue
+		}
+		switch name {
+		case "Nodes created":
+			counters.NodesCreated = n
+		case "Nodes deleted":
+			counters.NodesDeleted = n
+		case "Relationships created":
+			counters.RelationshipsCreated = n
+		case "Relationships deleted":
+			counters.RelationshipsDeleted = n
+		case "Properties set":
+			counters.PropertiesSet = n
+		case "Labels added":
+			counters.LabelsAdded = n
+		case "Labels removed":
+			counters.LabelsRemoved = n
+		}
+	}
+	return counters
+}
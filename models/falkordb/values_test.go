@@ -0,0 +1,36 @@
+package falkordb
+
+import (
+	"testing"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToMetadata(t *testing.T) {
+	statistics := []any{
+		"Labels added: 1",
+		"Nodes created: 2",
+		"Properties set: 3",
+		"Cached execution: 0",
+		"Query internal execution time: 0.123 milliseconds",
+	}
+	expectedCounters := &dbms.UpdateCounters{LabelsAdded: 1, NodesCreated: 2, PropertiesSet: 3}
+
+	columns, counters := toMetadata([]any{
+		[]any{[]any{int64(1), "a"}, []any{int64(1), "b"}},
+		[]any{},
+		statistics,
+	})
+	assert.Equal(t, []string{"a", "b"}, columns)
+	assert.Equal(t, expectedCounters, counters)
+
+	// Queries returning nothing only reply with statistics
+	columns, counters = toMetadata([]any{statistics})
+	assert.Nil(t, columns)
+	assert.Equal(t, expectedCounters, counters)
+
+	columns, counters = toMetadata(nil)
+	assert.Nil(t, columns)
+	assert.Nil(t, counters)
+}
@@ -0,0 +1,167 @@
+package inmemory
+
+import (
+	"math"
+	"slices"
+)
+
+// An aggregateFunction computes a single value from the values of a group of rows.
+type aggregateFunction struct {
+	// The number of arguments, the first argument is evaluated per row and aggregated
+	args int
+	// Called with the non-null values of the first argument and the remaining arguments, evaluated on the group's first row
+	aggregate func(values []any, args []any) any
+}
+
+// The aggregating functions supported by the engine, by their name in lower case.
+//
+// Null values are skipped, count(*) is handled separately as it counts rows.
+var aggregateFunctions = map[string]aggregateFunction{
+	"count": {1, func(values []any, _ []any) any {
+		return int64(len(values))
+	}},
+	"collect": {1, func(values []any, _ []any) any {
+		return append([]any{}, values...)
+	}},
+	"min": {1, func(values []any, _ []any) any {
+		if len(values) == 0 {
+			return nil
+		}
+		return slices.MinFunc(values, orderCompare)
+	}},
+	"max": {1, func(values []any, _ []any) any {
+		if len(values) == 0 {
+			return nil
+		}
+		return slices.MaxFunc(values, orderCompare)
+	}},
+	"sum": {1, func(values []any, _ []any) any {
+		var res any = int64(0)
+		for _, v := range values {
+			res = arithmetic("+", res, numberArg("sum", v))
+		}
+		return res
+	}},
+	"avg": {1, func(values []any, _ []any) any {
+		if len(values) == 0 {
+			return nil
+		}
+		return mean(floatValues("avg", values))
+	}},
+	"stdev": {1, func(values []any, _ []any) any {
+		return standardDeviation(floatValues("stdev", values), true)
+	}},
+	"stdevp": {1, func(values []any, _ []any) any {
+		return standardDeviation(floatValues("stdevp", values), false)
+	}},
+	"percentilecont": {2, func(values []any, args []any) any {
+		percentile := percentileArg("percentileCont", args[0])
+		floats := floatValues("percentileCont", values)
+		if len(floats) == 0 {
+			return nil
+		}
+		slices.SortFunc(floats, func(a, b float64) int { return orderCompare(a, b) })
+		position := percentile * float64(len(floats)-1)
+		lower, upper := math.Floor(position), math.Ceil(position)
+		if lower == upper {
+			return floats[int(lower)]
+		}
+		return floats[int(lower)] + (position-lower)*(floats[int(upper)]-floats[int(lower)])
+	}},
+	"percentiledisc": {2, func(values []any, args []any) any {
+		percentile := percentileArg("percentileDisc", args[0])
+		if len(values) == 0 {
+			return nil
+		}
+		for _, v := range values {
+			numberArg("percentileDisc", v)
+		}
+		sorted := slices.Clone(values)
+		slices.SortStableFunc(sorted, orderCompare)
+		position := percentile * float64(len(sorted))
+		idx := int(position)
+		if float64(idx) == position && idx != 0 {
+			idx--
+		}
+		return sorted[min(idx, len(sorted)-1)]
+	}},
+}
+
+// aggregate computes the aggregating function call over the passed rows.
+func (e *executor) aggregate(call *functionCall, rows []row) any {
+	if call.star {
+		return int64(len(rows))
+	}
+	var values []any
+	seen := map[string]bool{}
+	for _, r := range rows {
+		v := e.eval(call.arguments[0], r)
+		if v == nil {
+			continue
+		}
+		if call.distinct {
+			key := equivalenceKey(v)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		values = append(values, v)
+	}
+	var args []any
+	if len(call.arguments) > 1 {
+		// The remaining arguments are constant within a group, e.g. the percentile
+		first := row{}
+		if len(rows) > 0 {
+			first = rows[0]
+		}
+		for _, arg := range call.arguments[1:] {
+			args = append(args, e.eval(arg, first))
+		}
+	}
+	return aggregateFunctions[call.name].aggregate(values, args)
+}
+
+func floatValues(name string, values []any) []float64 {
+	res := make([]float64, len(values))
+	for i, v := range values {
+		res[i] = toFloat(numberArg(name, v))
+	}
+	return res
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// standardDeviation computes the sample or population standard deviation, which is 0 for too few values.
+func standardDeviation(values []float64, sample bool) float64 {
+	n := float64(len(values))
+	if sample {
+		n--
+	}
+	if n <= 0 {
+		return 0
+	}
+	avg := mean(values)
+	var sum float64
+	for _, v := range values {
+		sum += (v - avg) * (v - avg)
+	}
+	return math.Sqrt(sum / n)
+}
+
+func percentileArg(name string, v any) float64 {
+	if !isNumber(v) {
+		raise(ArgumentError, "%s expects a numeric percentile, but got a %s", name, typeName(v))
+	}
+	percentile := toFloat(v)
+	if !(percentile >= 0 && percentile <= 1) {
+		raise(ArgumentError, "%s expects a percentile between 0 and 1, but got %s", name, formatNumber(v))
+	}
+	return percentile
+}
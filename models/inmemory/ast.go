@@ -0,0 +1,263 @@
+package inmemory
+
+import "slices"
+
+// A statement is a sequence of clauses, optionally ending in a RETURN.
+type statement struct {
+	clauses []clause
+}
+
+type clause interface {
+	isClause()
+}
+
+type matchClause struct {
+	optional bool
+	patterns []*patternPart
+	where    expression
+}
+
+type createClause struct {
+	patterns []*patternPart
+}
+
+type setClause struct {
+	items []setItem
+}
+
+// A setItem sets a property (x.p = e), all properties (x = e), adds properties (x += e) or adds labels (x:A:B).
+type setItem struct {
+	variable string
+	// The property set, empty if the item isn't setting a single property
+	property string
+	value    expression
+	// Whether the item adds the map's properties instead of replacing all properties
+	merge  bool
+	labels []string
+}
+
+type unwindClause struct {
+	list  expression
+	alias string
+}
+
+// A projection is a WITH or RETURN clause.
+type projection struct {
+	isReturn bool
+	distinct bool
+	// Whether all variables in scope get projected, followed by the items
+	star    bool
+	items   []projectionItem
+	orderBy []sortItem
+	skip    expression
+	limit   expression
+	// Only set for WITH
+	where expression
+}
+
+type projectionItem struct {
+	expression expression
+	// The alias, or the expression as written if no alias was given
+	name string
+	// Whether the item was aliased explicitly
+	aliased bool
+}
+
+type sortItem struct {
+	expression expression
+	descending bool
+}
+
+func (*matchClause) isClause()  {}
+func (*createClause) isClause() {}
+func (*setClause) isClause()    {}
+func (*unwindClause) isClause() {}
+func (*projection) isClause()   {}
+
+// A patternPart is a chain of nodes connected by relationships, optionally assigned to a path variable.
+type patternPart struct {
+	pathVariable string
+	nodes        []*nodePattern
+	// The relationship at index i connects the nodes at index i and i+1
+	relationships []*relationshipPattern
+}
+
+type nodePattern struct {
+	variable   string
+	labels     labelExpression
+	properties *mapLiteral
+}
+
+type direction int
+
+const (
+	directionBoth direction = iota
+	// From the left node to the right node
+	directionRight
+	// From the right node to the left node
+	directionLeft
+)
+
+type relationshipPattern struct {
+	variable   string
+	types      labelExpression
+	properties *mapLiteral
+	direction  direction
+	// Whether the pattern matches paths of variable length, bounded by min and max
+	variableLength bool
+	min            int64
+	// Negative if unbounded
+	max int64
+}
+
+// A labelExpression matches sets of labels, for relationships a set holding their type.
+// A nil labelExpression matches any set.
+type labelExpression interface {
+	matches(labels []string) bool
+}
+
+type labelName string
+
+type labelConjunction struct{ left, right labelExpression }
+
+type labelDisjunction struct{ left, right labelExpression }
+
+type labelNegation struct{ operand labelExpression }
+
+// An expression evaluates to a value given a row.
+type expression interface {
+	isExpression()
+}
+
+type literal struct{ value any }
+
+type variable struct{ name string }
+
+type propertyAccess struct {
+	subject  expression
+	property string
+}
+
+type indexAccess struct{ subject, index expression }
+
+// A sliceAccess slices a list, from or to are nil if omitted.
+type sliceAccess struct{ subject, from, to expression }
+
+type listLiteral struct{ elements []expression }
+
+type mapLiteral struct {
+	keys   []string
+	values []expression
+}
+
+type unaryOperation struct {
+	operator string
+	operand  expression
+}
+
+type binaryOperation struct {
+	operator    string
+	left, right expression
+}
+
+// A nullCheck is an IS NULL or IS NOT NULL check
+type nullCheck struct {
+	operand expression
+	negated bool
+}
+
+// A labelCheck checks whether a node has labels or a relationship has a type, e.g. n:A
+type labelCheck struct {
+	subject expression
+	labels  labelExpression
+}
+
+type caseExpression struct {
+	// The expression compared to the alternatives, nil for a generic CASE
+	subject      expression
+	alternatives []caseAlternative
+	// nil if there is no ELSE
+	otherwise expression
+}
+
+type caseAlternative struct{ when, then expression }
+
+type functionCall struct {
+	// The function's name in lower case
+	name      string
+	distinct  bool
+	arguments []expression
+	// Whether the function got called with an asterisk, as in count(*)
+	star bool
+}
+
+type listComprehension struct {
+	variable string
+	list     expression
+	// nil if there is no WHERE
+	where expression
+	// nil if there is no projection
+	projection expression
+}
+
+// A quantifier is an all, any, none or single predicate
+type quantifier struct {
+	kind     string
+	variable string
+	list     expression
+	where    expression
+}
+
+func (*literal) isExpression()           {}
+func (*variable) isExpression()          {}
+func (*propertyAccess) isExpression()    {}
+func (*indexAccess) isExpression()       {}
+func (*sliceAccess) isExpression()       {}
+func (*listLiteral) isExpression()       {}
+func (*mapLiteral) isExpression()        {}
+func (*unaryOperation) isExpression()    {}
+func (*binaryOperation) isExpression()   {}
+func (*nullCheck) isExpression()         {}
+func (*labelCheck) isExpression()        {}
+func (*caseExpression) isExpression()    {}
+func (*functionCall) isExpression()      {}
+func (*listComprehension) isExpression() {}
+func (*quantifier) isExpression()        {}
+
+// children returns the direct subexpressions of the passed expression, omitting absent ones.
+func children(expr expression) []expression {
+	var res []expression
+	switch expr := expr.(type) {
+	case *propertyAccess:
+		res = []expression{expr.subject}
+	case *indexAccess:
+		res = []expression{expr.subject, expr.index}
+	case *sliceAccess:
+		res = []expression{expr.subject, expr.from, expr.to}
+	case *listLiteral:
+		res = expr.elements
+	case *mapLiteral:
+		res = expr.values
+	case *unaryOperation:
+		res = []expression{expr.operand}
+	case *binaryOperation:
+		res = []expression{expr.left, expr.right}
+	case *nullCheck:
+		res = []expression{expr.operand}
+	case *labelCheck:
+		res = []expression{expr.subject}
+	case *caseExpression:
+		res = append(res, expr.subject)
+		for _, alternative := range expr.alternatives {
+			res = append(res, alternative.when, alternative.then)
+		}
+		res = append(res, expr.otherwise)
+	case *functionCall:
+		res = expr.arguments
+	case *listComprehension:
+		res = []expression{expr.list, expr.where, expr.projection}
+	case *quantifier:
+		res = []expression{expr.list, expr.where}
+	}
+	return slices.DeleteFunc(slices.Clone(res), func(e expression) bool { return e == nil })
+}
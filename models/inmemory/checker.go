@@ -0,0 +1,413 @@
+package inmemory
+
+import (
+	"reflect"
+	"slices"
+)
+
+// The kind of value a variable is known to hold.
+type variableKind int
+
+const (
+	// A variable of unknown kind, e.g. one projected from an expression
+	kindValue variableKind = iota
+	kindNode
+	kindRelationship
+	// A list of relationships, bound by a variable length relationship pattern
+	kindRelationshipList
+	kindPath
+)
+
+// The checker validates a statement before it runs, so that invalid queries fail regardless of the graph.
+//
+// It checks that variables are defined before being used and not redeclared,
+// that aggregating functions are only used in projections and that the statement is complete.
+// Additionally, it expands the asterisk of projections into the variables in scope.
+type checker struct {
+	scope map[string]variableKind
+	// The variables declared before a projection with DISTINCT or an aggregation,
+	// which its ORDER BY and WHERE can't access anymore
+	hidden map[string]variableKind
+}
+
+// check checks the passed statement, returning an [*Error] if it is invalid.
+func check(stmt *statement) (err error) {
+	defer catch(&err)
+	c := &checker{scope: map[string]variableKind{}}
+	for i, cl := range stmt.clauses {
+		last := i == len(stmt.clauses)-1
+		switch cl := cl.(type) {
+		case *matchClause:
+			c.checkMatch(cl)
+		case *createClause:
+			c.checkCreate(cl)
+		case *setClause:
+			c.checkSet(cl)
+		case *unwindClause:
+			c.checkExpression(cl.list, nil, false)
+			c.declare(cl.alias, kindValue)
+		case *projection:
+			if cl.isReturn && !last {
+				raise(SyntaxError, "RETURN can only be used at the end of the query")
+			}
+			c.checkProjection(cl)
+		}
+		if last {
+			switch cl := cl.(type) {
+			case *matchClause, *unwindClause:
+				raise(SyntaxError, "the query can't conclude with a reading clause, it must end with RETURN or an updating clause")
+			case *projection:
+				if !cl.isReturn {
+					raise(SyntaxError, "the query can't conclude with WITH, it must end with RETURN or an updating clause")
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// declare adds a new variable to the scope, raising an error if it is already declared.
+func (c *checker) declare(name string, kind variableKind) {
+	if _, ok := c.scope[name]; ok {
+		raise(SemanticError, "variable %s is already declared", name)
+	}
+	c.scope[name] = kind
+}
+
+// checkNodeVariable checks a variable of a node pattern, returning whether it was already declared.
+func (c *checker) checkNodeVariable(name string) bool {
+	if name == "" {
+		return false
+	}
+	kind, ok := c.scope[name]
+	if !ok {
+		c.scope[name] = kindNode
+		return false
+	}
+	if kind != kindNode && kind != kindValue {
+		raise(SemanticError, "type mismatch: %s is not a node", name)
+	}
+	return true
+}
+
+func (c *checker) checkMatch(m *matchClause) {
+	var relationships []string
+	for _, part := range m.patterns {
+		for i, n := range part.nodes {
+			c.checkNodeVariable(n.variable)
+			if i == len(part.relationships) {
+				continue
+			}
+			r := part.relationships[i]
+			if r.variable == "" {
+				continue
+			}
+			if slices.Contains(relationships, r.variable) {
+				raise(SemanticError, "the relationship variable %s can't be used for multiple relationships of a pattern", r.variable)
+			}
+			relationships = append(relationships, r.variable)
+			kind, declared := c.scope[r.variable]
+			switch {
+			case !declared && r.variableLength:
+				c.scope[r.variable] = kindRelationshipList
+			case !declared:
+				c.scope[r.variable] = kindRelationship
+			case r.variableLength:
+				raise(SemanticError, "the variable length relationship variable %s is already declared", r.variable)
+			case kind != kindRelationship && kind != kindValue:
+				raise(SemanticError, "type mismatch: %s is not a relationship", r.variable)
+			}
+		}
+		if part.pathVariable != "" {
+			c.declare(part.pathVariable, kindPath)
+		}
+	}
+	// Like the WHERE, the properties of the patterns may use all variables bound by the patterns
+	for _, part := range m.patterns {
+		for _, n := range part.nodes {
+			c.checkProperties(n.properties)
+		}
+		for _, r := range part.relationships {
+			c.checkProperties(r.properties)
+		}
+	}
+	if m.where != nil {
+		c.checkExpression(m.where, nil, false)
+	}
+}
+
+func (c *checker) checkCreate(create *createClause) {
+	for _, part := range create.patterns {
+		if part.pathVariable != "" {
+			c.declare(part.pathVariable, kindPath)
+		}
+		for i, n := range part.nodes {
+			c.checkProperties(n.properties)
+			if _, ok := labelNames(n.labels); !ok {
+				raise(SyntaxError, "nodes can only be created with a conjunction of labels")
+			}
+			if c.checkNodeVariable(n.variable) && (n.labels != nil || n.properties != nil || len(part.nodes) == 1) {
+				raise(SemanticError, "can't create node %s, the variable is already declared", n.variable)
+			}
+			if i == len(part.relationships) {
+				continue
+			}
+			r := part.relationships[i]
+			c.checkProperties(r.properties)
+			if r.variable != "" {
+				c.declare(r.variable, kindRelationship)
+			}
+			if _, ok := r.types.(labelName); !ok {
+				raise(SyntaxError, "relationships must be created with exactly one type")
+			}
+			if r.direction == directionBoth {
+				raise(SemanticError, "only directed relationships can be created")
+			}
+			if r.variableLength {
+				raise(SemanticError, "variable length relationships can't be created")
+			}
+		}
+	}
+}
+
+func (c *checker) checkSet(s *setClause) {
+	for _, item := range s.items {
+		kind, ok := c.scope[item.variable]
+		if !ok {
+			raise(SemanticError, "variable %s is not defined", item.variable)
+		}
+		if item.labels != nil && kind != kindNode && kind != kindValue {
+			raise(SemanticError, "type mismatch: labels can only be set on nodes, but %s is not a node", item.variable)
+		}
+		if item.value != nil {
+			c.checkExpression(item.value, nil, false)
+		}
+	}
+}
+
+func (c *checker) checkProperties(m *mapLiteral) {
+	if m != nil {
+		c.checkExpression(m, nil, false)
+	}
+}
+
+func (c *checker) checkProjection(p *projection) {
+	if p.star {
+		if len(c.scope) == 0 {
+			raise(SemanticError, "%s * is not allowed when there are no variables in scope", p.keyword())
+		}
+		var items []projectionItem
+		for _, name := range sortedKeys(c.scope) {
+			items = append(items, projectionItem{expression: &variable{name: name}, name: name})
+		}
+		p.items = append(items, p.items...)
+		p.star = false
+	}
+
+	scope := map[string]variableKind{}
+	for _, item := range p.items {
+		c.checkExpression(item.expression, nil, true)
+		v, isVariable := item.expression.(*variable)
+		if !p.isReturn && !item.aliased && !isVariable {
+			raise(SemanticError, "expression %s in WITH must be aliased", item.name)
+		}
+		if _, ok := scope[item.name]; ok {
+			raise(SemanticError, "multiple result columns with the same name %s are not supported", item.name)
+		}
+		scope[item.name] = kindValue
+		if isVariable {
+			scope[item.name] = c.scope[v.name]
+		}
+	}
+
+	aggregating := p.isAggregating()
+	if aggregating {
+		var keys []expression
+		for _, item := range p.items {
+			if !containsAggregate(item.expression) {
+				keys = append(keys, item.expression)
+			}
+		}
+		for _, item := range p.items {
+			if containsAggregate(item.expression) {
+				checkGrouping(item.expression, keys, nil)
+			}
+		}
+	}
+
+	// After a DISTINCT or aggregation, only the projected variables remain accessible
+	sortScope := scope
+	if !p.distinct && !aggregating {
+		sortScope = map[string]variableKind{}
+		for name, kind := range c.scope {
+			sortScope[name] = kind
+		}
+		for name, kind := range scope {
+			sortScope[name] = kind
+		}
+	} else {
+		c.hidden = c.scope
+	}
+	c.scope = sortScope
+	for _, item := range p.orderBy {
+		if (p.distinct || aggregating) && p.projects(item.expression) {
+			continue
+		}
+		c.checkExpression(item.expression, nil, false)
+	}
+	if p.where != nil {
+		c.checkExpression(p.where, nil, false)
+	}
+	c.hidden = nil
+	c.scope = map[string]variableKind{}
+	for _, limit := range []expression{p.skip, p.limit} {
+		if limit != nil {
+			c.checkExpression(limit, nil, false)
+		}
+	}
+	c.scope = scope
+}
+
+func (p *projection) keyword() string {
+	if p.isReturn {
+		return "RETURN"
+	}
+	return "WITH"
+}
+
+// isAggregating returns whether any of the projected items aggregates.
+func (p *projection) isAggregating() bool {
+	for _, item := range p.items {
+		if containsAggregate(item.expression) {
+			return true
+		}
+	}
+	return false
+}
+
+// projectedIndex returns the index of the projected item with the passed expression, -1 if none has it.
+func (p *projection) projectedIndex(expr expression) int {
+	return slices.IndexFunc(p.items, func(item projectionItem) bool {
+		return reflect.DeepEqual(item.expression, expr)
+	})
+}
+
+// projects returns whether an item projects the passed expression.
+func (p *projection) projects(expr expression) bool {
+	return p.projectedIndex(expr) != -1
+}
+
+// checkExpression checks that the variables used by the expression are defined, locals holding the variables
+// bound by enclosing list comprehensions and quantifiers.
+//
+// Aggregating functions are only allowed if allowAggregates is set, and can't be nested.
+func (c *checker) checkExpression(expr expression, locals []string, allowAggregates bool) {
+	switch expr := expr.(type) {
+	case *variable:
+		if _, ok := c.scope[expr.name]; !ok && !slices.Contains(locals, expr.name) {
+			if _, ok := c.hidden[expr.name]; ok {
+				raise(SemanticError, "variable %s can't be accessed after DISTINCT or an aggregation", expr.name)
+			}
+			raise(SemanticError, "variable %s is not defined", expr.name)
+		}
+		return
+	case *functionCall:
+		c.checkFunctionCall(expr, allowAggregates)
+		if _, ok := aggregateFunctions[expr.name]; ok {
+			allowAggregates = false
+		}
+	case *listComprehension:
+		if allowAggregates && containsAggregate(expr) {
+			raise(SemanticError, "aggregating functions can't be used inside of expressions iterating over lists")
+		}
+		c.checkExpression(expr.list, locals, false)
+		locals = append(slices.Clone(locals), expr.variable)
+		for _, e := range []expression{expr.where, expr.projection} {
+			if e != nil {
+				c.checkExpression(e, locals, false)
+			}
+		}
+		return
+	case *quantifier:
+		if allowAggregates && containsAggregate(expr) {
+			raise(SemanticError, "aggregating functions can't be used inside of expressions iterating over lists")
+		}
+		c.checkExpression(expr.list, locals, false)
+		c.checkExpression(expr.where, append(slices.Clone(locals), expr.variable), false)
+		return
+	}
+	for _, child := range children(expr) {
+		c.checkExpression(child, locals, allowAggregates)
+	}
+}
+
+func (c *checker) checkFunctionCall(call *functionCall, allowAggregates bool) {
+	if aggregate, ok := aggregateFunctions[call.name]; ok {
+		if !allowAggregates {
+			raise(SemanticError, "invalid use of the aggregating function %s", call.name)
+		}
+		if !call.star && len(call.arguments) != aggregate.args {
+			raise(SemanticError, "%s expects %d arguments, but got %d", call.name, aggregate.args, len(call.arguments))
+		}
+		return
+	}
+	if call.distinct {
+		raise(SemanticError, "DISTINCT can only be used with aggregating functions, not with %s", call.name)
+	}
+	f, ok := functions[call.name]
+	if !ok {
+		raise(Unsupported, "unknown function %s", call.name)
+	}
+	if len(call.arguments) < f.minArgs || len(call.arguments) > f.maxArgs {
+		raise(SemanticError, "%s expects between %d and %d arguments, but got %d", call.name, f.minArgs, f.maxArgs, len(call.arguments))
+	}
+}
+
+// containsAggregate returns whether the expression calls an aggregating function.
+func containsAggregate(expr expression) bool {
+	if call, ok := expr.(*functionCall); ok {
+		if _, ok := aggregateFunctions[call.name]; ok {
+			return true
+		}
+	}
+	return slices.ContainsFunc(children(expr), containsAggregate)
+}
+
+// aggregateCalls returns the aggregating function calls of the expression.
+func aggregateCalls(expr expression) []*functionCall {
+	if call, ok := expr.(*functionCall); ok {
+		if _, ok := aggregateFunctions[call.name]; ok {
+			return []*functionCall{call}
+		}
+	}
+	var res []*functionCall
+	for _, child := range children(expr) {
+		res = append(res, aggregateCalls(child)...)
+	}
+	return res
+}
+
+// checkGrouping checks that an aggregating item only uses variables outside of its aggregating functions
+// as part of grouping keys, as the values of other variables differ within a group.
+func checkGrouping(expr expression, keys []expression, locals []string) {
+	if slices.ContainsFunc(keys, func(key expression) bool { return reflect.DeepEqual(key, expr) }) {
+		return
+	}
+	switch expr := expr.(type) {
+	case *functionCall:
+		if _, ok := aggregateFunctions[expr.name]; ok {
+			return
+		}
+	case *variable:
+		if !slices.Contains(locals, expr.name) {
+			raise(SemanticError, "the aggregating expression uses %s, which isn't a grouping key", expr.name)
+		}
+	case *listComprehension:
+		locals = append(slices.Clone(locals), expr.variable)
+	case *quantifier:
+		locals = append(slices.Clone(locals), expr.variable)
+	}
+	for _, child := range children(expr) {
+		checkGrouping(child, keys, locals)
+	}
+}
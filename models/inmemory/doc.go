@@ -0,0 +1,20 @@
+/*
+Package inmemory provides an in-memory Cypher engine, runnable as a fuzzing target without any database.
+
+The engine supports the subset of Cypher generated for it: MATCH, WHERE, CREATE, SET, WITH, UNWIND and RETURN
+over a property graph, following Neo4j's semantics where Cypher leaves them open.
+It serves as an always available reference for differential testing
+and allows running the scheduler and fuzzing strategies end to end in tests.
+
+Queries are parsed, checked and then executed clause by clause, each clause consuming all rows of the previous one.
+Every query runs on a copy of the graph, which replaces the graph once the query succeeded, making queries atomic.
+Errors are returned as an [*Error], whose code classifies the error in the targets config.
+
+The engine has its own parser, sharing only the lexer with the OpenCypher
+[github.com/Anon10214/dinkel/models/opencypher/parser.Parse].
+That parser turns queries into clause capturers for regenerating and reducing them,
+keeping operators, literals and label expressions as the template text they get rendered with.
+Evaluating them would mean parsing that text again, so the engine parses queries into its own typed statements instead.
+Both parsers have to accept the statements generated for the engine, which a test ensures.
+*/
+package inmemory
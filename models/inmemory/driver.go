@@ -0,0 +1,166 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/seed"
+	"github.com/sirupsen/logrus"
+)
+
+// Driver for the in-memory engine.
+//
+// The engine runs in-process, the connection options are thus ignored, except for the timeout.
+type Driver struct {
+	graph *graph
+}
+
+// Init creates an empty graph.
+func (d *Driver) Init(opts dbms.DBOptions) error {
+	d.graph = &graph{}
+	return nil
+}
+
+// Reset replaces the graph with an empty one, which also resets the IDs.
+func (d *Driver) Reset(opts dbms.DBOptions) error {
+	logrus.Debug("Resetting Database")
+	d.graph = &graph{}
+	return nil
+}
+
+// RunQuery runs the query and returns the result.
+//
+// Queries are atomic, the graph is only changed if the query succeeds.
+func (d *Driver) RunQuery(ctx context.Context, opts dbms.DBOptions, query string) dbms.QueryResult {
+	if d.graph == nil {
+		return dbms.QueryResult{ProducedError: errors.New("database not initialized")}
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	res, err := execute(ctx, d.graph, query)
+	if err != nil {
+		logrus.Debugf("Error %v produced when running query %s", err, query)
+		return dbms.QueryResult{ProducedError: err}
+	}
+	d.graph = res.graph
+	return toQueryResult(res)
+}
+
+// GetSchema returns the labels, relationship types and properties found in the graph.
+func (d *Driver) GetSchema(opts dbms.DBOptions) (*schema.Schema, error) {
+	if d.graph == nil {
+		return nil, errors.New("database not initialized")
+	}
+	s := &schema.Schema{}
+	s.Reset()
+
+	var labels, types []string
+	var properties []schema.Property
+	addProperties := func(props map[string]any) {
+		for _, key := range sortedKeys(props) {
+			propType, ok := propertyType(props[key])
+			if !ok {
+				continue
+			}
+			property := schema.Property{Name: key, Type: propType, Value: schema.Literal(props[key], nil)}
+			if !slices.Contains(properties, property) {
+				properties = append(properties, property)
+			}
+		}
+	}
+	for _, n := range d.graph.nodes {
+		labels = append(labels, n.labels...)
+		addProperties(n.properties)
+	}
+	for _, r := range d.graph.relationships {
+		types = append(types, r.typ)
+		addProperties(r.properties)
+	}
+	slices.Sort(labels)
+	slices.Sort(types)
+	s.Labels[schema.NODE] = slices.Compact(labels)
+	s.Labels[schema.RELATIONSHIP] = slices.Compact(types)
+	s.Labels[schema.ANY] = append(slices.Clone(s.Labels[schema.RELATIONSHIP]), s.Labels[schema.NODE]...)
+
+	slices.SortFunc(properties, schema.CompareProperties)
+	for _, property := range properties {
+		s.AddProperty(property)
+	}
+	return s, nil
+}
+
+// propertyType returns the [schema.PropertyType] of a stored property value.
+//
+// Empty lists are of type [schema.AnyType] with the list mask set, lists stored as properties never mix types.
+func propertyType(v any) (schema.PropertyType, bool) {
+	switch v := v.(type) {
+	case bool:
+		return schema.Boolean, true
+	case int64:
+		return schema.Integer, true
+	case float64:
+		return schema.Float, true
+	case string:
+		return schema.String, true
+	case []any:
+		elemType := schema.AnyType
+		if len(v) > 0 {
+			elemType, _ = propertyType(v[0])
+		}
+		return elemType | schema.PropertyType(schema.ListMask), true
+	}
+	return 0, false
+}
+
+// GetQueryResultType evaluates the produced result and returns the type the result indicates.
+//
+// Errors raised by the engine are classified by their code, other errors indicate a bug in the engine.
+func (d *Driver) GetQueryResultType(res dbms.QueryResult, errorMessageRegex *dbms.ErrorMessageRegex) dbms.QueryResultType {
+	err := res.ProducedError
+	if err == nil {
+		return dbms.Valid
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return dbms.Timeout
+	}
+	var code string
+	if engineErr := (*Error)(nil); errors.As(err, &engineErr) {
+		code = engineErr.Code
+	}
+	if resultType, ok := errorMessageRegex.Classify(err.Error(), code); ok {
+		return resultType
+	}
+	return dbms.Bug
+}
+
+// DiscardQuery returns true with probability 1/10 or if the query produced a non-nil error,
+// else it returns false. Thereby causing queries to have an expected amount of 11 statements
+// if they don't produce an error.
+func (d *Driver) DiscardQuery(res dbms.QueryResult, seed *seed.Seed) bool {
+	if res.ProducedError != nil {
+		return true
+	}
+
+	return seed.BooleanWithProbability(0.1)
+}
+
+// VerifyConnectivity returns whether the driver got initialized, the engine can't crash without the fuzzer crashing too.
+func (d *Driver) VerifyConnectivity(opts dbms.DBOptions) (bool, error) {
+	if d.graph == nil {
+		return false, errors.New("database not initialized")
+	}
+	return true, nil
+}
+
+// IsEqualResult returns whether the two passed results equal
+func (d *Driver) IsEqualResult(a dbms.QueryResult, b dbms.QueryResult) bool {
+	return dbms.DefaultComparator.EqualResults(a, b)
+}
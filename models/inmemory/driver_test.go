@@ -0,0 +1,115 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunQuery(t *testing.T) {
+	d := &Driver{}
+	opts := dbms.DBOptions{Timeout: time.Second}
+
+	res := d.RunQuery(context.Background(), opts, "RETURN 1 AS x")
+	assert.Error(t, res.ProducedError, "queries must fail before the driver got initialized")
+
+	require.NoError(t, d.Init(opts))
+	res = d.RunQuery(context.Background(), opts, "CREATE (a:A {p: 1})-[:R]->(b:B) RETURN a AS a")
+	require.NoError(t, res.ProducedError)
+	assert.Equal(t, []string{"a"}, res.Columns)
+	assert.Equal(t, []dbms.Row{{dbms.Node{Labels: []string{"A"}, Properties: dbms.Map{"p": dbms.Int(1)}}}}, res.Rows)
+	assert.Equal(t, &dbms.UpdateCounters{NodesCreated: 2, RelationshipsCreated: 1, LabelsAdded: 2, PropertiesSet: 1}, res.Counters)
+
+	expectedGraph := dbms.NewGraph()
+	expectedGraph.AddNode("0", dbms.Node{Labels: []string{"A"}, Properties: dbms.Map{"p": dbms.Int(1)}})
+	expectedGraph.AddNode("1", dbms.Node{Labels: []string{"B"}, Properties: dbms.Map{}})
+	// Nodes and relationships share their IDs
+	expectedGraph.AddRelationship("2", "0", "1", dbms.Relationship{Type: "R", Properties: dbms.Map{}})
+	assert.Equal(t, expectedGraph, res.Graph)
+
+	// Failing queries leave the graph untouched
+	res = d.RunQuery(context.Background(), opts, "MATCH (n) SET n.p = 1 / 0")
+	assert.Error(t, res.ProducedError)
+	res = d.RunQuery(context.Background(), opts, "MATCH (n:A) RETURN n.p AS p")
+	assert.Equal(t, []dbms.Row{{dbms.Int(1)}}, res.Rows)
+
+	require.NoError(t, d.Reset(opts))
+	res = d.RunQuery(context.Background(), opts, "MATCH (n) RETURN count(n) AS c")
+	assert.Equal(t, []dbms.Row{{dbms.Int(0)}}, res.Rows)
+}
+
+// Malformed queries must fail with an engine error instead of panicking, no matter where they end.
+func TestRunQuery_MalformedQueries(t *testing.T) {
+	d := &Driver{}
+	require.NoError(t, d.Init(dbms.DBOptions{}))
+
+	query := "MATCH p = (a:A {p: [x IN [1, 2] WHERE x > 1 | x]})-[r:R*1..2]->(b) " +
+		"WITH DISTINCT a, CASE WHEN a.q =~ 'a' THEN count(*) ELSE 0 END AS c ORDER BY c DESC SKIP 1 " +
+		"UNWIND [1.5e3, 'it\\'s', null] AS u SET a += {q: u} RETURN a.p[0..1] AS x"
+	require.NoError(t, d.RunQuery(context.Background(), dbms.DBOptions{}, query).ProducedError)
+	queries := []string{"", ";", "\x00", "\xff\xfe", "((((((((((", "RETURN " + strings.Repeat("[", 1000), "MATCH (n RETURN n", "RETURN 1e999 AS x"}
+	for i := range query {
+		queries = append(queries, query[:i])
+	}
+	for _, query := range queries {
+		var res dbms.QueryResult
+		require.NotPanics(t, func() { res = d.RunQuery(context.Background(), dbms.DBOptions{}, query) }, query)
+		if res.ProducedError != nil {
+			var engineErr *Error
+			assert.ErrorAs(t, res.ProducedError, &engineErr, query)
+		}
+	}
+}
+
+func TestGetQueryResultType(t *testing.T) {
+	d := &Driver{}
+	require.NoError(t, d.Init(dbms.DBOptions{}))
+	errorMessageRegex := &dbms.ErrorMessageRegex{IgnoredCodes: []string{SemanticError}}
+
+	run := func(ctx context.Context, query string) dbms.QueryResultType {
+		return d.GetQueryResultType(d.RunQuery(ctx, dbms.DBOptions{}, query), errorMessageRegex)
+	}
+
+	assert.Equal(t, dbms.Valid, run(context.Background(), "RETURN 1 AS x"))
+	assert.Equal(t, dbms.Invalid, run(context.Background(), "RETURN x"))
+	assert.Equal(t, dbms.Bug, run(context.Background(), "RETURN 1 / 0 AS x"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, dbms.Timeout, run(ctx, "UNWIND range(1, 100000) AS x RETURN x"))
+
+	assert.Equal(t, dbms.Bug, d.GetQueryResultType(dbms.QueryResult{ProducedError: errors.New("unexpected")}, errorMessageRegex))
+}
+
+func TestGetSchema(t *testing.T) {
+	d := &Driver{}
+	require.NoError(t, d.Init(dbms.DBOptions{}))
+	res := d.RunQuery(context.Background(), dbms.DBOptions{}, `CREATE (:B:A {s: 'a"b', l: [1.5, 2.0], e: [], f: 0.0 / 0.0})-[:R {i: 1}]->(:A {s: 'c'})`)
+	require.NoError(t, res.ProducedError)
+
+	s, err := d.GetSchema(dbms.DBOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "B"}, s.Labels[schema.NODE])
+	assert.Equal(t, []string{"R"}, s.Labels[schema.RELATIONSHIP])
+
+	expected := &schema.Schema{}
+	expected.Reset()
+	for _, property := range []schema.Property{
+		{Name: "e", Type: schema.AnyType | schema.PropertyType(schema.ListMask), Value: "[]"},
+		{Name: "f", Type: schema.Float, Value: ""},
+		{Name: "i", Type: schema.Integer, Value: "1"},
+		{Name: "l", Type: schema.Float | schema.PropertyType(schema.ListMask), Value: "[1.5, 2.0]"},
+		{Name: "s", Type: schema.String, Value: `"a\"b"`},
+		{Name: "s", Type: schema.String, Value: `"c"`},
+	} {
+		expected.AddProperty(property)
+	}
+	assert.Equal(t, expected.Properties, s.Properties)
+}
@@ -0,0 +1,60 @@
+package inmemory
+
+import "fmt"
+
+// The codes of the errors raised by the engine.
+const (
+	// The query isn't valid Cypher
+	SyntaxError = "SyntaxError"
+	// The query is valid Cypher, but semantically invalid, e.g. it references an undefined variable
+	SemanticError = "SemanticError"
+	// An operator or function was applied to a value of the wrong type
+	TypeError = "TypeError"
+	// An arithmetic operation failed, e.g. an integer overflowed or got divided by zero
+	ArithmeticError = "ArithmeticError"
+	// A value or argument is out of its valid range, e.g. a negative LIMIT
+	ArgumentError = "ArgumentError"
+	// The query uses a feature the engine doesn't support
+	Unsupported = "Unsupported"
+	// The query exceeded the engine's resource limits, e.g. it produced too many rows
+	ResourceError = "ResourceError"
+)
+
+// An Error is raised by the engine if a query is invalid or fails while running.
+//
+// Errors are classified by their code, which can be matched in the targets config.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+func newError(code string, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// raise aborts the current parsing, checking or execution by panicking with an [*Error].
+func raise(code string, format string, args ...any) {
+	panic(newError(code, format, args...))
+}
+
+// catch recovers from a panic of the parser, checker or executor, storing the error in err.
+//
+// Errors raised with [raise] are stored as is and cancellations as the error of their context.
+// Other panics indicate a bug in the engine, they are stored as errors as well, so that no panic escapes the engine.
+//
+// Must be deferred directly by every function running engine code, [execute] being the driver's only entry point.
+func catch(err *error) {
+	switch r := recover().(type) {
+	case nil:
+	case *Error:
+		*err = r
+	case cancellation:
+		*err = r.err
+	default:
+		*err = fmt.Errorf("the engine panicked: %v", r)
+	}
+}
@@ -0,0 +1,528 @@
+package inmemory
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A row binds the variables in scope to their values.
+//
+// Rows are never modified once they were passed on, binding a variable copies the row.
+type row map[string]any
+
+// with returns a copy of the row with the variable bound to the passed value.
+func (r row) with(name string, value any) row {
+	res := r.clone()
+	res[name] = value
+	return res
+}
+
+func (r row) clone() row {
+	res := make(row, len(r)+1)
+	for k, v := range r {
+		res[k] = v
+	}
+	return res
+}
+
+// eval evaluates the expression given the row, raising an [*Error] if the evaluation fails.
+func (e *executor) eval(expr expression, r row) any {
+	e.tick()
+	switch expr := expr.(type) {
+	case *literal:
+		return expr.value
+	case *variable:
+		v, ok := r[expr.name]
+		if !ok {
+			raise(SemanticError, "variable %s is not defined", expr.name)
+		}
+		return v
+	case *propertyAccess:
+		return property(e.eval(expr.subject, r), expr.property)
+	case *indexAccess:
+		return index(e.eval(expr.subject, r), e.eval(expr.index, r))
+	case *sliceAccess:
+		return e.evalSlice(expr, r)
+	case *listLiteral:
+		res := make([]any, len(expr.elements))
+		for i, elem := range expr.elements {
+			res[i] = e.eval(elem, r)
+		}
+		return res
+	case *mapLiteral:
+		return e.evalMap(expr, r)
+	case *unaryOperation:
+		return e.evalUnary(expr, r)
+	case *binaryOperation:
+		return e.evalBinary(expr, r)
+	case *nullCheck:
+		return (e.eval(expr.operand, r) == nil) != expr.negated
+	case *labelCheck:
+		switch subject := e.eval(expr.subject, r).(type) {
+		case nil:
+			return nil
+		case *node:
+			return expr.labels.matches(subject.labels)
+		case *relationship:
+			return expr.labels.matches([]string{subject.typ})
+		default:
+			raise(TypeError, "can't check the labels of a %s", typeName(subject))
+		}
+	case *caseExpression:
+		return e.evalCase(expr, r)
+	case *functionCall:
+		return e.evalFunction(expr, r)
+	case *listComprehension:
+		return e.evalListComprehension(expr, r)
+	case *quantifier:
+		return e.evalQuantifier(expr, r)
+	}
+	raise(Unsupported, "unsupported expression %T", expr)
+	return nil
+}
+
+func (e *executor) evalMap(m *mapLiteral, r row) map[string]any {
+	res := make(map[string]any, len(m.keys))
+	for i, key := range m.keys {
+		res[key] = e.eval(m.values[i], r)
+	}
+	return res
+}
+
+// evalPredicate evaluates a predicate, such as a WHERE or an operand of AND, which must be a boolean or null.
+func (e *executor) evalPredicate(expr expression, r row) any {
+	v := e.eval(expr, r)
+	if _, ok := v.(bool); !ok && v != nil {
+		raise(TypeError, "expected a predicate to be a Boolean, but got a %s", typeName(v))
+	}
+	return v
+}
+
+// property returns the property of a node, relationship or map, null if absent.
+func property(subject any, key string) any {
+	switch subject := subject.(type) {
+	case nil:
+		return nil
+	case *node:
+		return subject.properties[key]
+	case *relationship:
+		return subject.properties[key]
+	case map[string]any:
+		return subject[key]
+	}
+	raise(TypeError, "can't access property %s of a %s", key, typeName(subject))
+	return nil
+}
+
+func index(subject, idx any) any {
+	if subject == nil || idx == nil {
+		return nil
+	}
+	switch subject := subject.(type) {
+	case []any:
+		i, ok := idx.(int64)
+		if !ok {
+			raise(TypeError, "lists can only be indexed by an Integer, not a %s", typeName(idx))
+		}
+		if i < 0 {
+			i += int64(len(subject))
+		}
+		if i < 0 || i >= int64(len(subject)) {
+			return nil
+		}
+		return subject[i]
+	case map[string]any, *node, *relationship:
+		key, ok := idx.(string)
+		if !ok {
+			raise(TypeError, "a %s can only be indexed by a String, not a %s", typeName(subject), typeName(idx))
+		}
+		return property(subject, key)
+	}
+	raise(TypeError, "can't index a %s", typeName(subject))
+	return nil
+}
+
+func (e *executor) evalSlice(expr *sliceAccess, r row) any {
+	subject := e.eval(expr.subject, r)
+	bound := func(bound expression, def int64) (int64, bool) {
+		if bound == nil {
+			return def, true
+		}
+		switch v := e.eval(bound, r).(type) {
+		case nil:
+			return 0, false
+		case int64:
+			return v, true
+		default:
+			raise(TypeError, "lists can only be sliced by Integers, not a %s", typeName(v))
+		}
+		return 0, false
+	}
+	if subject == nil {
+		return nil
+	}
+	list, ok := subject.([]any)
+	if !ok {
+		raise(TypeError, "can't slice a %s", typeName(subject))
+	}
+	from, fromOk := bound(expr.from, 0)
+	to, toOk := bound(expr.to, int64(len(list)))
+	if !fromOk || !toOk {
+		return nil
+	}
+	clamp := func(i int64) int64 {
+		if i < 0 {
+			i += int64(len(list))
+		}
+		return min(max(i, 0), int64(len(list)))
+	}
+	from, to = clamp(from), clamp(to)
+	if from >= to {
+		return []any{}
+	}
+	return list[from:to]
+}
+
+func (e *executor) evalUnary(expr *unaryOperation, r row) any {
+	operand := e.eval(expr.operand, r)
+	switch expr.operator {
+	case "NOT":
+		switch operand := operand.(type) {
+		case nil:
+			return nil
+		case bool:
+			return !operand
+		}
+		raise(TypeError, "NOT expects a Boolean, but got a %s", typeName(operand))
+	case "-":
+		switch operand := operand.(type) {
+		case nil:
+			return nil
+		case int64:
+			if operand == math.MinInt64 {
+				raise(ArithmeticError, "integer overflow when negating %d", operand)
+			}
+			return -operand
+		case float64:
+			return -operand
+		}
+		raise(TypeError, "can't negate a %s", typeName(operand))
+	case "+":
+		if operand == nil || isNumber(operand) {
+			return operand
+		}
+		raise(TypeError, "unary plus expects a number, but got a %s", typeName(operand))
+	}
+	raise(Unsupported, "unsupported operator %s", expr.operator)
+	return nil
+}
+
+func (e *executor) evalBinary(expr *binaryOperation, r row) any {
+	switch expr.operator {
+	case "AND", "OR", "XOR":
+		return logical(expr.operator, e.evalPredicate(expr.left, r), e.evalPredicate(expr.right, r))
+	}
+	left, right := e.eval(expr.left, r), e.eval(expr.right, r)
+	switch expr.operator {
+	case "=":
+		return equal(left, right)
+	case "<>":
+		if res := equal(left, right); res != nil {
+			return !res.(bool)
+		}
+		return nil
+	case "<", ">", "<=", ">=":
+		return comparison(expr.operator, left, right)
+	case "STARTS WITH", "ENDS WITH", "CONTAINS":
+		l, lOk := left.(string)
+		r, rOk := right.(string)
+		if !lOk || !rOk {
+			return nil
+		}
+		switch expr.operator {
+		case "STARTS WITH":
+			return strings.HasPrefix(l, r)
+		case "ENDS WITH":
+			return strings.HasSuffix(l, r)
+		}
+		return strings.Contains(l, r)
+	case "=~":
+		l, lOk := left.(string)
+		r, rOk := right.(string)
+		if !lOk || !rOk {
+			return nil
+		}
+		regex, err := regexp.Compile("^(?:" + r + ")$")
+		if err != nil {
+			raise(ArgumentError, "invalid regular expression %q - %v", r, err)
+		}
+		return regex.MatchString(l)
+	case "IN":
+		return in(left, right)
+	}
+	return arithmetic(expr.operator, left, right)
+}
+
+// logical applies a boolean operator using ternary logic, where null is unknown.
+func logical(operator string, left, right any) any {
+	switch operator {
+	case "AND":
+		if left == false || right == false {
+			return false
+		}
+		if left == nil || right == nil {
+			return nil
+		}
+		return true
+	case "OR":
+		if left == true || right == true {
+			return true
+		}
+		if left == nil || right == nil {
+			return nil
+		}
+		return false
+	}
+	if left == nil || right == nil {
+		return nil
+	}
+	return left != right
+}
+
+func comparison(operator string, left, right any) any {
+	res, ok := compare(left, right)
+	if !ok {
+		// Comparisons involving NaN are false, other incomparable values yield null
+		if isNumber(left) && isNumber(right) {
+			return false
+		}
+		return nil
+	}
+	switch operator {
+	case "<":
+		return res < 0
+	case ">":
+		return res > 0
+	case "<=":
+		return res <= 0
+	}
+	return res >= 0
+}
+
+// in returns whether the list holds the value, using ternary logic.
+func in(value, list any) any {
+	if list == nil {
+		return nil
+	}
+	elems, ok := list.([]any)
+	if !ok {
+		raise(TypeError, "IN expects a List, but got a %s", typeName(list))
+	}
+	var res any = false
+	for _, elem := range elems {
+		switch equal(value, elem) {
+		case true:
+			return true
+		case nil:
+			res = nil
+		}
+	}
+	return res
+}
+
+// arithmetic applies an arithmetic operator.
+// Integer arithmetic raises an error on overflow, float arithmetic follows IEEE 754.
+func arithmetic(operator string, left, right any) any {
+	if left == nil || right == nil {
+		return nil
+	}
+	if operator == "+" {
+		if res, ok := concatenate(left, right); ok {
+			return res
+		}
+	}
+	if !isNumber(left) || !isNumber(right) {
+		raise(TypeError, "can't apply %s to a %s and a %s", operator, typeName(left), typeName(right))
+	}
+	if operator == "^" {
+		return math.Pow(toFloat(left), toFloat(right))
+	}
+	l, lIsInt := left.(int64)
+	r, rIsInt := right.(int64)
+	if !lIsInt || !rIsInt {
+		l, r := toFloat(left), toFloat(right)
+		switch operator {
+		case "+":
+			return l + r
+		case "-":
+			return l - r
+		case "*":
+			return l * r
+		case "/":
+			return l / r
+		}
+		return math.Mod(l, r)
+	}
+	switch operator {
+	case "+":
+		res := l + r
+		if (res > l) != (r > 0) {
+			raise(ArithmeticError, "integer overflow when adding %d and %d", l, r)
+		}
+		return res
+	case "-":
+		res := l - r
+		if (res < l) != (r > 0) {
+			raise(ArithmeticError, "integer overflow when subtracting %d from %d", r, l)
+		}
+		return res
+	case "*":
+		res := l * r
+		if l != 0 && (res/l != r || (l == -1 && r == math.MinInt64) || (r == -1 && l == math.MinInt64)) {
+			raise(ArithmeticError, "integer overflow when multiplying %d and %d", l, r)
+		}
+		return res
+	}
+	if r == 0 {
+		raise(ArithmeticError, "division by zero")
+	}
+	if operator == "/" {
+		if l == math.MinInt64 && r == -1 {
+			raise(ArithmeticError, "integer overflow when dividing %d by %d", l, r)
+		}
+		return l / r
+	}
+	return l % r
+}
+
+// concatenate concatenates strings and lists, returning false if neither operand is a string or list.
+//
+// A string concatenated with a number is concatenated with the number's string representation,
+// a list concatenated with a non-list value gets the value appended or prepended.
+func concatenate(left, right any) (any, bool) {
+	leftList, leftIsList := left.([]any)
+	rightList, rightIsList := right.([]any)
+	switch {
+	case leftIsList && rightIsList:
+		return append(append(make([]any, 0, len(leftList)+len(rightList)), leftList...), rightList...), true
+	case leftIsList:
+		return append(append(make([]any, 0, len(leftList)+1), leftList...), right), true
+	case rightIsList:
+		return append([]any{left}, rightList...), true
+	}
+	leftString, leftIsString := left.(string)
+	rightString, rightIsString := right.(string)
+	switch {
+	case leftIsString && rightIsString:
+		return leftString + rightString, true
+	case leftIsString && isNumber(right):
+		return leftString + formatNumber(right), true
+	case rightIsString && isNumber(left):
+		return formatNumber(left) + rightString, true
+	}
+	return nil, false
+}
+
+func formatNumber(v any) string {
+	if i, ok := v.(int64); ok {
+		return strconv.FormatInt(i, 10)
+	}
+	return formatFloat(v.(float64))
+}
+
+func (e *executor) evalCase(expr *caseExpression, r row) any {
+	var subject any
+	if expr.subject != nil {
+		subject = e.eval(expr.subject, r)
+	}
+	for _, alternative := range expr.alternatives {
+		var matches any
+		if expr.subject != nil {
+			matches = equal(subject, e.eval(alternative.when, r))
+		} else {
+			matches = e.evalPredicate(alternative.when, r)
+		}
+		if matches == true {
+			return e.eval(alternative.then, r)
+		}
+	}
+	if expr.otherwise != nil {
+		return e.eval(expr.otherwise, r)
+	}
+	return nil
+}
+
+// evalList evaluates the list iterated by a list comprehension or quantifier, returning false if it is null.
+func (e *executor) evalList(expr expression, r row) ([]any, bool) {
+	switch v := e.eval(expr, r).(type) {
+	case nil:
+		return nil, false
+	case []any:
+		return v, true
+	default:
+		raise(TypeError, "expected a List to iterate over, but got a %s", typeName(v))
+	}
+	return nil, false
+}
+
+func (e *executor) evalListComprehension(expr *listComprehension, r row) any {
+	list, ok := e.evalList(expr.list, r)
+	if !ok {
+		return nil
+	}
+	res := []any{}
+	for _, elem := range list {
+		inner := r.with(expr.variable, elem)
+		if expr.where != nil && e.evalPredicate(expr.where, inner) != true {
+			continue
+		}
+		if expr.projection != nil {
+			elem = e.eval(expr.projection, inner)
+		}
+		res = append(res, elem)
+	}
+	return res
+}
+
+func (e *executor) evalQuantifier(expr *quantifier, r row) any {
+	list, ok := e.evalList(expr.list, r)
+	if !ok {
+		return nil
+	}
+	var matched, unknown int
+	for _, elem := range list {
+		switch e.evalPredicate(expr.where, r.with(expr.variable, elem)) {
+		case true:
+			matched++
+		case nil:
+			unknown++
+		}
+	}
+	switch expr.kind {
+	case "all":
+		if matched+unknown < len(list) {
+			return false
+		}
+	case "any":
+		if matched > 0 {
+			return true
+		}
+	case "none":
+		if matched > 0 {
+			return false
+		}
+	case "single":
+		if matched > 1 {
+			return false
+		}
+		if matched == 1 && unknown == 0 {
+			return true
+		}
+	}
+	if unknown > 0 {
+		return nil
+	}
+	return expr.kind != "any" && expr.kind != "single"
+}
@@ -0,0 +1,484 @@
+package inmemory
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/Anon10214/dinkel/dbms"
+)
+
+// The maximum amount of rows a clause may produce, queries exceeding it fail with a [ResourceError].
+const maxRows = 1_000_000
+
+// The amount of evaluation steps after which the executor checks whether the query got cancelled.
+const ticksPerCancellationCheck = 1 << 12
+
+// A result holds what a successfully executed query returned.
+type result struct {
+	columns  []string
+	rows     [][]any
+	counters dbms.UpdateCounters
+	// The graph after running the query
+	graph *graph
+}
+
+// The executor runs a checked statement clause by clause, each clause consuming all rows of the preceding one.
+//
+// Like the parser, it raises errors by panicking with an [*Error].
+type executor struct {
+	ctx      context.Context
+	graph    *graph
+	counters dbms.UpdateCounters
+	ticks    int
+	// The values of the aggregating function calls while evaluating a group's projection
+	aggregates map[*functionCall]any
+}
+
+// A cancellation is raised by the executor once the query's context is done.
+type cancellation struct{ err error }
+
+// execute parses, checks and runs the query on a copy of the passed graph, which is returned in the result.
+// The passed graph is never modified, so failing queries don't have any effect.
+//
+// Context errors are returned if the context is done before the query finished,
+// unexpected panics are returned as errors, as they indicate a bug in the engine.
+func execute(ctx context.Context, g *graph, query string) (_ *result, err error) {
+	defer catch(&err)
+
+	stmt, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	if err := check(stmt); err != nil {
+		return nil, err
+	}
+
+	e := &executor{ctx: ctx, graph: g.clone()}
+	rows := []row{{}}
+	res := &result{}
+	for _, cl := range stmt.clauses {
+		switch cl := cl.(type) {
+		case *matchClause:
+			rows = e.match(cl, rows)
+		case *createClause:
+			rows = e.create(cl, rows)
+		case *setClause:
+			e.set(cl, rows)
+		case *unwindClause:
+			rows = e.unwind(cl, rows)
+		case *projection:
+			rows = e.project(cl, rows)
+			if cl.isReturn {
+				res.columns, res.rows = returnedRows(cl, rows)
+			}
+		}
+		e.checkRows(len(rows))
+	}
+	res.counters = e.counters
+	res.graph = e.graph
+	return res, nil
+}
+
+// tick counts an evaluation step, checking periodically whether the query got cancelled.
+func (e *executor) tick() {
+	e.ticks++
+	if e.ticks%ticksPerCancellationCheck == 0 {
+		if err := e.ctx.Err(); err != nil {
+			panic(cancellation{err: err})
+		}
+	}
+}
+
+// checkRows raises a [ResourceError] if a clause produced too many rows.
+func (e *executor) checkRows(n int) {
+	if n > maxRows {
+		raise(ResourceError, "the query produced more than %d rows", maxRows)
+	}
+}
+
+func returnedRows(p *projection, rows []row) ([]string, [][]any) {
+	columns := make([]string, len(p.items))
+	for i, item := range p.items {
+		columns[i] = item.name
+	}
+	res := make([][]any, len(rows))
+	for i, r := range rows {
+		res[i] = make([]any, len(columns))
+		for j, column := range columns {
+			res[i][j] = r[column]
+		}
+	}
+	return columns, res
+}
+
+func (e *executor) create(c *createClause, rows []row) []row {
+	res := make([]row, len(rows))
+	for i, r := range rows {
+		r = r.clone()
+		for _, part := range c.patterns {
+			e.createPart(part, r)
+		}
+		res[i] = r
+	}
+	return res
+}
+
+// createPart creates the nodes and relationships of the pattern part, binding their variables in the row.
+func (e *executor) createPart(part *patternPart, r row) {
+	nodes := make([]*node, len(part.nodes))
+	for i, pattern := range part.nodes {
+		if bound, ok := r[pattern.variable]; ok && pattern.variable != "" {
+			n, ok := bound.(*node)
+			if !ok {
+				if bound == nil {
+					raise(SemanticError, "can't create a relationship, node %s is null", pattern.variable)
+				}
+				raise(TypeError, "type mismatch: expected %s to be a Node, but it is a %s", pattern.variable, typeName(bound))
+			}
+			nodes[i] = n
+			continue
+		}
+		labels, _ := labelNames(pattern.labels)
+		n := e.graph.createNode(dedupe(labels), e.storedProperties(pattern.properties, r))
+		e.counters.NodesCreated++
+		e.counters.LabelsAdded += len(n.labels)
+		if pattern.variable != "" {
+			r[pattern.variable] = n
+		}
+		nodes[i] = n
+	}
+
+	p := path{nodes: nodes}
+	for i, pattern := range part.relationships {
+		start, end := nodes[i], nodes[i+1]
+		if pattern.direction == directionLeft {
+			start, end = end, start
+		}
+		rel := e.graph.createRelationship(string(pattern.types.(labelName)), start, end, e.storedProperties(pattern.properties, r))
+		e.counters.RelationshipsCreated++
+		if pattern.variable != "" {
+			r[pattern.variable] = rel
+		}
+		p.relationships = append(p.relationships, rel)
+	}
+	if part.pathVariable != "" {
+		r[part.pathVariable] = p
+	}
+}
+
+// storedProperties evaluates the properties of a created node or relationship, skipping null values.
+func (e *executor) storedProperties(m *mapLiteral, r row) map[string]any {
+	res := map[string]any{}
+	if m == nil {
+		return res
+	}
+	for key, v := range e.evalMap(m, r) {
+		if v == nil {
+			continue
+		}
+		checkStorable(v)
+		res[key] = v
+		e.counters.PropertiesSet++
+	}
+	return res
+}
+
+func dedupe(labels []string) []string {
+	var res []string
+	for _, label := range labels {
+		if !slices.Contains(res, label) {
+			res = append(res, label)
+		}
+	}
+	return res
+}
+
+// set applies the SET clause's items to every row, in order.
+func (e *executor) set(c *setClause, rows []row) {
+	for _, r := range rows {
+		for _, item := range c.items {
+			e.setItem(item, r)
+		}
+	}
+}
+
+func (e *executor) setItem(item setItem, r row) {
+	var properties map[string]any
+	switch target := r[item.variable].(type) {
+	case nil:
+		// Setting anything on null is a no-op, the value still gets evaluated
+		if item.value != nil {
+			e.eval(item.value, r)
+		}
+		return
+	case *node:
+		if item.labels != nil {
+			for _, label := range item.labels {
+				if !target.hasLabel(label) {
+					target.labels = append(target.labels, label)
+					e.counters.LabelsAdded++
+				}
+			}
+			return
+		}
+		properties = target.properties
+	case *relationship:
+		if item.labels != nil {
+			raise(TypeError, "labels can't be set on relationship %s", item.variable)
+		}
+		properties = target.properties
+	default:
+		raise(TypeError, "can't set properties on a %s", typeName(target))
+	}
+
+	value := e.eval(item.value, r)
+	if item.property != "" {
+		e.setProperty(properties, item.property, value)
+		return
+	}
+
+	var newProperties map[string]any
+	switch value := value.(type) {
+	case nil:
+	case map[string]any:
+		newProperties = value
+	case *node:
+		newProperties = cloneProperties(value.properties)
+	case *relationship:
+		newProperties = cloneProperties(value.properties)
+	default:
+		raise(TypeError, "properties can only be set from a Map, Node or Relationship, not a %s", typeName(value))
+	}
+	if !item.merge {
+		for _, key := range sortedKeys(properties) {
+			if _, ok := newProperties[key]; !ok {
+				delete(properties, key)
+				e.counters.PropertiesSet++
+			}
+		}
+	}
+	for _, key := range sortedKeys(newProperties) {
+		if !item.merge {
+			e.setProperty(properties, key, newProperties[key])
+			continue
+		}
+		// Adding properties counts every key, even if it removes a property that doesn't exist
+		if v := newProperties[key]; v != nil {
+			checkStorable(v)
+			properties[key] = v
+		} else {
+			delete(properties, key)
+		}
+		e.counters.PropertiesSet++
+	}
+}
+
+// setProperty sets the property, removing it if the value is null.
+func (e *executor) setProperty(properties map[string]any, key string, value any) {
+	if value == nil {
+		if _, ok := properties[key]; ok {
+			delete(properties, key)
+			e.counters.PropertiesSet++
+		}
+		return
+	}
+	checkStorable(value)
+	properties[key] = value
+	e.counters.PropertiesSet++
+}
+
+func (e *executor) unwind(c *unwindClause, rows []row) []row {
+	var res []row
+	for _, r := range rows {
+		switch list := e.eval(c.list, r).(type) {
+		case nil:
+		case []any:
+			for _, elem := range list {
+				res = append(res, r.with(c.alias, elem))
+				e.checkRows(len(res))
+			}
+		default:
+			res = append(res, r.with(c.alias, list))
+		}
+	}
+	return res
+}
+
+// project runs a WITH or RETURN clause, returning rows which only bind the projected items.
+func (e *executor) project(p *projection, rows []row) []row {
+	var projected []row
+	// The rows the ORDER BY and WHERE are evaluated on, extending the projected rows by the previous scope
+	var extended []row
+	if p.isAggregating() {
+		projected = e.aggregateRows(p, rows)
+		extended = projected
+	} else {
+		for _, r := range rows {
+			pr := row{}
+			for _, item := range p.items {
+				pr[item.name] = e.eval(item.expression, r)
+			}
+			projected = append(projected, pr)
+			if !p.distinct {
+				ext := r.clone()
+				for k, v := range pr {
+					ext[k] = v
+				}
+				extended = append(extended, ext)
+			}
+		}
+		if p.distinct {
+			projected = distinct(p, projected)
+			extended = projected
+		}
+	}
+
+	order := make([]int, len(projected))
+	for i := range order {
+		order[i] = i
+	}
+	if len(p.orderBy) > 0 {
+		keys := make([][]any, len(projected))
+		for i := range projected {
+			for _, item := range p.orderBy {
+				var key any
+				if idx := p.projectedIndex(item.expression); idx != -1 {
+					key = projected[i][p.items[idx].name]
+				} else {
+					key = e.eval(item.expression, extended[i])
+				}
+				keys[i] = append(keys[i], key)
+			}
+		}
+		slices.SortStableFunc(order, func(a, b int) int {
+			for j, item := range p.orderBy {
+				res := orderCompare(keys[a][j], keys[b][j])
+				if item.descending {
+					res = -res
+				}
+				if res != 0 {
+					return res
+				}
+			}
+			return 0
+		})
+	}
+
+	skip, limit := int64(0), int64(len(order))
+	if p.skip != nil {
+		skip = e.evalLimit("SKIP", p.skip)
+	}
+	if p.limit != nil {
+		limit = e.evalLimit("LIMIT", p.limit)
+	}
+	order = order[min(skip, int64(len(order))):]
+	order = order[:min(limit, int64(len(order)))]
+
+	res := make([]row, 0, len(order))
+	for _, i := range order {
+		if p.where != nil && e.evalPredicate(p.where, extended[i]) != true {
+			continue
+		}
+		res = append(res, projected[i])
+	}
+	return res
+}
+
+// evalLimit evaluates a SKIP or LIMIT, which must be a non-negative integer.
+func (e *executor) evalLimit(clause string, expr expression) int64 {
+	v := e.eval(expr, row{})
+	i, ok := v.(int64)
+	if !ok {
+		raise(ArgumentError, "%s expects an Integer, but got a %s", clause, typeName(v))
+	}
+	if i < 0 {
+		raise(ArgumentError, "%s expects a non-negative Integer, but got %d", clause, i)
+	}
+	return i
+}
+
+// distinct removes rows equivalent to a preceding row.
+func distinct(p *projection, rows []row) []row {
+	var res []row
+	seen := map[string]bool{}
+	for _, r := range rows {
+		key := groupingKey(p.items, r)
+		if !seen[key] {
+			seen[key] = true
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// groupingKey returns a key equal for rows holding equivalent values for the passed items.
+func groupingKey(items []projectionItem, r row) string {
+	keys := make([]string, len(items))
+	for i, item := range items {
+		keys[i] = equivalenceKey(r[item.name])
+	}
+	return strings.Join(keys, "|")
+}
+
+// aggregateRows groups the rows by the values of the non-aggregating items and projects each group,
+// in the order the groups first appeared.
+//
+// Without grouping keys, all rows form a single group, even if there are none.
+func (e *executor) aggregateRows(p *projection, rows []row) []row {
+	var keyItems []projectionItem
+	for _, item := range p.items {
+		if !containsAggregate(item.expression) {
+			keyItems = append(keyItems, item)
+		}
+	}
+
+	type group struct {
+		keys row
+		rows []row
+	}
+	var groups []*group
+	byKey := map[string]*group{}
+	for _, r := range rows {
+		keys := row{}
+		for _, item := range keyItems {
+			keys[item.name] = e.eval(item.expression, r)
+		}
+		key := groupingKey(keyItems, keys)
+		g, ok := byKey[key]
+		if !ok {
+			g = &group{keys: keys}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.rows = append(g.rows, r)
+	}
+	if len(keyItems) == 0 && len(groups) == 0 {
+		groups = append(groups, &group{keys: row{}})
+	}
+
+	res := make([]row, len(groups))
+	for i, g := range groups {
+		first := row{}
+		if len(g.rows) > 0 {
+			first = g.rows[0]
+		}
+		pr := g.keys.clone()
+		for _, item := range p.items {
+			if !containsAggregate(item.expression) {
+				continue
+			}
+			e.aggregates = map[*functionCall]any{}
+			for _, call := range aggregateCalls(item.expression) {
+				e.aggregates[call] = e.aggregate(call, g.rows)
+			}
+			pr[item.name] = e.eval(item.expression, first)
+			e.aggregates = nil
+		}
+		res[i] = pr
+	}
+	if p.distinct {
+		res = distinct(p, res)
+	}
+	return res
+}
@@ -0,0 +1,161 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// run executes the queries in order, returning the result of the last one.
+func run(t *testing.T, queries ...string) *result {
+	t.Helper()
+	g := &graph{}
+	var res *result
+	for _, query := range queries {
+		var err error
+		res, err = execute(context.Background(), g, query)
+		require.NoError(t, err, query)
+		g = res.graph
+	}
+	return res
+}
+
+// errorCode returns the code of the error the query fails with.
+func errorCode(t *testing.T, query string) string {
+	t.Helper()
+	_, err := execute(context.Background(), &graph{}, query)
+	var engineErr *Error
+	if !assert.True(t, errors.As(err, &engineErr), "query %q should fail with an engine error, got %v", query, err) {
+		return ""
+	}
+	return engineErr.Code
+}
+
+func TestExpressions(t *testing.T) {
+	for query, expected := range map[string]any{
+		"RETURN 1 + 2 * 3 AS x":                            int64(7),
+		"RETURN 7 / 2 AS x":                                int64(3),
+		"RETURN -7 % 3 AS x":                               int64(-1),
+		"RETURN 2 ^ 3 AS x":                                8.0,
+		"RETURN 1 / 0.0 AS x":                              math.Inf(1),
+		"RETURN 'a' + 1 AS x":                              "a1",
+		"RETURN [1] + 2 AS x":                              []any{int64(1), int64(2)},
+		"RETURN null = null AS x":                          nil,
+		"RETURN 1 = 1.0 AS x":                              true,
+		"RETURN 0.0 / 0.0 = 0.0 / 0.0 AS x":                false,
+		"RETURN 1 < 'a' AS x":                              nil,
+		"RETURN null OR true AS x":                         true,
+		"RETURN null AND true AS x":                        nil,
+		"RETURN 2 IN [1, null] AS x":                       nil,
+		"RETURN 'abc' STARTS WITH 'ab' AS x":               true,
+		"RETURN 'abc' =~ 'a.' AS x":                        false,
+		"RETURN [1, 2, 3][-1] AS x":                        int64(3),
+		"RETURN [1, 2, 3][1..] AS x":                       []any{int64(2), int64(3)},
+		"RETURN {a: 1}.b AS x":                             nil,
+		"RETURN CASE WHEN false THEN 1 ELSE 2 END AS x":    int64(2),
+		"RETURN [x IN [1, 2, 3] WHERE x > 1 | x * 2] AS x": []any{int64(4), int64(6)},
+		"RETURN any(x IN [1, null] WHERE x = 2) AS x":      nil,
+		"RETURN size(split('a,b', ',')) AS x":              int64(2),
+		"RETURN round(-2.5) AS x":                          -2.0,
+		"RETURN toInteger('12') AS x":                      int64(12),
+		"RETURN 1 <-1 AS x":                                false,
+		"RETURN 0x1F + -0o10 AS x":                         int64(23),
+		"RETURN 'it\\'s\\n' AS x":                          "it's\n",
+		"WITH 1 AS `a``b` RETURN `a``b` AS x":              int64(1),
+		"RETURN -Infinity < inf AS x":                      true,
+		"RETURN NaN <> NaN AS x":                           true,
+	} {
+		res := run(t, query)
+		assert.Equal(t, [][]any{{expected}}, res.rows, query)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for query, code := range map[string]string{
+		"RETURN":                            SyntaxError,
+		"RETURN 'a":                         SyntaxError,
+		"RETURN '\\q' AS x":                 SyntaxError,
+		"RETURN $p AS x":                    Unsupported,
+		"MATCH (n)":                         SyntaxError,
+		"RETURN 1 AS x RETURN 2 AS y":       SyntaxError,
+		"RETURN x":                          SemanticError,
+		"WITH 1 RETURN 1 AS x":              SemanticError,
+		"RETURN 1 AS x, 2 AS x":             SemanticError,
+		"RETURN count(count(1)) AS x":       SemanticError,
+		"RETURN [x IN [1] | count(x)] AS x": SemanticError,
+		"UNWIND [1] AS x RETURN DISTINCT 1 AS y ORDER BY x":                 SemanticError,
+		"RETURN nosuchfunction(1) AS x":                                     Unsupported,
+		"RETURN 1 + true AS x":                                              TypeError,
+		"RETURN 1 / 0 AS x":                                                 ArithmeticError,
+		"RETURN 9223372036854775807 + 1 AS x":                               ArithmeticError,
+		"RETURN 1 AS x LIMIT -1":                                            ArgumentError,
+		"RETURN 'a' =~ '[' AS x":                                            ArgumentError,
+		"CREATE (n {p: {a: 1}})":                                            TypeError,
+		"UNWIND range(1, 2000) AS a UNWIND range(1, 2000) AS b RETURN a, b": ResourceError,
+	} {
+		assert.Equal(t, code, errorCode(t, query), query)
+	}
+}
+
+func TestCreateAndMatch(t *testing.T) {
+	res := run(t, "CREATE (a:A {p: 1})-[:R {q: 'x'}]->(b:B), (a)-[:R]->(a) RETURN a.p AS p")
+	assert.Equal(t, []string{"p"}, res.columns)
+	assert.Equal(t, [][]any{{int64(1)}}, res.rows)
+	assert.Equal(t, dbms.UpdateCounters{NodesCreated: 2, RelationshipsCreated: 2, LabelsAdded: 2, PropertiesSet: 2}, res.counters)
+
+	setup := "CREATE (a:A {p: 1})-[:R]->(b:B {p: 2}), (b)-[:R]->(c:C {p: 3}), (c)-[:S]->(c)"
+	for query, expected := range map[string][][]any{
+		"MATCH (n) RETURN n.p AS p ORDER BY p DESC":                               {{int64(3)}, {int64(2)}, {int64(1)}},
+		"MATCH (n:A|C) WHERE n.p > 1 RETURN n.p AS p":                             {{int64(3)}},
+		"MATCH (n)-[:R]->(m) RETURN n.p AS n, m.p AS m ORDER BY n":                {{int64(1), int64(2)}, {int64(2), int64(3)}},
+		"MATCH (n)<-[:R]-(m) RETURN count(*) AS c":                                {{int64(2)}},
+		"MATCH (n)-[:S]-(m) RETURN count(*) AS c":                                 {{int64(1)}},
+		"MATCH (n:A)-[*]->(m) RETURN m.p AS p ORDER BY p":                         {{int64(2)}, {int64(3)}, {int64(3)}},
+		"MATCH p = (:A)-[*2]->() RETURN length(p) AS l":                           {{int64(2)}},
+		"MATCH (n)-[r]->(m), (m)-[s]->(o) RETURN count(*) AS c":                   {{int64(2)}},
+		"OPTIONAL MATCH (n:D) RETURN n AS n":                                      {{nil}},
+		"MATCH (n) WITH n.p % 2 AS k, count(*) AS c RETURN k, c ORDER BY k":       {{int64(0), int64(1)}, {int64(1), int64(2)}},
+		"MATCH (n) RETURN collect(n.p)[0] AS first, sum(n.p) AS s, avg(n.p) AS a": {{int64(1), int64(6), 2.0}},
+		"MATCH (n) RETURN DISTINCT labels(n)[0] <> 'B' AS x ORDER BY x":           {{false}, {true}},
+		"UNWIND [3, 1, 2] AS x RETURN x ORDER BY x SKIP 1 LIMIT 1":                {{int64(2)}},
+		"UNWIND [1, 2] AS x WITH x WHERE x > 1 RETURN x":                          {{int64(2)}},
+		"UNWIND [] AS x RETURN count(x) AS c, max(x) AS m":                        {{int64(0), nil}},
+		"MATCH (n {p: n.p}) RETURN count(*) AS c":                                 {{int64(3)}},
+		"MATCH (n {p: m.p - 1})-[:R]->(m) RETURN n.p AS p ORDER BY p":             {{int64(1)}, {int64(2)}},
+		"MATCH ()-[r]->(m {p: startNode(r).p + 1}) RETURN m.p AS p ORDER BY p":    {{int64(2)}, {int64(3)}},
+		"MATCH p = (n {p: length(p)})-[*]->() RETURN n.p AS p ORDER BY p":         {{int64(1)}, {int64(2)}},
+	} {
+		assert.Equal(t, expected, run(t, setup, query).rows, query)
+	}
+}
+
+func TestSet(t *testing.T) {
+	res := run(t, "CREATE (:A {p: 1})", "MATCH (n:A) SET n.p = null, n.q = 2, n:B:A RETURN n.q AS q, labels(n) AS l")
+	assert.Equal(t, [][]any{{int64(2), []any{"A", "B"}}}, res.rows)
+	assert.Equal(t, dbms.UpdateCounters{PropertiesSet: 2, LabelsAdded: 1}, res.counters)
+
+	res = run(t, "CREATE (:A {p: 1, q: 2})", "MATCH (n:A) SET n = {r: 3} RETURN keys(n) AS k")
+	assert.Equal(t, [][]any{{[]any{"r"}}}, res.rows)
+
+	res = run(t, "CREATE (:A {p: 1})", "MATCH (n:A) SET n += {q: 2} RETURN keys(n) AS k")
+	assert.Equal(t, [][]any{{[]any{"p", "q"}}}, res.rows)
+}
+
+func TestAtomicity(t *testing.T) {
+	g := &graph{}
+	_, err := execute(context.Background(), g, "CREATE (n) WITH n RETURN 1 / 0 AS x")
+	assert.Error(t, err)
+	assert.Empty(t, g.nodes, "failed query must not modify the graph")
+}
+
+func TestCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := execute(ctx, &graph{}, "UNWIND range(1, 100000) AS x RETURN x")
+	assert.ErrorIs(t, err, context.Canceled)
+}
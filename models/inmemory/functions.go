@@ -0,0 +1,462 @@
+package inmemory
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A function is a scalar function, evaluated once per row.
+type function struct {
+	minArgs, maxArgs int
+	// Called with the evaluated arguments
+	call func(args []any) any
+}
+
+// The scalar functions supported by the engine, by their name in lower case.
+//
+// Unless noted otherwise, functions return null if any argument is null.
+var functions = map[string]function{
+	"abs": {1, 1, nullSafe(func(args []any) any {
+		switch v := numberArg("abs", args[0]).(type) {
+		case int64:
+			if v == math.MinInt64 {
+				raise(ArithmeticError, "integer overflow when taking the absolute value of %d", v)
+			}
+			if v < 0 {
+				return -v
+			}
+			return v
+		case float64:
+			return math.Abs(v)
+		}
+		return nil
+	})},
+	"sign": {1, 1, nullSafe(func(args []any) any {
+		switch v := numberArg("sign", args[0]).(type) {
+		case int64:
+			return int64(cmpZero(float64(v)))
+		case float64:
+			return int64(cmpZero(v))
+		}
+		return nil
+	})},
+	"ceil":    floatFunction("ceil", math.Ceil),
+	"floor":   floatFunction("floor", math.Floor),
+	"round":   floatFunction("round", roundHalfUp),
+	"sqrt":    floatFunction("sqrt", math.Sqrt),
+	"exp":     floatFunction("exp", math.Exp),
+	"log":     floatFunction("log", math.Log),
+	"log10":   floatFunction("log10", math.Log10),
+	"sin":     floatFunction("sin", math.Sin),
+	"cos":     floatFunction("cos", math.Cos),
+	"tan":     floatFunction("tan", math.Tan),
+	"cot":     floatFunction("cot", func(f float64) float64 { return 1 / math.Tan(f) }),
+	"asin":    floatFunction("asin", math.Asin),
+	"acos":    floatFunction("acos", math.Acos),
+	"atan":    floatFunction("atan", math.Atan),
+	"degrees": floatFunction("degrees", func(f float64) float64 { return f * 180 / math.Pi }),
+	"radians": floatFunction("radians", func(f float64) float64 { return f * math.Pi / 180 }),
+	"atan2": {2, 2, nullSafe(func(args []any) any {
+		return math.Atan2(toFloat(numberArg("atan2", args[0])), toFloat(numberArg("atan2", args[1])))
+	})},
+	"e":  {0, 0, func([]any) any { return math.E }},
+	"pi": {0, 0, func([]any) any { return math.Pi }},
+
+	"tointeger": {1, 1, nullSafe(func(args []any) any {
+		switch v := args[0].(type) {
+		case int64:
+			return v
+		case float64:
+			return floatToInteger(v)
+		case bool:
+			if v {
+				return int64(1)
+			}
+			return int64(0)
+		case string:
+			if i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+				return i
+			}
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+				return floatToInteger(f)
+			}
+			return nil
+		}
+		raise(TypeError, "toInteger can't convert a %s", typeName(args[0]))
+		return nil
+	})},
+	"tofloat": {1, 1, nullSafe(func(args []any) any {
+		switch v := args[0].(type) {
+		case int64:
+			return float64(v)
+		case float64:
+			return v
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return f
+			}
+			return nil
+		}
+		raise(TypeError, "toFloat can't convert a %s", typeName(args[0]))
+		return nil
+	})},
+	"toboolean": {1, 1, nullSafe(func(args []any) any {
+		switch v := args[0].(type) {
+		case bool:
+			return v
+		case int64:
+			return v != 0
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true":
+				return true
+			case "false":
+				return false
+			}
+			return nil
+		}
+		raise(TypeError, "toBoolean can't convert a %s", typeName(args[0]))
+		return nil
+	})},
+	"tostring": {1, 1, nullSafe(func(args []any) any {
+		switch v := args[0].(type) {
+		case string:
+			return v
+		case bool:
+			return strconv.FormatBool(v)
+		case int64, float64:
+			return formatNumber(v)
+		}
+		raise(TypeError, "toString can't convert a %s", typeName(args[0]))
+		return nil
+	})},
+
+	"size": {1, 1, nullSafe(func(args []any) any {
+		switch v := args[0].(type) {
+		case []any:
+			return int64(len(v))
+		case string:
+			return int64(utf8.RuneCountInString(v))
+		}
+		raise(TypeError, "size expects a List or String, but got a %s", typeName(args[0]))
+		return nil
+	})},
+	"length": {1, 1, nullSafe(func(args []any) any {
+		return int64(len(pathArg("length", args[0]).relationships))
+	})},
+	"head": {1, 1, nullSafe(func(args []any) any {
+		list := listArg("head", args[0])
+		if len(list) == 0 {
+			return nil
+		}
+		return list[0]
+	})},
+	"last": {1, 1, nullSafe(func(args []any) any {
+		list := listArg("last", args[0])
+		if len(list) == 0 {
+			return nil
+		}
+		return list[len(list)-1]
+	})},
+	"tail": {1, 1, nullSafe(func(args []any) any {
+		list := listArg("tail", args[0])
+		if len(list) == 0 {
+			return []any{}
+		}
+		return list[1:]
+	})},
+	"reverse": {1, 1, nullSafe(func(args []any) any {
+		switch v := args[0].(type) {
+		case []any:
+			res := slices.Clone(v)
+			slices.Reverse(res)
+			return res
+		case string:
+			runes := []rune(v)
+			slices.Reverse(runes)
+			return string(runes)
+		}
+		raise(TypeError, "reverse expects a List or String, but got a %s", typeName(args[0]))
+		return nil
+	})},
+	"range": {2, 3, nullSafe(func(args []any) any {
+		start, end, step := integerArg("range", args[0]), integerArg("range", args[1]), int64(1)
+		if len(args) == 3 {
+			step = integerArg("range", args[2])
+		}
+		if step == 0 {
+			raise(ArgumentError, "range expects a non-zero step")
+		}
+		if (step > 0 && start > end) || (step < 0 && start < end) {
+			return []any{}
+		}
+		// The distance and step are converted to unsigned integers, as they may exceed the range of int64
+		distance, stride := uint64(end-start), uint64(step)
+		if step < 0 {
+			distance, stride = uint64(start-end), uint64(-step)
+		}
+		if distance/stride >= maxRows {
+			raise(ResourceError, "range would produce more than %d elements", maxRows)
+		}
+		res := make([]any, distance/stride+1)
+		for i := range res {
+			res[i] = start + int64(i)*step
+		}
+		return res
+	})},
+
+	"left": {2, 2, func(args []any) any {
+		length := lengthArg("left", args[1])
+		if args[0] == nil {
+			return nil
+		}
+		runes := []rune(stringArg("left", args[0]))
+		return string(runes[:min(length, int64(len(runes)))])
+	}},
+	"right": {2, 2, func(args []any) any {
+		length := lengthArg("right", args[1])
+		if args[0] == nil {
+			return nil
+		}
+		runes := []rune(stringArg("right", args[0]))
+		return string(runes[int64(len(runes))-min(length, int64(len(runes))):])
+	}},
+	"substring": {2, 3, func(args []any) any {
+		start := lengthArg("substring", args[1])
+		length := int64(math.MaxInt64)
+		if len(args) == 3 {
+			length = lengthArg("substring", args[2])
+		}
+		if args[0] == nil {
+			return nil
+		}
+		runes := []rune(stringArg("substring", args[0]))
+		if start >= int64(len(runes)) {
+			return ""
+		}
+		return string(runes[start : start+min(length, int64(len(runes))-start)])
+	}},
+	"trim":    stringFunction("trim", func(s string) string { return strings.TrimFunc(s, unicode.IsSpace) }),
+	"ltrim":   stringFunction("ltrim", func(s string) string { return strings.TrimLeftFunc(s, unicode.IsSpace) }),
+	"rtrim":   stringFunction("rtrim", func(s string) string { return strings.TrimRightFunc(s, unicode.IsSpace) }),
+	"tolower": stringFunction("toLower", strings.ToLower),
+	"toupper": stringFunction("toUpper", strings.ToUpper),
+	"replace": {3, 3, nullSafe(func(args []any) any {
+		return strings.ReplaceAll(stringArg("replace", args[0]), stringArg("replace", args[1]), stringArg("replace", args[2]))
+	})},
+	"split": {2, 2, nullSafe(func(args []any) any {
+		parts := strings.Split(stringArg("split", args[0]), stringArg("split", args[1]))
+		res := make([]any, len(parts))
+		for i, part := range parts {
+			res[i] = part
+		}
+		return res
+	})},
+
+	"id": {1, 1, nullSafe(func(args []any) any {
+		switch v := args[0].(type) {
+		case *node:
+			return v.id
+		case *relationship:
+			return v.id
+		}
+		raise(TypeError, "id expects a Node or Relationship, but got a %s", typeName(args[0]))
+		return nil
+	})},
+	"labels": {1, 1, nullSafe(func(args []any) any {
+		n, ok := args[0].(*node)
+		if !ok {
+			raise(TypeError, "labels expects a Node, but got a %s", typeName(args[0]))
+		}
+		return stringList(n.labels)
+	})},
+	"type": {1, 1, nullSafe(func(args []any) any {
+		return relationshipArg("type", args[0]).typ
+	})},
+	"startnode": {1, 1, nullSafe(func(args []any) any {
+		return relationshipArg("startNode", args[0]).start
+	})},
+	"endnode": {1, 1, nullSafe(func(args []any) any {
+		return relationshipArg("endNode", args[0]).end
+	})},
+	"keys": {1, 1, nullSafe(func(args []any) any {
+		switch v := args[0].(type) {
+		case *node:
+			return stringList(sortedKeys(v.properties))
+		case *relationship:
+			return stringList(sortedKeys(v.properties))
+		case map[string]any:
+			return stringList(sortedKeys(v))
+		}
+		raise(TypeError, "keys expects a Node, Relationship or Map, but got a %s", typeName(args[0]))
+		return nil
+	})},
+	"properties": {1, 1, nullSafe(func(args []any) any {
+		switch v := args[0].(type) {
+		case *node:
+			return cloneProperties(v.properties)
+		case *relationship:
+			return cloneProperties(v.properties)
+		case map[string]any:
+			return v
+		}
+		raise(TypeError, "properties expects a Node, Relationship or Map, but got a %s", typeName(args[0]))
+		return nil
+	})},
+	"nodes": {1, 1, nullSafe(func(args []any) any {
+		p := pathArg("nodes", args[0])
+		res := make([]any, len(p.nodes))
+		for i, n := range p.nodes {
+			res[i] = n
+		}
+		return res
+	})},
+	"relationships": {1, 1, nullSafe(func(args []any) any {
+		p := pathArg("relationships", args[0])
+		res := make([]any, len(p.relationships))
+		for i, r := range p.relationships {
+			res[i] = r
+		}
+		return res
+	})},
+}
+
+func (e *executor) evalFunction(call *functionCall, r row) any {
+	if _, ok := aggregateFunctions[call.name]; ok {
+		res, ok := e.aggregates[call]
+		if !ok {
+			raise(SemanticError, "invalid use of the aggregating function %s", call.name)
+		}
+		return res
+	}
+	f, ok := functions[call.name]
+	if !ok {
+		raise(Unsupported, "unknown function %s", call.name)
+	}
+	args := make([]any, len(call.arguments))
+	for i, arg := range call.arguments {
+		args[i] = e.eval(arg, r)
+	}
+	return f.call(args)
+}
+
+// nullSafe wraps a function to return null if any of its arguments is null.
+func nullSafe(call func([]any) any) func([]any) any {
+	return func(args []any) any {
+		if slices.Contains(args, nil) {
+			return nil
+		}
+		return call(args)
+	}
+}
+
+func floatFunction(name string, f func(float64) float64) function {
+	return function{1, 1, nullSafe(func(args []any) any {
+		return f(toFloat(numberArg(name, args[0])))
+	})}
+}
+
+func stringFunction(name string, f func(string) string) function {
+	return function{1, 1, nullSafe(func(args []any) any {
+		return f(stringArg(name, args[0]))
+	})}
+}
+
+func numberArg(name string, v any) any {
+	if !isNumber(v) {
+		raise(TypeError, "%s expects a number, but got a %s", name, typeName(v))
+	}
+	return v
+}
+
+func integerArg(name string, v any) int64 {
+	i, ok := v.(int64)
+	if !ok {
+		raise(TypeError, "%s expects an Integer, but got a %s", name, typeName(v))
+	}
+	return i
+}
+
+func stringArg(name string, v any) string {
+	s, ok := v.(string)
+	if !ok {
+		raise(TypeError, "%s expects a String, but got a %s", name, typeName(v))
+	}
+	return s
+}
+
+func listArg(name string, v any) []any {
+	list, ok := v.([]any)
+	if !ok {
+		raise(TypeError, "%s expects a List, but got a %s", name, typeName(v))
+	}
+	return list
+}
+
+func pathArg(name string, v any) path {
+	p, ok := v.(path)
+	if !ok {
+		raise(TypeError, "%s expects a Path, but got a %s", name, typeName(v))
+	}
+	return p
+}
+
+func relationshipArg(name string, v any) *relationship {
+	r, ok := v.(*relationship)
+	if !ok {
+		raise(TypeError, "%s expects a Relationship, but got a %s", name, typeName(v))
+	}
+	return r
+}
+
+// lengthArg returns a length or offset passed to a string function, which must be a non-negative integer.
+func lengthArg(name string, v any) int64 {
+	i, ok := v.(int64)
+	if !ok {
+		raise(ArgumentError, "%s expects a length of type Integer, but got a %s", name, typeName(v))
+	}
+	if i < 0 {
+		raise(ArgumentError, "%s expects a non-negative length, but got %d", name, i)
+	}
+	return i
+}
+
+func stringList(strs []string) []any {
+	res := make([]any, len(strs))
+	for i, s := range strs {
+		res[i] = s
+	}
+	return res
+}
+
+func cmpZero(f float64) int {
+	switch {
+	case f > 0:
+		return 1
+	case f < 0:
+		return -1
+	}
+	return 0
+}
+
+// roundHalfUp rounds to the nearest integer, rounding halfway values towards positive infinity.
+func roundHalfUp(f float64) float64 {
+	floor := math.Floor(f)
+	if f-floor >= 0.5 {
+		return floor + 1
+	}
+	return floor
+}
+
+// floatToInteger truncates a float, raising an error if the result isn't representable as an integer.
+func floatToInteger(f float64) int64 {
+	truncated := math.Trunc(f)
+	if math.IsNaN(f) || truncated < math.MinInt64 || truncated >= math.MaxInt64 {
+		raise(ArithmeticError, "float %s can't be converted into an integer", formatFloat(f))
+	}
+	return int64(truncated)
+}
@@ -0,0 +1,102 @@
+package inmemory
+
+import (
+	"fmt"
+	"slices"
+)
+
+// A node of the in-memory graph.
+type node struct {
+	id int64
+	// The node's labels in the order they were added
+	labels     []string
+	properties map[string]any
+	// The relationships starting and ending at the node, in the order they were created
+	outgoing, incoming []*relationship
+}
+
+// A relationship of the in-memory graph.
+type relationship struct {
+	id         int64
+	typ        string
+	start, end *node
+	properties map[string]any
+}
+
+// A path alternating between nodes and relationships, starting and ending with a node.
+type path struct {
+	nodes         []*node
+	relationships []*relationship
+}
+
+// The graph holds the nodes and relationships stored by the engine, in the order they were created.
+//
+// Matching iterates the graph in this order, making the order of returned rows deterministic.
+type graph struct {
+	nodes         []*node
+	relationships []*relationship
+	nextID        int64
+}
+
+func (g *graph) createNode(labels []string, properties map[string]any) *node {
+	n := &node{id: g.nextID, labels: labels, properties: properties}
+	g.nextID++
+	g.nodes = append(g.nodes, n)
+	return n
+}
+
+func (g *graph) createRelationship(typ string, start, end *node, properties map[string]any) *relationship {
+	r := &relationship{id: g.nextID, typ: typ, start: start, end: end, properties: properties}
+	g.nextID++
+	g.relationships = append(g.relationships, r)
+	start.outgoing = append(start.outgoing, r)
+	end.incoming = append(end.incoming, r)
+	return r
+}
+
+// clone returns a deep copy of the graph, so a query can modify it without affecting the original until it succeeded.
+func (g *graph) clone() *graph {
+	res := &graph{nextID: g.nextID}
+	nodes := make(map[*node]*node, len(g.nodes))
+	for _, n := range g.nodes {
+		cloned := &node{id: n.id, labels: slices.Clone(n.labels), properties: cloneProperties(n.properties)}
+		nodes[n] = cloned
+		res.nodes = append(res.nodes, cloned)
+	}
+	for _, r := range g.relationships {
+		cloned := &relationship{
+			id:         r.id,
+			typ:        r.typ,
+			start:      nodes[r.start],
+			end:        nodes[r.end],
+			properties: cloneProperties(r.properties),
+		}
+		cloned.start.outgoing = append(cloned.start.outgoing, cloned)
+		cloned.end.incoming = append(cloned.end.incoming, cloned)
+		res.relationships = append(res.relationships, cloned)
+	}
+	return res
+}
+
+// cloneProperties copies the passed properties, property values are immutable and thus shared.
+func cloneProperties(properties map[string]any) map[string]any {
+	res := make(map[string]any, len(properties))
+	for k, v := range properties {
+		res[k] = v
+	}
+	return res
+}
+
+func (n *node) hasLabel(label string) bool {
+	return slices.Contains(n.labels, label)
+}
+
+// String returns the node's ID, used in error messages.
+func (n *node) String() string {
+	return fmt.Sprintf("Node[%d]", n.id)
+}
+
+// String returns the relationship's ID, used in error messages.
+func (r *relationship) String() string {
+	return fmt.Sprintf("Relationship[%d]", r.id)
+}
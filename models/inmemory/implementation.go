@@ -0,0 +1,64 @@
+package inmemory
+
+import (
+	"reflect"
+
+	"github.com/Anon10214/dinkel/models/opencypher/clauses"
+	"github.com/Anon10214/dinkel/models/opencypher/config"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/seed"
+	"github.com/Anon10214/dinkel/translator"
+)
+
+// Implementation for the in-memory engine
+type Implementation struct{}
+
+// GetDropIns returns the clause drop-ins for the in-memory implementation,
+// replacing the clauses the engine doesn't support.
+func (Implementation) GetDropIns() translator.DropIns {
+	readClause := func(translator.Clause, *seed.Seed, *schema.Schema) translator.Clause {
+		return &clauses.ReadClause{}
+	}
+	writeClause := func(translator.Clause, *seed.Seed, *schema.Schema) translator.Clause {
+		return &clauses.WriteClause{}
+	}
+	return translator.DropIns{
+		// FOREACH, CALL subqueries and UNION are unsupported
+		reflect.TypeOf(&clauses.Foreach{}):      readClause,
+		reflect.TypeOf(&clauses.CallSubquery{}): readClause,
+		reflect.TypeOf(&clauses.Union{}):        readClause,
+
+		// Only CREATE and SET modify the graph
+		reflect.TypeOf(&clauses.Delete{}): writeClause,
+		reflect.TypeOf(&clauses.Merge{}):  writeClause,
+		reflect.TypeOf(&clauses.Remove{}): writeClause,
+
+		// Subquery expressions are unsupported
+		reflect.TypeOf(&clauses.Exists{}): func(translator.Clause, *seed.Seed, *schema.Schema) translator.Clause {
+			return &clauses.Expression{Conf: schema.ExpressionConfig{
+				TargetType:   schema.PropertyValue,
+				PropertyType: schema.Boolean,
+			}}
+		},
+		reflect.TypeOf(&clauses.Count{}): func(translator.Clause, *seed.Seed, *schema.Schema) translator.Clause {
+			return &clauses.Expression{Conf: schema.ExpressionConfig{
+				TargetType:   schema.PropertyValue,
+				PropertyType: schema.Integer,
+			}}
+		},
+	}
+}
+
+// GetOpenCypherConfig returns the generation config for the in-memory implementation
+func (Implementation) GetOpenCypherConfig() config.Config {
+	return config.Config{
+		OnlyVariablesAsWriteTarget: true,
+
+		AsteriskNeedsTargets: true,
+
+		// The engine has no temporal or spatial types
+		DisallowedPropertyTypes: []schema.PropertyType{
+			schema.Date, schema.Datetime, schema.Duration, schema.LocalDateTime, schema.LocalTime, schema.Time, schema.Point,
+		},
+	}
+}
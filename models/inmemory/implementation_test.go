@@ -0,0 +1,78 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/opencypher"
+	cypherparser "github.com/Anon10214/dinkel/models/opencypher/parser"
+	"github.com/Anon10214/dinkel/seed"
+	"github.com/Anon10214/dinkel/translator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Generated queries must either succeed or fail with an error raised by the engine,
+// else they indicate a gap between the generator config and the engine.
+func TestGeneratedQueries(t *testing.T) {
+	d := &Driver{}
+	opts := dbms.DBOptions{}
+	valid := 0
+	for i := int64(0); i < 200; i++ {
+		require.NoError(t, d.Reset(opts))
+		s := seed.GetRandomByteStringWithSource(*rand.New(rand.NewSource(i)))
+		for {
+			sc, err := d.GetSchema(opts)
+			require.NoError(t, err)
+			statement, err := translator.GenerateStatement(s, sc, &opencypher.RootClause{}, Implementation{}, 500)
+			if err != nil {
+				break
+			}
+			res := d.RunQuery(context.Background(), opts, statement)
+			if res.ProducedError == nil {
+				valid++
+			} else if !assert.True(t, errors.As(res.ProducedError, new(*Error)), "statement %s failed with %v", statement, res.ProducedError) {
+				return
+			}
+			if d.DiscardQuery(res, s) {
+				break
+			}
+		}
+	}
+	assert.NotZero(t, valid)
+}
+
+// The engine's parser and the OpenCypher parser are separate, see the package documentation.
+// Both have to accept the statements generated for the engine, else a grammar fix was only made to one of them.
+func TestGeneratedQueries_ParserParity(t *testing.T) {
+	d := &Driver{}
+	opts := dbms.DBOptions{}
+	parsed := 0
+	for i := int64(0); i < 200; i++ {
+		require.NoError(t, d.Reset(opts))
+		s := seed.GetRandomByteStringWithSource(*rand.New(rand.NewSource(i)))
+		sc, err := d.GetSchema(opts)
+		require.NoError(t, err)
+		statement, err := translator.GenerateStatement(s, sc, &opencypher.RootClause{}, Implementation{}, 500)
+		if err != nil {
+			continue
+		}
+		_, err = parse(statement)
+		var engineErr *Error
+		if errors.As(err, &engineErr) && engineErr.Code == Unsupported {
+			continue
+		}
+		if !assert.NoError(t, err, "engine failed parsing %s", statement) {
+			return
+		}
+		_, err = cypherparser.Parse(statement)
+		if !assert.NoError(t, err, "OpenCypher parser failed parsing %s", statement) {
+			return
+		}
+		parsed++
+	}
+	assert.NotZero(t, parsed)
+}
@@ -0,0 +1,38 @@
+package inmemory
+
+import "slices"
+
+func (l labelName) matches(labels []string) bool {
+	return slices.Contains(labels, string(l))
+}
+
+func (l labelConjunction) matches(labels []string) bool {
+	return l.left.matches(labels) && l.right.matches(labels)
+}
+
+func (l labelDisjunction) matches(labels []string) bool {
+	return l.left.matches(labels) || l.right.matches(labels)
+}
+
+func (l labelNegation) matches(labels []string) bool {
+	return !l.operand.matches(labels)
+}
+
+// labelNames returns the labels of a conjunction of label names, as written when creating or setting labels.
+// Returns false if the expression isn't such a conjunction, e.g. if it holds a disjunction.
+func labelNames(l labelExpression) ([]string, bool) {
+	switch l := l.(type) {
+	case nil:
+		return nil, true
+	case labelName:
+		return []string{string(l)}, true
+	case labelConjunction:
+		left, ok := labelNames(l.left)
+		if !ok {
+			return nil, false
+		}
+		right, ok := labelNames(l.right)
+		return append(left, right...), ok
+	}
+	return nil, false
+}
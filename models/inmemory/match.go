@@ -0,0 +1,321 @@
+package inmemory
+
+import "slices"
+
+// A matcher finds the matches of a MATCH clause's patterns for a single input row.
+//
+// Within a clause, every relationship is matched at most once, even across pattern parts.
+type matcher struct {
+	e        *executor
+	patterns []*patternPart
+	// The variables bound by the patterns
+	variables map[string]bool
+	used      map[*relationship]bool
+	// The property checks using variables of the patterns that weren't bound yet when matching their entity
+	deferred []propertyCheck
+	// Called for every match, with the input row extended by the pattern's variables
+	emit func(row)
+}
+
+// A propertyCheck checks that a property of a matched entity equals the value of an expression.
+type propertyCheck struct {
+	properties map[string]any
+	key        string
+	value      expression
+}
+
+func (e *executor) match(c *matchClause, rows []row) []row {
+	var res []row
+	for _, r := range rows {
+		matched := false
+		m := &matcher{e: e, patterns: c.patterns, variables: patternVariables(c), used: map[*relationship]bool{}, emit: func(match row) {
+			if c.where != nil && e.evalPredicate(c.where, match) != true {
+				return
+			}
+			matched = true
+			res = append(res, match)
+			e.checkRows(len(res))
+		}}
+		m.matchPart(r, 0)
+		if !matched && c.optional {
+			res = append(res, bindUnmatched(c, r))
+		}
+	}
+	return res
+}
+
+// patternVariables returns the variables bound by the patterns of the clause.
+func patternVariables(c *matchClause) map[string]bool {
+	res := map[string]bool{}
+	add := func(name string) {
+		if name != "" {
+			res[name] = true
+		}
+	}
+	for _, part := range c.patterns {
+		add(part.pathVariable)
+		for _, n := range part.nodes {
+			add(n.variable)
+		}
+		for _, rel := range part.relationships {
+			add(rel.variable)
+		}
+	}
+	return res
+}
+
+// bindUnmatched binds the variables an optional match didn't bind to null.
+func bindUnmatched(c *matchClause, r row) row {
+	res := r.clone()
+	bind := func(name string) {
+		if _, ok := r[name]; name != "" && !ok {
+			res[name] = nil
+		}
+	}
+	for _, part := range c.patterns {
+		bind(part.pathVariable)
+		for _, n := range part.nodes {
+			bind(n.variable)
+		}
+		for _, rel := range part.relationships {
+			bind(rel.variable)
+		}
+	}
+	return res
+}
+
+// matchPart matches the pattern part at the passed index, given the variables bound by the preceding parts.
+func (m *matcher) matchPart(r row, partIndex int) {
+	if partIndex == len(m.patterns) {
+		for _, check := range m.deferred {
+			if !m.passes(check, r) {
+				return
+			}
+		}
+		m.emit(r)
+		return
+	}
+	part := m.patterns[partIndex]
+	start := part.nodes[0]
+	candidates := m.e.graph.nodes
+	if bound, ok := r[start.variable]; ok && start.variable != "" {
+		n, ok := m.boundNode(start.variable, bound)
+		if !ok {
+			return
+		}
+		candidates = []*node{n}
+	}
+	for _, n := range candidates {
+		if bound, deferred, ok := m.bindNode(start, n, r); ok {
+			m.withDeferred(deferred, func() {
+				m.step(part, partIndex, 0, n, bound, path{nodes: []*node{n}})
+			})
+		}
+	}
+}
+
+// boundNode returns the node bound to a variable, false if it is null.
+func (m *matcher) boundNode(name string, v any) (*node, bool) {
+	switch v := v.(type) {
+	case nil:
+		return nil, false
+	case *node:
+		return v, true
+	}
+	raise(TypeError, "type mismatch: expected %s to be a Node, but it is a %s", name, typeName(v))
+	return nil, false
+}
+
+// bindNode checks whether the node matches the pattern, returning the row with the pattern's variable bound
+// and the property checks deferred until all variables are bound.
+func (m *matcher) bindNode(pattern *nodePattern, n *node, r row) (row, []propertyCheck, bool) {
+	m.e.tick()
+	if pattern.variable != "" {
+		if bound, ok := r[pattern.variable]; ok {
+			if boundNode, ok := m.boundNode(pattern.variable, bound); !ok || boundNode != n {
+				return nil, nil, false
+			}
+		}
+	}
+	if pattern.labels != nil && !pattern.labels.matches(n.labels) {
+		return nil, nil, false
+	}
+	if pattern.variable != "" {
+		r = r.with(pattern.variable, n)
+	}
+	deferred, ok := m.matchesProperties(pattern.properties, n.properties, r)
+	if !ok {
+		return nil, nil, false
+	}
+	return r, deferred, true
+}
+
+// matchesProperties checks whether the properties match the pattern's,
+// returning the checks using variables of the patterns which aren't bound yet instead of performing them.
+func (m *matcher) matchesProperties(pattern *mapLiteral, properties map[string]any, r row) ([]propertyCheck, bool) {
+	if pattern == nil {
+		return nil, true
+	}
+	var deferred []propertyCheck
+	for i, key := range pattern.keys {
+		check := propertyCheck{properties: properties, key: key, value: pattern.values[i]}
+		if m.usesUnbound(check.value, r) {
+			deferred = append(deferred, check)
+		} else if !m.passes(check, r) {
+			return nil, false
+		}
+	}
+	return deferred, true
+}
+
+func (m *matcher) passes(check propertyCheck, r row) bool {
+	return equal(check.properties[check.key], m.e.eval(check.value, r)) == true
+}
+
+// usesUnbound returns whether the expression uses a variable of the patterns that isn't bound by the row yet.
+func (m *matcher) usesUnbound(expr expression, r row) bool {
+	if v, ok := expr.(*variable); ok {
+		_, bound := r[v.name]
+		return m.variables[v.name] && !bound
+	}
+	return slices.ContainsFunc(children(expr), func(child expression) bool { return m.usesUnbound(child, r) })
+}
+
+// withDeferred runs f with the passed checks deferred until all variables are bound.
+func (m *matcher) withDeferred(checks []propertyCheck, f func()) {
+	n := len(m.deferred)
+	m.deferred = append(m.deferred, checks...)
+	f()
+	m.deferred = m.deferred[:n]
+}
+
+// An expansion is a relationship leading from a node to its neighbor.
+type expansion struct {
+	relationship *relationship
+	neighbor     *node
+	// The checks of the relationship's properties deferred until all variables are bound
+	deferred []propertyCheck
+}
+
+// expand returns the relationships of the node in the direction of the pattern that aren't used yet.
+//
+// Self-loops are only expanded once if the direction is ignored.
+func (m *matcher) expand(n *node, pattern *relationshipPattern, r row) []expansion {
+	var res []expansion
+	add := func(rel *relationship, neighbor *node) {
+		if m.used[rel] || (pattern.types != nil && !pattern.types.matches([]string{rel.typ})) {
+			return
+		}
+		bound := r
+		if pattern.variable != "" && !pattern.variableLength {
+			bound = r.with(pattern.variable, rel)
+		}
+		deferred, ok := m.matchesProperties(pattern.properties, rel.properties, bound)
+		if !ok {
+			return
+		}
+		res = append(res, expansion{relationship: rel, neighbor: neighbor, deferred: deferred})
+	}
+	if pattern.direction != directionLeft {
+		for _, rel := range n.outgoing {
+			add(rel, rel.end)
+		}
+	}
+	if pattern.direction != directionRight {
+		for _, rel := range n.incoming {
+			if pattern.direction == directionBoth && rel.start == rel.end {
+				continue
+			}
+			add(rel, rel.start)
+		}
+	}
+	return res
+}
+
+// step matches the relationship at index i of the pattern part and the node following it,
+// current being the node matched by the node preceding the relationship.
+func (m *matcher) step(part *patternPart, partIndex, i int, current *node, r row, p path) {
+	if i == len(part.relationships) {
+		if part.pathVariable != "" {
+			r = r.with(part.pathVariable, p)
+		}
+		m.matchPart(r, partIndex+1)
+		return
+	}
+	pattern := part.relationships[i]
+	target := part.nodes[i+1]
+	if pattern.variableLength {
+		m.stepVariableLength(part, partIndex, i, current, r, p, nil)
+		return
+	}
+	var bound any
+	isBound := false
+	if pattern.variable != "" {
+		bound, isBound = r[pattern.variable]
+		if isBound {
+			switch bound.(type) {
+			case nil:
+				return
+			case *relationship:
+			default:
+				raise(TypeError, "type mismatch: expected %s to be a Relationship, but it is a %s", pattern.variable, typeName(bound))
+			}
+		}
+	}
+	for _, exp := range m.expand(current, pattern, r) {
+		if isBound && bound != exp.relationship {
+			continue
+		}
+		next := r
+		if pattern.variable != "" {
+			next = r.with(pattern.variable, exp.relationship)
+		}
+		next, deferred, ok := m.bindNode(target, exp.neighbor, next)
+		if !ok {
+			continue
+		}
+		m.used[exp.relationship] = true
+		m.withDeferred(slices.Concat(exp.deferred, deferred), func() {
+			m.step(part, partIndex, i+1, exp.neighbor, next, p.extend(exp.relationship, exp.neighbor))
+		})
+		delete(m.used, exp.relationship)
+	}
+}
+
+// stepVariableLength matches a variable length relationship, traversed being the relationships traversed so far.
+func (m *matcher) stepVariableLength(part *patternPart, partIndex, i int, current *node, r row, p path, traversed []*relationship) {
+	pattern := part.relationships[i]
+	if int64(len(traversed)) >= pattern.min {
+		next := r
+		if pattern.variable != "" {
+			list := make([]any, len(traversed))
+			for j, rel := range traversed {
+				list[j] = rel
+			}
+			next = r.with(pattern.variable, list)
+		}
+		if next, deferred, ok := m.bindNode(part.nodes[i+1], current, next); ok {
+			m.withDeferred(deferred, func() {
+				m.step(part, partIndex, i+1, current, next, p)
+			})
+		}
+	}
+	if pattern.max >= 0 && int64(len(traversed)) >= pattern.max {
+		return
+	}
+	for _, exp := range m.expand(current, pattern, r) {
+		m.used[exp.relationship] = true
+		m.withDeferred(exp.deferred, func() {
+			m.stepVariableLength(part, partIndex, i, exp.neighbor, r, p.extend(exp.relationship, exp.neighbor), append(traversed, exp.relationship))
+		})
+		delete(m.used, exp.relationship)
+	}
+}
+
+// extend returns a copy of the path extended by the relationship leading to the node.
+func (p path) extend(r *relationship, n *node) path {
+	return path{
+		nodes:         append(append(make([]*node, 0, len(p.nodes)+1), p.nodes...), n),
+		relationships: append(append(make([]*relationship, 0, len(p.relationships)+1), p.relationships...), r),
+	}
+}
@@ -0,0 +1,773 @@
+package inmemory
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Anon10214/dinkel/models/opencypher/lexer"
+)
+
+// The keywords starting clauses the engine doesn't support.
+var unsupportedClauses = []string{
+	"CALL", "DELETE", "DETACH", "DROP", "EXPLAIN", "FINISH", "FOREACH", "LOAD", "MERGE", "PROFILE", "REMOVE", "SHOW", "UNION", "USE",
+}
+
+// The parser turns a query into a statement by recursive descent.
+//
+// Errors are raised by panicking with an [*Error], which parse recovers from.
+type parser struct {
+	query  string
+	tokens []lexer.Token
+	pos    int
+}
+
+// parse parses the passed query.
+func parse(query string) (stmt *statement, err error) {
+	defer catch(&err)
+	tokens, err := lexer.Tokenize(query)
+	if err != nil {
+		return nil, newError(SyntaxError, "%v", err)
+	}
+	p := &parser{query: query, tokens: tokens}
+	return p.parseStatement(), nil
+}
+
+func (p *parser) fail(code string, format string, args ...any) {
+	raise(code, format, args...)
+}
+
+func (p *parser) peek() lexer.Token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(offset int) lexer.Token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) next() lexer.Token {
+	tok := p.tokens[p.pos]
+	if tok.Kind != lexer.EOF {
+		p.pos++
+	}
+	return tok
+}
+
+// unexpected fails with a syntax error describing the current token.
+func (p *parser) unexpected(expected string) {
+	tok := p.peek()
+	if tok.Kind == lexer.EOF {
+		p.fail(SyntaxError, "expected %s, but the query ended", expected)
+	}
+	p.fail(SyntaxError, "expected %s, but got %s at %s", expected, tok, lexer.Position(p.query, tok.Pos))
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	return p.peek().IsKeyword(keyword)
+}
+
+func (p *parser) acceptKeyword(keyword string) bool {
+	if p.isKeyword(keyword) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(keyword string) {
+	if !p.acceptKeyword(keyword) {
+		p.unexpected(keyword)
+	}
+}
+
+func (p *parser) isSymbol(symbol string) bool {
+	return p.peek().IsSymbol(symbol)
+}
+
+func (p *parser) acceptSymbol(symbol string) bool {
+	if p.isSymbol(symbol) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectSymbol(symbol string) {
+	if !p.acceptSymbol(symbol) {
+		p.unexpected(strconv.Quote(symbol))
+	}
+}
+
+// parseName parses a symbolic name, such as a variable, label or property key.
+func (p *parser) parseName() string {
+	if !p.isName() {
+		p.unexpected("a name")
+	}
+	return p.value(p.next())
+}
+
+// value returns the unquoted and unescaped value of the token.
+func (p *parser) value(tok lexer.Token) string {
+	value, err := tok.Value()
+	if err != nil {
+		p.fail(SyntaxError, "%v", err)
+	}
+	return value
+}
+
+func (p *parser) isName() bool {
+	return p.peek().IsName()
+}
+
+func (p *parser) parseStatement() *statement {
+	stmt := &statement{}
+	for p.peek().Kind != lexer.EOF {
+		if p.acceptSymbol(";") {
+			if p.peek().Kind != lexer.EOF {
+				p.fail(Unsupported, "multiple statements in a single query are unsupported")
+			}
+			break
+		}
+		stmt.clauses = append(stmt.clauses, p.parseClause())
+	}
+	if len(stmt.clauses) == 0 {
+		p.fail(SyntaxError, "the query is empty")
+	}
+	return stmt
+}
+
+func (p *parser) parseClause() clause {
+	switch {
+	case p.isKeyword("OPTIONAL"):
+		p.next()
+		p.expectKeyword("MATCH")
+		return p.parseMatch(true)
+	case p.acceptKeyword("MATCH"):
+		return p.parseMatch(false)
+	case p.isKeyword("CREATE"):
+		if next := p.peekAt(1); next.IsKeyword("INDEX") || next.IsKeyword("CONSTRAINT") || next.IsKeyword("OR") {
+			p.fail(Unsupported, "indexes and constraints are unsupported")
+		}
+		p.next()
+		return &createClause{patterns: p.parsePatterns()}
+	case p.acceptKeyword("SET"):
+		return p.parseSet()
+	case p.acceptKeyword("UNWIND"):
+		list := p.parseExpression()
+		p.expectKeyword("AS")
+		return &unwindClause{list: list, alias: p.parseName()}
+	case p.acceptKeyword("WITH"):
+		return p.parseProjection(false)
+	case p.acceptKeyword("RETURN"):
+		return p.parseProjection(true)
+	}
+	for _, keyword := range unsupportedClauses {
+		if p.isKeyword(keyword) {
+			p.fail(Unsupported, "%s is unsupported", keyword)
+		}
+	}
+	p.unexpected("a clause")
+	return nil
+}
+
+func (p *parser) parseMatch(optional bool) *matchClause {
+	c := &matchClause{optional: optional, patterns: p.parsePatterns()}
+	if p.acceptKeyword("WHERE") {
+		c.where = p.parseExpression()
+	}
+	return c
+}
+
+func (p *parser) parseSet() *setClause {
+	c := &setClause{}
+	for {
+		item := setItem{variable: p.parseName()}
+		switch {
+		case p.acceptSymbol("."):
+			item.property = p.parseName()
+			p.expectSymbol("=")
+			item.value = p.parseExpression()
+		case p.acceptSymbol("="):
+			item.value = p.parseExpression()
+		case p.acceptSymbol("+="):
+			item.merge = true
+			item.value = p.parseExpression()
+		case p.isSymbol(":"):
+			for p.acceptSymbol(":") {
+				item.labels = append(item.labels, p.parseName())
+			}
+		default:
+			p.unexpected("a property, label or map to set")
+		}
+		c.items = append(c.items, item)
+		if !p.acceptSymbol(",") {
+			return c
+		}
+	}
+}
+
+func (p *parser) parseProjection(isReturn bool) *projection {
+	c := &projection{isReturn: isReturn, distinct: p.acceptKeyword("DISTINCT")}
+	if p.acceptSymbol("*") {
+		c.star = true
+		if !p.acceptSymbol(",") {
+			return p.parseProjectionSuffix(c)
+		}
+	}
+	for {
+		start := p.peek().Pos
+		item := projectionItem{expression: p.parseExpression()}
+		item.name = p.query[start:p.tokens[p.pos-1].End]
+		if p.acceptKeyword("AS") {
+			item.name = p.parseName()
+			item.aliased = true
+		}
+		c.items = append(c.items, item)
+		if !p.acceptSymbol(",") {
+			return p.parseProjectionSuffix(c)
+		}
+	}
+}
+
+// parseProjectionSuffix parses the ORDER BY, SKIP, LIMIT and WHERE following the projected items.
+func (p *parser) parseProjectionSuffix(c *projection) *projection {
+	if p.acceptKeyword("ORDER") {
+		p.expectKeyword("BY")
+		for {
+			item := sortItem{expression: p.parseExpression()}
+			switch {
+			case p.acceptKeyword("DESC"), p.acceptKeyword("DESCENDING"):
+				item.descending = true
+			case p.acceptKeyword("ASC"), p.acceptKeyword("ASCENDING"):
+			}
+			c.orderBy = append(c.orderBy, item)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+	if p.acceptKeyword("SKIP") || p.acceptKeyword("OFFSET") {
+		c.skip = p.parseExpression()
+	}
+	if p.acceptKeyword("LIMIT") {
+		c.limit = p.parseExpression()
+	}
+	if !c.isReturn && p.acceptKeyword("WHERE") {
+		c.where = p.parseExpression()
+	}
+	return c
+}
+
+func (p *parser) parsePatterns() []*patternPart {
+	patterns := []*patternPart{p.parsePatternPart()}
+	for p.acceptSymbol(",") {
+		patterns = append(patterns, p.parsePatternPart())
+	}
+	return patterns
+}
+
+func (p *parser) parsePatternPart() *patternPart {
+	part := &patternPart{}
+	if p.isName() && p.peekAt(1).IsSymbol("=") {
+		part.pathVariable = p.parseName()
+		p.next()
+	}
+	part.nodes = append(part.nodes, p.parseNodePattern())
+	for p.isSymbol("-") || (p.isSymbol("<") && p.peekAt(1).IsSymbol("-")) {
+		part.relationships = append(part.relationships, p.parseRelationshipPattern())
+		part.nodes = append(part.nodes, p.parseNodePattern())
+	}
+	return part
+}
+
+func (p *parser) parseNodePattern() *nodePattern {
+	p.expectSymbol("(")
+	n := &nodePattern{}
+	if p.isName() {
+		n.variable = p.parseName()
+	}
+	if p.acceptSymbol(":") {
+		n.labels = p.parseLabelExpression()
+	}
+	if p.isSymbol("{") {
+		n.properties = p.parseMapLiteral()
+	} else if p.peek().Kind == lexer.Parameter {
+		p.fail(Unsupported, "parameters are unsupported")
+	}
+	p.expectSymbol(")")
+	return n
+}
+
+func (p *parser) parseRelationshipPattern() *relationshipPattern {
+	r := &relationshipPattern{}
+	pointsLeft := p.acceptSymbol("<")
+	p.expectSymbol("-")
+	if p.acceptSymbol("[") {
+		if p.isName() {
+			r.variable = p.parseName()
+		}
+		if p.acceptSymbol(":") {
+			r.types = p.parseLabelExpression()
+		}
+		if p.acceptSymbol("*") {
+			r.variableLength = true
+			r.min, r.max = 1, -1
+			if p.peek().Kind == lexer.Integer {
+				r.min = p.parseLength()
+				r.max = r.min
+			}
+			if p.acceptSymbol("..") {
+				r.max = -1
+				if p.peek().Kind == lexer.Integer {
+					r.max = p.parseLength()
+				}
+			}
+		}
+		if p.isSymbol("{") {
+			r.properties = p.parseMapLiteral()
+		}
+		p.expectSymbol("]")
+	}
+	p.expectSymbol("-")
+	pointsRight := p.acceptSymbol(">")
+	switch {
+	case pointsLeft && !pointsRight:
+		r.direction = directionLeft
+	case pointsRight && !pointsLeft:
+		r.direction = directionRight
+	default:
+		r.direction = directionBoth
+	}
+	return r
+}
+
+// parseLength parses a bound of a variable length relationship.
+func (p *parser) parseLength() int64 {
+	tok := p.next()
+	length, err := strconv.ParseInt(tok.Text, 10, 64)
+	if err != nil {
+		p.fail(SyntaxError, "invalid length %s of a variable length relationship", tok.Text)
+	}
+	return length
+}
+
+// parseLabelExpression parses the labels following a colon, in either the old syntax (:A:B, :A|B)
+// or the new one (:A&!(B|C)).
+func (p *parser) parseLabelExpression() labelExpression {
+	expr := p.parseLabelConjunction()
+	for p.acceptSymbol("|") {
+		// The old syntax allows repeating the colon, as in :A|:B
+		p.acceptSymbol(":")
+		expr = labelDisjunction{left: expr, right: p.parseLabelConjunction()}
+	}
+	return expr
+}
+
+func (p *parser) parseLabelConjunction() labelExpression {
+	expr := p.parseLabelNegation()
+	for p.acceptSymbol("&") || p.acceptSymbol(":") {
+		expr = labelConjunction{left: expr, right: p.parseLabelNegation()}
+	}
+	return expr
+}
+
+func (p *parser) parseLabelNegation() labelExpression {
+	switch {
+	case p.acceptSymbol("!"):
+		return labelNegation{operand: p.parseLabelNegation()}
+	case p.acceptSymbol("("):
+		expr := p.parseLabelExpression()
+		p.expectSymbol(")")
+		return expr
+	case p.isSymbol("%"):
+		p.fail(Unsupported, "label wildcards are unsupported")
+	}
+	return labelName(p.parseName())
+}
+
+func (p *parser) parseExpression() expression {
+	return p.parseOr()
+}
+
+func (p *parser) parseOr() expression {
+	expr := p.parseXor()
+	for p.acceptKeyword("OR") {
+		expr = &binaryOperation{operator: "OR", left: expr, right: p.parseXor()}
+	}
+	return expr
+}
+
+func (p *parser) parseXor() expression {
+	expr := p.parseAnd()
+	for p.acceptKeyword("XOR") {
+		expr = &binaryOperation{operator: "XOR", left: expr, right: p.parseAnd()}
+	}
+	return expr
+}
+
+func (p *parser) parseAnd() expression {
+	expr := p.parseNot()
+	for p.acceptKeyword("AND") {
+		expr = &binaryOperation{operator: "AND", left: expr, right: p.parseNot()}
+	}
+	return expr
+}
+
+func (p *parser) parseNot() expression {
+	if p.acceptKeyword("NOT") {
+		return &unaryOperation{operator: "NOT", operand: p.parseNot()}
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses a chain of comparisons, a < b < c is equivalent to a < b AND b < c.
+func (p *parser) parseComparison() expression {
+	left := p.parsePredicate()
+	var chain expression
+	for {
+		tok := p.peek()
+		if tok.Kind != lexer.Symbol {
+			break
+		}
+		operator := tok.Text
+		switch operator {
+		case "=", "<>", "<", ">", "<=", ">=", "=~":
+			p.next()
+		default:
+			operator = ""
+		}
+		if operator == "" {
+			break
+		}
+		right := p.parsePredicate()
+		comparison := &binaryOperation{operator: operator, left: left, right: right}
+		if chain == nil {
+			chain = comparison
+		} else {
+			chain = &binaryOperation{operator: "AND", left: chain, right: comparison}
+		}
+		left = right
+	}
+	if chain == nil {
+		return left
+	}
+	return chain
+}
+
+// parsePredicate parses the string, list and null predicates.
+func (p *parser) parsePredicate() expression {
+	expr := p.parseAdditive()
+	for {
+		switch {
+		case p.acceptKeyword("IS"):
+			negated := p.acceptKeyword("NOT")
+			p.expectKeyword("NULL")
+			expr = &nullCheck{operand: expr, negated: negated}
+		case p.isKeyword("STARTS"):
+			p.next()
+			p.expectKeyword("WITH")
+			expr = &binaryOperation{operator: "STARTS WITH", left: expr, right: p.parseAdditive()}
+		case p.isKeyword("ENDS"):
+			p.next()
+			p.expectKeyword("WITH")
+			expr = &binaryOperation{operator: "ENDS WITH", left: expr, right: p.parseAdditive()}
+		case p.acceptKeyword("CONTAINS"):
+			expr = &binaryOperation{operator: "CONTAINS", left: expr, right: p.parseAdditive()}
+		case p.acceptKeyword("IN"):
+			expr = &binaryOperation{operator: "IN", left: expr, right: p.parseAdditive()}
+		default:
+			return expr
+		}
+	}
+}
+
+func (p *parser) parseAdditive() expression {
+	expr := p.parseMultiplicative()
+	for p.isSymbol("+") || p.isSymbol("-") {
+		operator := p.next().Text
+		expr = &binaryOperation{operator: operator, left: expr, right: p.parseMultiplicative()}
+	}
+	return expr
+}
+
+func (p *parser) parseMultiplicative() expression {
+	expr := p.parsePower()
+	for p.isSymbol("*") || p.isSymbol("/") || p.isSymbol("%") {
+		operator := p.next().Text
+		expr = &binaryOperation{operator: operator, left: expr, right: p.parsePower()}
+	}
+	return expr
+}
+
+// parsePower parses exponentiations, which are left associative.
+func (p *parser) parsePower() expression {
+	expr := p.parseUnary()
+	for p.acceptSymbol("^") {
+		expr = &binaryOperation{operator: "^", left: expr, right: p.parseUnary()}
+	}
+	return expr
+}
+
+func (p *parser) parseUnary() expression {
+	switch {
+	case p.isSymbol("-"):
+		p.next()
+		// Negative literals are parsed as a whole, as the smallest integer's absolute value overflows
+		if kind := p.peek().Kind; kind == lexer.Integer || kind == lexer.Float {
+			return p.parsePostfix(p.parseNumber(true))
+		}
+		return &unaryOperation{operator: "-", operand: p.parseUnary()}
+	case p.acceptSymbol("+"):
+		return &unaryOperation{operator: "+", operand: p.parseUnary()}
+	}
+	return p.parsePostfix(p.parseAtom())
+}
+
+// parsePostfix parses the property accesses, subscripts and label checks following an atom.
+func (p *parser) parsePostfix(expr expression) expression {
+	for {
+		switch {
+		case p.acceptSymbol("."):
+			expr = &propertyAccess{subject: expr, property: p.parseName()}
+		case p.acceptSymbol("["):
+			var from expression
+			if !p.isSymbol("..") {
+				from = p.parseExpression()
+			}
+			if p.acceptSymbol("..") {
+				var to expression
+				if !p.isSymbol("]") {
+					to = p.parseExpression()
+				}
+				expr = &sliceAccess{subject: expr, from: from, to: to}
+			} else {
+				expr = &indexAccess{subject: expr, index: from}
+			}
+			p.expectSymbol("]")
+		case p.isSymbol(":"):
+			p.next()
+			expr = &labelCheck{subject: expr, labels: p.parseLabelExpression()}
+		default:
+			return expr
+		}
+	}
+}
+
+func (p *parser) parseNumber(negative bool) expression {
+	tok := p.next()
+	text := tok.Text
+	if negative {
+		text = "-" + text
+	}
+	if tok.Kind == lexer.Float {
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil || math.IsInf(f, 0) {
+			p.fail(SyntaxError, "float literal %s is out of range", text)
+		}
+		return &literal{value: f}
+	}
+	i, err := parseInteger(text)
+	if err != nil {
+		p.fail(SyntaxError, "integer literal %s is out of range", text)
+	}
+	return &literal{value: i}
+}
+
+// parseInteger parses the possibly negative integer literal, which may be hexadecimal (0x) or octal (0o).
+func parseInteger(text string) (int64, error) {
+	digits, negative := strings.CutPrefix(text, "-")
+	base := 10
+	if len(digits) > 2 && digits[0] == '0' {
+		switch digits[1] {
+		case 'x', 'X':
+			base, digits = 16, digits[2:]
+		case 'o', 'O':
+			base, digits = 8, digits[2:]
+		}
+	}
+	if negative {
+		digits = "-" + digits
+	}
+	return strconv.ParseInt(digits, base, 64)
+}
+
+func (p *parser) parseAtom() expression {
+	tok := p.peek()
+	switch tok.Kind {
+	case lexer.Integer, lexer.Float:
+		return p.parseNumber(false)
+	case lexer.String:
+		return &literal{value: p.value(p.next())}
+	case lexer.EscapedIdentifier:
+		return &variable{name: p.value(p.next())}
+	case lexer.Parameter:
+		p.fail(Unsupported, "parameters are unsupported")
+	case lexer.Symbol:
+		switch tok.Text {
+		case "(":
+			p.next()
+			expr := p.parseExpression()
+			p.expectSymbol(")")
+			return expr
+		case "[":
+			return p.parseList()
+		case "{":
+			return p.parseMapLiteral()
+		}
+	case lexer.Identifier:
+		return p.parseIdentifierAtom()
+	}
+	p.unexpected("an expression")
+	return nil
+}
+
+// parseIdentifierAtom parses an atom starting with an identifier, such as a literal, a function call or a variable.
+func (p *parser) parseIdentifierAtom() expression {
+	tok := p.peek()
+	next := p.peekAt(1)
+	opensParenthesis := next.IsSymbol("(")
+	switch {
+	case p.acceptKeyword("TRUE"):
+		return &literal{value: true}
+	case p.acceptKeyword("FALSE"):
+		return &literal{value: false}
+	case p.acceptKeyword("NULL"):
+		return &literal{value: nil}
+	// Like in Neo4j, non-finite floats are written as keywords
+	case p.acceptKeyword("NAN"):
+		return &literal{value: math.NaN()}
+	case p.acceptKeyword("INF"), p.acceptKeyword("INFINITY"):
+		return &literal{value: math.Inf(1)}
+	case p.acceptKeyword("CASE"):
+		return p.parseCase()
+	case (tok.IsKeyword("EXISTS") || tok.IsKeyword("COUNT") || tok.IsKeyword("COLLECT")) && next.IsSymbol("{"):
+		p.fail(Unsupported, "subquery expressions are unsupported")
+	case opensParenthesis && isQuantifier(tok) && p.peekAt(2).Kind == lexer.Identifier && p.peekAt(3).IsKeyword("IN"):
+		return p.parseQuantifier()
+	case opensParenthesis:
+		return p.parseFunctionCall()
+	case next.IsSymbol(".") && p.peekAt(2).Kind == lexer.Identifier && p.peekAt(3).IsSymbol("("):
+		p.fail(Unsupported, "namespaced functions are unsupported")
+	}
+	p.next()
+	return &variable{name: tok.Text}
+}
+
+func isQuantifier(tok lexer.Token) bool {
+	for _, kind := range []string{"all", "any", "none", "single"} {
+		if tok.IsKeyword(kind) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseQuantifier() expression {
+	q := &quantifier{kind: strings.ToLower(p.next().Text)}
+	p.expectSymbol("(")
+	q.variable = p.parseName()
+	p.expectKeyword("IN")
+	q.list = p.parseExpression()
+	if p.acceptKeyword("WHERE") {
+		q.where = p.parseExpression()
+	} else {
+		p.fail(SyntaxError, "%s(...) requires a WHERE predicate", q.kind)
+	}
+	p.expectSymbol(")")
+	return q
+}
+
+func (p *parser) parseFunctionCall() expression {
+	call := &functionCall{name: strings.ToLower(p.next().Text)}
+	p.expectSymbol("(")
+	if call.name == "count" && p.acceptSymbol("*") {
+		call.star = true
+		p.expectSymbol(")")
+		return call
+	}
+	call.distinct = p.acceptKeyword("DISTINCT")
+	if p.acceptSymbol(")") {
+		return call
+	}
+	for {
+		call.arguments = append(call.arguments, p.parseExpression())
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	p.expectSymbol(")")
+	return call
+}
+
+func (p *parser) parseCase() expression {
+	c := &caseExpression{}
+	if !p.isKeyword("WHEN") {
+		c.subject = p.parseExpression()
+	}
+	for p.acceptKeyword("WHEN") {
+		alternative := caseAlternative{when: p.parseExpression()}
+		p.expectKeyword("THEN")
+		alternative.then = p.parseExpression()
+		c.alternatives = append(c.alternatives, alternative)
+	}
+	if len(c.alternatives) == 0 {
+		p.unexpected("WHEN")
+	}
+	if p.acceptKeyword("ELSE") {
+		c.otherwise = p.parseExpression()
+	}
+	p.expectKeyword("END")
+	return c
+}
+
+// parseList parses a list literal or a list comprehension.
+func (p *parser) parseList() expression {
+	p.expectSymbol("[")
+	if p.isName() && p.peekAt(1).IsKeyword("IN") {
+		c := &listComprehension{variable: p.parseName()}
+		p.expectKeyword("IN")
+		c.list = p.parseExpression()
+		if p.acceptKeyword("WHERE") {
+			c.where = p.parseExpression()
+		}
+		if p.acceptSymbol("|") {
+			c.projection = p.parseExpression()
+		}
+		p.expectSymbol("]")
+		return c
+	}
+	l := &listLiteral{}
+	if p.acceptSymbol("]") {
+		return l
+	}
+	for {
+		l.elements = append(l.elements, p.parseExpression())
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	p.expectSymbol("]")
+	return l
+}
+
+func (p *parser) parseMapLiteral() *mapLiteral {
+	p.expectSymbol("{")
+	m := &mapLiteral{}
+	if p.acceptSymbol("}") {
+		return m
+	}
+	for {
+		key := p.parseName()
+		p.expectSymbol(":")
+		m.keys = append(m.keys, key)
+		m.values = append(m.values, p.parseExpression())
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	p.expectSymbol("}")
+	return m
+}
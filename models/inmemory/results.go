@@ -0,0 +1,86 @@
+package inmemory
+
+import (
+	"fmt"
+
+	"github.com/Anon10214/dinkel/dbms"
+)
+
+// toValue converts a value of the engine into its [dbms.Value].
+func toValue(v any) dbms.Value {
+	switch v := v.(type) {
+	case nil:
+		return dbms.Null{}
+	case bool:
+		return dbms.Bool(v)
+	case int64:
+		return dbms.Int(v)
+	case float64:
+		return dbms.Float(v)
+	case string:
+		return dbms.String(v)
+	case []any:
+		list := make(dbms.List, len(v))
+		for i, elem := range v {
+			list[i] = toValue(elem)
+		}
+		return list
+	case map[string]any:
+		return toMap(v)
+	case *node:
+		return toNode(v)
+	case *relationship:
+		return toRelationship(v)
+	case path:
+		p := dbms.Path{}
+		for _, n := range v.nodes {
+			p.Nodes = append(p.Nodes, toNode(n))
+		}
+		for _, r := range v.relationships {
+			p.Relationships = append(p.Relationships, toRelationship(r))
+		}
+		return p
+	}
+	return dbms.UnknownValue(v)
+}
+
+func toMap(m map[string]any) dbms.Map {
+	res := make(dbms.Map, len(m))
+	for k, v := range m {
+		res[k] = toValue(v)
+	}
+	return res
+}
+
+func toNode(n *node) dbms.Node {
+	return dbms.Node{Labels: append([]string{}, n.labels...), Properties: toMap(n.properties)}
+}
+
+func toRelationship(r *relationship) dbms.Relationship {
+	return dbms.Relationship{Type: r.typ, Properties: toMap(r.properties)}
+}
+
+// toQueryResult converts the result of a successfully executed query into a [dbms.QueryResult].
+func toQueryResult(res *result) dbms.QueryResult {
+	counters := res.counters
+	queryResult := dbms.QueryResult{Columns: res.columns, Counters: &counters, Graph: toGraph(res.graph)}
+	for _, r := range res.rows {
+		row := make(dbms.Row, len(r))
+		for i, v := range r {
+			row[i] = toValue(v)
+		}
+		queryResult.Rows = append(queryResult.Rows, row)
+	}
+	return queryResult
+}
+
+func toGraph(g *graph) *dbms.Graph {
+	res := dbms.NewGraph()
+	for _, n := range g.nodes {
+		res.AddNode(fmt.Sprint(n.id), toNode(n))
+	}
+	for _, r := range g.relationships {
+		res.AddRelationship(fmt.Sprint(r.id), fmt.Sprint(r.start.id), fmt.Sprint(r.end.id), toRelationship(r))
+	}
+	return res
+}
@@ -0,0 +1,421 @@
+package inmemory
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Values are represented by nil, bool, int64, float64, string, []any, map[string]any, *node, *relationship and path.
+// Lists and maps are never modified once created, so they can be shared between rows.
+
+// typeName returns the Cypher name of the passed value's type, used in error messages.
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "Null"
+	case bool:
+		return "Boolean"
+	case int64:
+		return "Integer"
+	case float64:
+		return "Float"
+	case string:
+		return "String"
+	case []any:
+		return "List"
+	case map[string]any:
+		return "Map"
+	case *node:
+		return "Node"
+	case *relationship:
+		return "Relationship"
+	case path:
+		return "Path"
+	}
+	return "Unknown"
+}
+
+// isNumber returns whether the value is an integer or a float.
+func isNumber(v any) bool {
+	switch v.(type) {
+	case int64, float64:
+		return true
+	}
+	return false
+}
+
+// toFloat converts a number to a float.
+func toFloat(v any) float64 {
+	if i, ok := v.(int64); ok {
+		return float64(i)
+	}
+	return v.(float64)
+}
+
+// exactInt returns the integer equal to the float, false if there is none.
+func exactInt(f float64) (int64, bool) {
+	// 2^63 can't be represented as an int64, but is representable as a float
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// compareNumbers compares two numbers exactly, even if integers and floats are mixed.
+// Returns false if one of them is NaN.
+func compareNumbers(a, b any) (int, bool) {
+	ai, aIsInt := a.(int64)
+	bi, bIsInt := b.(int64)
+	switch {
+	case aIsInt && bIsInt:
+		return cmp.Compare(ai, bi), true
+	case aIsInt:
+		res, ok := compareIntToFloat(ai, b.(float64))
+		return res, ok
+	case bIsInt:
+		res, ok := compareIntToFloat(bi, a.(float64))
+		return -res, ok
+	}
+	af, bf := a.(float64), b.(float64)
+	if math.IsNaN(af) || math.IsNaN(bf) {
+		return 0, false
+	}
+	return cmp.Compare(af, bf), true
+}
+
+func compareIntToFloat(i int64, f float64) (int, bool) {
+	switch {
+	case math.IsNaN(f):
+		return 0, false
+	case f >= math.MaxInt64:
+		return -1, true
+	case f < math.MinInt64:
+		return 1, true
+	}
+	// Compare the integral parts exactly, the fraction breaks ties
+	truncated := math.Trunc(f)
+	if res := cmp.Compare(i, int64(truncated)); res != 0 {
+		return res, true
+	}
+	return cmp.Compare(0, f-truncated), true
+}
+
+// equal returns whether two values are equal, using ternary logic.
+// Returns nil if the result is unknown, e.g. if one of the values is null.
+func equal(a, b any) any {
+	if a == nil || b == nil {
+		return nil
+	}
+	switch a := a.(type) {
+	case int64, float64:
+		if !isNumber(b) {
+			return false
+		}
+		res, ok := compareNumbers(a, b)
+		return ok && res == 0
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		return equalElements(len(a), func(i int) any { return equal(a[i], b[i]) })
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		keys := sortedKeys(a)
+		for _, key := range keys {
+			if _, ok := b[key]; !ok {
+				return false
+			}
+		}
+		return equalElements(len(keys), func(i int) any { return equal(a[keys[i]], b[keys[i]]) })
+	case path:
+		b, ok := b.(path)
+		if !ok || len(a.relationships) != len(b.relationships) {
+			return false
+		}
+		return slices.Equal(a.nodes, b.nodes) && slices.Equal(a.relationships, b.relationships)
+	}
+	return a == b
+}
+
+// equalElements combines the equality of a list's or map's elements, which is false if any element differs
+// and unknown if any element's equality is unknown.
+func equalElements(n int, equalAt func(int) any) any {
+	var res any = true
+	for i := 0; i < n; i++ {
+		switch equalAt(i) {
+		case false:
+			return false
+		case nil:
+			res = nil
+		}
+	}
+	return res
+}
+
+// compare compares two values for the inequality operators.
+// Returns false if the values aren't comparable, as is the case for values of differing types,
+// null or NaN, in which case the comparison yields null (or false for NaN).
+func compare(a, b any) (int, bool) {
+	switch a := a.(type) {
+	case int64, float64:
+		if !isNumber(b) {
+			return 0, false
+		}
+		return compareNumbers(a, b)
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), true
+		}
+	case bool:
+		if b, ok := b.(bool); ok {
+			return compareBools(a, b), true
+		}
+	case []any:
+		b, ok := b.([]any)
+		if !ok {
+			return 0, false
+		}
+		for i := 0; i < len(a) && i < len(b); i++ {
+			res, ok := compare(a[i], b[i])
+			if !ok {
+				return 0, false
+			}
+			if res != 0 {
+				return res, true
+			}
+		}
+		return cmp.Compare(len(a), len(b)), true
+	}
+	return 0, false
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case b:
+		return -1
+	}
+	return 1
+}
+
+// The rank of a type in the global sort order, as used by ORDER BY, min and max.
+func orderRank(v any) int {
+	switch v.(type) {
+	case map[string]any:
+		return 0
+	case *node:
+		return 1
+	case *relationship:
+		return 2
+	case []any:
+		return 3
+	case path:
+		return 4
+	case string:
+		return 5
+	case bool:
+		return 6
+	case int64, float64:
+		return 7
+	}
+	// Null is ordered last
+	return 8
+}
+
+// orderCompare compares two values by the global sort order, which orders values of any type.
+// Values of differing types are ordered by their type, NaN is the largest number.
+func orderCompare(a, b any) int {
+	if res := cmp.Compare(orderRank(a), orderRank(b)); res != 0 {
+		return res
+	}
+	switch a := a.(type) {
+	case map[string]any:
+		b := b.(map[string]any)
+		aKeys, bKeys := sortedKeys(a), sortedKeys(b)
+		if res := slices.Compare(aKeys, bKeys); res != 0 {
+			return res
+		}
+		for _, key := range aKeys {
+			if res := orderCompare(a[key], b[key]); res != 0 {
+				return res
+			}
+		}
+		return 0
+	case *node:
+		return cmp.Compare(a.id, b.(*node).id)
+	case *relationship:
+		return cmp.Compare(a.id, b.(*relationship).id)
+	case []any:
+		return orderCompareLists(a, b.([]any))
+	case path:
+		b := b.(path)
+		for i := 0; i < len(a.relationships) && i < len(b.relationships); i++ {
+			if res := cmp.Compare(a.nodes[i].id, b.nodes[i].id); res != 0 {
+				return res
+			}
+			if res := cmp.Compare(a.relationships[i].id, b.relationships[i].id); res != 0 {
+				return res
+			}
+		}
+		if res := cmp.Compare(len(a.relationships), len(b.relationships)); res != 0 {
+			return res
+		}
+		return cmp.Compare(a.nodes[len(a.nodes)-1].id, b.nodes[len(b.nodes)-1].id)
+	case string:
+		return strings.Compare(a, b.(string))
+	case bool:
+		return compareBools(a, b.(bool))
+	case int64, float64:
+		aNaN, bNaN := isNaN(a), isNaN(b)
+		switch {
+		case aNaN && bNaN:
+			return 0
+		case aNaN:
+			return 1
+		case bNaN:
+			return -1
+		}
+		res, _ := compareNumbers(a, b)
+		return res
+	}
+	return 0
+}
+
+func orderCompareLists(a, b []any) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if res := orderCompare(a[i], b[i]); res != 0 {
+			return res
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+func isNaN(v any) bool {
+	f, ok := v.(float64)
+	return ok && math.IsNaN(f)
+}
+
+// equivalenceKey returns a key that is equal for equivalent values, as used by DISTINCT and grouping.
+//
+// Equivalence differs from equality in that null is equivalent to null and NaN to NaN.
+// As for equality, integers are equivalent to floats of the same value.
+func equivalenceKey(v any) string {
+	var b strings.Builder
+	writeEquivalenceKey(&b, v)
+	return b.String()
+}
+
+func writeEquivalenceKey(b *strings.Builder, v any) {
+	switch v := v.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case int64:
+		b.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		if i, ok := exactInt(v); ok {
+			b.WriteString(strconv.FormatInt(i, 10))
+		} else {
+			b.WriteString(strconv.FormatFloat(v, 'g', -1, 64) + "f")
+		}
+	case string:
+		b.WriteString(strconv.Quote(v))
+	case []any:
+		b.WriteByte('[')
+		for _, elem := range v {
+			writeEquivalenceKey(b, elem)
+			b.WriteByte(',')
+		}
+		b.WriteByte(']')
+	case map[string]any:
+		b.WriteByte('{')
+		for _, key := range sortedKeys(v) {
+			b.WriteString(strconv.Quote(key) + ":")
+			writeEquivalenceKey(b, v[key])
+			b.WriteByte(',')
+		}
+		b.WriteByte('}')
+	case *node:
+		b.WriteString(v.String())
+	case *relationship:
+		b.WriteString(v.String())
+	case path:
+		b.WriteString("<")
+		for i, n := range v.nodes {
+			if i > 0 {
+				b.WriteString(v.relationships[i-1].String())
+			}
+			b.WriteString(n.String())
+		}
+		b.WriteString(">")
+	}
+}
+
+// formatFloat formats a float the way Java does, as Cypher's toString and string concatenation do.
+//
+// Floats with an absolute value in [10^-3, 10^7) are written in decimal notation,
+// others in scientific notation, e.g. 1.0E20.
+func formatFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	if abs := math.Abs(f); f == 0 || (abs >= 1e-3 && abs < 1e7) {
+		res := strconv.FormatFloat(f, 'f', -1, 64)
+		if !strings.Contains(res, ".") {
+			res += ".0"
+		}
+		return res
+	}
+	mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	if !strings.Contains(mantissa, ".") {
+		mantissa += ".0"
+	}
+	exp, _ := strconv.Atoi(exponent)
+	return mantissa + "E" + strconv.Itoa(exp)
+}
+
+// checkStorable raises a type error if the value can't be stored as a property.
+//
+// Properties are either primitives or lists of primitives of the same type.
+func checkStorable(v any) {
+	switch v := v.(type) {
+	case bool, int64, float64, string:
+		return
+	case []any:
+		for _, elem := range v {
+			switch elem.(type) {
+			case bool, int64, float64, string:
+			default:
+				raise(TypeError, "lists stored as properties can only hold booleans, numbers and strings, not %s", typeName(elem))
+			}
+			if typeName(elem) != typeName(v[0]) {
+				raise(TypeError, "lists stored as properties must not mix types, got %s and %s", typeName(v[0]), typeName(elem))
+			}
+		}
+		return
+	}
+	raise(TypeError, "a %s can't be stored as a property", typeName(v))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
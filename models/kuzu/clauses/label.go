@@ -0,0 +1,33 @@
+package clauses
+
+import (
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/seed"
+	"github.com/Anon10214/dinkel/translator"
+)
+
+// ExistingLabel drop-in for Kùzu, replacing both new and existing labels.
+type ExistingLabel struct {
+	LabelType schema.StructuralType `ast:"labelType"`
+	name      string                `ast:"name"`
+}
+
+// Generate the ExistingLabel subclauses.
+// Different from the OpenCypher implementation, this drop-in never creates a new label,
+// as every label has to refer to an existing table.
+// The label is only chosen from labels of a different structural type if there are none for its LabelType.
+func (c *ExistingLabel) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause {
+	labels := s.Labels[c.LabelType]
+	if len(labels) == 0 {
+		labels = s.Labels[schema.ANY]
+	}
+	if len(labels) > 0 {
+		c.name = labels[seed.GetRandomIntn(len(labels))]
+	}
+	return nil
+}
+
+// TemplateString returns the chosen name
+func (c ExistingLabel) TemplateString() string {
+	return c.name
+}
@@ -0,0 +1,118 @@
+package clauses
+
+import (
+	"github.com/Anon10214/dinkel/models/opencypher/clauses"
+	"github.com/Anon10214/dinkel/models/opencypher/config"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/seed"
+	"github.com/Anon10214/dinkel/translator"
+)
+
+// Property drop-in for Kùzu, replacing both new and existing properties in property maps.
+type Property struct {
+	name string `ast:"name"`
+}
+
+// Generate the Property subclauses.
+// Different from the OpenCypher implementation, this drop-in only uses existing properties,
+// as every property has to be a column of the table.
+// The assigned expression is of the column's type, as Kùzu doesn't cast values implicitly.
+func (c *Property) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause {
+	property, ok := chooseProperty(seed, s)
+	if !ok {
+		return nil
+	}
+	c.name = property.Name
+	return []translator.Clause{&clauses.Expression{Conf: propertyExpressionConfig(property, s.IsInMergeClause)}}
+}
+
+// TemplateString for the Property drop-in
+func (c Property) TemplateString() string {
+	if c.name == "" {
+		return ""
+	}
+	return c.name + ": %s"
+}
+
+// PropertyName drop-in for Kùzu, only choosing names of existing properties.
+type PropertyName struct {
+	name string `ast:"name"`
+}
+
+// Generate the PropertyName subclauses
+func (c *PropertyName) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause {
+	if property, ok := chooseProperty(seed, s); ok {
+		c.name = property.Name
+	}
+	return nil
+}
+
+// TemplateString returns the chosen name
+func (c PropertyName) TemplateString() string {
+	return c.name
+}
+
+// SetPropertyExpression drop-in for Kùzu.
+// Different from the OpenCypher implementation, this drop-in never assigns maps,
+// as Kùzu only supports setting single properties.
+type SetPropertyExpression struct {
+	name string `ast:"name"`
+}
+
+// Generate the SetPropertyExpression subclauses.
+// Falls back to an empty clause if the schema holds no properties.
+func (c *SetPropertyExpression) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause {
+	property, ok := chooseProperty(seed, s)
+	if !ok {
+		return []translator.Clause{&clauses.EmptyClause{}}
+	}
+	c.name = property.Name
+	return []translator.Clause{
+		&clauses.WriteTarget{TargetType: writeTargetType(seed, s)},
+		&clauses.Expression{Conf: propertyExpressionConfig(property, false)},
+	}
+}
+
+// TemplateString for the SetPropertyExpression drop-in
+func (c SetPropertyExpression) TemplateString() string {
+	if c.name == "" {
+		return "%s"
+	}
+	return "%s." + c.name + " = %s"
+}
+
+// chooseProperty returns a random property from the schema.
+// Returns false if the schema holds no properties.
+func chooseProperty(seed *seed.Seed, s *schema.Schema) (schema.Property, bool) {
+	properties := s.Properties[schema.AnyType]
+	if len(properties) == 0 {
+		return schema.Property{}, false
+	}
+	return properties[seed.GetRandomIntn(len(properties))], true
+}
+
+// propertyExpressionConfig returns the config for an expression evaluating to a value of the passed property's type.
+func propertyExpressionConfig(property schema.Property, mustBeNonNull bool) schema.ExpressionConfig {
+	listMask := schema.PropertyType(schema.ListMask)
+	return schema.ExpressionConfig{
+		TargetType:    schema.PropertyValue,
+		PropertyType:  property.Type &^ listMask,
+		IsList:        property.Type&listMask != 0,
+		MustBeNonNull: mustBeNonNull,
+	}
+}
+
+// writeTargetType returns the structural type of a write target, choosing one for which a variable exists if necessary.
+func writeTargetType(seed *seed.Seed, s *schema.Schema) schema.StructuralType {
+	if config.GetConfig().OnlyVariablesAsWriteTarget {
+		if len(s.StructuralVariablesByType[schema.NODE]) == 0 ||
+			(len(s.StructuralVariablesByType[schema.RELATIONSHIP]) != 0 && seed.RandomBoolean()) {
+			return schema.RELATIONSHIP
+		}
+		return schema.NODE
+	}
+	if seed.RandomBoolean() {
+		return schema.RELATIONSHIP
+	}
+	return schema.NODE
+}
@@ -0,0 +1,12 @@
+package clauses
+
+import "github.com/Anon10214/dinkel/translator/helperclauses"
+
+// Register the Kùzu specific clauses, allowing ASTs containing them to be loaded from bug reports.
+func init() {
+	helperclauses.RegisterClauses(
+		&ExistingLabel{},
+		&Property{}, &PropertyName{}, &SetPropertyExpression{},
+		&RemoveClause{}, &RemovePropertyExpression{},
+	)
+}
@@ -0,0 +1,35 @@
+package clauses
+
+import (
+	"github.com/Anon10214/dinkel/models/opencypher/clauses"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/seed"
+	"github.com/Anon10214/dinkel/translator"
+)
+
+// RemoveClause drop-in for Kùzu.
+// Kùzu doesn't support REMOVE, properties are removed by setting them to null instead.
+type RemoveClause struct{}
+
+// Generate the RemoveClause subclauses
+func (c *RemoveClause) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause {
+	return []translator.Clause{&clauses.RemoveSubclause{}}
+}
+
+// TemplateString for the RemoveClause drop-in
+func (c RemoveClause) TemplateString() string {
+	return "SET %s"
+}
+
+// RemovePropertyExpression drop-in for Kùzu, setting the property to null.
+type RemovePropertyExpression struct{}
+
+// Generate the RemovePropertyExpression subclauses
+func (c *RemovePropertyExpression) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause {
+	return []translator.Clause{&clauses.WriteTarget{TargetType: writeTargetType(seed, s)}, &PropertyName{}}
+}
+
+// TemplateString for the RemovePropertyExpression drop-in
+func (c RemovePropertyExpression) TemplateString() string {
+	return "%s.%s = NULL"
+}
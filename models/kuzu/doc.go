@@ -0,0 +1,7 @@
+/*
+Package kuzu provides the model for Kùzu, an embeddable graph database.
+
+As Kùzu runs in-process, the driver is only compiled in if the kuzu build tag is set,
+as it requires cgo and links against the Kùzu library shipped with its go bindings.
+*/
+package kuzu
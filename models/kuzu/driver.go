@@ -0,0 +1,324 @@
+//go:build kuzu
+
+package kuzu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/seed"
+	"github.com/kuzudb/go-kuzu"
+	"github.com/sirupsen/logrus"
+)
+
+// The size of Kùzu's buffer pool. Without a limit, Kùzu may use up to 80% of the system's memory,
+// causing queries generating huge lists to bring down the whole machine instead of just failing.
+const bufferPoolSize = 1 << 30
+
+// Driver for Kùzu
+type Driver struct {
+	db   *kuzu.Database
+	conn *kuzu.Connection
+}
+
+// Init the DB driver.
+//
+// As Kùzu is embedded, the database host is interpreted as the path to an on-disk database.
+// If the host is left at its default, localhost, an in-memory database is used instead.
+//
+// Credentials and TLS options are ignored, as there is no connection to secure.
+// The namespace is ignored too, as every database is only ever used by a single fuzzer.
+func (d *Driver) Init(opts dbms.DBOptions) error {
+	d.close()
+
+	path := opts.Host
+	if path == "localhost" || path == "" {
+		path = ":memory:"
+	}
+
+	config := kuzu.DefaultSystemConfig()
+	config.BufferPoolSize = bufferPoolSize
+
+	logrus.Debugf("Opening Kùzu database at %s", path)
+	db, err := kuzu.OpenDatabase(path, config)
+	if err != nil {
+		return err
+	}
+	conn, err := kuzu.OpenConnection(db)
+	if err != nil {
+		db.Close()
+		return err
+	}
+	conn.SetTimeout(uint64(opts.Timeout.Milliseconds()))
+
+	d.db = db
+	d.conn = conn
+	return nil
+}
+
+// close the connection and database, if opened.
+func (d *Driver) close() {
+	if d.conn != nil {
+		d.conn.Close()
+		d.conn = nil
+	}
+	if d.db != nil {
+		d.db.Close()
+		d.db = nil
+	}
+}
+
+// Reset the database by dropping all tables and recreating the fixed node and relationship tables.
+//
+// Recreating the tables also resets the serial primary keys of the nodes.
+func (d *Driver) Reset(opts dbms.DBOptions) error {
+	logrus.Debug("Resetting Database")
+
+	tables, err := d.query("CALL show_tables() RETURN name, type")
+	if err != nil {
+		logrus.Errorf("couldn't fetch tables - %v", err)
+		return err
+	}
+	// Relationship tables reference node tables, they have to be dropped first
+	slices.SortStableFunc(tables, func(a, b []any) int {
+		if a[1] == b[1] {
+			return 0
+		}
+		if a[1] == "REL" {
+			return -1
+		}
+		return 1
+	})
+	for _, table := range tables {
+		if _, err := d.query(fmt.Sprintf("DROP TABLE `%s`", table[0])); err != nil {
+			logrus.Errorf("couldn't drop table %s - %v", table[0], err)
+			return err
+		}
+	}
+
+	for _, statement := range createTableStatements() {
+		if _, err := d.query(statement); err != nil {
+			logrus.Errorf("couldn't create table - %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// query runs the passed query and returns all resulting rows.
+//
+// The driver panics when converting some values (e.g. null nodes inside of paths),
+// such panics are returned as errors instead of terminating the fuzzer.
+func (d *Driver) query(query string) (rows [][]any, err error) {
+	_, rows, err = d.queryWithColumns(query)
+	return rows, err
+}
+
+// queryWithColumns runs the query like [Driver.query], additionally returning the names of the returned columns.
+func (d *Driver) queryWithColumns(query string) (columns []string, rows [][]any, err error) {
+	res, err := d.conn.Query(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Close()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("go-kuzu panicked while reading the result: %v", r)
+		}
+	}()
+
+	columns = res.GetColumnNames()
+	for res.HasNext() {
+		tuple, err := res.Next()
+		if err != nil {
+			return columns, rows, err
+		}
+		row, err := tuple.GetAsSlice()
+		tuple.Close()
+		if err != nil {
+			return columns, rows, err
+		}
+		rows = append(rows, row)
+	}
+	return columns, rows, nil
+}
+
+// The interval in which a cancelled query gets interrupted until it returns
+const interruptInterval = 100 * time.Millisecond
+
+// RunQuery runs the query and returns the result
+//
+// Once the context is done, the query gets interrupted.
+func (d *Driver) RunQuery(ctx context.Context, opts dbms.DBOptions, query string) dbms.QueryResult {
+	logrus.Debug("Sending query to database")
+	// Interrupts before Kùzu started executing the query have no effect, so keep interrupting until the query returns
+	returned := make(chan struct{})
+	defer close(returned)
+	stop := context.AfterFunc(ctx, func() {
+		ticker := time.NewTicker(interruptInterval)
+		defer ticker.Stop()
+		for {
+			d.conn.Interrupt()
+			select {
+			case <-returned:
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+	defer stop()
+
+	columns, rows, err := d.queryWithColumns(query)
+	if err != nil {
+		logrus.Debugf("Error %v produced when running query %s", err, query)
+		return dbms.QueryResult{ProducedError: err}
+	}
+
+	// Kùzu doesn't report how the query changed the graph
+	queryResult := dbms.QueryResult{Columns: columns}
+	for _, row := range rows {
+		queryResult.Rows = append(queryResult.Rows, toRow(row))
+	}
+
+	// Get the graph to compare it when fuzzing for logic bugs,
+	// nodes and relationships can't be combined in a UNION as their types differ
+	queryResult.Graph = dbms.NewGraph()
+	for _, schemaQuery := range []string{"MATCH (n) RETURN n", "MATCH ()-[m]->() RETURN m"} {
+		schemaRows, err := d.query(schemaQuery)
+		if err != nil {
+			queryResult.ProducedError = err
+			logrus.Debugf("Error %v produced when trying to get schema", err)
+			return queryResult
+		}
+		for _, row := range schemaRows {
+			for _, v := range row {
+				addToGraph(queryResult.Graph, v)
+			}
+		}
+	}
+
+	logrus.Debug("Query finished")
+	return queryResult
+}
+
+// GetSchema returns the database's current schema.
+//
+// All tables are part of the schema, regardless of whether they hold any nodes or relationships,
+// as the generated labels are restricted to existing tables.
+func (d *Driver) GetSchema(opts dbms.DBOptions) (*schema.Schema, error) {
+	s := &schema.Schema{}
+	s.Reset()
+
+	tables, err := d.query("CALL show_tables() RETURN name, type ORDER BY name")
+	if err != nil {
+		logrus.Errorf("Couldn't get tables for schema - %v", err)
+		return nil, err
+	}
+	for _, table := range tables {
+		name := table[0].(string)
+		switch table[1] {
+		case "NODE":
+			s.Labels[schema.NODE] = append(s.Labels[schema.NODE], name)
+		case "REL":
+			s.Labels[schema.RELATIONSHIP] = append(s.Labels[schema.RELATIONSHIP], name)
+		default:
+			continue
+		}
+
+		if err := d.populateProperties(name, s); err != nil {
+			logrus.Errorf("Couldn't get properties of table %s for schema - %v", name, err)
+			return nil, err
+		}
+	}
+
+	s.Labels[schema.ANY] = append(s.Labels[schema.RELATIONSHIP], s.Labels[schema.NODE]...)
+
+	return s, nil
+}
+
+// populateProperties adds the properties of the passed table to the schema, omitting primary keys.
+func (d *Driver) populateProperties(table string, s *schema.Schema) error {
+	res, err := d.conn.Query(fmt.Sprintf("CALL table_info('%s') RETURN *", table))
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	for res.HasNext() {
+		tuple, err := res.Next()
+		if err != nil {
+			return err
+		}
+		column, err := tuple.GetAsMap()
+		tuple.Close()
+		if err != nil {
+			return err
+		}
+
+		if isPrimaryKey, _ := column["primary key"].(bool); isPrimaryKey {
+			continue
+		}
+		propType, ok := kuzuTypeToPropertyType(column["type"].(string))
+		if !ok {
+			logrus.Debugf("Skipping property %v of unsupported type %v", column["name"], column["type"])
+			continue
+		}
+		s.AddProperty(schema.Property{
+			Name: column["name"].(string),
+			Type: propType,
+		})
+	}
+	return nil
+}
+
+// GetQueryResultType evaluates the produced result and returns the type the result indicates.
+func (d *Driver) GetQueryResultType(res dbms.QueryResult, errorMessageRegex *dbms.ErrorMessageRegex) dbms.QueryResultType {
+	err := res.ProducedError
+	if err == nil {
+		return dbms.Valid
+	}
+
+	if err.Error() == "Interrupted." {
+		return dbms.Timeout
+	}
+	// go-kuzu only returns the error message
+	if resultType, ok := errorMessageRegex.Classify(err.Error()); ok {
+		return resultType
+	}
+	return dbms.Bug
+}
+
+// DiscardQuery returns true with probability 1/10 or if the query produced a non-nil error,
+// else it returns false. Thereby causing queries to have an expected amount of 11 statements
+// if they don't produce an error.
+func (d *Driver) DiscardQuery(res dbms.QueryResult, seed *seed.Seed) bool {
+	if res.ProducedError != nil {
+		return true
+	}
+
+	return seed.BooleanWithProbability(0.1)
+}
+
+// VerifyConnectivity checks whether the database can still answer queries.
+//
+// As Kùzu runs in-process, a crashing database takes down the fuzzer with it.
+// Crashes thus can't be detected, only closed connections.
+func (d *Driver) VerifyConnectivity(opts dbms.DBOptions) (bool, error) {
+	if d.conn == nil {
+		return false, errors.New("database not opened")
+	}
+	if _, err := d.query("RETURN 1"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// IsEqualResult returns whether the two passed results equal
+func (d *Driver) IsEqualResult(a dbms.QueryResult, b dbms.QueryResult) bool {
+	return dbms.DefaultComparator.EqualResults(a, b)
+}
@@ -0,0 +1,135 @@
+package kuzu
+
+import (
+	"reflect"
+	"strings"
+
+	kuzuclauses "github.com/Anon10214/dinkel/models/kuzu/clauses"
+	"github.com/Anon10214/dinkel/models/opencypher/clauses"
+	"github.com/Anon10214/dinkel/models/opencypher/config"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/seed"
+	"github.com/Anon10214/dinkel/translator"
+	"github.com/Anon10214/dinkel/translator/helperclauses"
+)
+
+// Implementation for Kùzu
+type Implementation struct{}
+
+// GetDropIns returns the clause drop-ins for the Kùzu implementation
+func (Implementation) GetDropIns() translator.DropIns {
+	return translator.DropIns{
+		// Every node and relationship belongs to exactly one table, labels are the tables' names
+		reflect.TypeOf(&clauses.NewLabel{}): func(c translator.Clause, seed *seed.Seed, s *schema.Schema) translator.Clause {
+			return &kuzuclauses.ExistingLabel{LabelType: c.(*clauses.NewLabel).LabelType}
+		},
+		reflect.TypeOf(&clauses.ExistingLabel{}): func(c translator.Clause, seed *seed.Seed, s *schema.Schema) translator.Clause {
+			return &kuzuclauses.ExistingLabel{LabelType: c.(*clauses.ExistingLabel).LabelType}
+		},
+		reflect.TypeOf(&clauses.Labels{}): func(c translator.Clause, seed *seed.Seed, s *schema.Schema) translator.Clause {
+			return &clauses.Label{LabelType: c.(*clauses.Labels).LabelType}
+		},
+		reflect.TypeOf(&clauses.LabelMatch{}): func(c translator.Clause, seed *seed.Seed, s *schema.Schema) translator.Clause {
+			return &clauses.LabelName{LabelType: c.(*clauses.LabelMatch).LabelType}
+		},
+		// A node's table can't be changed
+		reflect.TypeOf(&clauses.SetLabelExpression{}): func(translator.Clause, *seed.Seed, *schema.Schema) translator.Clause {
+			return &kuzuclauses.SetPropertyExpression{}
+		},
+		reflect.TypeOf(&clauses.RemoveLabelExpression{}): func(translator.Clause, *seed.Seed, *schema.Schema) translator.Clause {
+			return &kuzuclauses.RemovePropertyExpression{}
+		},
+
+		// Properties are the tables' columns, which all tables share
+		reflect.TypeOf(&clauses.NewProperty{}): func(translator.Clause, *seed.Seed, *schema.Schema) translator.Clause {
+			return &kuzuclauses.Property{}
+		},
+		reflect.TypeOf(&clauses.ExistingProperty{}): func(translator.Clause, *seed.Seed, *schema.Schema) translator.Clause {
+			return &kuzuclauses.Property{}
+		},
+		reflect.TypeOf(&clauses.PropertyName{}): func(translator.Clause, *seed.Seed, *schema.Schema) translator.Clause {
+			return &kuzuclauses.PropertyName{}
+		},
+		reflect.TypeOf(&clauses.SetPropertyExpression{}): func(translator.Clause, *seed.Seed, *schema.Schema) translator.Clause {
+			return &kuzuclauses.SetPropertyExpression{}
+		},
+
+		// REMOVE is not supported
+		reflect.TypeOf(&clauses.RemoveClause{}): func(translator.Clause, *seed.Seed, *schema.Schema) translator.Clause {
+			return &kuzuclauses.RemoveClause{}
+		},
+		reflect.TypeOf(&clauses.RemovePropertyExpression{}): func(translator.Clause, *seed.Seed, *schema.Schema) translator.Clause {
+			return &kuzuclauses.RemovePropertyExpression{}
+		},
+
+		// Exponents can't have an explicit sign, 1e+10 has to be written as 1e10
+		reflect.TypeOf(&clauses.PropertyLiteral{}): func(c translator.Clause, seed *seed.Seed, s *schema.Schema) translator.Clause {
+			subclauses := c.Generate(seed, s)
+			return helperclauses.CreateAssembler(strings.Replace(c.(*clauses.PropertyLiteral).TemplateString(), "e+", "e", 1), subclauses...)
+		},
+
+		// The parser rejects a space before the comma separating sort items, always add the sort order
+		reflect.TypeOf(&clauses.OrderByExpression{}): func(c translator.Clause, seed *seed.Seed, s *schema.Schema) translator.Clause {
+			return helperclauses.CreateAssembler("%s "+seed.RandomStringFromChoice("ASC", "DESC"), &clauses.Expression{Conf: schema.ExpressionConfig{AllowMaps: true}})
+		},
+
+		// Sorting with a large SKIP and a LIMIT segfaults Kùzu, taking down the fuzzer with it
+		reflect.TypeOf(&clauses.OptionalSkip{}): func(c translator.Clause, seed *seed.Seed, s *schema.Schema) translator.Clause {
+			c.(*clauses.OptionalSkip).MaxValue = 1000
+			return c
+		},
+
+		// List comprehensions with a WHERE clause are unsupported, those without one don't bind the variable
+		reflect.TypeOf(&clauses.ListComprehension{}): func(c translator.Clause, seed *seed.Seed, s *schema.Schema) translator.Clause {
+			return &clauses.Expression{Conf: c.(*clauses.ListComprehension).Conf}
+		},
+
+		// FOREACH is unsupported
+		reflect.TypeOf(&clauses.Foreach{}): func(translator.Clause, *seed.Seed, *schema.Schema) translator.Clause {
+			return &clauses.ReadClause{}
+		},
+
+		// CALL subqueries are unsupported
+		reflect.TypeOf(&clauses.CallSubquery{}): func(translator.Clause, *seed.Seed, *schema.Schema) translator.Clause {
+			return &clauses.ReadClause{}
+		},
+
+		// Subquery expressions have to start with MATCH
+		reflect.TypeOf(&clauses.Exists{}): func(translator.Clause, *seed.Seed, *schema.Schema) translator.Clause {
+			return &clauses.Expression{Conf: schema.ExpressionConfig{
+				TargetType:   schema.PropertyValue,
+				PropertyType: schema.Boolean,
+			}}
+		},
+		reflect.TypeOf(&clauses.Count{}): func(translator.Clause, *seed.Seed, *schema.Schema) translator.Clause {
+			return &clauses.Expression{Conf: schema.ExpressionConfig{
+				TargetType:   schema.PropertyValue,
+				PropertyType: schema.Integer,
+			}}
+		},
+	}
+}
+
+// GetOpenCypherConfig returns the generation config for the Kùzu implementation
+func (Implementation) GetOpenCypherConfig() config.Config {
+	return config.Config{
+		OnlyVariablesAsWriteTarget: true,
+
+		AsteriskNeedsTargets: true,
+
+		DisallowedPropertyTypes: []schema.PropertyType{
+			// The tables only hold properties of the remaining types
+			schema.Date, schema.Datetime, schema.Duration, schema.LocalDateTime, schema.LocalTime, schema.Time,
+			// Kùzu does not support points.
+			schema.Point,
+		},
+
+		DisallowedFunctions: []string{
+			// Functions Kùzu doesn't implement (under this name)
+			"e", "endNode", "exp", "head", "last", "percentileCont", "percentileDisc", "replace", "split", "startNode",
+			"stdev", "stdevp", "tail", "toBoolean", "toFloat", "toInteger", "toString", "type",
+			// Passing them a null literal segfaults Kùzu, taking down the fuzzer with it
+			"keys", "labels", "length", "nodes", "properties", "relationships",
+		},
+	}
+}
@@ -0,0 +1,77 @@
+package kuzu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+)
+
+// Kùzu requires every node and relationship to belong to a table declared beforehand.
+// These tables get created on every reset, the generated labels are restricted to them.
+var (
+	nodeTables = []string{"N0", "N1", "N2"}
+	relTables  = []string{"R0", "R1"}
+)
+
+// The properties every node and relationship table holds, along with their Kùzu type.
+var tableProperties = []struct {
+	name     string
+	kuzuType string
+}{
+	{"p_boolean", "BOOL"},
+	{"p_integer", "INT64"},
+	{"p_float", "DOUBLE"},
+	{"p_string", "STRING"},
+	{"p_boolean_list", "BOOL[]"},
+	{"p_integer_list", "INT64[]"},
+	{"p_float_list", "DOUBLE[]"},
+	{"p_string_list", "STRING[]"},
+}
+
+// createTableStatements returns the DDL statements creating all node and relationship tables.
+//
+// Each node table has a serial primary key, each relationship table may connect nodes of any two node tables.
+func createTableStatements() []string {
+	var columns []string
+	for _, property := range tableProperties {
+		columns = append(columns, property.name+" "+property.kuzuType)
+	}
+
+	var statements []string
+	for _, table := range nodeTables {
+		statements = append(statements, fmt.Sprintf("CREATE NODE TABLE %s(id SERIAL PRIMARY KEY, %s)", table, strings.Join(columns, ", ")))
+	}
+	for _, table := range relTables {
+		var connections []string
+		for _, from := range nodeTables {
+			for _, to := range nodeTables {
+				connections = append(connections, fmt.Sprintf("FROM %s TO %s", from, to))
+			}
+		}
+		statements = append(statements, fmt.Sprintf("CREATE REL TABLE %s(%s, %s)", table, strings.Join(connections, ", "), strings.Join(columns, ", ")))
+	}
+	return statements
+}
+
+// kuzuTypeToPropertyType maps a column type as returned by Kùzu's table_info function to its equivalent [schema.PropertyType].
+//
+// Returns false if the type has no equivalent.
+func kuzuTypeToPropertyType(kuzuType string) (schema.PropertyType, bool) {
+	var mask int
+	if elemType, found := strings.CutSuffix(kuzuType, "[]"); found {
+		kuzuType = elemType
+		mask = schema.ListMask
+	}
+
+	propType, found := map[string]schema.PropertyType{
+		"BOOL":      schema.Boolean,
+		"DATE":      schema.Date,
+		"DOUBLE":    schema.Float,
+		"INT64":     schema.Integer,
+		"INTERVAL":  schema.Duration,
+		"STRING":    schema.String,
+		"TIMESTAMP": schema.LocalDateTime,
+	}[kuzuType]
+	return propType | schema.PropertyType(mask), found
+}
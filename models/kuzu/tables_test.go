@@ -0,0 +1,36 @@
+package kuzu
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateTableStatements(t *testing.T) {
+	statements := createTableStatements()
+	if !assert.Len(t, statements, len(nodeTables)+len(relTables)) {
+		return
+	}
+
+	assert.True(t, strings.HasPrefix(statements[0], "CREATE NODE TABLE N0(id SERIAL PRIMARY KEY, p_boolean BOOL, "), statements[0])
+	// Relationship tables are created last, as they reference the node tables
+	last := statements[len(statements)-1]
+	assert.True(t, strings.HasPrefix(last, "CREATE REL TABLE R1(FROM N0 TO N0, FROM N0 TO N1, "), last)
+	assert.True(t, strings.HasSuffix(last, "p_string_list STRING[])"), last)
+}
+
+func TestKuzuTypeToPropertyType(t *testing.T) {
+	for _, property := range tableProperties {
+		_, ok := kuzuTypeToPropertyType(property.kuzuType)
+		assert.True(t, ok, "Type %s of property %s is unknown", property.kuzuType, property.name)
+	}
+
+	propType, ok := kuzuTypeToPropertyType("STRING[]")
+	assert.True(t, ok)
+	assert.Equal(t, schema.String|schema.PropertyType(schema.ListMask), propType)
+
+	_, ok = kuzuTypeToPropertyType("SERIAL")
+	assert.False(t, ok)
+}
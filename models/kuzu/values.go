@@ -0,0 +1,107 @@
+//go:build kuzu
+
+package kuzu
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/kuzudb/go-kuzu"
+)
+
+// toRow converts the values of a tuple returned by go-kuzu into a row.
+func toRow(values []any) dbms.Row {
+	row := make(dbms.Row, len(values))
+	for i, v := range values {
+		row[i] = toValue(v)
+	}
+	return row
+}
+
+// toValue converts a value returned by go-kuzu into its [dbms.Value].
+//
+// go-kuzu returns both dates and timestamps as [time.Time], they are thus both converted into a [dbms.DateTime].
+func toValue(v any) dbms.Value {
+	switch v := v.(type) {
+	case []any:
+		list := make(dbms.List, len(v))
+		for i, elem := range v {
+			list[i] = toValue(elem)
+		}
+		return list
+	case map[string]any:
+		return toMap(v)
+	case []kuzu.MapItem:
+		// Keys of Kùzu maps may be of any type
+		m := make(dbms.Map, len(v))
+		for _, item := range v {
+			m[fmt.Sprint(item.Key)] = toValue(item.Value)
+		}
+		return m
+	case time.Time:
+		return dbms.DateTime{Time: v}
+	case kuzu.Node:
+		return toNode(v)
+	case kuzu.Relationship:
+		return toRelationship(v)
+	case kuzu.RecursiveRelationship:
+		path := dbms.Path{}
+		for _, node := range v.Nodes {
+			path.Nodes = append(path.Nodes, toNode(node))
+		}
+		for _, rel := range v.Relationships {
+			path.Relationships = append(path.Relationships, toRelationship(rel))
+		}
+		return path
+	}
+	if value, ok := dbms.ScalarValue(v); ok {
+		return value
+	}
+	return dbms.UnknownValue(v)
+}
+
+// addToGraph adds the nodes and relationships found in a value returned by go-kuzu to the graph.
+// Nodes and relationships may be nested in lists, maps and recursive relationships.
+//
+// go-kuzu doesn't expose the IDs of relationships, they are thus numbered in the order they are added.
+// Every relationship must therefore only be added once.
+func addToGraph(graph *dbms.Graph, v any) {
+	switch v := v.(type) {
+	case []any:
+		for _, elem := range v {
+			addToGraph(graph, elem)
+		}
+	case map[string]any:
+		for _, elem := range v {
+			addToGraph(graph, elem)
+		}
+	case kuzu.Node:
+		graph.AddNode(fmt.Sprint(v.ID), toNode(v))
+	case kuzu.Relationship:
+		graph.AddRelationship(fmt.Sprint(len(graph.Relationships)), fmt.Sprint(v.SourceID), fmt.Sprint(v.DestinationID), toRelationship(v))
+	case kuzu.RecursiveRelationship:
+		for _, node := range v.Nodes {
+			addToGraph(graph, node)
+		}
+		for _, rel := range v.Relationships {
+			addToGraph(graph, rel)
+		}
+	}
+}
+
+func toMap(m map[string]any) dbms.Map {
+	res := make(dbms.Map, len(m))
+	for k, v := range m {
+		res[k] = toValue(v)
+	}
+	return res
+}
+
+func toNode(n kuzu.Node) dbms.Node {
+	return dbms.Node{Labels: []string{n.Label}, Properties: toMap(n.Properties)}
+}
+
+func toRelationship(r kuzu.Relationship) dbms.Relationship {
+	return dbms.Relationship{Type: r.Label, Properties: toMap(r.Properties)}
+}
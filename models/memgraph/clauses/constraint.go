@@ -51,8 +51,8 @@ func (c *RelationshipConstraint) TemplateString() string {
 
 // ConstraintPropertyChain represents a chain of properties over which the constraints are set.
 type ConstraintPropertyChain struct {
-	VarName    string
-	isBasecase bool
+	VarName    string `ast:"varName"`
+	isBasecase bool   `ast:"isBasecase"`
 }
 
 // Generate subclauses for ConstraintPropertyChain
@@ -74,7 +74,7 @@ func (c ConstraintPropertyChain) TemplateString() string {
 
 // A ConstraintProperty is a single property over which the constraint is set.
 type ConstraintProperty struct {
-	VarName string
+	VarName string `ast:"varName"`
 }
 
 // Generate subclauses for ConstraintProperty
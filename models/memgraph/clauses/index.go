@@ -12,8 +12,8 @@ import (
 // Index is the Neo4j implementation for creating or dropping a database index
 // as well as for creating constraints on properties.
 type Index struct {
-	generateConstraint bool
-	indexType          string
+	generateConstraint bool   `ast:"generateConstraint"`
+	indexType          string `ast:"indexType"`
 }
 
 // Generate subclauses for Index
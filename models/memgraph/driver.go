@@ -28,9 +28,14 @@ func (d *Driver) Init(opts dbms.DBOptions) error {
 	if opts.Port != nil {
 		connPort = *opts.Port
 	}
-	driver, err := neo4j.NewDriverWithContext(fmt.Sprintf("bolt://%s:%d", opts.Host, connPort), neo4j.NoAuth(), func(c *neo4j.Config) {
+	tlsConfig, err := opts.TLS.Config()
+	if err != nil {
+		return err
+	}
+	driver, err := neo4j.NewDriverWithContext(neo4jimpl.TargetURI("bolt", connPort, opts), neo4jimpl.AuthToken(opts), func(c *neo4j.Config) {
 		c.ConnectionAcquisitionTimeout = opts.Timeout
 		c.MaxTransactionRetryTime = 0
+		c.TlsConfig = tlsConfig
 	})
 	if err != nil {
 		return err
@@ -47,11 +52,11 @@ func (d *Driver) Init(opts dbms.DBOptions) error {
 	return nil
 }
 
-// Reset the database
+// Reset the database passed as the namespace, or the default database if none was passed
 func (d *Driver) Reset(opts dbms.DBOptions) error {
 	logrus.Debug("Resetting Database")
 	ctx := context.Background()
-	d.session = d.driver.NewSession(context.Background(), neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	d.session = d.driver.NewSession(context.Background(), neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite, DatabaseName: opts.Namespace})
 
 	// Delete all nodes and edges
 	if _, err := d.session.Run(ctx, "MATCH (n) DETACH DELETE n", nil, neo4j.WithTxTimeout(opts.Timeout)); err != nil {
@@ -60,55 +65,128 @@ func (d *Driver) Reset(opts dbms.DBOptions) error {
 	}
 
 	// Delete all constraints and indexes
+	if err := d.dropSchema(opts); err != nil {
+		logrus.Errorf("couldn't reset database - %v", err)
+		return err
+	}
 
 	return nil
 }
 
 // RunQuery runs the query against the memgraph DB and returns its result.
-func (d Driver) RunQuery(opts dbms.DBOptions, query string) dbms.QueryResult {
-	ctx := context.Background()
+//
+// Once the context is done, the query's transactions get terminated.
+func (d Driver) RunQuery(ctx context.Context, opts dbms.DBOptions, query string) dbms.QueryResult {
 	logrus.Debug("Sending query to database")
+	queryID := neo4jimpl.NewQueryID()
+	stop := context.AfterFunc(ctx, func() {
+		if err := d.terminateQuery(opts, queryID); err != nil {
+			logrus.Warnf("Couldn't terminate cancelled query - %v", err)
+		}
+	})
+	defer stop()
 
 	var queryResult dbms.QueryResult
 	var res neo4j.ResultWithContext
 	var err error
 
 	// Run the query
-	if res, err = d.session.Run(ctx, query, nil, neo4j.WithTxTimeout(opts.Timeout)); err != nil {
+	if res, err = d.session.Run(ctx, query, nil, neo4j.WithTxTimeout(opts.Timeout), neo4jimpl.WithQueryID(queryID)); err != nil {
 		queryResult = dbms.QueryResult{
 			ProducedError: err,
 		}
 		logrus.Debugf("Error %v produced when running query %s", err, query)
 		return queryResult
 	}
-	for res.Next(ctx) {
-		queryResult.Rows = append(queryResult.Rows, res.Record().Values)
-	}
+	if strings.HasPrefix(query, dbms.ProfilePrefix) {
+		// Memgraph only returns the plan of profiled queries, the query's rows are not returned
+		var planRows [][]any
+		for res.Next(ctx) {
+			planRows = append(planRows, res.Record().Values)
+		}
+		if res.Err() != nil {
+			queryResult.ProducedError = res.Err()
+			logrus.Debugf("Error %v produced when profiling query %s", res.Err(), query)
+			return queryResult
+		}
+		profile, err := parseProfile(planRows)
+		if err != nil {
+			logrus.Warnf("Couldn't parse profiled plan - %v", err)
+		}
 
-	queryResult.ProducedError = res.Err()
-	if res.Err() != nil {
-		logrus.Debugf("Error %v produced when running query %s", err, query)
+		// Count the returned rows by running the query again without profiling it.
+		// Profiled queries only read from the graph, so running them twice leaves it unchanged.
+		if res, err = d.session.Run(ctx, strings.TrimPrefix(query, dbms.ProfilePrefix), nil, neo4j.WithTxTimeout(opts.Timeout), neo4jimpl.WithQueryID(queryID)); err != nil {
+			queryResult.ProducedError = err
+			logrus.Debugf("Error %v produced when running profiled query %s", err, query)
+			return queryResult
+		}
+		if queryResult, _ = neo4jimpl.CollectResult(ctx, res); queryResult.ProducedError != nil {
+			logrus.Debugf("Error %v produced when running profiled query %s", queryResult.ProducedError, query)
+			return queryResult
+		}
+		if profile != nil {
+			profile.ReturnedRows = int64(len(queryResult.Rows))
+			queryResult.Profile = profile
+		}
+	} else if queryResult, _ = neo4jimpl.CollectResult(ctx, res); queryResult.ProducedError != nil {
+		logrus.Debugf("Error %v produced when running query %s", queryResult.ProducedError, query)
 		return queryResult
 	}
 
-	// Get query result schema
-	queryResult.Schema = []any{}
-	if res, err = d.session.Run(ctx, "MATCH (n) RETURN n AS x UNION MATCH ()-[m]-() RETURN m AS x", nil, neo4j.WithTxTimeout(opts.Timeout)); err != nil {
+	// Get the graph to compare it when fuzzing for logic bugs
+	if res, err = d.session.Run(ctx, "MATCH (n) RETURN n AS x UNION MATCH ()-[m]->() RETURN m AS x", nil, neo4j.WithTxTimeout(opts.Timeout), neo4jimpl.WithQueryID(queryID)); err != nil {
 		queryResult.ProducedError = err
 		logrus.Debugf("Error %v produced when trying to get schema", err)
 		return queryResult
 	}
+	var records [][]any
 	for res.Next(ctx) {
-		queryResult.Schema = append(queryResult.Schema.([]any), res.Record().Values)
+		records = append(records, res.Record().Values)
 	}
 	if res.Err() != nil {
-		logrus.Debugf("Error %v produced when trying to get schema", err)
+		logrus.Debugf("Error %v produced when trying to get schema", res.Err())
 	}
+	queryResult.Graph = neo4jimpl.ToGraph(records)
 
 	logrus.Debug("Query finished")
 	return queryResult
 }
 
+// terminateQuery terminates the running transactions tagged with the query ID.
+//
+// Unlike Neo4j, memgraph doesn't allow filtering the transactions it shows,
+// so they are filtered by their metadata here.
+func (d Driver) terminateQuery(opts dbms.DBOptions, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	session := d.driver.NewSession(ctx, neo4j.SessionConfig{DatabaseName: opts.Namespace})
+	defer session.Close(ctx)
+
+	res, err := session.Run(ctx, "SHOW TRANSACTIONS", nil)
+	if err != nil {
+		return err
+	}
+	var transactionIDs []string
+	for res.Next(ctx) {
+		transactionID, _ := res.Record().Get("transaction_id")
+		metadata, _ := res.Record().Get("metadata")
+		if neo4jimpl.QueryIDOf(metadata) == id {
+			transactionIDs = append(transactionIDs, fmt.Sprintf("'%v'", transactionID))
+		}
+	}
+	if err := res.Err(); err != nil || len(transactionIDs) == 0 {
+		return err
+	}
+
+	res, err = session.Run(ctx, "TERMINATE TRANSACTIONS "+strings.Join(transactionIDs, ", "), nil)
+	if err != nil {
+		return err
+	}
+	_, err = res.Consume(ctx)
+	return err
+}
+
 // GetSchema returns the database's current schema
 func (d Driver) GetSchema(opts dbms.DBOptions) (*schema.Schema, error) {
 	s := &schema.Schema{}
@@ -138,6 +216,12 @@ func (d Driver) GetSchema(opts dbms.DBOptions) (*schema.Schema, error) {
 
 	s.Labels[schema.ANY] = append(s.Labels[schema.RELATIONSHIP], s.Labels[schema.NODE]...)
 
+	if err := d.populateProperties(opts, s); err != nil {
+		logrus.Errorf("Couldn't get properties for schema - %v", err)
+		return nil, err
+	}
+	logrus.Tracef("Populated schema with properties %v", s.Properties)
+
 	return s, nil
 }
 
@@ -157,12 +241,9 @@ func (d Driver) GetQueryResultType(res dbms.QueryResult, errorMessageRegex *dbms
 		}
 
 		if err.Title() == "MemgraphError" {
-			if errorMessageRegex.Ignored.MatchString(err.Msg) {
-				return dbms.Invalid
-			}
-
-			if errorMessageRegex.Reported.MatchString(err.Msg) {
-				return dbms.ReportedBug
+			// Memgraph's codes only differ in their class, e.g. ClientError or DatabaseError, allow matching it on its own
+			if resultType, ok := errorMessageRegex.Classify(err.Msg, err.Code, err.Classification()); ok {
+				return resultType
 			}
 		}
 		logrus.Warnf("Encountered Neo4jError with error title %q and msg %s", err.Title(), err.Msg)
@@ -194,36 +275,7 @@ func (d Driver) VerifyConnectivity(opts dbms.DBOptions) (bool, error) {
 	return err == nil, err
 }
 
-// IsEqualResult panics for memgraph, as it hasn't been implemented yet
-func (d Driver) IsEqualResult(a dbms.QueryResult, b dbms.QueryResult) bool {
-	if len(a.Rows) != len(b.Rows) {
-		logrus.Warn("Encountered mismatching results")
-		logrus.Infof("\n\t%v\nvs\n\t%v", a.Rows, b.Rows)
-		return false
-	}
-
-	// Check that result rows match
-	if !neo4jimpl.IsMatchingRows(a.Rows, b.Rows) {
-		logrus.Warn("Encountered mismatching rows")
-		logrus.Infof("\n\t%v\nvs\n\t%v", a.Rows, b.Rows)
-		return false
-	}
-
-	// Check if the schemas match
-	if !neo4jimpl.IsMatchingRows(a.Schema, b.Schema) {
-		logrus.Warnf("Mismatching Schemas")
-		logrus.Infof("Schemas:\n\t%+v\nvs\n\t%+v", a.Schema, b.Schema)
-		return false
-	}
-
-	if a.ProducedError != nil || b.ProducedError != nil {
-		if a.ProducedError == nil || b.ProducedError == nil {
-			return false
-		}
-		if a.ProducedError.Error() != b.ProducedError.Error() {
-			return false
-		}
-	}
-
-	return true
+// IsEqualResult returns true if the two passed query results hold the same information, else false.
+func (d Driver) IsEqualResult(a, b dbms.QueryResult) bool {
+	return dbms.DefaultComparator.EqualResults(a, b)
 }
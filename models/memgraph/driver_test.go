@@ -0,0 +1,102 @@
+package memgraph
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/fakeserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startDriver starts a fake memgraph answering queries using the passed handler and returns a driver connected to it.
+func startDriver(t *testing.T, handler fakeserver.BoltHandler) (*Driver, *fakeserver.BoltServer, dbms.DBOptions) {
+	server := fakeserver.StartBolt(t, handler)
+	d, opts, err := fakeserver.InitDriver(server, &Driver{})
+	require.NoError(t, err)
+	t.Cleanup(func() { d.driver.Close(context.Background()) })
+	return d, server, opts
+}
+
+func TestInit(t *testing.T) {
+	_, server, _ := startDriver(t, nil)
+	assert.Equal(t, []string{`SET DATABASE SETTING "query.timeout" TO "5.000000";`}, server.Received())
+}
+
+func TestReset(t *testing.T) {
+	d, server, opts := startDriver(t, func(query string, params map[string]any) fakeserver.BoltResult {
+		switch query {
+		case "SHOW INDEX INFO":
+			return fakeserver.BoltResult{
+				Columns: []string{"index type", "label", "property", "count"},
+				Rows: [][]any{
+					{"label", "A", nil, int64(1)},
+					{"label+property", "A", "p", int64(1)},
+				},
+			}
+		case "SHOW CONSTRAINT INFO":
+			return fakeserver.BoltResult{
+				Columns: []string{"constraint type", "label", "properties"},
+				Rows:    [][]any{{"unique", "B", []any{"p", "q"}}},
+			}
+		case "SHOW TRIGGERS":
+			return fakeserver.BoltResult{Columns: []string{"trigger name"}}
+		case "SHOW STREAMS":
+			// Streams are only supported by the enterprise edition
+			return fakeserver.BoltResult{Code: "Memgraph.ClientError.MemgraphError.MemgraphError", Message: "streams are not supported"}
+		}
+		return fakeserver.BoltResult{}
+	})
+
+	assert.NoError(t, d.Reset(opts))
+	assert.Equal(t, []string{
+		`SET DATABASE SETTING "query.timeout" TO "5.000000";`,
+		"MATCH (n) DETACH DELETE n",
+		"SHOW INDEX INFO",
+		"DROP INDEX ON :`A`;",
+		"DROP INDEX ON :`A`(`p`);",
+		"SHOW CONSTRAINT INFO",
+		"DROP CONSTRAINT ON (n:`B`) ASSERT n.`p`, n.`q` IS UNIQUE;",
+		"SHOW TRIGGERS",
+		"SHOW STREAMS",
+	}, server.Received())
+}
+
+func TestGetQueryResultType(t *testing.T) {
+	d, _, opts := startDriver(t, func(query string, params map[string]any) fakeserver.BoltResult {
+		switch query {
+		case "RETURN 1 / 0":
+			return fakeserver.BoltResult{Code: "Memgraph.ClientError.MemgraphError.MemgraphError", Message: "Division by zero"}
+		case "RETURN 'reported'":
+			return fakeserver.BoltResult{Code: "Memgraph.DatabaseError.MemgraphError.MemgraphError", Message: "reported"}
+		case "RETURN 'slow'":
+			return fakeserver.BoltResult{Code: "Memgraph.ClientError.MemgraphError.MemgraphError", Message: "Transaction was asked to abort because of transaction timeout."}
+		case "RETURN 'unexpected'":
+			return fakeserver.BoltResult{Code: "Memgraph.ClientError.MemgraphError.MemgraphError", Message: "unexpected"}
+		}
+		return fakeserver.BoltResult{}
+	})
+	assert.NoError(t, d.Reset(opts))
+	regex := &dbms.ErrorMessageRegex{
+		Ignored:       regexp.MustCompile("^Division by zero$"),
+		ReportedCodes: []string{"DatabaseError"},
+	}
+
+	for _, tc := range []struct {
+		query    string
+		expected dbms.QueryResultType
+	}{
+		{"RETURN 1", dbms.Valid},
+		{"RETURN 1 / 0", dbms.Invalid},
+		{"RETURN 'reported'", dbms.ReportedBug},
+		{"RETURN 'slow'", dbms.Timeout},
+		{"RETURN 'unexpected'", dbms.Bug},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			res := d.RunQuery(context.Background(), opts, tc.query)
+			assert.Equal(t, tc.expected, d.GetQueryResultType(res, regex))
+		})
+	}
+}
@@ -57,10 +57,6 @@ func (Implementation) GetDropIns() translator.DropIns {
 			return c
 		},
 
-		reflect.TypeOf(&clauses.ExistingProperty{}): func(c translator.Clause, seed *seed.Seed, s *schema.Schema) translator.Clause {
-			return &clauses.NewProperty{}
-		},
-
 		reflect.TypeOf(&clauses.PropertyLiteral{}): func(c translator.Clause, s1 *seed.Seed, s2 *schema.Schema) translator.Clause {
 			clause := c.(*clauses.PropertyLiteral)
 			if clause.Conf.PropertyType == schema.Float {
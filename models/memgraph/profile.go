@@ -0,0 +1,92 @@
+package memgraph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Anon10214/dinkel/dbms"
+)
+
+// parseProfile builds the operator tree from the rows returned by a PROFILE query.
+//
+// Memgraph returns one row per operator, holding the operator's name in the first and its hits in the second column.
+// Nesting is encoded in the name: every level of nesting adds a "| " prefix and an operator's
+// additional inputs are introduced by a "|\" row, e.g.
+//
+//	"* Produce"
+//	"* Cartesian"
+//	"|\"
+//	"| * ScanAll"
+//	"| * Once"
+//	"* ScanAll"
+//	"* Once"
+//
+// An operator gets pulled once more than the amount of rows it produces, as the last pull signals its exhaustion.
+// The rows of an operator are therefore its hits minus one.
+func parseProfile(rows [][]any) (*dbms.ProfiledOperator, error) {
+	var root *dbms.ProfiledOperator
+	// The last operator encountered at each depth
+	var last []*dbms.ProfiledOperator
+	// Whether the next operator at the given depth starts a new branch of the operator one level above
+	startsBranch := map[int]bool{}
+
+	for i, row := range rows {
+		if len(row) < 2 {
+			return nil, fmt.Errorf("row %d holds %d instead of at least 2 columns", i, len(row))
+		}
+		name, ok := row[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("operator in row %d is of type %T instead of string", i, row[0])
+		}
+
+		depth := 0
+		for strings.HasPrefix(name, "| ") {
+			name = name[2:]
+			depth++
+		}
+
+		if name == `|\` {
+			startsBranch[depth+1] = true
+			continue
+		}
+
+		name, found := strings.CutPrefix(name, "* ")
+		if !found {
+			return nil, fmt.Errorf("couldn't parse operator %q in row %d", row[0], i)
+		}
+		hits, ok := row[1].(int64)
+		if !ok {
+			return nil, fmt.Errorf("hits in row %d are of type %T instead of int64", i, row[1])
+		}
+		op := &dbms.ProfiledOperator{Name: name, Rows: max(hits-1, 0)}
+
+		switch {
+		case root == nil:
+			if depth != 0 {
+				return nil, fmt.Errorf("first operator %q is nested", name)
+			}
+			root = op
+		case startsBranch[depth]:
+			if depth == 0 || last[depth-1] == nil {
+				return nil, fmt.Errorf("branch of operator %q in row %d has no parent", name, i)
+			}
+			last[depth-1].Children = append(last[depth-1].Children, op)
+			startsBranch[depth] = false
+		default:
+			if depth >= len(last) || last[depth] == nil {
+				return nil, fmt.Errorf("operator %q in row %d has no parent", name, i)
+			}
+			last[depth].Children = append(last[depth].Children, op)
+		}
+
+		for len(last) <= depth {
+			last = append(last, nil)
+		}
+		last[depth] = op
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("no operators in profiled plan")
+	}
+	return root, nil
+}
@@ -0,0 +1,57 @@
+package memgraph
+
+import (
+	"testing"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProfile_Chain(t *testing.T) {
+	profile, err := parseProfile([][]any{
+		{"* Produce", int64(3), " 20.0 %", " 0.001 ms"},
+		{"* Filter", int64(3), " 30.0 %", " 0.001 ms"},
+		{"* ScanAll", int64(5), " 40.0 %", " 0.001 ms"},
+		{"* Once", int64(2), " 10.0 %", " 0.001 ms"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &dbms.ProfiledOperator{Name: "Produce", Rows: 2, Children: []*dbms.ProfiledOperator{
+		{Name: "Filter", Rows: 2, Children: []*dbms.ProfiledOperator{
+			{Name: "ScanAll", Rows: 4, Children: []*dbms.ProfiledOperator{
+				{Name: "Once", Rows: 1},
+			}},
+		}},
+	}}, profile)
+}
+
+func TestParseProfile_Branches(t *testing.T) {
+	profile, err := parseProfile([][]any{
+		{"* Produce", int64(5), "", ""},
+		{"* Cartesian", int64(5), "", ""},
+		{`|\`, "", "", ""},
+		{"| * ScanAll", int64(3), "", ""},
+		{"| * Once", int64(2), "", ""},
+		{"* ScanAll", int64(3), "", ""},
+		{"* Once", int64(2), "", ""},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &dbms.ProfiledOperator{Name: "Produce", Rows: 4, Children: []*dbms.ProfiledOperator{
+		{Name: "Cartesian", Rows: 4, Children: []*dbms.ProfiledOperator{
+			{Name: "ScanAll", Rows: 2, Children: []*dbms.ProfiledOperator{{Name: "Once", Rows: 1}}},
+			{Name: "ScanAll", Rows: 2, Children: []*dbms.ProfiledOperator{{Name: "Once", Rows: 1}}},
+		}},
+	}}, profile)
+}
+
+func TestParseProfile_Malformed(t *testing.T) {
+	_, err := parseProfile(nil)
+	assert.Error(t, err, "Empty plan parsed")
+
+	_, err = parseProfile([][]any{{"| * Produce", int64(1)}})
+	assert.Error(t, err, "Nested root parsed")
+
+	_, err = parseProfile([][]any{{"Produce", int64(1)}})
+	assert.Error(t, err, "Operator without marker parsed")
+}
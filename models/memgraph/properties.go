@@ -0,0 +1,79 @@
+package memgraph
+
+import (
+	"context"
+	"slices"
+
+	"github.com/Anon10214/dinkel/dbms"
+	neo4jimpl "github.com/Anon10214/dinkel/models/neo4j"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/sirupsen/logrus"
+)
+
+// populateProperties fetches all properties in the DB and inserts them into the schema.
+//
+// The properties get sorted before being added, as the order of the returned rows isn't deterministic.
+func (d Driver) populateProperties(opts dbms.DBOptions, s *schema.Schema) error {
+	ctx := context.Background()
+	res, err := d.session.Run(ctx, `
+	MATCH (n) UNWIND keys(n) AS key RETURN key, valueType(n[key]) AS type, n[key] AS value
+		UNION
+	MATCH ()-[m]->() UNWIND keys(m) AS key RETURN key, valueType(m[key]) AS type, m[key] AS value
+	`, nil, neo4j.WithTxTimeout(opts.Timeout))
+	if err != nil {
+		return err
+	}
+
+	var properties []schema.Property
+	for res.Next(ctx) {
+		values := res.Record().Values
+		propType, ok := valueTypeToPropertyType(values[1].(string), values[2])
+		if !ok {
+			logrus.Debugf("Skipping property %v of unsupported type %v", values[0], values[1])
+			continue
+		}
+		property := schema.Property{
+			Name:  values[0].(string),
+			Type:  propType,
+			Value: schema.Literal(values[2], nil),
+		}
+		if !slices.Contains(properties, property) {
+			properties = append(properties, property)
+		}
+	}
+	if err := res.Err(); err != nil {
+		return err
+	}
+
+	slices.SortFunc(properties, schema.CompareProperties)
+	for _, property := range properties {
+		s.AddProperty(property)
+	}
+	return nil
+}
+
+// valueTypeToPropertyType maps a type as returned by memgraph's valueType function to its equivalent [schema.PropertyType].
+//
+// As valueType doesn't reveal the type of a list's elements, the element type is derived from the value itself.
+// Lists with elements of differing types are of type [schema.AnyType] with the list mask set.
+// Returns false if the type has no equivalent, as is the case for maps.
+func valueTypeToPropertyType(valueType string, value any) (schema.PropertyType, bool) {
+	if valueType != "LIST" {
+		propType, found := map[string]schema.PropertyType{
+			"BOOLEAN":         schema.Boolean,
+			"DATE":            schema.Date,
+			"DURATION":        schema.Duration,
+			"FLOAT":           schema.Float,
+			"INTEGER":         schema.Integer,
+			"LOCAL_DATE_TIME": schema.LocalDateTime,
+			"LOCAL_TIME":      schema.LocalTime,
+			"POINT":           schema.Point,
+			"STRING":          schema.String,
+			"ZONED_DATE_TIME": schema.Datetime,
+		}[valueType]
+		return propType, found
+	}
+
+	return neo4jimpl.PropertyTypeOf(value)
+}
@@ -0,0 +1,34 @@
+package memgraph
+
+import (
+	"testing"
+
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueTypeToPropertyType(t *testing.T) {
+	listMask := schema.PropertyType(schema.ListMask)
+	for _, tc := range []struct {
+		valueType string
+		value     any
+		expected  schema.PropertyType
+	}{
+		{"INTEGER", int64(1), schema.Integer},
+		{"STRING", "a", schema.String},
+		{"ZONED_DATE_TIME", nil, schema.Datetime},
+		{"LIST", []any{int64(1), int64(2)}, schema.Integer | listMask},
+		{"LIST", []any{neo4j.Date{}}, schema.Date | listMask},
+		{"LIST", []any{int64(1), "a"}, schema.AnyType | listMask},
+		{"LIST", []any{}, schema.AnyType | listMask},
+		{"LIST", []any{[]any{int64(1)}}, schema.AnyType | listMask},
+	} {
+		propType, ok := valueTypeToPropertyType(tc.valueType, tc.value)
+		assert.True(t, ok, tc.valueType)
+		assert.Equal(t, tc.expected, propType, tc.value)
+	}
+
+	_, ok := valueTypeToPropertyType("MAP", map[string]any{})
+	assert.False(t, ok)
+}
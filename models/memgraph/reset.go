@@ -0,0 +1,172 @@
+package memgraph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/sirupsen/logrus"
+)
+
+// dropSchema drops all indexes, constraints, triggers and streams present in the database.
+//
+// Indexes and constraints have to be dropped for a rerun to start from the same state the fuzzing run had.
+// Triggers and streams may not be supported by the target, failing to fetch them is thus only logged.
+func (d *Driver) dropSchema(opts dbms.DBOptions) error {
+	for _, info := range []struct {
+		query     string
+		dropQuery func(map[string]any) (string, error)
+		optional  bool
+	}{
+		{"SHOW INDEX INFO", dropIndexQuery, false},
+		{"SHOW CONSTRAINT INFO", dropConstraintQuery, false},
+		{"SHOW TRIGGERS", dropTriggerQuery, true},
+		{"SHOW STREAMS", dropStreamQuery, true},
+	} {
+		records, err := d.collect(opts, info.query)
+		if err != nil {
+			if info.optional {
+				logrus.Debugf("Couldn't run %s, skipping - %v", info.query, err)
+				continue
+			}
+			return fmt.Errorf("couldn't run %s - %v", info.query, err)
+		}
+
+		for _, record := range records {
+			dropQuery, err := info.dropQuery(record)
+			if err != nil {
+				logrus.Warnf("Couldn't drop %v - %v", record, err)
+				continue
+			}
+			if _, err := d.collect(opts, dropQuery); err != nil {
+				return fmt.Errorf("couldn't run %s - %v", dropQuery, err)
+			}
+		}
+	}
+	return nil
+}
+
+// collect runs the query and returns all records it produced as maps, indexed by the records' keys.
+func (d *Driver) collect(opts dbms.DBOptions, query string) ([]map[string]any, error) {
+	ctx := context.Background()
+	res, err := d.session.Run(ctx, query, nil, neo4j.WithTxTimeout(opts.Timeout))
+	if err != nil {
+		return nil, err
+	}
+	var records []map[string]any
+	for res.Next(ctx) {
+		record := res.Record()
+		values := make(map[string]any, len(record.Keys))
+		for i, key := range record.Keys {
+			values[key] = record.Values[i]
+		}
+		records = append(records, values)
+	}
+	return records, res.Err()
+}
+
+// dropIndexQuery returns the query dropping the index described by a record returned by SHOW INDEX INFO.
+func dropIndexQuery(record map[string]any) (string, error) {
+	indexType, _ := record["index type"].(string)
+	label, _ := record["label"].(string)
+	if label == "" {
+		return "", fmt.Errorf("index of type %q has no label", indexType)
+	}
+	properties, err := propertyList(record["property"], "")
+	if err != nil {
+		return "", err
+	}
+
+	switch indexType {
+	case "label":
+		return fmt.Sprintf("DROP INDEX ON :%s;", escapeName(label)), nil
+	case "label+property":
+		return fmt.Sprintf("DROP INDEX ON :%s(%s);", escapeName(label), properties), nil
+	case "edge-type":
+		return fmt.Sprintf("DROP EDGE INDEX ON :%s;", escapeName(label)), nil
+	case "edge-type+property":
+		return fmt.Sprintf("DROP EDGE INDEX ON :%s(%s);", escapeName(label), properties), nil
+	case "point":
+		return fmt.Sprintf("DROP POINT INDEX ON :%s(%s);", escapeName(label), properties), nil
+	}
+	return "", fmt.Errorf("unknown index type %q", indexType)
+}
+
+// dropConstraintQuery returns the query dropping the constraint described by a record returned by SHOW CONSTRAINT INFO.
+func dropConstraintQuery(record map[string]any) (string, error) {
+	constraintType, _ := record["constraint type"].(string)
+	label, _ := record["label"].(string)
+	if label == "" {
+		return "", fmt.Errorf("constraint of type %q has no label", constraintType)
+	}
+	properties, err := propertyList(record["properties"], "n.")
+	if err != nil {
+		return "", err
+	}
+
+	switch constraintType {
+	case "exists":
+		return fmt.Sprintf("DROP CONSTRAINT ON (n:%s) ASSERT EXISTS (%s);", escapeName(label), properties), nil
+	case "unique":
+		return fmt.Sprintf("DROP CONSTRAINT ON (n:%s) ASSERT %s IS UNIQUE;", escapeName(label), properties), nil
+	case "data_type":
+		dataType, _ := record["data_type"].(string)
+		if dataType == "" {
+			return "", fmt.Errorf("data type constraint on %s has no data type", label)
+		}
+		return fmt.Sprintf("DROP CONSTRAINT ON (n:%s) ASSERT %s IS TYPED %s;", escapeName(label), properties, dataType), nil
+	}
+	return "", fmt.Errorf("unknown constraint type %q", constraintType)
+}
+
+// dropTriggerQuery returns the query dropping the trigger described by a record returned by SHOW TRIGGERS.
+func dropTriggerQuery(record map[string]any) (string, error) {
+	name, _ := record["trigger name"].(string)
+	if name == "" {
+		return "", fmt.Errorf("trigger has no name")
+	}
+	return fmt.Sprintf("DROP TRIGGER %s;", escapeName(name)), nil
+}
+
+// dropStreamQuery returns the query dropping the stream described by a record returned by SHOW STREAMS.
+func dropStreamQuery(record map[string]any) (string, error) {
+	name, _ := record["name"].(string)
+	if name == "" {
+		return "", fmt.Errorf("stream has no name")
+	}
+	return fmt.Sprintf("DROP STREAM %s;", escapeName(name)), nil
+}
+
+// propertyList joins the property or list of properties returned by memgraph, prefixing every escaped property with the passed prefix.
+//
+// Returns the empty string if no properties are passed.
+func propertyList(properties any, prefix string) (string, error) {
+	var names []string
+	switch properties := properties.(type) {
+	case nil:
+	case string:
+		names = []string{properties}
+	case []any:
+		for _, property := range properties {
+			name, ok := property.(string)
+			if !ok {
+				return "", fmt.Errorf("property %v is of type %T instead of string", property, property)
+			}
+			names = append(names, name)
+		}
+	default:
+		return "", fmt.Errorf("properties %v are of unexpected type %T", properties, properties)
+	}
+
+	for i := range names {
+		names[i] = prefix + escapeName(names[i])
+	}
+	return strings.Join(names, ", "), nil
+}
+
+// escapeName escapes a label, property or other name using backticks.
+func escapeName(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
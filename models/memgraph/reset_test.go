@@ -0,0 +1,60 @@
+package memgraph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDropIndexQuery(t *testing.T) {
+	for _, tc := range []struct {
+		record   map[string]any
+		expected string
+	}{
+		{map[string]any{"index type": "label", "label": "L", "property": nil}, "DROP INDEX ON :`L`;"},
+		{map[string]any{"index type": "label+property", "label": "L", "property": "p"}, "DROP INDEX ON :`L`(`p`);"},
+		{map[string]any{"index type": "label+property", "label": "L", "property": []any{"p", "q"}}, "DROP INDEX ON :`L`(`p`, `q`);"},
+		{map[string]any{"index type": "edge-type", "label": "T", "property": nil}, "DROP EDGE INDEX ON :`T`;"},
+		{map[string]any{"index type": "edge-type+property", "label": "T", "property": "p"}, "DROP EDGE INDEX ON :`T`(`p`);"},
+		{map[string]any{"index type": "point", "label": "L", "property": "loc"}, "DROP POINT INDEX ON :`L`(`loc`);"},
+		{map[string]any{"index type": "label", "label": "a`b", "property": nil}, "DROP INDEX ON :`a``b`;"},
+	} {
+		query, err := dropIndexQuery(tc.record)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, query)
+	}
+
+	_, err := dropIndexQuery(map[string]any{"index type": "text", "label": "L", "property": "p"})
+	assert.Error(t, err)
+}
+
+func TestDropConstraintQuery(t *testing.T) {
+	for _, tc := range []struct {
+		record   map[string]any
+		expected string
+	}{
+		{map[string]any{"constraint type": "exists", "label": "L", "properties": "p"}, "DROP CONSTRAINT ON (n:`L`) ASSERT EXISTS (n.`p`);"},
+		{map[string]any{"constraint type": "unique", "label": "L", "properties": []any{"p", "q"}}, "DROP CONSTRAINT ON (n:`L`) ASSERT n.`p`, n.`q` IS UNIQUE;"},
+		{map[string]any{"constraint type": "data_type", "label": "L", "properties": "p", "data_type": "INTEGER"}, "DROP CONSTRAINT ON (n:`L`) ASSERT n.`p` IS TYPED INTEGER;"},
+	} {
+		query, err := dropConstraintQuery(tc.record)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, query)
+	}
+
+	_, err := dropConstraintQuery(map[string]any{"constraint type": "unique", "label": "L", "properties": []any{int64(1)}})
+	assert.Error(t, err)
+}
+
+func TestDropTriggerAndStreamQuery(t *testing.T) {
+	query, err := dropTriggerQuery(map[string]any{"trigger name": "my_trigger"})
+	assert.NoError(t, err)
+	assert.Equal(t, "DROP TRIGGER `my_trigger`;", query)
+
+	query, err = dropStreamQuery(map[string]any{"name": "my_stream"})
+	assert.NoError(t, err)
+	assert.Equal(t, "DROP STREAM `my_stream`;", query)
+
+	_, err = dropTriggerQuery(map[string]any{})
+	assert.Error(t, err)
+}
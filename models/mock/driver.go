@@ -1,9 +1,14 @@
 /*
-Package mock provides a mock implementation and driver for testing purposes.
+Package mock provides a mock implementation and drivers for testing purposes.
+
+The [Driver] answers every statement with an empty result,
+while the [ScriptedDriver] answers each statement as scripted, allowing to test the scheduler and strategies without a database.
 */
 package mock
 
 import (
+	"context"
+
 	"github.com/Anon10214/dinkel/dbms"
 	"github.com/Anon10214/dinkel/models/opencypher/schema"
 	"github.com/Anon10214/dinkel/seed"
@@ -23,7 +28,7 @@ func (d *Driver) Reset(opts dbms.DBOptions) error {
 }
 
 // RunQuery does nothing and returns an empty query result
-func (d Driver) RunQuery(opts dbms.DBOptions, query string) dbms.QueryResult {
+func (d Driver) RunQuery(ctx context.Context, opts dbms.DBOptions, query string) dbms.QueryResult {
 	return dbms.QueryResult{}
 }
 
@@ -35,7 +40,7 @@ func (d Driver) GetSchema(opts dbms.DBOptions) (*schema.Schema, error) {
 }
 
 // GetQueryResultType always returns [dbms.Valid]
-func (d Driver) GetQueryResultType(res dbms.QueryResult) dbms.QueryResultType {
+func (d Driver) GetQueryResultType(res dbms.QueryResult, errorMessageRegex *dbms.ErrorMessageRegex) dbms.QueryResultType {
 	return dbms.Valid
 }
 
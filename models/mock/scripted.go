@@ -0,0 +1,215 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/seed"
+)
+
+// A Step scripts how a [ScriptedDriver] answers a single statement.
+type Step struct {
+	// The result returned for the statement
+	Result dbms.QueryResult
+	// How long the statement runs before its result gets returned.
+	//
+	// If the context gets cancelled first, the statement is aborted and its result is the context's error.
+	Delay time.Duration
+	// If true, the statement keeps running after its context got cancelled,
+	// like a driver failing to abort a query
+	IgnoreCancellation bool
+	// How many of the following calls to VerifyConnectivity fail, simulating a crash caused by the statement
+	FailConnectivity int
+	// If true, DiscardQuery returns false for the statement's result, causing the query to be generated further
+	Continue bool
+}
+
+// A Call records a call made to a [ScriptedDriver].
+type Call struct {
+	// The name of the called method, e.g. "RunQuery"
+	Method string
+	// The statement passed to RunQuery, empty for other methods
+	Query string
+}
+
+// ScriptedDriver is a driver answering each statement as scripted, recording every call made to it.
+//
+// It allows testing the scheduler and strategies without a database. All methods are safe for concurrent use.
+type ScriptedDriver struct {
+	// Steps answer the statements run, in order. Once all steps are used up, statements are answered by Default.
+	Steps []Step
+	// The step answering statements once Steps are used up, returning an empty result by default
+	Default Step
+	// Errors returned by the calls to Init in order, nil once used up
+	InitErrors []error
+	// Errors returned by the calls to Reset in order, nil once used up
+	ResetErrors []error
+	// Returned by GetSchema if non-nil
+	SchemaError error
+	// How many of the following calls to VerifyConnectivity fail
+	ConnectivityFailures int
+
+	mu    sync.Mutex
+	calls []Call
+	// The index of the next step to answer a statement
+	nextStep int
+	// The step answering the last statement
+	lastStep Step
+}
+
+// Calls returns the calls made to the driver so far, in order.
+func (d *ScriptedDriver) Calls() []Call {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Call{}, d.calls...)
+}
+
+// Queries returns the statements run so far, in order.
+func (d *ScriptedDriver) Queries() []string {
+	var queries []string
+	for _, call := range d.Calls() {
+		if call.Method == "RunQuery" {
+			queries = append(queries, call.Query)
+		}
+	}
+	return queries
+}
+
+// CallCount returns how many times the method with the passed name got called.
+func (d *ScriptedDriver) CallCount(method string) int {
+	count := 0
+	for _, call := range d.Calls() {
+		if call.Method == method {
+			count++
+		}
+	}
+	return count
+}
+
+// record records a call, the caller has to hold the lock.
+func (d *ScriptedDriver) record(method, query string) {
+	d.calls = append(d.calls, Call{Method: method, Query: query})
+}
+
+// popError returns and removes the first of the passed errors, nil if there are none.
+func popError(errs *[]error) error {
+	if len(*errs) == 0 {
+		return nil
+	}
+	err := (*errs)[0]
+	*errs = (*errs)[1:]
+	return err
+}
+
+// Init returns the next of the scripted InitErrors.
+func (d *ScriptedDriver) Init(opts dbms.DBOptions) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.record("Init", "")
+	return popError(&d.InitErrors)
+}
+
+// Reset returns the next of the scripted ResetErrors.
+func (d *ScriptedDriver) Reset(opts dbms.DBOptions) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.record("Reset", "")
+	return popError(&d.ResetErrors)
+}
+
+// GetSchema returns a default, initialized schema, or the scripted SchemaError.
+func (d *ScriptedDriver) GetSchema(opts dbms.DBOptions) (*schema.Schema, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.record("GetSchema", "")
+	if d.SchemaError != nil {
+		return nil, d.SchemaError
+	}
+	s := &schema.Schema{}
+	s.Reset()
+	return s, nil
+}
+
+// RunQuery answers the statement using the next step.
+func (d *ScriptedDriver) RunQuery(ctx context.Context, opts dbms.DBOptions, query string) dbms.QueryResult {
+	d.mu.Lock()
+	d.record("RunQuery", query)
+	step := d.Default
+	if d.nextStep < len(d.Steps) {
+		step = d.Steps[d.nextStep]
+		d.nextStep++
+	}
+	d.lastStep = step
+	d.mu.Unlock()
+
+	timer := time.NewTimer(step.Delay)
+	defer timer.Stop()
+	if step.IgnoreCancellation {
+		<-timer.C
+	} else {
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return dbms.QueryResult{ProducedError: ctx.Err()}
+		}
+	}
+
+	d.mu.Lock()
+	d.ConnectivityFailures += step.FailConnectivity
+	d.mu.Unlock()
+	return step.Result
+}
+
+// VerifyConnectivity fails while there are scripted ConnectivityFailures left.
+func (d *ScriptedDriver) VerifyConnectivity(opts dbms.DBOptions) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.record("VerifyConnectivity", "")
+	if d.ConnectivityFailures > 0 {
+		d.ConnectivityFailures--
+		return false, errors.New("scripted connectivity failure")
+	}
+	return true, nil
+}
+
+// GetQueryResultType returns [dbms.Valid] for results without an error and [dbms.Timeout] for cancelled statements.
+// Other errors are classified by their message, errors neither ignored nor reported are bugs.
+func (d *ScriptedDriver) GetQueryResultType(res dbms.QueryResult, errorMessageRegex *dbms.ErrorMessageRegex) dbms.QueryResultType {
+	d.mu.Lock()
+	d.record("GetQueryResultType", "")
+	d.mu.Unlock()
+
+	err := res.ProducedError
+	if err == nil {
+		return dbms.Valid
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return dbms.Timeout
+	}
+	if errorMessageRegex != nil {
+		if resultType, ok := errorMessageRegex.Classify(err.Error()); ok {
+			return resultType
+		}
+	}
+	return dbms.Bug
+}
+
+// DiscardQuery returns false if the step answering the last statement is scripted to continue, else true.
+func (d *ScriptedDriver) DiscardQuery(res dbms.QueryResult, seed *seed.Seed) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.record("DiscardQuery", "")
+	return !d.lastStep.Continue
+}
+
+// IsEqualResult compares the results using the [dbms.DefaultComparator].
+func (d *ScriptedDriver) IsEqualResult(a, b dbms.QueryResult) bool {
+	d.mu.Lock()
+	d.record("IsEqualResult", "")
+	d.mu.Unlock()
+	return dbms.DefaultComparator.EqualResults(a, b)
+}
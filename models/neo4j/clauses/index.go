@@ -12,9 +12,9 @@ import (
 // Index is the Neo4j implementation for creating or dropping a database index
 // as well as for creating constraints on properties.
 type Index struct {
-	generateConstraint bool
-	indexType          string
-	indexName          string
+	generateConstraint bool   `ast:"generateConstraint"`
+	indexType          string `ast:"indexType"`
+	indexName          string `ast:"indexName"`
 }
 
 // Generate subclauses for Index
@@ -55,8 +55,8 @@ func (c Index) TemplateString() string {
 
 // DropIndex represents the statement dropping an existing index.
 type DropIndex struct {
-	useIfExist bool
-	indexName  string
+	useIfExist bool   `ast:"useIfExist"`
+	indexName  string `ast:"indexName"`
 }
 
 // Generate subclauses for DropIndex
@@ -91,8 +91,8 @@ func (c DropIndex) TemplateString() string {
 // Applicable for index types: range
 type IndexOnProperties struct {
 	// If false, is for relationship
-	isForNode bool
-	varName   string
+	isForNode bool   `ast:"isForNode"`
+	varName   string `ast:"varName"`
 }
 
 // Generate subclauses for IndexOnProperties
@@ -112,8 +112,8 @@ func (c IndexOnProperties) TemplateString() string {
 
 // IndexOnPropertiesProperties represents the properties on which the IndexOnProperties generates the index
 type IndexOnPropertiesProperties struct {
-	hasNext bool
-	varName string
+	hasNext bool   `ast:"hasNext"`
+	varName string `ast:"varName"`
 }
 
 // Generate subclauses for IndexOnPropertiesProperties
@@ -141,8 +141,8 @@ func (c IndexOnPropertiesProperties) TemplateString() string {
 // Applicable for index types: lookup
 type IndexOnLabels struct {
 	// If false, is for relationship
-	isForNode bool
-	varName   string
+	isForNode bool   `ast:"isForNode"`
+	varName   string `ast:"varName"`
 }
 
 // Generate subclauses for IndexOnLabels
@@ -164,8 +164,8 @@ func (c IndexOnLabels) TemplateString() string {
 // Applicable for index types: text, point
 type IndexOnProperty struct {
 	// If false, is for relationship
-	isForNode bool
-	varName   string
+	isForNode bool   `ast:"isForNode"`
+	varName   string `ast:"varName"`
 }
 
 // Generate subclauses for IndexOnProperty
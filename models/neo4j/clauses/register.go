@@ -0,0 +1,13 @@
+package clauses
+
+import "github.com/Anon10214/dinkel/translator/helperclauses"
+
+// Register the Neo4j specific clauses, allowing ASTs containing them to be loaded from bug reports.
+func init() {
+	helperclauses.RegisterClauses(
+		&Constraint{}, &NodeConstraint{}, &RelationshipConstraint{}, &ConstraintPropertyChain{}, &ConstraintProperty{},
+		&Index{}, &DropIndex{}, &IndexOnProperties{}, &IndexOnPropertiesProperties{}, &IndexOnLabels{}, &IndexOnProperty{},
+		&RootClause{},
+		&Runtime{},
+	)
+}
@@ -20,7 +20,7 @@ const (
 )
 
 type Runtime struct {
-	runtime RuntimeType
+	runtime RuntimeType `ast:"runtime"`
 }
 
 // Generate subclauses for OpenCypherRootClause
@@ -0,0 +1,35 @@
+package neo4j
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// TargetURI returns the URI of the Bolt server described by the passed options.
+//
+// If TLS is enabled, the scheme gets suffixed according to whether the server's certificate should be verified,
+// unless the scheme already specifies this itself, e.g. bolt+s.
+func TargetURI(scheme string, port int, opts dbms.DBOptions) string {
+	if opts.TLS != nil && !strings.Contains(scheme, "+") {
+		if opts.TLS.SkipVerify {
+			scheme += "+ssc"
+		} else {
+			scheme += "+s"
+		}
+	}
+	return fmt.Sprintf("%s://%s:%d", scheme, opts.Host, port)
+}
+
+// AuthToken returns the token to authenticate with as described by the passed options.
+func AuthToken(opts dbms.DBOptions) neo4j.AuthToken {
+	if opts.Token != "" {
+		return neo4j.BearerAuth(opts.Token)
+	}
+	if opts.Username != "" || opts.Password != "" {
+		return neo4j.BasicAuth(opts.Username, opts.Password, "")
+	}
+	return neo4j.NoAuth()
+}
@@ -0,0 +1,33 @@
+package neo4j
+
+import (
+	"testing"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTargetURI(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		scheme   string
+		tls      *dbms.TLSOptions
+		expected string
+	}{
+		{"Plain", "bolt", nil, "bolt://localhost:7687"},
+		{"TLS", "bolt", &dbms.TLSOptions{}, "bolt+s://localhost:7687"},
+		{"TLS without verification", "neo4j", &dbms.TLSOptions{SkipVerify: true}, "neo4j+ssc://localhost:7687"},
+		{"Scheme specifying TLS", "bolt+s", &dbms.TLSOptions{SkipVerify: true}, "bolt+s://localhost:7687"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, TargetURI(tc.scheme, 7687, dbms.DBOptions{Host: "localhost", TLS: tc.tls}))
+		})
+	}
+}
+
+func TestAuthToken(t *testing.T) {
+	assert.Equal(t, neo4j.NoAuth(), AuthToken(dbms.DBOptions{}))
+	assert.Equal(t, neo4j.BasicAuth("user", "password", ""), AuthToken(dbms.DBOptions{Username: "user", Password: "password"}))
+	assert.Equal(t, neo4j.BearerAuth("token"), AuthToken(dbms.DBOptions{Username: "user", Password: "password", Token: "token"}))
+}
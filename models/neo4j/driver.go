@@ -7,8 +7,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math"
-	"reflect"
 	"strings"
 	"time"
 
@@ -34,8 +32,13 @@ func (d *Driver) Init(opts dbms.DBOptions) error {
 	if opts.Port != nil {
 		connPort = *opts.Port
 	}
-	driver, err := neo4j.NewDriverWithContext(fmt.Sprintf("bolt://%s:%d", opts.Host, connPort), neo4j.NoAuth(), func(c *neo4j.Config) {
+	tlsConfig, err := opts.TLS.Config()
+	if err != nil {
+		return err
+	}
+	driver, err := neo4j.NewDriverWithContext(TargetURI("bolt", connPort, opts), AuthToken(opts), func(c *neo4j.Config) {
 		c.ConnectionAcquisitionTimeout = opts.Timeout
+		c.TlsConfig = tlsConfig
 	})
 	if err != nil {
 		return err
@@ -47,11 +50,11 @@ func (d *Driver) Init(opts dbms.DBOptions) error {
 	return nil
 }
 
-// Reset the database
+// Reset the database passed as the namespace, or the default database if none was passed
 func (d *Driver) Reset(opts dbms.DBOptions) error {
 	logrus.Debug("Resetting Database")
 	ctx := context.Background()
-	d.session = d.driver.NewSession(context.Background(), neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	d.session = d.driver.NewSession(context.Background(), neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite, DatabaseName: opts.Namespace})
 	if _, err := d.session.ExecuteWrite(ctx, func(transaction neo4j.ManagedTransaction) (any, error) {
 		// Delete nodes and relationships
 		if _, err := transaction.Run(ctx, "MATCH (n) DETACH DELETE n", nil); err != nil {
@@ -91,9 +94,18 @@ func (d *Driver) Reset(opts dbms.DBOptions) error {
 }
 
 // RunQuery runs the query against the Neo4j DB and returns its result.
-func (d Driver) RunQuery(opts dbms.DBOptions, query string) dbms.QueryResult {
-	ctx := context.Background()
+//
+// Once the context is done, the query's transactions get terminated.
+func (d Driver) RunQuery(ctx context.Context, opts dbms.DBOptions, query string) dbms.QueryResult {
 	logrus.Debug("Sending query to database")
+	queryID := NewQueryID()
+	stop := context.AfterFunc(ctx, func() {
+		if err := TerminateQuery(d.driver, opts, queryID); err != nil {
+			logrus.Warnf("Couldn't terminate cancelled query - %v", err)
+		}
+	})
+	defer stop()
+
 	var queryResult dbms.QueryResult
 	// Ignore the result, only consider err, (maybe use it later for statistics?)
 	if _, err := d.session.ExecuteWrite(ctx, func(transaction neo4j.ManagedTransaction) (any, error) {
@@ -105,35 +117,43 @@ func (d Driver) RunQuery(opts dbms.DBOptions, query string) dbms.QueryResult {
 		if err != nil {
 			return nil, err
 		}
-		for res.Next(ctx) {
-			queryResult.Rows = append(queryResult.Rows, res.Record().Values)
+		var summary neo4j.ResultSummary
+		if queryResult, summary = CollectResult(ctx, res); queryResult.ProducedError != nil {
+			return nil, queryResult.ProducedError
 		}
 
-		queryResult.ProducedError = res.Err()
-		return nil, res.Err()
-	}, neo4j.WithTxTimeout(opts.Timeout)); err != nil {
+		// Fetch the profiled plan from the result summary
+		if strings.HasPrefix(query, dbms.ProfilePrefix) {
+			if plan := summary.Profile(); plan != nil {
+				queryResult.Profile = toProfiledOperator(plan)
+				queryResult.Profile.ReturnedRows = int64(len(queryResult.Rows))
+			}
+		}
+		return nil, nil
+	}, neo4j.WithTxTimeout(opts.Timeout), WithQueryID(queryID)); err != nil {
 		logrus.Debugf("Error %v produced when running query %s", err, query)
 		return queryResult
 	}
 
 	if _, err := d.session.ExecuteRead(ctx, func(transaction neo4j.ManagedTransaction) (any, error) {
-		queryResult.Schema = []any{}
-
-		res, err := transaction.Run(ctx, "MATCH (n) RETURN n AS x UNION MATCH ()-[m]-() RETURN m AS x", nil)
+		res, err := transaction.Run(ctx, "MATCH (n) RETURN n AS x UNION MATCH ()-[m]->() RETURN m AS x", nil)
 		if err != nil {
 			queryResult.ProducedError = err
 			return nil, err
 		}
 
+		var records [][]any
 		for res.Next(ctx) {
-			queryResult.Schema = append(queryResult.Schema.([]any), res.Record().Values)
+			records = append(records, res.Record().Values)
 		}
 
 		if res.Err() != nil {
 			queryResult.ProducedError = res.Err()
+			return nil, res.Err()
 		}
-		return nil, res.Err()
-	}, neo4j.WithTxTimeout(opts.Timeout)); err != nil {
+		queryResult.Graph = ToGraph(records)
+		return nil, nil
+	}, neo4j.WithTxTimeout(opts.Timeout), WithQueryID(queryID)); err != nil {
 		logrus.Debugf("Error %v produced when trying to get schema", err)
 		return queryResult
 	}
@@ -220,6 +240,58 @@ func propertyStringToType(neo4jType any) schema.PropertyType {
 	return schema.PropertyType(mask) | propType
 }
 
+// PropertyTypeOf returns the [schema.PropertyType] of a value returned by the neo4j driver.
+//
+// The element type of a list is derived from its elements.
+// Lists with elements of differing types are of type [schema.AnyType] with the list mask set.
+// Returns false if the type has no equivalent, as is the case for maps.
+func PropertyTypeOf(value any) (schema.PropertyType, bool) {
+	elems, isList := value.([]any)
+	if !isList {
+		return scalarPropertyTypeOf(value)
+	}
+
+	var elemType schema.PropertyType
+	for i, elem := range elems {
+		t, ok := scalarPropertyTypeOf(elem)
+		if !ok || (i > 0 && t != elemType) {
+			elemType = schema.AnyType
+			break
+		}
+		elemType = t
+	}
+	return elemType | schema.PropertyType(schema.ListMask), true
+}
+
+// scalarPropertyTypeOf returns the [schema.PropertyType] of a non-list value returned by the neo4j driver.
+func scalarPropertyTypeOf(value any) (schema.PropertyType, bool) {
+	switch value.(type) {
+	case bool:
+		return schema.Boolean, true
+	case int64:
+		return schema.Integer, true
+	case float64:
+		return schema.Float, true
+	case string:
+		return schema.String, true
+	case neo4j.Date:
+		return schema.Date, true
+	case neo4j.Time:
+		return schema.Time, true
+	case neo4j.LocalTime:
+		return schema.LocalTime, true
+	case neo4j.LocalDateTime:
+		return schema.LocalDateTime, true
+	case time.Time:
+		return schema.Datetime, true
+	case neo4j.Duration:
+		return schema.Duration, true
+	case neo4j.Point2D, neo4j.Point3D:
+		return schema.Point, true
+	}
+	return schema.AnyType, false
+}
+
 // populateProperties fetches all properties in the DB and inserts them into the schema.
 func (d Driver) populateProperties(opts dbms.DBOptions, s *schema.Schema) error {
 	ctx := context.Background()
@@ -235,31 +307,9 @@ func (d Driver) populateProperties(opts dbms.DBOptions, s *schema.Schema) error
 
 		for res.Next(ctx) {
 			property := schema.Property{
-				Name: res.Record().Values[0].(string),
-				Type: propertyStringToType(res.Record().Values[1]),
-			}
-			switch val := res.Record().Values[2].(type) {
-			case neo4j.Point2D:
-				property.Value = fmt.Sprintf("point({x: %f, y: %f})", val.X, val.Y)
-			case neo4j.Point3D:
-				property.Value = fmt.Sprintf("point({x: %f, y: %f, z: %f})", val.X, val.Y, val.Z)
-			case string:
-				property.Value = fmt.Sprintf(`"%s"`, val)
-			// TODO: Implement all the rest of the data types
-			case neo4j.Date:
-				property.Value = "date('2000-01-01')"
-			case neo4j.LocalDateTime:
-				property.Value = "localdatetime('2015185T19:32:24')"
-			case neo4j.LocalTime:
-				property.Value = "localtime('12:50:35.556')"
-			case neo4j.Time:
-				property.Value = "time('125035.556+0100')"
-			case neo4j.Duration:
-				property.Value = `duration("P1Y")`
-			case time.Time:
-				property.Value = "datetime('2015-06-24T12:50:35.556+0100')"
-			default:
-				property.Value = fmt.Sprint(res.Record().Values[2])
+				Name:  res.Record().Values[0].(string),
+				Type:  propertyStringToType(res.Record().Values[1]),
+				Value: schema.Literal(res.Record().Values[2], ValueExpression),
 			}
 			s.AddProperty(property)
 		}
@@ -268,142 +318,6 @@ func (d Driver) populateProperties(opts dbms.DBOptions, s *schema.Schema) error
 	return err
 }
 
-// IsMatchingRows Compares the string representations of two neo4j result rows.
-// It returns true if they match, else false.
-func IsMatchingRows(first, second any) bool {
-	if first == nil || second == nil {
-		// If only one row is nil
-		if first != nil || second != nil {
-			return false
-		}
-		// If both are nil
-		return true
-	}
-
-	firstRow := first.([]any)
-	secondRow := second.([]any)
-	// Copy second row since it gets manipulated during the comparison
-	secondRowCopy := make([]any, len(secondRow))
-	copy(secondRowCopy, secondRow)
-
-	if len(firstRow) != len(secondRow) {
-		return false
-	}
-
-	for i := range firstRow {
-		matchedIndex := -1
-		for j := range secondRowCopy {
-			if isMatchingNeo4jElement(firstRow[i], secondRowCopy[j]) {
-				matchedIndex = j
-				break
-			}
-		}
-		if matchedIndex == -1 {
-			return false
-		}
-		secondRowCopy = append(secondRowCopy[:matchedIndex], secondRowCopy[matchedIndex+1:]...)
-	}
-	return true
-}
-
-// isMatchingNeo4jElement returns true if the two passed elements
-// evaluate to the same neo4j elements, else it returns false.
-func isMatchingNeo4jElement(a, b any) bool {
-	secondElement := b
-	switch a := a.(type) {
-	case nil:
-		return b == nil
-	case []any:
-		if b, ok := secondElement.([]any); ok {
-			if len(a) != len(b) {
-				return false
-			}
-			for i := range a {
-				if !isMatchingNeo4jElement(a[i], b[i]) {
-					return false
-				}
-			}
-			return true
-		}
-		return false
-	case neo4j.Node:
-		b, ok := secondElement.(neo4j.Node)
-		if !(ok && isMatchingNode(a, b)) {
-			return false
-		}
-	case neo4j.Relationship:
-		b, ok := secondElement.(neo4j.Relationship)
-		if !(ok && isMatchingRelationship(a, b)) {
-			return false
-		}
-	case neo4j.Path:
-		b, ok := secondElement.(neo4j.Path)
-		if !ok {
-			return false
-		}
-		if len(a.Nodes) != len(b.Nodes) || len(a.Relationships) != len(b.Relationships) {
-			return false
-		}
-		for i := range a.Nodes {
-			if !isMatchingNode(a.Nodes[i], b.Nodes[i]) {
-				return false
-			}
-		}
-		for i := range a.Relationships {
-			if !isMatchingRelationship(a.Relationships[i], b.Relationships[i]) {
-				return false
-			}
-		}
-	case float64:
-		b, ok := secondElement.(float64)
-		return ok && (b == a || (math.IsNaN(a) && math.IsNaN(b)))
-	default:
-		if !reflect.DeepEqual(a, b) {
-			return false
-		}
-	}
-	return true
-}
-
-func isMatchingNode(a, b neo4j.Node) bool {
-	if len(a.Labels) != len(b.Labels) {
-		return false
-	}
-	for i := range a.Labels {
-		if a.Labels[i] != b.Labels[i] {
-			return false
-		}
-	}
-
-	if len(a.Props) != len(b.Props) {
-		return false
-	}
-
-	for k, v := range a.Props {
-		if !isMatchingNeo4jElement(v, b.Props[k]) {
-			return false
-		}
-	}
-	return true
-}
-
-func isMatchingRelationship(a, b neo4j.Relationship) bool {
-	if a.Type != b.Type {
-		return false
-	}
-
-	if len(a.Props) != len(b.Props) {
-		return false
-	}
-
-	for k, v := range a.Props {
-		if !isMatchingNeo4jElement(v, b.Props[k]) {
-			return false
-		}
-	}
-	return true
-}
-
 // GetQueryResultType evaluates the produced result and returns the type the result indicates.
 func (d Driver) GetQueryResultType(res dbms.QueryResult, errorMessageRegex *dbms.ErrorMessageRegex) dbms.QueryResultType {
 	err := res.ProducedError
@@ -425,15 +339,11 @@ func (d Driver) GetQueryResultType(res dbms.QueryResult, errorMessageRegex *dbms
 		// Generation itself should try its best to avoid syntax and semantic errors
 		// Error messages here should be unavoidable or resource-heavy to detect during generation
 
-		if errorMessageRegex.Ignored.MatchString(err.Msg) {
-			return dbms.Invalid
-		}
-
 		// TODO: Move below comment to docs somewhere
-		// These error messages indicate bugs which have already been reported
+		// Reported errors indicate bugs which have already been reported
 
-		if errorMessageRegex.Reported.MatchString(err.Msg) {
-			return dbms.ReportedBug
+		if resultType, ok := errorMessageRegex.Classify(err.Msg, err.Code, err.GqlStatus); ok {
+			return resultType
 		}
 
 		logrus.Warnf("Encountered Neo4jError with error title %q and msg %s", err.Title(), err.Msg)
@@ -462,34 +372,5 @@ func (d Driver) VerifyConnectivity(dbms.DBOptions) (bool, error) {
 
 // IsEqualResult returns true if the two passed query results hold the same information, else false.
 func (d Driver) IsEqualResult(a, b dbms.QueryResult) bool {
-	if len(a.Rows) != len(b.Rows) {
-		logrus.Warn("Encountered mismatching results")
-		logrus.Infof("\n\t%v\nvs\n\t%v", a.Rows, b.Rows)
-		return false
-	}
-
-	// Check that result rows match
-	if !IsMatchingRows(a.Rows, b.Rows) {
-		logrus.Warn("Encountered mismatching rows")
-		logrus.Infof("\n\t%v\nvs\n\t%v", a.Rows, b.Rows)
-		return false
-	}
-
-	// Check if the schemas match
-	if !IsMatchingRows(a.Schema, b.Schema) {
-		logrus.Warnf("Mismatching Schemas")
-		logrus.Infof("Schemas:\n\t%+v\nvs\n\t%+v", a.Schema, b.Schema)
-		return false
-	}
-
-	if a.ProducedError != nil || b.ProducedError != nil {
-		if a.ProducedError == nil || b.ProducedError == nil {
-			return false
-		}
-		if a.ProducedError.Error() != b.ProducedError.Error() {
-			return false
-		}
-	}
-
-	return true
+	return dbms.DefaultComparator.EqualResults(a, b)
 }
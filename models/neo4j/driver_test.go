@@ -1,11 +1,16 @@
 package neo4j
 
 import (
+	"context"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/fakeserver"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestInit_Port(t *testing.T) {
@@ -32,3 +37,117 @@ func TestInit_Port(t *testing.T) {
 		assert.Equal(t, "host:123", driver.driver.Target().Host, "Default port wrong")
 	})
 }
+
+// startDriver starts a fake Neo4j answering queries using the passed handler and returns a driver connected to it.
+func startDriver(t *testing.T, handler fakeserver.BoltHandler) (*Driver, *fakeserver.BoltServer, dbms.DBOptions) {
+	server := fakeserver.StartBolt(t, handler)
+	d, opts, err := fakeserver.InitDriver(server, &Driver{})
+	require.NoError(t, err)
+	t.Cleanup(func() { d.driver.Close(context.Background()) })
+	require.NoError(t, d.Reset(opts))
+	return d, server, opts
+}
+
+func TestRunQuery(t *testing.T) {
+	a := fakeserver.Node{ID: 0, Labels: []string{"A"}, Properties: map[string]any{"p": int64(1)}}
+	b := fakeserver.Node{ID: 1, Labels: []string{"B"}}
+	r := fakeserver.Relationship{ID: 2, StartID: 0, EndID: 1, Type: "T"}
+
+	d, _, opts := startDriver(t, func(query string, params map[string]any) fakeserver.BoltResult {
+		switch query {
+		case "CREATE p = (a:A {p: 1})-[:T]->(:B) RETURN a, p, 1.5 AS f":
+			return fakeserver.BoltResult{
+				Columns:  []string{"a", "p", "f"},
+				Rows:     [][]any{{a, fakeserver.Path{Nodes: []fakeserver.Node{a, b}, Relationships: []fakeserver.Relationship{r}}, 1.5}},
+				Metadata: map[string]any{"stats": map[string]any{"nodes-created": int64(2), "relationships-created": int64(1), "labels-added": int64(2), "properties-set": int64(1)}},
+			}
+		case "MATCH (n) RETURN n AS x UNION MATCH ()-[m]->() RETURN m AS x":
+			return fakeserver.BoltResult{Columns: []string{"x"}, Rows: [][]any{{a}, {b}, {r}}}
+		}
+		return fakeserver.BoltResult{}
+	})
+
+	res := d.RunQuery(context.Background(), opts, "CREATE p = (a:A {p: 1})-[:T]->(:B) RETURN a, p, 1.5 AS f")
+	assert.NoError(t, res.ProducedError)
+	assert.Equal(t, []string{"a", "p", "f"}, res.Columns)
+	nodeA := dbms.Node{Labels: []string{"A"}, Properties: dbms.Map{"p": dbms.Int(1)}}
+	nodeB := dbms.Node{Labels: []string{"B"}, Properties: dbms.Map{}}
+	relationship := dbms.Relationship{Type: "T", Properties: dbms.Map{}}
+	assert.Equal(t, []dbms.Row{{
+		nodeA,
+		dbms.Path{Nodes: []dbms.Node{nodeA, nodeB}, Relationships: []dbms.Relationship{relationship}},
+		dbms.Float(1.5),
+	}}, res.Rows)
+	assert.Equal(t, &dbms.UpdateCounters{NodesCreated: 2, RelationshipsCreated: 1, LabelsAdded: 2, PropertiesSet: 1}, res.Counters)
+
+	expectedGraph := dbms.NewGraph()
+	expectedGraph.AddNode("0", nodeA)
+	expectedGraph.AddNode("1", nodeB)
+	expectedGraph.AddRelationship("2", "0", "1", relationship)
+	assert.Equal(t, expectedGraph, res.Graph)
+}
+
+func TestReset(t *testing.T) {
+	_, server, _ := startDriver(t, nil)
+
+	assert.Equal(t, []string{"MATCH (n) DETACH DELETE n"}, server.Received(),
+		"only the graph should be deleted, as apoc may be unavailable")
+}
+
+func TestGetSchema(t *testing.T) {
+	d, _, opts := startDriver(t, func(query string, params map[string]any) fakeserver.BoltResult {
+		switch {
+		case query == "MATCH (n) UNWIND labels(n) AS label RETURN DISTINCT label ORDER BY label":
+			return fakeserver.BoltResult{Columns: []string{"label"}, Rows: [][]any{{"A"}, {"B"}}}
+		case query == "MATCH ()-[m]-() RETURN DISTINCT type(m) AS label ORDER BY label":
+			return fakeserver.BoltResult{Columns: []string{"label"}, Rows: [][]any{{"T"}}}
+		case strings.Contains(query, "apoc.meta.cypher.type"):
+			return fakeserver.BoltResult{Columns: []string{"key", "type", "value"}, Rows: [][]any{
+				{"l", "LIST OF STRING", []any{"a"}},
+				{"p", "INTEGER", int64(1)},
+			}}
+		}
+		return fakeserver.BoltResult{}
+	})
+
+	s, err := d.GetSchema(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"A", "B"}, s.Labels[schema.NODE])
+	assert.Equal(t, []string{"T"}, s.Labels[schema.RELATIONSHIP])
+	assert.Equal(t, []string{"T", "A", "B"}, s.Labels[schema.ANY])
+
+	expected := &schema.Schema{}
+	expected.Reset()
+	expected.AddProperty(schema.Property{Name: "l", Type: schema.String | schema.PropertyType(schema.ListMask), Value: `["a"]`})
+	expected.AddProperty(schema.Property{Name: "p", Type: schema.Integer, Value: "1"})
+	assert.Equal(t, expected.Properties, s.Properties)
+}
+
+func TestGetQueryResultType(t *testing.T) {
+	d, _, opts := startDriver(t, func(query string, params map[string]any) fakeserver.BoltResult {
+		if code, isError := strings.CutPrefix(query, "FAIL "); isError {
+			return fakeserver.BoltResult{Code: code, Message: "failed"}
+		}
+		return fakeserver.BoltResult{}
+	})
+	regex := &dbms.ErrorMessageRegex{
+		IgnoredCodes:  []string{"Neo.ClientError.Statement.ArithmeticError"},
+		ReportedCodes: []string{"Neo.DatabaseError.General.UnknownError"},
+	}
+
+	for _, tc := range []struct {
+		query    string
+		expected dbms.QueryResultType
+	}{
+		{"RETURN 1", dbms.Valid},
+		{"FAIL Neo.ClientError.Statement.ArithmeticError", dbms.Invalid},
+		{"FAIL Neo.DatabaseError.General.UnknownError", dbms.ReportedBug},
+		{"FAIL Neo.ClientError.Transaction.TransactionTimedOutClientConfiguration", dbms.Timeout},
+		{"FAIL Neo.ClientError.Statement.SyntaxError", dbms.Bug},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			res := d.RunQuery(context.Background(), opts, tc.query)
+			assert.Equal(t, tc.expected, d.GetQueryResultType(res, regex))
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package neo4j
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// ValueExpression returns a Cypher expression evaluating to exactly the passed value, as returned by the neo4j driver,
+// if it can't be expressed by a plain literal. It is the fallback passed to [schema.Literal].
+//
+// Temporal values get constructed from their components rather than parsed from strings,
+// as this retains nanoseconds and years outside of the range supported by ISO 8601 strings.
+// Returns the empty string if the value can't be expressed, as is the case for maps or byte arrays.
+func ValueExpression(value any) string {
+	switch value := value.(type) {
+	case int64:
+		if value == math.MinInt64 {
+			// Negating the literal 9223372036854775808 overflows
+			return "(-9223372036854775807 - 1)"
+		}
+		return strconv.FormatInt(value, 10)
+	case float64:
+		return floatExpression(value)
+	case neo4j.Date:
+		return fmt.Sprintf("date({%s})", dateComponents(value.Time()))
+	case neo4j.LocalTime:
+		return fmt.Sprintf("localtime({%s})", timeComponents(value.Time()))
+	case neo4j.Time:
+		t := value.Time()
+		return fmt.Sprintf("time({%s, timezone: %s})", timeComponents(t), schema.StringLiteral(zoneOffset(t)))
+	case neo4j.LocalDateTime:
+		t := value.Time()
+		return fmt.Sprintf("localdatetime({%s, %s})", dateComponents(t), timeComponents(t))
+	case time.Time:
+		// The driver names zones only consisting of an offset "Offset", all other zones are named by their zone ID
+		zone := value.Location().String()
+		if zone == "Offset" {
+			zone = zoneOffset(value)
+		}
+		return fmt.Sprintf("datetime({%s, %s, timezone: %s})", dateComponents(value), timeComponents(value), schema.StringLiteral(zone))
+	case neo4j.Duration:
+		return fmt.Sprintf("duration({months: %d, days: %d, seconds: %d, nanoseconds: %d})", value.Months, value.Days, value.Seconds, value.Nanos)
+	case neo4j.Point2D:
+		return fmt.Sprintf("point({x: %s, y: %s, srid: %d})", floatExpression(value.X), floatExpression(value.Y), value.SpatialRefId)
+	case neo4j.Point3D:
+		return fmt.Sprintf("point({x: %s, y: %s, z: %s, srid: %d})", floatExpression(value.X), floatExpression(value.Y), floatExpression(value.Z), value.SpatialRefId)
+	}
+	return ""
+}
+
+// floatExpression returns the shortest Cypher expression evaluating to exactly the passed float, which may be non-finite.
+func floatExpression(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "(0.0 / 0.0)"
+	case math.IsInf(f, 1):
+		return "(1.0 / 0.0)"
+	case math.IsInf(f, -1):
+		return "(-1.0 / 0.0)"
+	}
+	return schema.FloatLiteral(f)
+}
+
+// dateComponents returns the map entries specifying the date of the passed time.
+func dateComponents(t time.Time) string {
+	return fmt.Sprintf("year: %d, month: %d, day: %d", t.Year(), t.Month(), t.Day())
+}
+
+// timeComponents returns the map entries specifying the time of day of the passed time.
+func timeComponents(t time.Time) string {
+	return fmt.Sprintf("hour: %d, minute: %d, second: %d, nanosecond: %d", t.Hour(), t.Minute(), t.Second(), t.Nanosecond())
+}
+
+// zoneOffset returns the UTC offset of the passed time in the form ±HH:MM, or ±HH:MM:SS if the offset has seconds.
+func zoneOffset(t time.Time) string {
+	_, offset := t.Zone()
+	sign := '+'
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	if offset%60 != 0 {
+		return fmt.Sprintf("%c%02d:%02d:%02d", sign, offset/3600, offset/60%60, offset%60)
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, offset/3600, offset/60%60)
+}
@@ -0,0 +1,59 @@
+package neo4j
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueExpression(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database unavailable")
+	}
+	instant := time.Date(2015, 7, 21, 21, 40, 32, 142000001, time.UTC)
+
+	for _, tc := range []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{"Boolean", true, "true"},
+		{"Integer", int64(-12), "-12"},
+		{"Min integer", int64(math.MinInt64), "(-9223372036854775807 - 1)"},
+		{"Float", 0.1, "0.1"},
+		{"Whole float", float64(-3), "-3.0"},
+		{"Large float", 1.7976931348623157e308, "1.7976931348623157e308"},
+		{"Small float", 5e-324, "5e-324"},
+		{"NaN", math.NaN(), "(0.0 / 0.0)"},
+		{"Infinity", math.Inf(-1), "(-1.0 / 0.0)"},
+		{"String", "it's a \"test\"\\\n\t😀", `"it's a \"test\"\\\n\t😀"`},
+		{"Date", neo4j.Date(instant), "date({year: 2015, month: 7, day: 21})"},
+		{"Negative year", neo4j.Date(time.Date(-12345, 1, 2, 0, 0, 0, 0, time.UTC)), "date({year: -12345, month: 1, day: 2})"},
+		{"Local time", neo4j.LocalTime(instant), "localtime({hour: 21, minute: 40, second: 32, nanosecond: 142000001})"},
+		{"Time", neo4j.Time(instant.In(time.FixedZone("Offset", -(9*3600 + 30*60)))),
+			"time({hour: 12, minute: 10, second: 32, nanosecond: 142000001, timezone: \"-09:30\"})"},
+		{"Local datetime", neo4j.LocalDateTime(instant),
+			"localdatetime({year: 2015, month: 7, day: 21, hour: 21, minute: 40, second: 32, nanosecond: 142000001})"},
+		{"Datetime with offset", instant.In(time.FixedZone("Offset", 3600+45)),
+			"datetime({year: 2015, month: 7, day: 21, hour: 22, minute: 41, second: 17, nanosecond: 142000001, timezone: \"+01:00:45\"})"},
+		{"Datetime with zone ID", instant.In(berlin),
+			"datetime({year: 2015, month: 7, day: 21, hour: 23, minute: 40, second: 32, nanosecond: 142000001, timezone: \"Europe/Berlin\"})"},
+		{"Duration", neo4j.Duration{Months: 14, Days: -3, Seconds: 3661, Nanos: 5},
+			"duration({months: 14, days: -3, seconds: 3661, nanoseconds: 5})"},
+		{"Cartesian point", neo4j.Point2D{X: 1, Y: -2.5, SpatialRefId: 7203}, "point({x: 1.0, y: -2.5, srid: 7203})"},
+		{"WGS-84 3D point", neo4j.Point3D{X: 12.99, Y: 56.7, Z: 100, SpatialRefId: 4979}, "point({x: 12.99, y: 56.7, z: 100.0, srid: 4979})"},
+		{"List", []any{int64(1), "a", []any{}}, `[1, "a", []]`},
+		{"List of dates", []any{neo4j.Date(instant)}, "[date({year: 2015, month: 7, day: 21})]"},
+		{"Map", map[string]any{"a": int64(1)}, ""},
+		{"List containing byte array", []any{[]byte{1}}, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, schema.Literal(tc.value, ValueExpression))
+		})
+	}
+}
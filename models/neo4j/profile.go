@@ -0,0 +1,24 @@
+package neo4j
+
+import (
+	"strings"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// toProfiledOperator converts a plan returned by the neo4j driver to a [dbms.ProfiledOperator].
+//
+// The operator names returned by Neo4j are suffixed by the name of the database (e.g. Filter@neo4j),
+// this suffix gets stripped.
+func toProfiledOperator(plan neo4j.ProfiledPlan) *dbms.ProfiledOperator {
+	name, _, _ := strings.Cut(plan.Operator(), "@")
+	op := &dbms.ProfiledOperator{
+		Name: name,
+		Rows: plan.Records(),
+	}
+	for _, child := range plan.Children() {
+		op.Children = append(op.Children, toProfiledOperator(child))
+	}
+	return op
+}
@@ -0,0 +1,46 @@
+package neo4j
+
+import (
+	"context"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// CollectResult consumes the result returned by the Neo4j driver, returning its rows, column names and update counters.
+//
+// The result's summary is returned as well, e.g. to read the profiled plan from it.
+// If consuming the result fails, the returned query result holds the rows collected until then and the error.
+func CollectResult(ctx context.Context, res neo4j.ResultWithContext) (dbms.QueryResult, neo4j.ResultSummary) {
+	var queryResult dbms.QueryResult
+	if queryResult.Columns, queryResult.ProducedError = res.Keys(); queryResult.ProducedError != nil {
+		return queryResult, nil
+	}
+	for res.Next(ctx) {
+		queryResult.Rows = append(queryResult.Rows, ToRow(res.Record().Values))
+	}
+	if queryResult.ProducedError = res.Err(); queryResult.ProducedError != nil {
+		return queryResult, nil
+	}
+
+	summary, err := res.Consume(ctx)
+	if err != nil {
+		queryResult.ProducedError = err
+		return queryResult, nil
+	}
+	queryResult.Counters = ToUpdateCounters(summary.Counters())
+	return queryResult, summary
+}
+
+// ToUpdateCounters converts the counters of a result summary returned by the Neo4j driver into [dbms.UpdateCounters].
+func ToUpdateCounters(counters neo4j.Counters) *dbms.UpdateCounters {
+	return &dbms.UpdateCounters{
+		NodesCreated:         counters.NodesCreated(),
+		NodesDeleted:         counters.NodesDeleted(),
+		RelationshipsCreated: counters.RelationshipsCreated(),
+		RelationshipsDeleted: counters.RelationshipsDeleted(),
+		PropertiesSet:        counters.PropertiesSet(),
+		LabelsAdded:          counters.LabelsAdded(),
+		LabelsRemoved:        counters.LabelsRemoved(),
+	}
+}
@@ -0,0 +1,77 @@
+package neo4j
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// The key of the transaction metadata tagging a query's transactions,
+// allowing them to be found and terminated once the query gets cancelled.
+const queryIDMetadataKey = "dinkelQueryId"
+
+// NewQueryID returns a random ID identifying the transactions of a query.
+//
+// The ID is random instead of sequential, as multiple fuzzers may share a server.
+func NewQueryID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// WithQueryID tags the transaction with the query ID, see [TerminateQuery].
+func WithQueryID(id string) func(*neo4j.TransactionConfig) {
+	return neo4j.WithTxMetadata(map[string]any{queryIDMetadataKey: id})
+}
+
+// QueryIDOf returns the query ID the transaction metadata got tagged with using [WithQueryID].
+func QueryIDOf(metadata any) string {
+	m, _ := metadata.(map[string]any)
+	id, _ := m[queryIDMetadataKey].(string)
+	return id
+}
+
+// The query returning the IDs of the transactions tagged with the query ID passed as $id,
+// and the statement terminating the transactions with the IDs passed as $ids, as supported by Neo4j.
+const (
+	TransactionsQuery  = "SHOW TRANSACTIONS YIELD transactionId, metaData WHERE metaData." + queryIDMetadataKey + " = $id RETURN transactionId"
+	TerminateStatement = "TERMINATE TRANSACTIONS $ids"
+)
+
+// TerminateQuery terminates the running transactions tagged with the query ID.
+func TerminateQuery(driver neo4j.DriverWithContext, opts dbms.DBOptions, id string) error {
+	return TerminateTransactions(driver, opts, id, TransactionsQuery, TerminateStatement)
+}
+
+// TerminateTransactions terminates the running transactions tagged with the query ID using the passed statements.
+//
+// The transactions query gets passed the query ID as $id and returns the IDs of its transactions in its first column.
+// These get passed to the terminate statement as $ids, which is only run if there are any.
+func TerminateTransactions(driver neo4j.DriverWithContext, opts dbms.DBOptions, id, transactionsQuery, terminateStatement string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	session := driver.NewSession(ctx, neo4j.SessionConfig{DatabaseName: opts.Namespace})
+	defer session.Close(ctx)
+
+	res, err := session.Run(ctx, transactionsQuery, map[string]any{"id": id})
+	if err != nil {
+		return err
+	}
+	var transactionIDs []any
+	for res.Next(ctx) {
+		transactionIDs = append(transactionIDs, res.Record().Values[0])
+	}
+	if err := res.Err(); err != nil || len(transactionIDs) == 0 {
+		return err
+	}
+
+	res, err = session.Run(ctx, terminateStatement, map[string]any{"ids": transactionIDs})
+	if err != nil {
+		return err
+	}
+	_, err = res.Consume(ctx)
+	return err
+}
@@ -0,0 +1,116 @@
+package neo4j
+
+import (
+	"time"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// ToRow converts the values of a record returned by the Neo4j driver into a row.
+func ToRow(values []any) dbms.Row {
+	row := make(dbms.Row, len(values))
+	for i, v := range values {
+		row[i] = ToValue(v)
+	}
+	return row
+}
+
+// ToValue converts a value returned by the Neo4j driver into its [dbms.Value].
+func ToValue(v any) dbms.Value {
+	switch v := v.(type) {
+	case []any:
+		list := make(dbms.List, len(v))
+		for i, elem := range v {
+			list[i] = ToValue(elem)
+		}
+		return list
+	case map[string]any:
+		return toMap(v)
+	case neo4j.Node:
+		return toNode(v)
+	case neo4j.Relationship:
+		return toRelationship(v)
+	case neo4j.Path:
+		path := dbms.Path{}
+		for _, node := range v.Nodes {
+			path.Nodes = append(path.Nodes, toNode(node))
+		}
+		for _, relationship := range v.Relationships {
+			path.Relationships = append(path.Relationships, toRelationship(relationship))
+		}
+		return path
+	case neo4j.Date:
+		return dbms.NewDate(v.Time())
+	case neo4j.LocalTime:
+		return dbms.NewLocalTime(v.Time())
+	case neo4j.Time:
+		return dbms.NewTime(v.Time())
+	case neo4j.LocalDateTime:
+		return dbms.NewLocalDateTime(v.Time())
+	case time.Time:
+		return dbms.DateTime{Time: v}
+	case neo4j.Duration:
+		return dbms.Duration{Months: v.Months, Days: v.Days, Seconds: v.Seconds, Nanos: int64(v.Nanos)}
+	case neo4j.Point2D:
+		return dbms.Point{SRID: v.SpatialRefId, Coordinates: []float64{v.X, v.Y}}
+	case neo4j.Point3D:
+		return dbms.Point{SRID: v.SpatialRefId, Coordinates: []float64{v.X, v.Y, v.Z}}
+	}
+	if value, ok := dbms.ScalarValue(v); ok {
+		return value
+	}
+	return dbms.UnknownValue(v)
+}
+
+// ToGraph collects the nodes and relationships found in the values of the records returned by the Neo4j driver into a graph.
+// Nodes and relationships may be nested in lists, maps and paths.
+func ToGraph(records [][]any) *dbms.Graph {
+	graph := dbms.NewGraph()
+	for _, values := range records {
+		for _, v := range values {
+			addToGraph(graph, v)
+		}
+	}
+	return graph
+}
+
+func addToGraph(graph *dbms.Graph, v any) {
+	switch v := v.(type) {
+	case []any:
+		for _, elem := range v {
+			addToGraph(graph, elem)
+		}
+	case map[string]any:
+		for _, elem := range v {
+			addToGraph(graph, elem)
+		}
+	case neo4j.Node:
+		graph.AddNode(v.ElementId, toNode(v))
+	case neo4j.Relationship:
+		graph.AddRelationship(v.ElementId, v.StartElementId, v.EndElementId, toRelationship(v))
+	case neo4j.Path:
+		for _, node := range v.Nodes {
+			addToGraph(graph, node)
+		}
+		for _, relationship := range v.Relationships {
+			addToGraph(graph, relationship)
+		}
+	}
+}
+
+func toMap(m map[string]any) dbms.Map {
+	res := make(dbms.Map, len(m))
+	for k, v := range m {
+		res[k] = ToValue(v)
+	}
+	return res
+}
+
+func toNode(n neo4j.Node) dbms.Node {
+	return dbms.Node{Labels: n.Labels, Properties: toMap(n.Props)}
+}
+
+func toRelationship(r neo4j.Relationship) dbms.Relationship {
+	return dbms.Relationship{Type: r.Type, Properties: toMap(r.Props)}
+}
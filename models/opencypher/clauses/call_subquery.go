@@ -24,9 +24,9 @@ func (c CallSubquery) TemplateString() string {
 }
 
 type CallSubqueryClause struct {
-	oldSchema schema.Schema
+	oldSchema schema.Schema `ast:"oldSchema"`
 	// Start the call subquery with a WITH *
-	includeAll bool
+	includeAll bool `ast:"includeAll"`
 }
 
 // Generate subclauses for CallSubqueryClause
@@ -51,16 +51,16 @@ func (c *CallSubqueryClause) Generate(seed *seed.Seed, s *schema.Schema) []trans
 			c.includeAll = false
 		}
 	}
-	for _, variable := range c.oldSchema.PropertyVariablesByName {
+	for _, name := range sortedKeys(c.oldSchema.PropertyVariablesByName) {
 		if c.includeAll || seed.BooleanWithProbability(0.1) {
-			s.AddPropertyVariable(variable)
-			variablesToInclude = append(variablesToInclude, variable.Name)
+			s.AddPropertyVariable(c.oldSchema.PropertyVariablesByName[name])
+			variablesToInclude = append(variablesToInclude, name)
 		}
 	}
-	for _, variable := range c.oldSchema.StructuralVariablesByName {
+	for _, name := range sortedKeys(c.oldSchema.StructuralVariablesByName) {
 		if c.includeAll || seed.BooleanWithProbability(0.1) {
-			s.AddStructuralVariable(variable)
-			variablesToInclude = append(variablesToInclude, variable.Name)
+			s.AddStructuralVariable(c.oldSchema.StructuralVariablesByName[name])
+			variablesToInclude = append(variablesToInclude, name)
 		}
 	}
 
@@ -98,8 +98,8 @@ func (c CallSubqueryClause) ModifySchema(s *schema.Schema) {
 }
 
 type CallSubqueryWith struct {
-	IsIncludeAll       bool
-	VariablesToInclude []string
+	IsIncludeAll       bool     `ast:"isIncludeAll"`
+	VariablesToInclude []string `ast:"variablesToInclude"`
 }
 
 // Generate subclauses for CallSubqueryWith
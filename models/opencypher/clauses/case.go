@@ -8,7 +8,7 @@ import (
 )
 
 type CaseExpression struct {
-	Conf schema.ExpressionConfig
+	Conf schema.ExpressionConfig `ast:"conf"`
 }
 
 // Generate subclauses for CaseExpression
@@ -23,7 +23,7 @@ func (c *CaseExpression) Generate(seed *seed.Seed, s *schema.Schema) []translato
 }
 
 type SimpleCaseExpression struct {
-	Conf schema.ExpressionConfig
+	Conf schema.ExpressionConfig `ast:"conf"`
 }
 
 // Generate subclauses for SimpleCaseExpression
@@ -43,7 +43,7 @@ func (c SimpleCaseExpression) TemplateString() string {
 }
 
 type GenericCaseExpression struct {
-	Conf schema.ExpressionConfig
+	Conf schema.ExpressionConfig `ast:"conf"`
 }
 
 // Generate subclauses for GenericCaseExpression
@@ -63,9 +63,9 @@ func (c GenericCaseExpression) TemplateString() string {
 }
 
 type CaseExpressionWhen struct {
-	Conf schema.ExpressionConfig
+	Conf schema.ExpressionConfig `ast:"conf"`
 	// Whether this clause is part of a generic or simple case expression
-	IsGeneric bool
+	IsGeneric bool `ast:"isGeneric"`
 }
 
 // Generate subclauses for CaseExpressionWhen
@@ -95,7 +95,7 @@ func (c CaseExpressionWhen) NoStrategyReduce(clause *helperclauses.ClauseCapture
 }
 
 type CaseExpressionElse struct {
-	Conf schema.ExpressionConfig
+	Conf schema.ExpressionConfig `ast:"conf"`
 }
 
 // Generate subclauses for CaseExpressionElse
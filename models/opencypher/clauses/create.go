@@ -39,7 +39,7 @@ func (c CreateClause) TemplateString() string {
 }
 
 type CreateElementChain struct {
-	isBasecase bool
+	isBasecase bool `ast:"isBasecase"`
 }
 
 // Generate subclauses for CreateElementChain
@@ -66,7 +66,7 @@ func (c CreateElementChain) NoStrategyReduce(clause *helperclauses.ClauseCapture
 }
 
 type CreateElement struct {
-	pathVariableName string
+	pathVariableName string `ast:"pathVariableName"`
 }
 
 // Generate subclauses for CreateElement
@@ -90,10 +90,10 @@ func (c CreateElement) TemplateString() string {
 }
 
 type CreatePathElement struct {
-	direction relationshipDirection
+	direction relationshipDirection `ast:"direction"`
 	// Whether this element is part of a relationship being created
-	InRelationship   bool
-	relationshipName string
+	InRelationship   bool   `ast:"inRelationship"`
+	relationshipName string `ast:"relationshipName"`
 }
 
 // Generate subclauses for CreatePathElement
@@ -135,7 +135,7 @@ func (c CreatePathElement) NoStrategyReduce(clause *helperclauses.ClauseCapturer
 
 type CreateNode struct {
 	// Can only return CreateExisting if set to true (cannot recreate existing node)
-	InRelationship bool
+	InRelationship bool `ast:"inRelationship"`
 }
 
 // Generate subclauses for CreateNode
@@ -147,7 +147,7 @@ func (c *CreateNode) Generate(seed *seed.Seed, s *schema.Schema) []translator.Cl
 }
 
 type CreateNewNode struct {
-	name string
+	name string `ast:"name"`
 }
 
 // Generate subclauses for CreateNewNode
@@ -170,7 +170,7 @@ func (c CreateNewNode) TemplateString() string {
 
 type CreateExistingNode struct {
 	// Empty if no name was found
-	usedName string
+	usedName string `ast:"usedName"`
 }
 
 // Generate subclauses for CreateExistingNode
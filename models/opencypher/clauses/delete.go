@@ -24,7 +24,7 @@ func (c Delete) TemplateString() string {
 }
 
 type DeleteClause struct {
-	useDetach bool
+	useDetach bool `ast:"useDetach"`
 }
 
 // Generate subclauses for DeleteClause
@@ -53,7 +53,7 @@ func (c DeleteClause) TemplateString() string {
 
 type DeleteElementChain struct {
 	// If this is a DETACH DELETE clause
-	UseDetach bool
+	UseDetach bool `ast:"useDetach"`
 }
 
 // Generate subclauses for DeleteElementChain
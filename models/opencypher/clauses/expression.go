@@ -12,7 +12,7 @@ import (
 )
 
 type Expression struct {
-	Conf schema.ExpressionConfig
+	Conf schema.ExpressionConfig `ast:"conf"`
 }
 
 // Generate subclauses for Expression
@@ -86,9 +86,9 @@ func (c Expression) NoStrategyReduce(clause *helperclauses.ClauseCapturer) trans
 }
 
 type VariableExpression struct {
-	Conf                       schema.ExpressionConfig
-	name                       string
-	IsStructuralPropertyAccess bool
+	Conf                       schema.ExpressionConfig `ast:"conf"`
+	name                       string                  `ast:"name"`
+	IsStructuralPropertyAccess bool                    `ast:"isStructuralPropertyAccess"`
 }
 
 // Generate subclauses for VariableExpression
@@ -178,9 +178,9 @@ func (c VariableExpression) TemplateString() string {
 // If NoWriteTargetIndirection is set in the generation config, this clause
 // expects that it was already checked that there is an available candidate.
 type WriteTarget struct {
-	TargetType schema.StructuralType
+	TargetType schema.StructuralType `ast:"targetType"`
 	// If true, then the write target this will generate will end up being deleted
-	GetsDeleted bool
+	GetsDeleted bool `ast:"getsDeleted"`
 }
 
 // Generate subclauses for WriteTarget
@@ -64,9 +64,9 @@ func (c *Expression) Transform(seed *seed.Seed, s *schema.Schema, subclauses []t
 					)
 				}
 
-				// Don't transform to `x/1` if division is inaccurate
+				// Don't transform to `x/1` if division is inaccurate, unless it's a float division whose inaccuracy gets tolerated
 				choices := []string{"((%s) * 1)", "(1 * (%s))"}
-				if !config.GetConfig().InaccurateDivision {
+				if !config.GetConfig().InaccurateDivision || (c.Conf.PropertyType == schema.Float && config.HasFloatTolerance()) {
 					choices = append(choices, "((%s) / 1)")
 				}
 				return helperclauses.CreateAssembler(
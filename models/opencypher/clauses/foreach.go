@@ -19,7 +19,7 @@ func (c Foreach) TemplateString() string {
 }
 
 type ForeachClause struct {
-	oldSchema schema.Schema
+	oldSchema schema.Schema `ast:"oldSchema"`
 }
 
 // Generate subclauses for ForeachClause
@@ -46,8 +46,8 @@ func (c ForeachClause) ModifySchema(s *schema.Schema) {
 }
 
 type ForeachVariable struct {
-	name    string
-	varConf schema.ExpressionConfig
+	name    string                  `ast:"name"`
+	varConf schema.ExpressionConfig `ast:"varConf"`
 }
 
 // Generate subclauses for ForeachVariable
@@ -17,8 +17,8 @@ import (
 // It never generates a function in the list of forbidden functions
 // defined in the generation config.
 type FunctionApplicationExpression struct {
-	Conf   schema.ExpressionConfig
-	target *schema.Function
+	Conf   schema.ExpressionConfig `ast:"conf"`
+	target *schema.Function        `ast:"target"`
 }
 
 // Generate subclauses for FunctionApplicationExpression
@@ -136,8 +136,8 @@ func (c FunctionApplicationExpression) TemplateString() string {
 }
 
 type CountFunction struct {
-	distinct bool
-	asterisk bool
+	distinct bool `ast:"distinct"`
+	asterisk bool `ast:"asterisk"`
 }
 
 // Generate subclauses for CountFunction
@@ -8,7 +8,7 @@ import (
 )
 
 type Labels struct {
-	LabelType schema.StructuralType
+	LabelType schema.StructuralType `ast:"labelType"`
 }
 
 // Generate subclauses for Labels
@@ -27,7 +27,7 @@ func (c *Labels) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause
 }
 
 type Label struct {
-	LabelType schema.StructuralType
+	LabelType schema.StructuralType `ast:"labelType"`
 }
 
 // Generate subclauses for Label
@@ -41,7 +41,7 @@ func (c Label) TemplateString() string {
 }
 
 type LabelName struct {
-	LabelType schema.StructuralType
+	LabelType schema.StructuralType `ast:"labelType"`
 }
 
 // Generate subclauses for LabelName
@@ -53,8 +53,8 @@ func (c *LabelName) Generate(seed *seed.Seed, s *schema.Schema) []translator.Cla
 }
 
 type NewLabel struct {
-	LabelType schema.StructuralType
-	name      string
+	LabelType schema.StructuralType `ast:"labelType"`
+	name      string                `ast:"name"`
 }
 
 // Generate subclauses for NewLabel
@@ -74,8 +74,8 @@ func (c NewLabel) TemplateString() string {
 }
 
 type ExistingLabel struct {
-	LabelType schema.StructuralType
-	name      string
+	LabelType schema.StructuralType `ast:"labelType"`
+	name      string                `ast:"name"`
 }
 
 // Generate subclauses for ExistingLabel
@@ -100,13 +100,13 @@ func (c ExistingLabel) TemplateString() string {
 }
 
 type LabelMatch struct {
-	LabelType schema.StructuralType
+	LabelType schema.StructuralType `ast:"labelType"`
 	// Whether this clause should stop recursing and generate a single label instead of combining two more subclauses
-	isBasecase bool
-	operator   string
-	isNegated  bool
+	isBasecase bool   `ast:"isBasecase"`
+	operator   string `ast:"operator"`
+	isNegated  bool   `ast:"isNegated"`
 	// For the template string
-	useNewSyntax bool
+	useNewSyntax bool `ast:"useNewSyntax"`
 }
 
 // Generate subclauses for LabelMatch
@@ -153,9 +153,9 @@ func (c LabelMatch) NoStrategyReduce(clause *helperclauses.ClauseCapturer) trans
 }
 
 type OptionalLabelMatch struct {
-	LabelType schema.StructuralType
+	LabelType schema.StructuralType `ast:"labelType"`
 	// Whether a label match will be generated
-	generateMatch bool
+	generateMatch bool `ast:"generateMatch"`
 }
 
 // Generate subclauses for OptionalLabelMatch
@@ -8,8 +8,8 @@ import (
 )
 
 type ListExpression struct {
-	Conf   schema.ExpressionConfig
-	isNull bool
+	Conf   schema.ExpressionConfig `ast:"conf"`
+	isNull bool                    `ast:"isNull"`
 }
 
 // Generate subclauses for ListExpression
@@ -47,7 +47,7 @@ func (c ListExpression) TemplateString() string {
 }
 
 type ListLiteral struct {
-	Conf schema.ExpressionConfig
+	Conf schema.ExpressionConfig `ast:"conf"`
 }
 
 // Generate subclauses for ListLiteral
@@ -65,8 +65,8 @@ func (c ListLiteral) TemplateString() string {
 }
 
 type ListLiteralItem struct {
-	Conf       schema.ExpressionConfig
-	isBasecase bool
+	Conf       schema.ExpressionConfig `ast:"conf"`
+	isBasecase bool                    `ast:"isBasecase"`
 }
 
 // Generate subclauses for ListLiteralItem
@@ -88,8 +88,8 @@ func (c ListLiteralItem) TemplateString() string {
 }
 
 type ListComprehension struct {
-	Conf      schema.ExpressionConfig
-	oldSchema schema.Schema
+	Conf      schema.ExpressionConfig `ast:"conf"`
+	oldSchema schema.Schema           `ast:"oldSchema"`
 }
 
 // Generate subclauses for ListComprehension
@@ -111,8 +111,8 @@ func (c ListComprehension) ModifySchema(s *schema.Schema) {
 }
 
 type ListComprehensionPrefix struct {
-	Conf     schema.ExpressionConfig
-	iterator string
+	Conf     schema.ExpressionConfig `ast:"conf"`
+	iterator string                  `ast:"iterator"`
 }
 
 // Generate subclauses for ListComprehensionPrefix
@@ -132,7 +132,7 @@ func (c ListComprehensionPrefix) ModifySchema(s *schema.Schema) {
 }
 
 type ListComprehensionSuffix struct {
-	Conf schema.ExpressionConfig
+	Conf schema.ExpressionConfig `ast:"conf"`
 }
 
 // Generate subclauses for ListComprehensionSuffix
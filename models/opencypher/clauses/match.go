@@ -16,7 +16,7 @@ func (c *Match) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause
 }
 
 type MatchClause struct {
-	isOptionalMatch bool
+	isOptionalMatch bool `ast:"isOptionalMatch"`
 }
 
 // Generate subclauses for MatchClause
@@ -38,8 +38,8 @@ func (c MatchClause) TemplateString() string {
 }
 
 type MatchElementChain struct {
-	IsBaseCase bool
-	IsOptional bool
+	IsBaseCase bool `ast:"isBaseCase"`
+	IsOptional bool `ast:"isOptional"`
 }
 
 // Generate subclauses for MatchElementChain
@@ -67,7 +67,7 @@ func (c MatchElementChain) NoStrategyReduce(clause *helperclauses.ClauseCapturer
 }
 
 type OptionalWhereClause struct {
-	willGenerate bool
+	willGenerate bool `ast:"willGenerate"`
 }
 
 // Generate subclauses for OptionalWhereClause
@@ -20,9 +20,9 @@ func (c *Merge) TemplateString() string {
 }
 
 type MergeClause struct {
-	hasOnCreate                            bool
-	hasOnMatch                             bool
-	oldAllowOnlyNonNullPropertyExpressions bool
+	hasOnCreate                            bool `ast:"hasOnCreate"`
+	hasOnMatch                             bool `ast:"hasOnMatch"`
+	oldAllowOnlyNonNullPropertyExpressions bool `ast:"oldAllowOnlyNonNullPropertyExpressions"`
 }
 
 // Generate subclauses for MergeClause
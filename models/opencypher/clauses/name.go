@@ -16,8 +16,8 @@ func getOptionalStructureName(nameType schema.StructuralType, likelyNull bool) *
 }
 
 type OptionalStructureName struct {
-	NameType   *schema.StructuralType
-	likelyNull bool
+	NameType   *schema.StructuralType `ast:"nameType"`
+	likelyNull bool                   `ast:"likelyNull"`
 }
 
 // Generate subclauses for OptionalStructureName
@@ -29,9 +29,9 @@ func (c *OptionalStructureName) Generate(seed *seed.Seed, s *schema.Schema) []tr
 }
 
 type StructureName struct {
-	name       string
-	NameType   *schema.StructuralType
-	likelyNull bool
+	name       string                 `ast:"name"`
+	NameType   *schema.StructuralType `ast:"nameType"`
+	likelyNull bool                   `ast:"likelyNull"`
 }
 
 // Generate subclauses for StructureName
@@ -50,9 +50,9 @@ func (c StructureName) TemplateString() string {
 }
 
 type PropertyName struct {
-	name           string
-	NameType       *schema.PropertyType
-	UseExstingName bool // Used by the prometheus exporter for tracking dependencies
+	name           string               `ast:"name"`
+	NameType       *schema.PropertyType `ast:"nameType"`
+	UseExstingName bool                 `ast:"useExstingName"` // Used by the prometheus exporter for tracking dependencies
 }
 
 // Generate subclauses for PropertyName
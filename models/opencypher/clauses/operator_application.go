@@ -7,8 +7,8 @@ import (
 )
 
 type OperatorApplicationExpression struct {
-	Conf           schema.ExpressionConfig
-	templateString string
+	Conf           schema.ExpressionConfig `ast:"conf"`
+	templateString string                  `ast:"templateString"`
 }
 
 // Generate subclauses for OperatorApplicationExpression
@@ -10,10 +10,10 @@ import (
 )
 
 type PathPatternExpression struct {
-	direction relationshipDirection
+	direction relationshipDirection `ast:"direction"`
 	// If this is the child of another path pattern expression
-	isChild    bool
-	IsOptional bool
+	isChild    bool `ast:"isChild"`
+	IsOptional bool `ast:"isOptional"`
 }
 
 // Generate subclauses for PathPatternExpression
@@ -58,7 +58,7 @@ func (c PathPatternExpression) NoStrategyReduce(clause *helperclauses.ClauseCapt
 }
 
 type MatchNode struct {
-	IsOptional bool
+	IsOptional bool `ast:"isOptional"`
 }
 
 // Generate subclauses for MatchNode
@@ -72,10 +72,10 @@ func (c *MatchNode) TemplateString() string {
 }
 
 type MatchRelationship struct {
-	minVariableLength *int
-	maxVariableLength *int
-	hasStructureName  bool
-	IsOptional        bool
+	minVariableLength *int `ast:"minVariableLength"`
+	maxVariableLength *int `ast:"maxVariableLength"`
+	hasStructureName  bool `ast:"hasStructureName"`
+	IsOptional        bool `ast:"isOptional"`
 }
 
 // Generate subclauses for MatchRelationship
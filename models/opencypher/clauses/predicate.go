@@ -7,9 +7,9 @@ import (
 )
 
 type Predicate struct {
-	Conf      schema.ExpressionConfig
-	oldSchema schema.Schema
-	funcName  string
+	Conf      schema.ExpressionConfig `ast:"conf"`
+	oldSchema schema.Schema           `ast:"oldSchema"`
+	funcName  string                  `ast:"funcName"`
 }
 
 // Generate subclauses for Predicate
@@ -31,9 +31,9 @@ func (c Predicate) ModifySchema(s *schema.Schema) {
 
 // The PredicatePrefix represents the left part of the predicate which defines a new variable.
 type PredicatePrefix struct {
-	iteratorName string
-	Conf         schema.ExpressionConfig
-	iteratorConf schema.ExpressionConfig
+	iteratorName string                  `ast:"iteratorName"`
+	Conf         schema.ExpressionConfig `ast:"conf"`
+	iteratorConf schema.ExpressionConfig `ast:"iteratorConf"`
 }
 
 // Generate subclauses for PredicatePrefix
@@ -1,6 +1,8 @@
 package clauses
 
 import (
+	"strings"
+
 	"github.com/Anon10214/dinkel/models/opencypher/schema"
 	"github.com/Anon10214/dinkel/seed"
 	"github.com/Anon10214/dinkel/translator"
@@ -20,10 +22,10 @@ func (c Properties) TemplateString() string {
 }
 
 type PropertyChain struct {
-	isBasecase bool
+	isBasecase bool `ast:"isBasecase"`
 
 	// Probability of choosing to create a new property instead of using an existing one
-	CreateNewPropertyProbability float64
+	CreateNewPropertyProbability float64 `ast:"createNewPropertyProbability"`
 }
 
 // Generate subclauses for PropertyChain
@@ -79,7 +81,7 @@ func (c *Property) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clau
 }
 
 type NewProperty struct {
-	name string
+	name string `ast:"name"`
 }
 
 // Generate subclauses for NewProperty
@@ -101,8 +103,8 @@ func (c NewProperty) TemplateString() string {
 }
 
 type ExistingProperty struct {
-	name  string
-	value string
+	name  string `ast:"name"`
+	value string `ast:"value"`
 }
 
 // Generate subclauses for ExistingProperty
@@ -138,7 +140,8 @@ func (c ExistingProperty) TemplateString() string {
 	if c.value == "" {
 		return c.name + ":%s"
 	}
-	return c.name + ":" + c.value
+	// The value is a literal which may contain percent signs, e.g. within a string
+	return c.name + ":" + strings.ReplaceAll(c.value, "%", "%%")
 }
 
 type OptionalPropertyMatch struct{}
@@ -13,8 +13,8 @@ import (
 )
 
 type PropertyLiteral struct {
-	Conf  schema.ExpressionConfig
-	value string
+	Conf  schema.ExpressionConfig `ast:"conf"`
+	value string                  `ast:"value"`
 }
 
 // Generate subclauses for PropertyLiteral
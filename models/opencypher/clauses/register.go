@@ -0,0 +1,37 @@
+package clauses
+
+import "github.com/Anon10214/dinkel/translator/helperclauses"
+
+// Register the clauses, allowing ASTs generated by OpenCypher to be loaded from bug reports.
+func init() {
+	helperclauses.RegisterClauses(
+		&CallSubquery{}, &CallSubqueryClause{}, &CallSubqueryWith{},
+		&CaseExpression{}, &SimpleCaseExpression{}, &GenericCaseExpression{}, &CaseExpressionWhen{}, &CaseExpressionElse{},
+		&Create{}, &CreateClause{}, &CreateElementChain{}, &CreateElement{}, &CreatePathElement{}, &CreateNode{}, &CreateNewNode{}, &CreateExistingNode{},
+		&Delete{}, &DeleteClause{}, &DeleteElementChain{},
+		&Expression{}, &VariableExpression{}, &WriteTarget{},
+		&Foreach{}, &ForeachClause{}, &ForeachVariable{}, &ForeachCommand{},
+		&FunctionApplicationExpression{}, &CountFunction{},
+		&Index{},
+		&Labels{}, &Label{}, &LabelName{}, &NewLabel{}, &ExistingLabel{}, &LabelMatch{}, &OptionalLabelMatch{},
+		&ListExpression{}, &ListLiteral{}, &ListLiteralItem{}, &ListComprehension{}, &ListComprehensionPrefix{}, &ListComprehensionSuffix{},
+		&Match{}, &MatchClause{}, &MatchElementChain{}, &OptionalWhereClause{}, &WhereClause{}, &WhereExpression{},
+		&Merge{}, &MergeClause{},
+		&OptionalStructureName{}, &StructureName{}, &PropertyName{},
+		&OperatorApplicationExpression{},
+		&PathPatternExpression{}, &MatchNode{}, &MatchRelationship{},
+		&Predicate{}, &PredicatePrefix{},
+		&Properties{}, &PropertyChain{}, &OptionalProperties{}, &Property{}, &NewProperty{}, &ExistingProperty{}, &OptionalPropertyMatch{}, &PropertiesMatch{},
+		&PropertyLiteral{}, &StringLiteral{},
+		&Remove{}, &RemoveClause{}, &RemoveSubclause{}, &RemovePropertyExpression{}, &RemoveLabelExpression{},
+		&Return{}, &ReturnElementChain{}, &ReturnElement{}, &PredeterminedReturn{}, &OptionalOrderBy{}, &OrderByExpressionChain{}, &OrderByExpression{}, &OptionalLimit{}, &OptionalSkip{},
+		&ReadClause{}, &WriteClause{}, &OptionalWriteQuery{}, &EmptyClause{},
+		&Set{}, &SetClause{}, &SetExpression{}, &SetPropertyExpression{}, &SetLabelExpression{},
+		&Exists{}, &Count{}, &Collect{}, &SimpleSubqueryExpressionBody{}, &SubqueryExpression{}, &SubqueryExpressionBody{}, &SubqueryExpressionBodyPart{},
+		&Tautum{}, &Falsum{}, &DeadCode{}, &NonexistantPattern{}, &TautumPartition{}, &FalsumPartition{},
+		&TransformablePath{}, &ReversiblePath{},
+		&Union{}, &UnionClause{},
+		&Unwind{}, &UnwindClause{},
+		&With{}, &WithClause{}, &WithElementChain{}, &WithElement{},
+	)
+}
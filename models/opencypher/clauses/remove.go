@@ -36,7 +36,7 @@ func (c RemoveClause) TemplateString() string {
 }
 
 type RemoveSubclause struct {
-	isBasecase bool
+	isBasecase bool `ast:"isBasecase"`
 }
 
 // Generate subclauses for RemoveSubclause
@@ -91,7 +91,7 @@ func (c RemovePropertyExpression) TemplateString() string {
 // a structural variable.
 // If there is no available candidate, it defaults to generating a RemovePropertyExpression.
 type RemoveLabelExpression struct {
-	name string
+	name string `ast:"name"`
 }
 
 // Generate subclauses for RemoveLabelExpression
@@ -1,8 +1,10 @@
 package clauses
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/Anon10214/dinkel/models/opencypher/config"
 	"github.com/Anon10214/dinkel/models/opencypher/schema"
 	"github.com/Anon10214/dinkel/seed"
 	"github.com/Anon10214/dinkel/translator"
@@ -11,7 +13,12 @@ import (
 
 type Return struct {
 	// If the return is predetermined
-	isPredetermined bool
+	isPredetermined bool `ast:"isPredetermined"`
+
+	// The clauses following the returned elements, deciding how the returned rows are ordered
+	orderBy *OptionalOrderBy `ast:"orderBy"`
+	skip    *OptionalSkip    `ast:"skip"`
+	limit   *OptionalLimit   `ast:"limit"`
 }
 
 // Generate subclauses for Return
@@ -27,16 +34,23 @@ func (c *Return) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause
 		return []translator.Clause{&PredeterminedReturn{}}
 	}
 
+	c.orderBy, c.skip, c.limit = &OptionalOrderBy{}, &OptionalSkip{}, &OptionalLimit{}
+
 	subclauses := []translator.Clause{optionalClause(seed, helperclauses.CreateStringer("DISTINCT"))}
 	if len(s.PropertyVariablesByType[schema.AnyType])+len(s.StructuralVariablesByType[schema.ANY]) != 0 &&
 		seed.RandomBoolean() && !s.IsInSubquery && !s.DisallowReturnAll {
 		// Generate RETURN * if variables in scope and not in subquery
 		subclauses = append(subclauses, helperclauses.CreateStringer("*"))
+	} else if config.GeneratesOrderedReturns() && !s.IsInSubquery && seed.BooleanWithProbability(0.25) {
+		// Order the rows by the returned elements, allowing results to be compared in order
+		elements := &ReturnElementChain{Orderable: true}
+		c.orderBy.ReturnElements = elements
+		subclauses = append(subclauses, elements)
 	} else {
 		subclauses = append(subclauses, &ReturnElementChain{})
 	}
 
-	return append(subclauses, &OptionalOrderBy{}, &OptionalSkip{}, &OptionalLimit{})
+	return append(subclauses, c.orderBy, c.skip, c.limit)
 }
 
 // TemplateString for Return
@@ -47,17 +61,45 @@ func (c Return) TemplateString() string {
 	return "RETURN %s %s %s %s %s"
 }
 
+// ModifySchema sets the order of the returned rows
+func (c Return) ModifySchema(s *schema.Schema) {
+	if c.isPredetermined {
+		return
+	}
+	s.ReturnSortColumns = 0
+	switch {
+	case !c.orderBy.isGenerated:
+		s.ReturnOrder = schema.Unordered
+	case c.orderBy.ReturnElements != nil && c.orderBy.columns == len(c.orderBy.ReturnElements.aliases()):
+		s.ReturnOrder = schema.TotallyOrdered
+	case c.skip.willGenerate || c.limit.willGenerate:
+		s.ReturnOrder = schema.TruncatedTies
+		if c.orderBy.ReturnElements != nil {
+			s.ReturnSortColumns = c.orderBy.columns
+		}
+	default:
+		s.ReturnOrder = schema.Unordered
+	}
+}
+
 type ReturnElementChain struct {
-	isBasecase bool
+	// If set, only elements of types with a total order get returned
+	Orderable bool `ast:"orderable"`
+
+	isBasecase bool                `ast:"isBasecase"`
+	element    *ReturnElement      `ast:"element"`
+	next       *ReturnElementChain `ast:"next"`
 }
 
 // Generate subclauses for ReturnElementChain
 func (c *ReturnElementChain) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause {
 	c.isBasecase = seed.RandomBoolean()
 
-	subclauses := []translator.Clause{&ReturnElement{}}
+	c.element = &ReturnElement{Orderable: c.Orderable}
+	subclauses := []translator.Clause{c.element}
 	if !c.isBasecase {
-		subclauses = append(subclauses, &ReturnElementChain{})
+		c.next = &ReturnElementChain{Orderable: c.Orderable}
+		subclauses = append(subclauses, c.next)
 	}
 
 	return subclauses
@@ -75,11 +117,31 @@ func (c ReturnElementChain) NoStrategyReduce(clause *helperclauses.ClauseCapture
 	return clause.GetSubclauseClauseCapturers()[0]
 }
 
-type ReturnElement struct{}
+// aliases returns the aliases of the returned elements, in the order they are returned.
+// Only valid once the chain has been generated.
+func (c ReturnElementChain) aliases() []string {
+	aliases := []string{c.element.alias.name}
+	if c.next != nil {
+		aliases = append(aliases, c.next.aliases()...)
+	}
+	return aliases
+}
+
+type ReturnElement struct {
+	// If set, the returned expression evaluates to a property of a type with a total order
+	Orderable bool `ast:"orderable"`
+
+	alias *StructureName `ast:"alias"`
+}
 
 // Generate subclauses for ReturnElement
 func (c *ReturnElement) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause {
-	return []translator.Clause{&Expression{Conf: schema.ExpressionConfig{AllowMaps: true, CanContainAggregatingFunctions: true}}, &StructureName{}}
+	conf := schema.ExpressionConfig{AllowMaps: true, CanContainAggregatingFunctions: true}
+	if c.Orderable {
+		conf = schema.ExpressionConfig{TargetType: schema.PropertyValue, PropertyType: generateOrderablePropertyType(seed), CanContainAggregatingFunctions: true}
+	}
+	c.alias = &StructureName{}
+	return []translator.Clause{&Expression{Conf: conf}, c.alias}
 }
 
 // TemplateString for ReturnElement
@@ -90,7 +152,7 @@ func (c ReturnElement) TemplateString() string {
 // A PredeterminedReturn has its return names and types defined in the schema
 // and is only generated if the schema's MustReturn boolean is set to true
 type PredeterminedReturn struct {
-	aliases []string
+	aliases []string `ast:"aliases"`
 }
 
 // Generate subclauses for PredeterminedReturn
@@ -125,11 +187,29 @@ func (c PredeterminedReturn) TemplateString() string {
 }
 
 type OptionalOrderBy struct {
-	isGenerated bool
+	// If set, the ORDER BY is always generated and orders the rows by the chain's leading returned elements
+	ReturnElements *ReturnElementChain `ast:"returnElements"`
+
+	isGenerated bool `ast:"isGenerated"`
+	// The amount of returned elements ordered by
+	columns int `ast:"columns"`
 }
 
 // Generate subclauses for OptionalOrderBy
 func (c *OptionalOrderBy) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause {
+	if c.ReturnElements != nil {
+		c.isGenerated = true
+		var subclauses []translator.Clause
+		aliases := c.ReturnElements.aliases()
+		// Ordering by only some of the elements lets SKIP and LIMIT cut through ties of known sort keys
+		aliases = aliases[:1+seed.GetRandomIntn(len(aliases))]
+		// Always add the sort order, some targets reject a space before the comma separating sort items
+		for _, alias := range aliases {
+			subclauses = append(subclauses, helperclauses.CreateStringer(alias+" "+seed.RandomStringFromChoice("ASC", "DESC")))
+		}
+		c.columns = len(subclauses)
+		return subclauses
+	}
 	if seed.RandomBoolean() {
 		return nil
 	}
@@ -142,11 +222,14 @@ func (c OptionalOrderBy) TemplateString() string {
 	if !c.isGenerated {
 		return ""
 	}
+	if c.ReturnElements != nil {
+		return "ORDER BY " + strings.TrimSuffix(strings.Repeat("%s, ", c.columns), ", ")
+	}
 	return "ORDER BY %s"
 }
 
 type OrderByExpressionChain struct {
-	isBasecase bool
+	isBasecase bool `ast:"isBasecase"`
 }
 
 // Generate subclauses for OrderByExpressionChain
@@ -170,7 +253,7 @@ func (c OrderByExpressionChain) TemplateString() string {
 }
 
 type OrderByExpression struct {
-	orderByType string
+	orderByType string `ast:"orderByType"`
 }
 
 // Generate subclauses for OrderByExpression
@@ -190,7 +273,7 @@ func (c OrderByExpression) TemplateString() string {
 }
 
 type OptionalLimit struct {
-	willGenerate bool
+	willGenerate bool `ast:"willGenerate"`
 }
 
 // Generate subclauses for OptionalLimit
@@ -211,13 +294,19 @@ func (c OptionalLimit) TemplateString() string {
 }
 
 type OptionalSkip struct {
-	willGenerate bool
+	// If set, a constant below MaxValue is skipped instead of a generated expression
+	MaxValue int `ast:"maxValue"`
+
+	willGenerate bool `ast:"willGenerate"`
 }
 
 // Generate subclauses for OptionalSkip
 func (c *OptionalSkip) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause {
 	if seed.RandomBoolean() {
 		c.willGenerate = true
+		if c.MaxValue != 0 {
+			return []translator.Clause{helperclauses.CreateStringer(fmt.Sprintf("(%d)", seed.GetRandomIntn(c.MaxValue)))}
+		}
 		return []translator.Clause{&Expression{Conf: schema.ExpressionConfig{TargetType: schema.PropertyValue, PropertyType: schema.PositiveInteger, MustBeNonNull: true, IsConstantExpression: true}}}
 	}
 	return nil
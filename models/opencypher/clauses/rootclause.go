@@ -10,7 +10,7 @@ import (
 type ReadClause struct {
 	// If this is set, a generated WITH cannot generate additonal ORDER BY, SKIP or LIMIT clauses.
 	// Used by CALL {} subquery and UNION
-	SimpleWithClause bool
+	SimpleWithClause bool `ast:"simpleWithClause"`
 }
 
 // Generate subclauses for RootClause
@@ -114,7 +114,7 @@ func (c WriteClause) Transform(seed *seed.Seed, s *schema.Schema, subclauses []t
 	case 2:
 		var toDelete []string
 		for seed.RandomBoolean() {
-			for delVar := range s.DeletedVars {
+			for _, delVar := range sortedKeys(s.DeletedVars) {
 				if seed.RandomBoolean() {
 					toDelete = append(toDelete, delVar)
 				}
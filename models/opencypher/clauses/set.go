@@ -37,7 +37,7 @@ func (c SetClause) TemplateString() string {
 }
 
 type SetExpression struct {
-	isBasecase bool
+	isBasecase bool `ast:"isBasecase"`
 }
 
 // Generate subclauses for SetExpression
@@ -68,11 +68,11 @@ func (c SetExpression) NoStrategyReduce(clause *helperclauses.ClauseCapturer) tr
 }
 
 type SetPropertyExpression struct {
-	name string
+	name string `ast:"name"`
 	// Whether the set clause assigns a map or just a single value
-	isMapAssign bool
+	isMapAssign bool `ast:"isMapAssign"`
 	// If this is an addition of a map instead of just an assign
-	isMapAddition bool
+	isMapAddition bool `ast:"isMapAddition"`
 }
 
 // Generate subclauses for SetPropertyExpression
@@ -121,7 +121,7 @@ func (c SetPropertyExpression) TemplateString() string {
 }
 
 type SetLabelExpression struct {
-	name string
+	name string `ast:"name"`
 }
 
 // Generate subclauses for SetLabelExpression
@@ -37,7 +37,7 @@ func (c Count) TemplateString() string {
 }
 
 type Collect struct {
-	Conf schema.ExpressionConfig
+	Conf schema.ExpressionConfig `ast:"conf"`
 }
 
 // Generate subclauses for Collect
@@ -54,7 +54,7 @@ func (c Collect) TemplateString() string {
 
 // Only has a path pattern and an optional WHERE clause
 type SimpleSubqueryExpressionBody struct {
-	oldSchema schema.Schema
+	oldSchema schema.Schema `ast:"oldSchema"`
 }
 
 // Generate subclauses for SimpleSubqueryExpressionBody
@@ -75,9 +75,9 @@ func (c SimpleSubqueryExpressionBody) ModifySchema(s *schema.Schema) {
 }
 
 type SubqueryExpression struct {
-	ColumnNameToReturn       string
-	ColumnExpressionToReturn schema.ExpressionConfig
-	oldSchema                schema.Schema
+	ColumnNameToReturn       string                  `ast:"columnNameToReturn"`
+	ColumnExpressionToReturn schema.ExpressionConfig `ast:"columnExpressionToReturn"`
+	oldSchema                schema.Schema           `ast:"oldSchema"`
 }
 
 // Generate subclauses for SubqueryExpression
@@ -127,10 +127,10 @@ func (c SubqueryExpression) ModifySchema(s *schema.Schema) {
 
 type SubqueryExpressionBody struct {
 	// If unset, no union. If set and true, UNION ALL, if set and false, UNION
-	IsUnionAll *bool
+	IsUnionAll *bool `ast:"isUnionAll"`
 	// If this body generates a UNION
-	hasUnion   bool
-	MustReturn bool
+	hasUnion   bool `ast:"hasUnion"`
+	MustReturn bool `ast:"mustReturn"`
 }
 
 // Generate subclauses for SubqueryExpressionBody
@@ -165,8 +165,8 @@ func (c SubqueryExpressionBody) TemplateString() string {
 }
 
 type SubqueryExpressionBodyPart struct {
-	oldSchema  schema.Schema
-	MustReturn bool
+	oldSchema  schema.Schema `ast:"oldSchema"`
+	MustReturn bool          `ast:"mustReturn"`
 }
 
 // Generate subclauses for SubqueryExpressionBodyPart
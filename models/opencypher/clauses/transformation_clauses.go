@@ -23,7 +23,7 @@ func generateExpressionAST(conf schema.ExpressionConfig, seed *seed.Seed, schema
 
 // Tautum always evaluates to true
 type Tautum struct {
-	conf schema.ExpressionConfig
+	conf schema.ExpressionConfig `ast:"conf"`
 }
 
 func (c *Tautum) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause {
@@ -43,7 +43,7 @@ func (c *Tautum) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause
 
 // Falsum always evaluates to false
 type Falsum struct {
-	conf schema.ExpressionConfig
+	conf schema.ExpressionConfig `ast:"conf"`
 }
 
 func (c *Falsum) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause {
@@ -62,7 +62,7 @@ func (c *Falsum) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause
 }
 
 type DeadCode struct {
-	oldAllowOnlyNonNullPropertyExpressions bool
+	oldAllowOnlyNonNullPropertyExpressions bool `ast:"oldAllowOnlyNonNullPropertyExpressions"`
 }
 
 func (c *DeadCode) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause {
@@ -91,10 +91,10 @@ func (c *DeadCode) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clau
 		createElem := helperclauses.GetClauseCapturerForClause(&CreateElement{})
 		createElem.GenerateAST(seed, s)
 		vars := ""
-		for v := range s.PropertyVariablesByName {
+		for _, v := range sortedKeys(s.PropertyVariablesByName) {
 			vars += v + ", "
 		}
-		for v := range s.StructuralVariablesByName {
+		for _, v := range sortedKeys(s.StructuralVariablesByName) {
 			vars += v + ", "
 		}
 
@@ -245,7 +245,7 @@ func (c *NonexistantPattern) Generate(seed *seed.Seed, s *schema.Schema) []trans
 //
 //	(P) or (not P) or (P IS NULL)
 type TautumPartition struct {
-	conf schema.ExpressionConfig
+	conf schema.ExpressionConfig `ast:"conf"`
 }
 
 func (c *TautumPartition) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause {
@@ -270,7 +270,7 @@ func (c *TautumPartition) Generate(seed *seed.Seed, s *schema.Schema) []translat
 //
 //	(P) and (not P) and (P IS NOT NULL)
 type FalsumPartition struct {
-	conf schema.ExpressionConfig
+	conf schema.ExpressionConfig `ast:"conf"`
 }
 
 func (c *FalsumPartition) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause {
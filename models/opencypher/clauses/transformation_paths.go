@@ -10,8 +10,8 @@ import (
 )
 
 type TransformablePath struct {
-	isChild    bool
-	IsOptional bool
+	isChild    bool `ast:"isChild"`
+	IsOptional bool `ast:"isOptional"`
 }
 
 func (c *TransformablePath) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause {
@@ -25,10 +25,10 @@ func (c *TransformablePath) Generate(seed *seed.Seed, s *schema.Schema) []transl
 //
 //	(a)-[b]->(c) ==> (c)<-[b]-(a)
 type ReversiblePath struct {
-	direction              relationshipDirection
-	templateString         string
-	reversedTemplateString string
-	IsOptional             bool
+	direction              relationshipDirection `ast:"direction"`
+	templateString         string                `ast:"templateString"`
+	reversedTemplateString string                `ast:"reversedTemplateString"`
+	IsOptional             bool                  `ast:"isOptional"`
 }
 
 func (c *ReversiblePath) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause {
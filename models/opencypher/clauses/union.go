@@ -8,7 +8,7 @@ import (
 )
 
 type Union struct {
-	isUnionAll bool
+	isUnionAll bool `ast:"isUnionAll"`
 }
 
 // Generate subclauses for Union
@@ -41,7 +41,7 @@ func (c Union) NoStrategyReduce(clause *helperclauses.ClauseCapturer) translator
 }
 
 type UnionClause struct {
-	oldSchema schema.Schema
+	oldSchema schema.Schema `ast:"oldSchema"`
 }
 
 // Generate subclauses for UnionClause
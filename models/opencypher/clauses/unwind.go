@@ -21,8 +21,8 @@ func (c Unwind) TemplateString() string {
 // TODO: Add more deeply nested lists?
 
 type UnwindClause struct {
-	name           string
-	VariableConfig schema.ExpressionConfig
+	name           string                  `ast:"name"`
+	VariableConfig schema.ExpressionConfig `ast:"variableConfig"`
 }
 
 // Generate subclauses for UnwindClause
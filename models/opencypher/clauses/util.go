@@ -1,6 +1,7 @@
 package clauses
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/Anon10214/dinkel/models/opencypher/config"
@@ -9,6 +10,17 @@ import (
 	"github.com/Anon10214/dinkel/translator"
 )
 
+// sortedKeys returns the keys of the passed map in ascending order.
+// Iterating over maps in random order while drawing from the seed would make regenerating statements from their byte strings impossible.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
 func generatePropertyType(seed *seed.Seed) schema.PropertyType {
 	conf := config.GetConfig()
 	for {
@@ -28,6 +40,21 @@ func generatePropertyType(seed *seed.Seed) schema.PropertyType {
 	}
 }
 
+// The property types whose values are totally ordered, with values only tying if they are equal.
+// Excludes types whose ordering is implementation-defined, such as durations and points.
+var orderablePropertyTypes = []schema.PropertyType{schema.Boolean, schema.Date, schema.Float, schema.Integer, schema.LocalDateTime, schema.LocalTime, schema.String}
+
+// generateOrderablePropertyType returns an allowed property type out of [orderablePropertyTypes]
+func generateOrderablePropertyType(seed *seed.Seed) schema.PropertyType {
+	conf := config.GetConfig()
+	for {
+		genType := orderablePropertyTypes[seed.GetRandomIntn(len(orderablePropertyTypes))]
+		if !slices.Contains(conf.DisallowedPropertyTypes, genType) {
+			return genType
+		}
+	}
+}
+
 func addVariableToSchema(s *schema.Schema, name string, Conf schema.ExpressionConfig) {
 	var mask int
 	if Conf.IsList {
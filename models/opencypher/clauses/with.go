@@ -9,7 +9,7 @@ import (
 )
 
 type With struct {
-	SimpleWithClause bool
+	SimpleWithClause bool `ast:"simpleWithClause"`
 }
 
 // Generate subclauses for With
@@ -33,7 +33,7 @@ func (c With) NoStrategyReduce(clause *helperclauses.ClauseCapturer) translator.
 }
 
 type WithClause struct {
-	IsIncludeAll bool
+	IsIncludeAll bool `ast:"isIncludeAll"`
 }
 
 // Generate subclauses for WithClause
@@ -56,10 +56,10 @@ func (c WithClause) TemplateString() string {
 }
 
 type WithElementChain struct {
-	isBasecase bool
+	isBasecase bool `ast:"isBasecase"`
 
-	elementName   string
-	elementConfig schema.ExpressionConfig
+	elementName   string                  `ast:"elementName"`
+	elementConfig schema.ExpressionConfig `ast:"elementConfig"`
 }
 
 // Generate subclauses for WithElementChain
@@ -98,8 +98,8 @@ func (c WithElementChain) NoStrategyReduce(clause *helperclauses.ClauseCapturer)
 }
 
 type WithElement struct {
-	Name string
-	Conf schema.ExpressionConfig
+	Name string                  `ast:"name"`
+	Conf schema.ExpressionConfig `ast:"conf"`
 }
 
 // Generate subclauses for WithElement
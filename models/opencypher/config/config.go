@@ -20,7 +20,9 @@ type Config struct {
 	// Whether WITH * is invalid if there are no variables in scope
 	AsteriskNeedsTargets bool
 	// Integer division may always be inaccurate.
-	// This option disallows equivalence transformations such as `x` -> `x/1`
+	// This option disallows equivalence transformations such as `x` -> `x/1`.
+	// Float expressions may still be transformed if the target's results get compared
+	// with a float tolerance, see [SetFloatTolerance].
 	InaccurateDivision bool
 	// These property types won't be generated
 	DisallowedPropertyTypes []schema.PropertyType
@@ -48,6 +50,12 @@ type Config struct {
 // The config currently in use for generation
 var usedConfig Config
 
+// If the target's results get compared with a float tolerance
+var floatTolerance bool
+
+// If returns ordered by all returned elements get generated
+var orderedReturns bool
+
 // SetConfig sets the generation config to be used by all clauses.
 func SetConfig(conf Config) {
 	// Ensure additional functions are not nil
@@ -68,3 +76,30 @@ func SetConfig(conf Config) {
 func GetConfig() Config {
 	return usedConfig
 }
+
+// SetFloatTolerance sets whether the target's results get compared with a float tolerance.
+//
+// Unlike the config, this doesn't depend on the implementation but on the target's comparison settings,
+// so it doesn't get reset by [SetConfig].
+func SetFloatTolerance(tolerant bool) {
+	floatTolerance = tolerant
+}
+
+// HasFloatTolerance returns true if the target's results get compared with a float tolerance.
+func HasFloatTolerance() bool {
+	return floatTolerance
+}
+
+// SetOrderedReturns sets whether returns ordered by all of their returned elements get generated,
+// allowing the rows of such returns to be compared in order.
+//
+// Enabling it changes the statements generated from existing byte strings.
+// Like the float tolerance, it doesn't get reset by [SetConfig].
+func SetOrderedReturns(ordered bool) {
+	orderedReturns = ordered
+}
+
+// GeneratesOrderedReturns returns true if returns ordered by all of their returned elements get generated.
+func GeneratesOrderedReturns() bool {
+	return orderedReturns
+}
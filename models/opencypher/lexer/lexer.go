@@ -0,0 +1,301 @@
+/*
+Package lexer splits Cypher queries into tokens.
+
+It is shared by everything reading Cypher queries, such as the [parser] importing external queries
+and the in-memory engine.
+
+[parser]: github.com/Anon10214/dinkel/models/opencypher/parser
+*/
+package lexer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The Kind of a token
+type Kind int
+
+const (
+	// The end of the query
+	EOF Kind = iota
+	// Unescaped symbolic names, including all keywords
+	Identifier
+	// Symbolic names enclosed in backticks
+	EscapedIdentifier
+	// Integer literals, including hexadecimal and octal ones
+	Integer
+	// Floating point literals
+	Float
+	// String literals, in single or double quotes
+	String
+	// Parameters, such as $name or $0
+	Parameter
+	// Operators and punctuation.
+	// Arrows aren't symbols of their own, -> is lexed as - followed by >.
+	Symbol
+)
+
+// A Token of a Cypher query.
+type Token struct {
+	Kind Kind
+	// The token as written in the query
+	Text string
+	// The byte offsets of the token's start and end in the query
+	Pos, End int
+}
+
+// IsKeyword returns true if the token is the passed keyword, ignoring case.
+func (t Token) IsKeyword(keyword string) bool {
+	return t.Kind == Identifier && strings.EqualFold(t.Text, keyword)
+}
+
+// IsSymbol returns true if the token is the passed symbol.
+func (t Token) IsSymbol(s string) bool {
+	return t.Kind == Symbol && t.Text == s
+}
+
+// IsName returns true if the token is a symbolic name, escaped or not.
+func (t Token) IsName() bool {
+	return t.Kind == Identifier || t.Kind == EscapedIdentifier
+}
+
+func (t Token) String() string {
+	if t.Kind == EOF {
+		return "end of query"
+	}
+	return fmt.Sprintf("%q", t.Text)
+}
+
+// Value returns the name of escaped identifiers without their backticks and the unescaped text of string literals.
+// The value of other tokens is their text.
+//
+// Returns an error if a string literal holds an invalid escape sequence.
+func (t Token) Value() (string, error) {
+	switch t.Kind {
+	case EscapedIdentifier:
+		return strings.ReplaceAll(t.Text[1:len(t.Text)-1], "``", "`"), nil
+	case String:
+		return unescape(t.Text[1:len(t.Text)-1], t.Pos+1)
+	}
+	return t.Text, nil
+}
+
+// unescape returns the passed string literal's content with its escape sequences replaced.
+// The content starts at the passed offset in the query.
+func unescape(content string, offset int) (string, error) {
+	var text strings.Builder
+	for i := 0; i < len(content); i++ {
+		if content[i] != '\\' {
+			text.WriteByte(content[i])
+			continue
+		}
+		i++
+		switch esc := content[i]; esc {
+		case '\\', '\'', '"':
+			text.WriteByte(esc)
+		case 'b':
+			text.WriteByte('\b')
+		case 'f':
+			text.WriteByte('\f')
+		case 'n':
+			text.WriteByte('\n')
+		case 'r':
+			text.WriteByte('\r')
+		case 't':
+			text.WriteByte('\t')
+		case 'u':
+			// Unicode escapes, as in \u00e4
+			if i+4 >= len(content) {
+				return "", fmt.Errorf("invalid unicode escape sequence at position %d", offset+i-1)
+			}
+			codePoint, err := strconv.ParseUint(content[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape sequence at position %d", offset+i-1)
+			}
+			text.WriteRune(rune(codePoint))
+			i += 4
+		default:
+			return "", fmt.Errorf("invalid escape sequence \\%c at position %d", esc, offset+i-1)
+		}
+	}
+	return text.String(), nil
+}
+
+// An Error is returned if a query can't be tokenized.
+type Error struct {
+	Message string
+	// The byte offset of the error in the query
+	Pos int
+	// Where the error is located, as returned by [Position]
+	Location string
+}
+
+func (e *Error) Error() string {
+	return e.Message + " at " + e.Location
+}
+
+func newError(query string, pos int, message string) *Error {
+	return &Error{Message: message, Pos: pos, Location: Position(query, pos)}
+}
+
+// Symbols consisting of multiple characters, longer symbols have to come first.
+// Any other character that doesn't start another token is a symbol of its own.
+var multiCharSymbols = []string{"<>", "<=", ">=", "=~", "+=", ".."}
+
+// Tokenize splits the query into its tokens, skipping whitespace and comments.
+//
+// The returned tokens always end with a token of kind [EOF].
+// If the query can't be tokenized, an [*Error] gets returned.
+func Tokenize(query string) ([]Token, error) {
+	var tokens []Token
+	pos := 0
+	for {
+		// Skip whitespace and comments
+		for pos < len(query) {
+			r, size := utf8.DecodeRuneInString(query[pos:])
+			switch {
+			case unicode.IsSpace(r):
+				pos += size
+				continue
+			case strings.HasPrefix(query[pos:], "//"):
+				end := strings.IndexByte(query[pos:], '\n')
+				if end == -1 {
+					pos = len(query)
+				} else {
+					pos += end + 1
+				}
+				continue
+			case strings.HasPrefix(query[pos:], "/*"):
+				end := strings.Index(query[pos+2:], "*/")
+				if end == -1 {
+					return nil, newError(query, pos, "unterminated comment")
+				}
+				pos += end + 4
+				continue
+			}
+			break
+		}
+		if pos == len(query) {
+			return append(tokens, Token{Kind: EOF, Pos: pos, End: pos}), nil
+		}
+
+		start := pos
+		var kind Kind
+		r, size := utf8.DecodeRuneInString(query[pos:])
+		switch {
+		case isIdentifierStart(r):
+			kind = Identifier
+			pos += size
+			pos += lengthOf(query[pos:], isIdentifierPart)
+		case r == '`':
+			kind = EscapedIdentifier
+			// Backticks are escaped with double backticks
+			for {
+				end := strings.IndexByte(query[pos+1:], '`')
+				if end == -1 {
+					return nil, newError(query, start, "unterminated escaped name")
+				}
+				pos += end + 2
+				if pos == len(query) || query[pos] != '`' {
+					break
+				}
+			}
+		case r == '"' || r == '\'':
+			kind = String
+			pos++
+			for {
+				if pos >= len(query) {
+					return nil, newError(query, start, "unterminated string")
+				}
+				if query[pos] == '\\' {
+					pos += 2
+					continue
+				}
+				pos++
+				if rune(query[pos-1]) == r {
+					break
+				}
+			}
+		case isDigit(r) || (r == '.' && pos+1 < len(query) && isDigit(rune(query[pos+1]))):
+			var length int
+			kind, length = lexNumber(query[pos:])
+			pos += length
+		case r == '$':
+			kind = Parameter
+			pos++
+			pos += lengthOf(query[pos:], isIdentifierPart)
+			if pos == start+1 {
+				return nil, newError(query, start, "missing parameter name")
+			}
+		default:
+			kind = Symbol
+			pos += size
+			for _, s := range multiCharSymbols {
+				if strings.HasPrefix(query[start:], s) {
+					pos = start + len(s)
+					break
+				}
+			}
+		}
+		tokens = append(tokens, Token{Kind: kind, Text: query[start:pos], Pos: start, End: pos})
+	}
+}
+
+// lexNumber returns the kind and length of the number literal at the start of the passed string.
+func lexNumber(s string) (Kind, int) {
+	if len(s) > 2 && s[0] == '0' && strings.ContainsRune("xXoO", rune(s[1])) {
+		return Integer, 2 + lengthOf(s[2:], isIdentifierPart)
+	}
+	kind := Integer
+	length := lengthOf(s, isDigit)
+	// Don't treat the dots of ranges as decimal points, as in *1..2
+	if length < len(s)-1 && s[length] == '.' && isDigit(rune(s[length+1])) {
+		kind = Float
+		length++
+		length += lengthOf(s[length:], isDigit)
+	}
+	if length < len(s) && (s[length] == 'e' || s[length] == 'E') {
+		exponent := length + 1
+		if exponent < len(s) && (s[exponent] == '+' || s[exponent] == '-') {
+			exponent++
+		}
+		if digits := lengthOf(s[exponent:], isDigit); digits != 0 {
+			kind = Float
+			length = exponent + digits
+		}
+	}
+	return kind, length
+}
+
+// Returns the length of the longest prefix of the passed string only consisting of runes matching the passed predicate
+func lengthOf(s string, predicate func(rune) bool) int {
+	for i, r := range s {
+		if !predicate(r) {
+			return i
+		}
+	}
+	return len(s)
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentifierPart(r rune) bool {
+	return isIdentifierStart(r) || unicode.IsDigit(r) || unicode.Is(unicode.Sc, r)
+}
+
+func isDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
+
+// Position returns the line and column of the passed byte offset in the query, e.g. "line 1, column 8".
+func Position(query string, pos int) string {
+	line := 1 + strings.Count(query[:pos], "\n")
+	column := 1 + utf8.RuneCountInString(query[strings.LastIndexByte(query[:pos], '\n')+1:pos])
+	return fmt.Sprintf("line %d, column %d", line, column)
+}
@@ -0,0 +1,71 @@
+package lexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tokens, err := Tokenize("MATCH (`a``b`)<--() // comment\nWHERE x.y <= 1.5e3 /* comment */ RETURN $p, 'it\\'s', *1..2, 0x1F, .5")
+	assert.NoError(t, err)
+
+	type kindAndText struct {
+		Kind Kind
+		Text string
+	}
+	var actual []kindAndText
+	for _, token := range tokens {
+		actual = append(actual, kindAndText{token.Kind, token.Text})
+	}
+	assert.Equal(t, []kindAndText{
+		{Identifier, "MATCH"}, {Symbol, "("}, {EscapedIdentifier, "`a``b`"}, {Symbol, ")"},
+		{Symbol, "<"}, {Symbol, "-"}, {Symbol, "-"}, {Symbol, "("}, {Symbol, ")"},
+		{Identifier, "WHERE"}, {Identifier, "x"}, {Symbol, "."}, {Identifier, "y"}, {Symbol, "<="}, {Float, "1.5e3"},
+		{Identifier, "RETURN"}, {Parameter, "$p"}, {Symbol, ","}, {String, "'it\\'s'"}, {Symbol, ","},
+		{Symbol, "*"}, {Integer, "1"}, {Symbol, ".."}, {Integer, "2"}, {Symbol, ","},
+		{Integer, "0x1F"}, {Symbol, ","}, {Float, ".5"},
+		{EOF, ""},
+	}, actual)
+}
+
+func TestTokenize_Errors(t *testing.T) {
+	for query, expected := range map[string]string{
+		"RETURN 'a":      "unterminated string at line 1, column 8",
+		"RETURN\n`a":     "unterminated escaped name at line 2, column 1",
+		"RETURN $":       "missing parameter name at line 1, column 8",
+		"RETURN 1 /* 2 ": "unterminated comment at line 1, column 10",
+	} {
+		_, err := Tokenize(query)
+		var lexerErr *Error
+		if assert.ErrorAs(t, err, &lexerErr, query) {
+			assert.Equal(t, expected, lexerErr.Error(), query)
+		}
+	}
+}
+
+func TestValue(t *testing.T) {
+	for text, expected := range map[string]string{
+		"`a``b`":         "a`b",
+		`'it\'s\n'`:      "it's\n",
+		`"say \"hi\"\\"`: `say "hi"\`,
+		`'\u00e4\u0001'`: "ä\x01",
+		"name":           "name",
+		"$p":             "$p",
+	} {
+		tokens, err := Tokenize(text)
+		if assert.NoError(t, err, text) {
+			value, err := tokens[0].Value()
+			assert.NoError(t, err, text)
+			assert.Equal(t, expected, value, text)
+		}
+	}
+
+	for _, text := range []string{`'\q'`, `'\u12'`, `'\u12g4'`} {
+		tokens, err := Tokenize(text)
+		if assert.NoError(t, err, text) {
+			_, err = tokens[0].Value()
+			assert.Error(t, err, "invalid escape sequences should be rejected")
+		}
+	}
+}
@@ -0,0 +1,465 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/Anon10214/dinkel/models/opencypher/clauses"
+	"github.com/Anon10214/dinkel/models/opencypher/lexer"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/translator"
+	"github.com/Anon10214/dinkel/translator/helperclauses"
+)
+
+// Returns the AST node of an expression of the passed type holding the passed subclause
+func (p *parser) expression(propertyType schema.PropertyType, subclause *helperclauses.ClauseCapturer) *helperclauses.ClauseCapturer {
+	conf := schema.ExpressionConfig{PropertyType: propertyType}
+	if propertyType != schema.AnyType {
+		conf.TargetType = schema.PropertyValue
+	}
+	return p.node(&clauses.Expression{Conf: conf}, subclause)
+}
+
+// Returns the AST node of an expression of the passed type, applying the passed template to the passed operands
+func (p *parser) operation(propertyType schema.PropertyType, template string, operands ...*helperclauses.ClauseCapturer) *helperclauses.ClauseCapturer {
+	return p.expression(propertyType, p.assemble(template, operands...))
+}
+
+// Returns the property type of the passed expression, or AnyType if it isn't known
+func typeOf(expression *helperclauses.ClauseCapturer) schema.PropertyType {
+	if asExpression, ok := expression.GetCapturedClause().(*clauses.Expression); ok {
+		return asExpression.Conf.PropertyType
+	}
+	return schema.AnyType
+}
+
+// Returns the type of an arithmetic operation on the passed operands
+func arithmeticType(lhs, rhs *helperclauses.ClauseCapturer) schema.PropertyType {
+	if lhsType := typeOf(lhs); lhsType == typeOf(rhs) {
+		return lhsType
+	}
+	return schema.AnyType
+}
+
+// Parses an expression, marking it as being of the passed type.
+//
+// The type determines the kind of literal the expression gets replaced with when reducing.
+func (p *parser) parseTypedExpression(propertyType schema.PropertyType) *helperclauses.ClauseCapturer {
+	expression := p.parseExpression()
+	if asExpression, ok := expression.GetCapturedClause().(*clauses.Expression); ok {
+		asExpression.Conf.TargetType = schema.PropertyValue
+		asExpression.Conf.PropertyType = propertyType
+	}
+	return expression
+}
+
+func (p *parser) parseExpression() *helperclauses.ClauseCapturer {
+	return p.parseOr()
+}
+
+func (p *parser) parseOr() *helperclauses.ClauseCapturer {
+	expression := p.parseXor()
+	for p.acceptKeywords("OR") {
+		expression = p.operation(schema.Boolean, "%s OR %s", expression, p.parseXor())
+	}
+	return expression
+}
+
+func (p *parser) parseXor() *helperclauses.ClauseCapturer {
+	expression := p.parseAnd()
+	for p.acceptKeywords("XOR") {
+		expression = p.operation(schema.Boolean, "%s XOR %s", expression, p.parseAnd())
+	}
+	return expression
+}
+
+func (p *parser) parseAnd() *helperclauses.ClauseCapturer {
+	expression := p.parseNot()
+	for p.acceptKeywords("AND") {
+		expression = p.operation(schema.Boolean, "%s AND %s", expression, p.parseNot())
+	}
+	return expression
+}
+
+func (p *parser) parseNot() *helperclauses.ClauseCapturer {
+	if p.acceptKeywords("NOT") {
+		return p.operation(schema.Boolean, "NOT %s", p.parseNot())
+	}
+	return p.parseComparison()
+}
+
+var comparisonOperators = []string{"=", "<>", "<", ">", "<=", ">=", "=~"}
+
+func (p *parser) parseComparison() *helperclauses.ClauseCapturer {
+	expression := p.parsePredicate()
+	for {
+		var operator string
+		for _, comparisonOperator := range comparisonOperators {
+			if p.isSymbol(comparisonOperator) {
+				operator = comparisonOperator
+			}
+		}
+		if operator == "" {
+			return expression
+		}
+		p.next()
+		expression = p.operation(schema.Boolean, "%s "+operator+" %s", expression, p.parsePredicate())
+	}
+}
+
+// Parses string, list and null predicates, such as a STARTS WITH b or a IS NOT NULL
+func (p *parser) parsePredicate() *helperclauses.ClauseCapturer {
+	expression := p.parseAdditive()
+	for {
+		switch {
+		case p.acceptKeywords("STARTS", "WITH"):
+			expression = p.operation(schema.Boolean, "%s STARTS WITH %s", expression, p.parseAdditive())
+		case p.acceptKeywords("ENDS", "WITH"):
+			expression = p.operation(schema.Boolean, "%s ENDS WITH %s", expression, p.parseAdditive())
+		case p.acceptKeywords("CONTAINS"):
+			expression = p.operation(schema.Boolean, "%s CONTAINS %s", expression, p.parseAdditive())
+		case p.acceptKeywords("IN"):
+			expression = p.operation(schema.Boolean, "%s IN %s", expression, p.parseAdditive())
+		case p.acceptKeywords("IS", "NULL"):
+			expression = p.operation(schema.Boolean, "%s IS NULL", expression)
+		case p.acceptKeywords("IS", "NOT", "NULL"):
+			expression = p.operation(schema.Boolean, "%s IS NOT NULL", expression)
+		default:
+			return expression
+		}
+	}
+}
+
+func (p *parser) parseAdditive() *helperclauses.ClauseCapturer {
+	expression := p.parseMultiplicative()
+	for p.isSymbol("+") || p.isSymbol("-") {
+		operator := p.next().Text
+		rhs := p.parseMultiplicative()
+		expression = p.operation(arithmeticType(expression, rhs), "%s "+operator+" %s", expression, rhs)
+	}
+	return expression
+}
+
+func (p *parser) parseMultiplicative() *helperclauses.ClauseCapturer {
+	expression := p.parsePower()
+	for p.isSymbol("*") || p.isSymbol("/") || p.isSymbol("%") {
+		operator := p.next().Text
+		if operator == "%" {
+			operator = "%%"
+		}
+		rhs := p.parsePower()
+		expression = p.operation(arithmeticType(expression, rhs), "%s "+operator+" %s", expression, rhs)
+	}
+	return expression
+}
+
+func (p *parser) parsePower() *helperclauses.ClauseCapturer {
+	expression := p.parseUnary()
+	for p.acceptSymbol("^") {
+		expression = p.operation(schema.Float, "%s ^ %s", expression, p.parseUnary())
+	}
+	return expression
+}
+
+func (p *parser) parseUnary() *helperclauses.ClauseCapturer {
+	if p.isSymbol("-") || p.isSymbol("+") {
+		operator := p.next().Text
+		// Keep negative numbers as a single literal
+		if isNumber(p.peek()) && operator == "-" {
+			number := p.next()
+			return p.parsePostfix(p.expression(numberType(number), p.text("-"+number.Text)))
+		}
+		operand := p.parseUnary()
+		return p.operation(typeOf(operand), operator+"%s", operand)
+	}
+	return p.parsePostfix(p.parseAtom())
+}
+
+// Parses property accesses, subscripts and label predicates following the passed expression
+func (p *parser) parsePostfix(expression *helperclauses.ClauseCapturer) *helperclauses.ClauseCapturer {
+	for {
+		switch {
+		case p.isSymbol(".") && p.peekAt(1).IsName():
+			p.next()
+			expression = p.operation(schema.AnyType, "%s.%s", expression, p.parseName())
+		case p.acceptSymbol("["):
+			var template string
+			var subclauses []*helperclauses.ClauseCapturer
+			if p.acceptSymbol("..") {
+				template, subclauses = "%s[..%s]", []*helperclauses.ClauseCapturer{expression, p.parseExpression()}
+			} else {
+				index := p.parseExpression()
+				if !p.acceptSymbol("..") {
+					template, subclauses = "%s[%s]", []*helperclauses.ClauseCapturer{expression, index}
+				} else if p.isSymbol("]") {
+					template, subclauses = "%s[%s..]", []*helperclauses.ClauseCapturer{expression, index}
+				} else {
+					template, subclauses = "%s[%s..%s]", []*helperclauses.ClauseCapturer{expression, index, p.parseExpression()}
+				}
+			}
+			p.expectSymbol("]")
+			expression = p.operation(schema.AnyType, template, subclauses...)
+		case p.isSymbol(":") && p.peekAt(1).IsName():
+			expression = p.operation(schema.Boolean, "%s%s", expression, p.parseLabels(false))
+		default:
+			return expression
+		}
+	}
+}
+
+func isNumber(t lexer.Token) bool {
+	return t.Kind == lexer.Integer || t.Kind == lexer.Float
+}
+
+// Returns the type of the passed number literal
+func numberType(number lexer.Token) schema.PropertyType {
+	if number.Kind == lexer.Float {
+		return schema.Float
+	}
+	return schema.Integer
+}
+
+func (p *parser) parseAtom() *helperclauses.ClauseCapturer {
+	t := p.peek()
+	switch {
+	case isNumber(t):
+		p.next()
+		return p.expression(numberType(t), p.text(t.Text))
+	case t.Kind == lexer.String:
+		p.next()
+		return p.expression(schema.String, p.text(t.Text))
+	case t.Kind == lexer.Parameter:
+		p.next()
+		return p.expression(schema.AnyType, p.text(t.Text))
+	case t.IsKeyword("TRUE") || t.IsKeyword("FALSE"):
+		p.next()
+		return p.expression(schema.Boolean, p.text(t.Text))
+	case t.IsKeyword("NULL"):
+		p.next()
+		return p.expression(schema.AnyType, p.text(t.Text))
+	case t.IsKeyword("CASE"):
+		return p.parseCase()
+	case t.IsKeyword("COUNT") && p.peekAt(1).IsSymbol("(") && p.peekAt(2).IsSymbol("*"):
+		p.pos += 3
+		p.expectSymbol(")")
+		return p.expression(schema.Integer, p.text(t.Text+"(*)"))
+	case (t.IsKeyword("EXISTS") || t.IsKeyword("COUNT") || t.IsKeyword("COLLECT")) && p.peekAt(1).IsSymbol("{"):
+		return p.parseSubqueryExpression()
+	case (t.IsKeyword("ALL") || t.IsKeyword("ANY") || t.IsKeyword("NONE") || t.IsKeyword("SINGLE")) && p.peekAt(1).IsSymbol("(") && p.peekAt(2).IsName() && p.peekAt(3).IsKeyword("IN"):
+		quantifier := p.next().Text
+		p.next()
+		return p.expression(schema.Boolean, p.assemble("%s(%s)", p.text(quantifier), p.parseFilter(false)))
+	case t.IsName():
+		if p.isFunctionCall() {
+			return p.parseFunctionCall()
+		}
+		return p.expression(schema.AnyType, p.parseName())
+	case t.IsSymbol("["):
+		return p.parseList()
+	case t.IsSymbol("{"):
+		return p.expression(schema.AnyType, p.parseMap(func() translator.Clause { return &clauses.Properties{} }))
+	case t.IsSymbol("("):
+		// Pattern predicates, such as (a)-->(), have to hold at least one relationship.
+		// The variables they declare aren't in scope outside of them.
+		scope := p.schema.Copy()
+		if pattern, ok := p.try(func() *helperclauses.ClauseCapturer {
+			pattern := p.parsePatternElement()
+			if _, isNode := pattern.GetCapturedClause().(*clauses.MatchNode); isNode {
+				p.fail("a relationship")
+			}
+			return pattern
+		}); ok {
+			p.schema = scope
+			return p.expression(schema.Boolean, pattern)
+		}
+		p.next()
+		expression := p.parseExpression()
+		p.expectSymbol(")")
+		return p.operation(typeOf(expression), "(%s)", expression)
+	}
+	p.fail("an expression")
+	return nil
+}
+
+// Returns true if the current token is the start of a function name followed by its arguments
+func (p *parser) isFunctionCall() bool {
+	offset := 1
+	for p.peekAt(offset).IsSymbol(".") && p.peekAt(offset+1).IsName() {
+		offset += 2
+	}
+	return p.peekAt(offset).IsSymbol("(")
+}
+
+func (p *parser) parseFunctionCall() *helperclauses.ClauseCapturer {
+	name := p.parseQualifiedName()
+	p.expectSymbol("(")
+	template := "%s("
+	if p.acceptKeywords("DISTINCT") {
+		template += "DISTINCT "
+	}
+	var arguments []*helperclauses.ClauseCapturer
+	for !p.isSymbol(")") {
+		if len(arguments) != 0 {
+			p.expectSymbol(",")
+		}
+		arguments = append(arguments, p.parseExpression())
+	}
+	p.expectSymbol(")")
+	template += strings.TrimSuffix(strings.Repeat("%s, ", len(arguments)), ", ") + ")"
+	return p.operation(schema.AnyType, template, append([]*helperclauses.ClauseCapturer{name}, arguments...)...)
+}
+
+// Parses a filter, as in the parentheses of all(x IN list WHERE x > 0) or the brackets of list comprehensions.
+// If allowMapping is set, the filter may be followed by a mapping, as in [x IN list | x + 1].
+// The filter's variable is only declared within the filter.
+func (p *parser) parseFilter(allowMapping bool) *helperclauses.ClauseCapturer {
+	variable := p.parseName()
+	p.expectKeywords("IN")
+	template := "%s IN %s"
+	subclauses := []*helperclauses.ClauseCapturer{variable, p.parseExpression()}
+	scope := p.schema.Copy()
+	p.declareProperty(nameOf(variable), schema.AnyType)
+	if p.acceptKeywords("WHERE") {
+		template += " WHERE %s"
+		subclauses = append(subclauses, p.parseTypedExpression(schema.Boolean))
+	}
+	if allowMapping && p.acceptSymbol("|") {
+		template += " | %s"
+		subclauses = append(subclauses, p.parseExpression())
+	}
+	if !allowMapping {
+		p.expectSymbol(")")
+	}
+	p.schema = scope
+	return p.assemble(template, subclauses...)
+}
+
+// Parses list literals, list comprehensions and pattern comprehensions
+func (p *parser) parseList() *helperclauses.ClauseCapturer {
+	p.expectSymbol("[")
+	if p.peek().IsName() && p.peekAt(1).IsKeyword("IN") {
+		filter := p.parseFilter(true)
+		p.expectSymbol("]")
+		return p.operation(schema.AnyType, "[%s]", filter)
+	}
+	if comprehension, ok := p.try(p.parsePatternComprehension); ok {
+		return comprehension
+	}
+
+	if p.acceptSymbol("]") {
+		return p.expression(schema.AnyType, p.node(&clauses.ListLiteral{}, p.node(&clauses.EmptyClause{})))
+	}
+	var items []*helperclauses.ClauseCapturer
+	for ok := true; ok; ok = p.acceptSymbol(",") {
+		items = append(items, p.parseExpression())
+	}
+	p.expectSymbol("]")
+	list := p.node(&clauses.ListLiteral{}, p.chain(items, func() translator.Clause { return &clauses.ListLiteralItem{} }))
+	return p.expression(schema.AnyType, list)
+}
+
+// Parses the pattern comprehension following the opening bracket, as in [(a)-->(b) WHERE b.x > 0 | b.y]
+//
+// The variables declared by the pattern are only in scope within the comprehension.
+func (p *parser) parsePatternComprehension() *helperclauses.ClauseCapturer {
+	scope := p.schema.Copy()
+	template := "[%s"
+	subclauses := []*helperclauses.ClauseCapturer{p.parsePatternPart()}
+	if p.acceptKeywords("WHERE") {
+		template += " WHERE %s"
+		subclauses = append(subclauses, p.parseTypedExpression(schema.Boolean))
+	}
+	p.expectSymbol("|")
+	template += " | %s]"
+	subclauses = append(subclauses, p.parseExpression())
+	p.expectSymbol("]")
+	p.schema = scope
+	return p.operation(schema.AnyType, template, subclauses...)
+}
+
+func (p *parser) parseCase() *helperclauses.ClauseCapturer {
+	p.expectKeywords("CASE")
+	var subject *helperclauses.ClauseCapturer
+	if !p.isKeywords("WHEN") {
+		subject = p.parseExpression()
+	}
+
+	var whens, results []*helperclauses.ClauseCapturer
+	for p.acceptKeywords("WHEN") {
+		if subject == nil {
+			whens = append(whens, p.parseTypedExpression(schema.Boolean))
+		} else {
+			whens = append(whens, p.parseExpression())
+		}
+		p.expectKeywords("THEN")
+		results = append(results, p.parseExpression())
+	}
+	if len(whens) == 0 {
+		p.fail("WHEN")
+	}
+	elseExpression := p.node(&clauses.EmptyClause{})
+	if p.acceptKeywords("ELSE") {
+		elseExpression = p.node(&clauses.CaseExpressionElse{}, p.parseExpression())
+	}
+	p.expectKeywords("END")
+
+	conf := schema.ExpressionConfig{}
+	// Each WHEN holds the following ones
+	when := p.node(&clauses.EmptyClause{})
+	for i := len(whens) - 1; i >= 0; i-- {
+		when = p.node(&clauses.CaseExpressionWhen{Conf: conf, IsGeneric: subject == nil}, whens[i], results[i], when)
+	}
+	var caseExpression *helperclauses.ClauseCapturer
+	if subject == nil {
+		caseExpression = p.node(&clauses.GenericCaseExpression{Conf: conf}, when, elseExpression)
+	} else {
+		caseExpression = p.node(&clauses.SimpleCaseExpression{Conf: conf}, subject, when, elseExpression)
+	}
+	return p.expression(schema.AnyType, p.node(&clauses.CaseExpression{Conf: conf}, caseExpression))
+}
+
+// Parses EXISTS, COUNT and COLLECT subqueries
+func (p *parser) parseSubqueryExpression() *helperclauses.ClauseCapturer {
+	var subquery translator.Clause
+	propertyType := schema.AnyType
+	switch keyword := p.next(); {
+	case keyword.IsKeyword("EXISTS"):
+		subquery, propertyType = &clauses.Exists{}, schema.Boolean
+	case keyword.IsKeyword("COUNT"):
+		subquery, propertyType = &clauses.Count{}, schema.Integer
+	default:
+		subquery = &clauses.Collect{}
+	}
+	p.expectSymbol("{")
+
+	// Variables declared and returned within the subquery aren't in scope outside of it
+	scope, returned := p.schema.Copy(), p.returned
+	p.schema.IsInSubquery = true
+	var body *helperclauses.ClauseCapturer
+	if p.isSymbol("(") || (p.peek().IsName() && p.peekAt(1).IsSymbol("=")) {
+		// Simple subqueries only consisting of a pattern and an optional WHERE
+		var patterns []*helperclauses.ClauseCapturer
+		for ok := true; ok; ok = p.acceptSymbol(",") {
+			patterns = append(patterns, p.parsePatternPart())
+		}
+		body = p.assemble("%s %s", p.list(patterns), p.parseOptionalWhere())
+	} else {
+		body = p.parseQuery()
+	}
+	p.expectSymbol("}")
+	p.schema, p.returned = scope, returned
+	return p.expression(propertyType, p.node(subquery, body))
+}
+
+// Parses the target and key of a property expression, such as n.a in SET n.a = 1
+func (p *parser) parsePropertyExpression() (target, key *helperclauses.ClauseCapturer) {
+	start := p.peek()
+	expression := p.parsePostfix(p.parseAtom())
+	// Property accesses are expressions holding an assembler holding the target and the key
+	if subclauses := expression.GetSubclauseClauseCapturers(); len(subclauses) == 1 {
+		if assembler, ok := subclauses[0].GetCapturedClause().(*helperclauses.Assembler); ok && assembler.TemplateString() == "%s.%s" {
+			return subclauses[0].GetSubclauseClauseCapturers()[0], subclauses[0].GetSubclauseClauseCapturers()[1]
+		}
+	}
+	p.failAt(start, "a property")
+	return nil, nil
+}
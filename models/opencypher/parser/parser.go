@@ -0,0 +1,640 @@
+/*
+Package parser parses Cypher queries into ASTs made up of OpenCypher [clauses].
+
+This allows running, reducing and regenerating queries which weren't generated by dinkel,
+such as ones taken from bug trackers or regression suites.
+
+Every node of a parsed AST is a clause capturer holding the clause which would have generated it,
+created using [helperclauses.CreateGeneratedClauseCapturer].
+The resulting ASTs can thus be used wherever dinkel expects captured ASTs, for example as a bug report's AST.
+Each node captures a schema holding the variables declared before it, allowing regenerated parts of the AST to reference them.
+
+Some clauses get rendered differently depending on state only set during their generation, OPTIONAL MATCH
+being an example of this. Such constructs, as well as ones dinkel cannot generate, are represented by
+[helperclauses.Assembler]-s with the matching template strings.
+Names and literals are represented by [helperclauses.Stringer]-s.
+*/
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Anon10214/dinkel/models/opencypher"
+	"github.com/Anon10214/dinkel/models/opencypher/clauses"
+	"github.com/Anon10214/dinkel/models/opencypher/lexer"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/translator"
+	"github.com/Anon10214/dinkel/translator/helperclauses"
+)
+
+type parser struct {
+	query  string
+	tokens []lexer.Token
+	// The index of the current token
+	pos int
+	// The scope of the current clause, holding the variables declared so far.
+	// It is captured by the parsed clauses.
+	schema *schema.Schema
+	// The variables returned by the last parsed RETURN, nil if there wasn't one in the current query
+	returned *schema.Schema
+}
+
+// A parseError gets raised as a panic on syntax errors and recovered by [Parse]
+type parseError struct {
+	err error
+}
+
+// Parse parses the passed query and returns the AST of each of its statements.
+//
+// The query may hold multiple statements separated by semicolons.
+// Schema commands, such as CREATE INDEX, aren't parsed and are kept as is.
+func Parse(query string) (statements []*helperclauses.ClauseCapturer, err error) {
+	tokens, err := lexer.Tokenize(query)
+	if err != nil {
+		return nil, errors.Join(errors.New("couldn't tokenize query - "), err)
+	}
+	p := &parser{query: query, tokens: tokens}
+
+	defer func() {
+		if r := recover(); r != nil {
+			asParseError, ok := r.(parseError)
+			if !ok {
+				panic(r)
+			}
+			statements, err = nil, asParseError.err
+		}
+	}()
+
+	for {
+		for p.acceptSymbol(";") {
+		}
+		if p.peek().Kind == lexer.EOF {
+			break
+		}
+		// Every statement starts with an empty scope
+		p.schema = &schema.Schema{}
+		p.schema.Reset()
+		scope := p.schema.Copy()
+		statements = append(statements, p.nodeIn(scope, &opencypher.RootClause{}, p.parseStatement()))
+		if !p.acceptSymbol(";") && p.peek().Kind != lexer.EOF {
+			p.fail("a semicolon or the end of the query")
+		}
+	}
+	if len(statements) == 0 {
+		return nil, errors.New("query holds no statements")
+	}
+	return statements, nil
+}
+
+func (p *parser) parseStatement() *helperclauses.ClauseCapturer {
+	switch {
+	case p.acceptKeywords("EXPLAIN"):
+		return p.assemble("EXPLAIN %s", p.parseQuery())
+	case p.acceptKeywords("PROFILE"):
+		return p.assemble("PROFILE %s", p.parseQuery())
+	case p.isKeywords("CYPHER"):
+		// Keep query options, such as CYPHER runtime = slotted, as is
+		start := p.next().Pos
+		if isNumber(p.peek()) {
+			p.next()
+		}
+		for p.peek().IsName() && p.peekAt(1).IsSymbol("=") {
+			p.pos += 2
+			p.parseName()
+		}
+		options := p.text(strings.TrimSpace(p.query[start:p.peek().Pos]))
+		return p.assemble("%s %s", options, p.parseStatement())
+	case p.isSchemaCommand():
+		// Keep the statement as is
+		start := p.peek().Pos
+		for !p.peek().IsSymbol(";") && p.peek().Kind != lexer.EOF {
+			p.next()
+		}
+		return p.text(strings.TrimSpace(p.query[start:p.peek().Pos]))
+	}
+	return p.parseQuery()
+}
+
+// Returns true if the current statement is a schema command, such as CREATE INDEX or DROP CONSTRAINT
+func (p *parser) isSchemaCommand() bool {
+	for _, keyword := range []string{"DROP", "SHOW", "ALTER", "COPY", "LOAD", "USE", "BEGIN", "COMMIT"} {
+		if p.isKeywords(keyword) {
+			return true
+		}
+	}
+	// A CREATE clause is always followed by a pattern, optionally assigned to a path variable
+	return p.isKeywords("CREATE") && p.peekAt(1).Kind == lexer.Identifier && !p.peekAt(2).IsSymbol("=")
+}
+
+// Parses a query, possibly consisting of multiple queries combined using UNION
+func (p *parser) parseQuery() *helperclauses.ClauseCapturer {
+	scope := p.schema.Copy()
+	query := p.parseClauses(false)
+	for p.acceptKeywords("UNION") {
+		var union translator.Clause = &clauses.Union{}
+		if p.acceptKeywords("ALL") {
+			union = helperclauses.CreateAssembler("%s UNION ALL %s")
+		}
+		// Each of the combined queries starts with the scope of the first one
+		p.schema = scope.Copy()
+		next := p.nodeIn(scope, &clauses.UnionClause{}, p.parseClauses(false))
+		query = p.nodeIn(scope, &clauses.ReadClause{}, p.nodeIn(scope, union, query, next))
+	}
+	return query
+}
+
+// Parses the remaining clauses of a query.
+//
+// Following the structure of generated queries, every clause holds the clauses following it.
+// These are held by an OptionalWriteQuery if they follow a write clause, or by a ReadClause otherwise.
+func (p *parser) parseClauses(afterWrite bool) *helperclauses.ClauseCapturer {
+	var continuation translator.Clause = &clauses.ReadClause{}
+	if afterWrite {
+		continuation = &clauses.OptionalWriteQuery{}
+	}
+	scope := p.schema.Copy()
+	if clause := p.parseClause(); clause != nil {
+		return p.nodeIn(scope, continuation, clause)
+	}
+	return p.node(continuation)
+}
+
+// Parses the next clause and all of the clauses following it.
+// Returns nil if there are no more clauses in the current query.
+//
+// Clauses declare their variables in the current scope, the clauses holding them capture the scope before that.
+func (p *parser) parseClause() *helperclauses.ClauseCapturer {
+	scope := p.schema.Copy()
+	switch {
+	case p.isKeywords("MATCH") || p.isKeywords("OPTIONAL", "MATCH"):
+		var match translator.Clause = &clauses.MatchClause{}
+		if p.acceptKeywords("OPTIONAL") {
+			match = helperclauses.CreateAssembler("OPTIONAL MATCH %s %s ")
+		}
+		p.expectKeywords("MATCH")
+		pattern := p.parseMatchPattern()
+		matchClause := p.nodeIn(scope, match, pattern, p.parseOptionalWhere())
+		return p.nodeIn(scope, &clauses.Match{}, matchClause, p.parseClauses(false))
+
+	case p.acceptKeywords("UNWIND"):
+		expression := p.parseExpression()
+		p.expectKeywords("AS")
+		alias := p.parseName()
+		unwindClause := p.assemble("UNWIND %s AS %s", expression, alias)
+		p.declareProperty(nameOf(alias), schema.AnyType)
+		return p.nodeIn(scope, &clauses.Unwind{}, unwindClause, p.parseClauses(false))
+
+	case p.acceptKeywords("WITH"):
+		distinct := p.parseDistinct()
+		items, isIncludeAll, projected := p.parseProjectionItems(p.withElement, func() translator.Clause { return &clauses.WithElementChain{} })
+		withClause := p.node(&clauses.WithClause{IsIncludeAll: isIncludeAll}, distinct, items)
+		// Only the projected variables are in scope after a WITH
+		p.schema = projected
+		orderBy, skip, limit := p.parseOrderBy(), p.parseSkip(), p.parseLimit()
+		// The WHERE following all other subclauses gets appended to the LIMIT
+		if p.isKeywords("WHERE") {
+			limit = p.assemble("%s %s", limit, p.parseOptionalWhere())
+		}
+		return p.nodeIn(scope, &clauses.With{}, withClause, orderBy, skip, limit, p.parseClauses(false))
+
+	case p.acceptKeywords("RETURN"):
+		distinct := p.parseDistinct()
+		items, _, projected := p.parseProjectionItems(p.returnElement, func() translator.Clause { return &clauses.ReturnElementChain{} })
+		returnClause := p.nodeIn(scope, &clauses.Return{}, distinct, items, p.parseOrderBy(), p.parseSkip(), p.parseLimit())
+		p.returned = projected
+		return p.nodeIn(scope, &clauses.WriteClause{}, returnClause)
+
+	case p.acceptKeywords("CREATE"):
+		var elements []*helperclauses.ClauseCapturer
+		for ok := true; ok; ok = p.acceptSymbol(",") {
+			elements = append(elements, p.parsePatternPart())
+		}
+		pattern := p.chain(elements, func() translator.Clause { return &clauses.CreateElementChain{} })
+		createClause := p.nodeIn(scope, &clauses.CreateClause{}, pattern)
+		create := p.nodeIn(scope, &clauses.Create{}, createClause, p.parseClauses(true))
+		return p.nodeIn(scope, &clauses.WriteClause{}, create)
+
+	case p.acceptKeywords("MERGE"):
+		mergeClause := p.parseMergeClause(scope)
+		merge := p.nodeIn(scope, &clauses.Merge{}, mergeClause, p.parseClauses(true))
+		return p.nodeIn(scope, &clauses.WriteClause{}, merge)
+
+	case p.isKeywords("DELETE") || p.isKeywords("DETACH", "DELETE"):
+		var deleteClause translator.Clause = &clauses.DeleteClause{}
+		if p.acceptKeywords("DETACH") {
+			deleteClause = helperclauses.CreateAssembler("DETACH DELETE %s")
+		}
+		p.expectKeywords("DELETE")
+		var targets []*helperclauses.ClauseCapturer
+		for ok := true; ok; ok = p.acceptSymbol(",") {
+			targets = append(targets, p.parseExpression())
+		}
+		deleteNode := p.nodeIn(scope, &clauses.Delete{}, p.node(deleteClause, p.list(targets)), p.parseClauses(true))
+		return p.nodeIn(scope, &clauses.WriteClause{}, deleteNode)
+
+	case p.acceptKeywords("SET"):
+		setClause := p.node(&clauses.SetClause{}, p.parseSetItems())
+		set := p.nodeIn(scope, &clauses.Set{}, setClause, p.parseClauses(true))
+		return p.nodeIn(scope, &clauses.WriteClause{}, set)
+
+	case p.acceptKeywords("REMOVE"):
+		var items []*helperclauses.ClauseCapturer
+		for ok := true; ok; ok = p.acceptSymbol(",") {
+			items = append(items, p.parseRemoveItem())
+		}
+		removeClause := p.node(&clauses.RemoveClause{}, p.chain(items, func() translator.Clause { return &clauses.RemoveSubclause{} }))
+		return p.nodeIn(scope, &clauses.WriteClause{}, p.nodeIn(scope, &clauses.Remove{}, removeClause, p.parseClauses(true)))
+
+	case p.acceptKeywords("FOREACH"):
+		p.expectSymbol("(")
+		variable := p.parseName()
+		p.expectKeywords("IN")
+		list := p.parseExpression()
+		p.expectSymbol("|")
+		iteration := p.assemble("%s IN %s", variable, list)
+		// The variable is only declared within the updating commands
+		p.declareProperty(nameOf(variable), schema.AnyType)
+		commands := p.parseClauses(true)
+		p.expectSymbol(")")
+		p.schema = scope.Copy()
+		foreachClause := p.nodeIn(scope, &clauses.ForeachClause{}, iteration, commands)
+		return p.nodeIn(scope, &clauses.WriteClause{}, p.nodeIn(scope, &clauses.Foreach{}, foreachClause, p.parseClauses(true)))
+
+	case p.isKeywords("CALL") && p.peekAt(1).IsSymbol("{"):
+		p.next()
+		p.next()
+		p.schema.IsInSubquery = true
+		p.returned = nil
+		subquery := p.parseQuery()
+		p.expectSymbol("}")
+		// Only the variables returned by the subquery get declared in the enclosing query
+		returned := p.returned
+		p.schema, p.returned = scope.Copy(), nil
+		if returned != nil {
+			p.declareAll(returned)
+		}
+		callSubqueryClause := p.nodeIn(scope, &clauses.CallSubqueryClause{}, p.nodeIn(scope, &clauses.EmptyClause{}), subquery)
+		return p.nodeIn(scope, &clauses.CallSubquery{}, callSubqueryClause, p.parseClauses(false))
+
+	case p.acceptKeywords("CALL"):
+		call := p.parseProcedureCall()
+		return p.nodeIn(scope, helperclauses.CreateAssembler("%s %s"), call, p.parseClauses(false))
+
+	case p.isEndOfQuery():
+		return nil
+	}
+	p.fail("a clause")
+	return nil
+}
+
+// Returns true if the current token terminates a query
+func (p *parser) isEndOfQuery() bool {
+	t := p.peek()
+	return t.Kind == lexer.EOF || t.IsSymbol(";") || t.IsSymbol("}") || t.IsSymbol(")") || t.IsKeyword("UNION")
+}
+
+func (p *parser) parseDistinct() *helperclauses.ClauseCapturer {
+	if p.isKeywords("DISTINCT") {
+		return p.text(p.next().Text)
+	}
+	return p.node(&clauses.EmptyClause{})
+}
+
+// Parses the items of a WITH or RETURN, using the passed functions to create the aliased items and the chain linking them.
+// Returns true if all variables get projected using an asterisk, as well as a scope only holding the projected variables.
+func (p *parser) parseProjectionItems(element func(expression, alias *helperclauses.ClauseCapturer) *helperclauses.ClauseCapturer, link func() translator.Clause) (*helperclauses.ClauseCapturer, bool, *schema.Schema) {
+	scope := p.schema
+	projected := scope.NewContext()
+	var asterisk *helperclauses.ClauseCapturer
+	if p.acceptSymbol("*") {
+		asterisk = p.text("*")
+		projected = scope.Copy()
+		if !p.acceptSymbol(",") {
+			return asterisk, true, projected
+		}
+	}
+
+	var items []*helperclauses.ClauseCapturer
+	// The names of the projected items, mapped to the variables they project
+	var names, variables []string
+	for ok := true; ok; ok = p.acceptSymbol(",") {
+		expression := p.parseExpression()
+		variable := p.referencedVariable(expression)
+		name := variable
+		if p.acceptKeywords("AS") {
+			alias := p.parseName()
+			name = nameOf(alias)
+			expression = element(expression, alias)
+		}
+		items = append(items, expression)
+		names, variables = append(names, name), append(variables, variable)
+	}
+
+	p.schema = projected
+	for i, name := range names {
+		p.declareAs(name, scope, variables[i])
+	}
+	p.schema = scope
+
+	if asterisk != nil {
+		return p.assemble("%s, %s", asterisk, p.chain(items, link)), true, projected
+	}
+	return p.chain(items, link), false, projected
+}
+
+func (p *parser) returnElement(expression, alias *helperclauses.ClauseCapturer) *helperclauses.ClauseCapturer {
+	return p.node(&clauses.ReturnElement{}, expression, alias)
+}
+
+func (p *parser) withElement(expression, alias *helperclauses.ClauseCapturer) *helperclauses.ClauseCapturer {
+	name := alias.GetCapturedClause().(translator.Templater).TemplateString()
+	// The name is part of the WithElement's template string
+	if strings.Contains(name, "%") {
+		return p.assemble("%s AS %s", expression, alias)
+	}
+	return p.node(&clauses.WithElement{Name: name}, expression)
+}
+
+func (p *parser) parseOrderBy() *helperclauses.ClauseCapturer {
+	if !p.acceptKeywords("ORDER", "BY") {
+		return p.node(&clauses.EmptyClause{})
+	}
+	var items []*helperclauses.ClauseCapturer
+	for ok := true; ok; ok = p.acceptSymbol(",") {
+		item := p.parseExpression()
+		for _, order := range []string{"ASC", "ASCENDING", "DESC", "DESCENDING"} {
+			if p.isKeywords(order) {
+				item = p.assemble("%s %s", item, p.text(p.next().Text))
+				break
+			}
+		}
+		items = append(items, item)
+	}
+	return p.assemble("ORDER BY %s", p.list(items))
+}
+
+func (p *parser) parseSkip() *helperclauses.ClauseCapturer {
+	if !p.acceptKeywords("SKIP") {
+		return p.node(&clauses.EmptyClause{})
+	}
+	return p.assemble("SKIP %s", p.parseTypedExpression(schema.Integer))
+}
+
+func (p *parser) parseLimit() *helperclauses.ClauseCapturer {
+	if !p.acceptKeywords("LIMIT") {
+		return p.node(&clauses.EmptyClause{})
+	}
+	return p.assemble("LIMIT %s", p.parseTypedExpression(schema.Integer))
+}
+
+// Parses an optional WHERE, always returning an OptionalWhereClause
+func (p *parser) parseOptionalWhere() *helperclauses.ClauseCapturer {
+	if !p.acceptKeywords("WHERE") {
+		return p.node(&clauses.OptionalWhereClause{})
+	}
+	where := p.node(&clauses.WhereClause{}, p.node(&clauses.WhereExpression{}, p.parseTypedExpression(schema.Boolean)))
+	return p.node(&clauses.OptionalWhereClause{}, where)
+}
+
+// Parses a MERGE clause following the MERGE keyword, capturing the passed scope of the clause
+func (p *parser) parseMergeClause(scope *schema.Schema) *helperclauses.ClauseCapturer {
+	pattern := p.parsePatternPart()
+	template := "MERGE %s"
+	subclauses := []*helperclauses.ClauseCapturer{pattern}
+	for p.isKeywords("ON", "MATCH") || p.isKeywords("ON", "CREATE") {
+		p.next()
+		template += " ON " + strings.ToUpper(p.next().Text)
+		p.expectKeywords("SET")
+		template += " SET %s"
+		subclauses = append(subclauses, p.parseSetItems())
+	}
+	if len(subclauses) == 1 {
+		return p.nodeIn(scope, &clauses.MergeClause{}, pattern, p.node(&clauses.EmptyClause{}), p.node(&clauses.EmptyClause{}))
+	}
+	return p.nodeIn(scope, helperclauses.CreateAssembler(template), subclauses...)
+}
+
+func (p *parser) parseSetItems() *helperclauses.ClauseCapturer {
+	var items []*helperclauses.ClauseCapturer
+	for ok := true; ok; ok = p.acceptSymbol(",") {
+		items = append(items, p.parseSetItem())
+	}
+	return p.chain(items, func() translator.Clause { return &clauses.SetExpression{} })
+}
+
+func (p *parser) parseSetItem() *helperclauses.ClauseCapturer {
+	if p.peek().IsName() {
+		switch next := p.peekAt(1); {
+		case next.IsSymbol("="), next.IsSymbol("+="):
+			variable := p.parseName()
+			operator := p.next().Text
+			return p.assemble("%s "+operator+" %s", variable, p.parseExpression())
+		case next.IsSymbol(":"):
+			return p.assemble("%s%s", p.parseName(), p.parseLabels(false))
+		}
+	}
+	target, key := p.parsePropertyExpression()
+	p.expectSymbol("=")
+	return p.node(&clauses.SetPropertyExpression{}, target, key, p.parseExpression())
+}
+
+func (p *parser) parseRemoveItem() *helperclauses.ClauseCapturer {
+	if p.peek().IsName() && p.peekAt(1).IsSymbol(":") {
+		return p.assemble("%s%s", p.parseName(), p.parseLabels(false))
+	}
+	target, key := p.parsePropertyExpression()
+	return p.node(&clauses.RemovePropertyExpression{}, target, key)
+}
+
+// Parses a procedure call following the CALL keyword, including its YIELD
+func (p *parser) parseProcedureCall() *helperclauses.ClauseCapturer {
+	template := "CALL %s"
+	subclauses := []*helperclauses.ClauseCapturer{p.parseQualifiedName()}
+	if p.acceptSymbol("(") {
+		var arguments []*helperclauses.ClauseCapturer
+		for !p.isSymbol(")") {
+			if len(arguments) != 0 {
+				p.expectSymbol(",")
+			}
+			arguments = append(arguments, p.parseExpression())
+		}
+		p.expectSymbol(")")
+		template += "(" + strings.TrimSuffix(strings.Repeat("%s, ", len(arguments)), ", ") + ")"
+		subclauses = append(subclauses, arguments...)
+	}
+	if !p.acceptKeywords("YIELD") {
+		return p.assemble(template, subclauses...)
+	}
+	template += " YIELD %s"
+	if p.acceptSymbol("*") {
+		subclauses = append(subclauses, p.text("*"))
+	} else {
+		var items []*helperclauses.ClauseCapturer
+		var names []string
+		for ok := true; ok; ok = p.acceptSymbol(",") {
+			item := p.parseName()
+			name := nameOf(item)
+			if p.acceptKeywords("AS") {
+				alias := p.parseName()
+				item, name = p.assemble("%s AS %s", item, alias), nameOf(alias)
+			}
+			items = append(items, item)
+			names = append(names, name)
+		}
+		for _, name := range names {
+			p.declareProperty(name, schema.AnyType)
+		}
+		subclauses = append(subclauses, p.list(items))
+	}
+	if p.isKeywords("WHERE") {
+		template += " %s"
+		subclauses = append(subclauses, p.parseOptionalWhere())
+	}
+	return p.assemble(template, subclauses...)
+}
+
+// Parses a name, such as a variable or a label
+func (p *parser) parseName() *helperclauses.ClauseCapturer {
+	if !p.peek().IsName() {
+		p.fail("a name")
+	}
+	return p.text(p.next().Text)
+}
+
+// Parses a name which may be qualified by a namespace, such as a function name
+func (p *parser) parseQualifiedName() *helperclauses.ClauseCapturer {
+	if !p.peek().IsName() {
+		p.fail("a name")
+	}
+	name := p.next().Text
+	for p.isSymbol(".") && p.peekAt(1).IsName() {
+		p.next()
+		name += "." + p.next().Text
+	}
+	return p.text(name)
+}
+
+// Returns the AST node capturing the passed clause with the passed subclauses
+func (p *parser) node(clause translator.Clause, subclauses ...*helperclauses.ClauseCapturer) *helperclauses.ClauseCapturer {
+	return helperclauses.CreateGeneratedClauseCapturer(clause, p.schema, subclauses...)
+}
+
+// Returns the AST node of an assembler with the passed template string and subclauses
+func (p *parser) assemble(template string, subclauses ...*helperclauses.ClauseCapturer) *helperclauses.ClauseCapturer {
+	return p.node(helperclauses.CreateAssembler(template), subclauses...)
+}
+
+// Returns the AST node of a stringer with the passed value
+func (p *parser) text(value string) *helperclauses.ClauseCapturer {
+	return p.node(helperclauses.CreateStringer(value))
+}
+
+// Returns the passed items linked by clauses created by the passed function.
+// Like the chains of generated ASTs, every link holds an item and the link holding the next items.
+// The last item isn't held by a link of its own.
+func (p *parser) chain(items []*helperclauses.ClauseCapturer, link func() translator.Clause) *helperclauses.ClauseCapturer {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return p.node(link(), items[0], p.chain(items[1:], link))
+}
+
+// Returns the passed items separated by commas
+func (p *parser) list(items []*helperclauses.ClauseCapturer) *helperclauses.ClauseCapturer {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return p.assemble(strings.TrimSuffix(strings.Repeat("%s, ", len(items)), ", "), items...)
+}
+
+func (p *parser) peek() lexer.Token {
+	return p.peekAt(0)
+}
+
+// Returns the token at the passed offset from the current one
+func (p *parser) peekAt(offset int) lexer.Token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+offset]
+}
+
+// Returns the current token and advances to the next one
+func (p *parser) next() lexer.Token {
+	t := p.peek()
+	if t.Kind != lexer.EOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isSymbol(s string) bool {
+	return p.peek().IsSymbol(s)
+}
+
+func (p *parser) acceptSymbol(s string) bool {
+	if p.isSymbol(s) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectSymbol(s string) {
+	if !p.acceptSymbol(s) {
+		p.fail(fmt.Sprintf("%q", s))
+	}
+}
+
+// Returns true if the next tokens are the passed keywords
+func (p *parser) isKeywords(keywords ...string) bool {
+	for i, keyword := range keywords {
+		if !p.peekAt(i).IsKeyword(keyword) {
+			return false
+		}
+	}
+	return true
+}
+
+// Advances past the passed keywords and returns true if the next tokens are these keywords
+func (p *parser) acceptKeywords(keywords ...string) bool {
+	if p.isKeywords(keywords...) {
+		p.pos += len(keywords)
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeywords(keywords ...string) {
+	if !p.acceptKeywords(keywords...) {
+		p.fail(strings.Join(keywords, " "))
+	}
+}
+
+// Aborts parsing, reporting that something else than the current token was expected
+func (p *parser) fail(expected string) {
+	p.failAt(p.peek(), expected)
+}
+
+// Aborts parsing, reporting that something else than the passed token was expected
+func (p *parser) failAt(t lexer.Token, expected string) {
+	panic(parseError{fmt.Errorf("unexpected %s at %s, expected %s", t, lexer.Position(p.query, t.Pos), expected)})
+}
+
+// Calls the passed function, returning its result and true if it parsed successfully.
+// If it failed, the parser gets reset to the state before the call and false is returned.
+func (p *parser) try(parse func() *helperclauses.ClauseCapturer) (res *helperclauses.ClauseCapturer, ok bool) {
+	pos, scope := p.pos, p.schema.Copy()
+	defer func() {
+		if r := recover(); r != nil {
+			if _, isParseError := r.(parseError); !isParseError {
+				panic(r)
+			}
+			p.pos, p.schema = pos, scope
+			res, ok = nil, false
+		}
+	}()
+	return parse(), true
+}
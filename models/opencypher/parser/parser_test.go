@@ -0,0 +1,205 @@
+package parser
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Anon10214/dinkel/models/mock"
+	"github.com/Anon10214/dinkel/models/opencypher"
+	"github.com/Anon10214/dinkel/models/opencypher/clauses"
+	"github.com/Anon10214/dinkel/models/opencypher/lexer"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/seed"
+	"github.com/Anon10214/dinkel/translator"
+	"github.com/Anon10214/dinkel/translator/helperclauses"
+	"github.com/stretchr/testify/assert"
+)
+
+func translate(clause translator.Clause, seed *seed.Seed) string {
+	s := &schema.Schema{}
+	s.Reset()
+	res, _ := translator.GenerateStatement(seed, s, clause, mock.Implementation{}, 0)
+	return res
+}
+
+// Asserts that both queries consist of the same tokens, ignoring the case of keywords
+func assertSameTokens(t *testing.T, expected, actual string) bool {
+	expectedTokens, err := lexer.Tokenize(expected)
+	if !assert.NoError(t, err) {
+		return false
+	}
+	actualTokens, err := lexer.Tokenize(actual)
+	if !assert.NoError(t, err) {
+		return false
+	}
+	normalize := func(tokens []lexer.Token) []string {
+		var res []string
+		for _, t := range tokens {
+			if t.Kind == lexer.Identifier {
+				res = append(res, strings.ToUpper(t.Text))
+			} else {
+				res = append(res, t.Text)
+			}
+		}
+		return res
+	}
+	return assert.Equal(t, normalize(expectedTokens), normalize(actualTokens), "the parsed query %q should translate to %q", expected, actual)
+}
+
+// Parsing generated statements should result in ASTs translating to the same statements
+func TestParse_GeneratedStatements(t *testing.T) {
+	helperclauses.SetImplementation(mock.Implementation{})
+
+	for i := int64(0); i < 300; i++ {
+		seed := seed.GetRandomByteStringWithSource(*rand.New(rand.NewSource(i)))
+		statement := translate(&opencypher.RootClause{}, seed)
+
+		parsed, err := Parse(statement)
+		if !assert.NoError(t, err, "failed to parse %q", statement) {
+			continue
+		}
+		if assert.Len(t, parsed, 1) {
+			assertSameTokens(t, statement, translate(parsed[0], nil))
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	helperclauses.SetImplementation(mock.Implementation{})
+
+	for _, query := range []string{
+		"MATCH (n) RETURN n",
+		"OPTIONAL MATCH (a:A {x: 1})-[r:R|S *1..3]->(b), p = (c)<--(d:`weird %s name`) WHERE a.x > 1.5e3 AND NOT b:B RETURN DISTINCT a AS x, count(*) ORDER BY x DESC SKIP 1 LIMIT 2",
+		"UNWIND [1, 2, 3] AS x WITH x, x % 2 AS `100%` WHERE x <> 2 RETURN x UNION ALL RETURN 1 AS x UNION RETURN $param AS x",
+		"CREATE (a:A {name: 'it\\'s'}), (a)-[:R]->(:B) SET a.x = 1, a += {y: [1, 2]}, a:C:D REMOVE a.x, a:C DETACH DELETE a",
+		"MERGE (n:A {id: 1}) ON CREATE SET n.created = true ON MATCH SET n.matched = true RETURN *",
+		"MATCH (n) FOREACH (x IN [1] | CREATE (:A) FOREACH (y IN [x] | SET n.y = y)) WITH * CALL { WITH n RETURN n AS m } RETURN m",
+		"RETURN CASE 1 WHEN 1 THEN 'a' ELSE 'b' END, CASE WHEN true THEN 1 END.prop, -1, -(1 + 2), 2 ^ 3, 'a' STARTS WITH 'b', null IS NOT NULL",
+		"MATCH (n) WHERE EXISTS { (n)-->() } AND COUNT { MATCH (n)-->(m) RETURN m } > 1 RETURN COLLECT { MATCH (m) RETURN m.x UNION RETURN 1 }",
+		"RETURN [x IN range(0, 10) WHERE x % 2 = 0 | x * 2], [(a)-->(b) | b.x], all(x IN [1] WHERE x > 0), [1, 2][0..1], {a: 1}.a, apoc.coll.sum([1])",
+		"MATCH (n:!(A|(B&!C))) MATCH ()-[:%]-() RETURN n",
+		"CALL db.labels() YIELD label AS l WHERE l <> 'A' RETURN l",
+		"MATCH (n) RETURN count(DISTINCT n), []",
+		"CYPHER runtime = slotted MATCH (n) RETURN n",
+	} {
+		parsed, err := Parse(query)
+		if assert.NoError(t, err, "failed to parse %q", query) && assert.Len(t, parsed, 1) {
+			assertSameTokens(t, query, translate(parsed[0], nil))
+		}
+	}
+}
+
+func TestParse_MultipleStatements(t *testing.T) {
+	helperclauses.SetImplementation(mock.Implementation{})
+
+	parsed, err := Parse("CREATE INDEX FOR (n:A) ON (n.x);\n// A comment\nCREATE (:A {x: 1});\nMATCH (n) RETURN n;")
+	if assert.NoError(t, err) && assert.Len(t, parsed, 3) {
+		assert.Equal(t, "CREATE INDEX FOR (n:A) ON (n.x)", translate(parsed[0], nil))
+		assertSameTokens(t, "CREATE (:A {x: 1})", translate(parsed[1], nil))
+		assertSameTokens(t, "MATCH (n) RETURN n", translate(parsed[2], nil))
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for query, expectedErr := range map[string]string{
+		"":                     "no statements",
+		"MATCH (n RETURN n":    `unexpected "RETURN" at line 1, column 10, expected ")"`,
+		"MATCH (n)\nRETURN n,": "unexpected end of query at line 2, column 10, expected an expression",
+		"RETURN 'a":            "unterminated string at line 1, column 8",
+		"RETURN 1 RETURN 2":    `unexpected "RETURN" at line 1, column 10, expected a semicolon or the end of the query`,
+		"MATCH (n) FOO":        `unexpected "FOO" at line 1, column 11, expected a clause`,
+	} {
+		_, err := Parse(query)
+		assert.ErrorContains(t, err, expectedErr, "parsing %q", query)
+	}
+}
+
+// Parsed ASTs should be encodable for bug reports and reducible by the none strategy
+func TestParse_AST(t *testing.T) {
+	helperclauses.SetImplementation(mock.Implementation{})
+
+	query := "MATCH (n) WHERE n.x > 1 RETURN n.y + 2 AS z"
+	parsed, err := Parse(query)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	data, err := helperclauses.MarshalAST(parsed)
+	if !assert.NoError(t, err) {
+		return
+	}
+	loaded, err := helperclauses.UnmarshalAST(data)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, translate(parsed[0], nil), translate(loaded[0], nil))
+
+	// Reduce the WHERE expression to a boolean literal
+	where := findClause(loaded[0], func(clause translator.Clause) bool {
+		_, ok := clause.(*clauses.WhereExpression)
+		return ok
+	}).GetSubclauseClauseCapturers()[0]
+	expression := where.GetCapturedClause().(*clauses.Expression)
+	assert.Equal(t, schema.Boolean, expression.Conf.PropertyType)
+	where.UpdateClause(expression.NoStrategyReduce(where))
+
+	reduced := translate(loaded[0], nil)
+	assert.NotContains(t, reduced, "n.x")
+	assert.Contains(t, reduced, "n.y + 2 AS z")
+}
+
+// Returns the first clause capturer in the AST whose captured clause matches the predicate
+func findClause(root *helperclauses.ClauseCapturer, predicate func(translator.Clause) bool) *helperclauses.ClauseCapturer {
+	if predicate(root.GetCapturedClause()) {
+		return root
+	}
+	for _, subclause := range root.GetSubclauseClauseCapturers() {
+		if res := findClause(subclause, predicate); res != nil {
+			return res
+		}
+	}
+	return nil
+}
+
+// Parsed clauses should capture the variables declared before them
+func TestParse_CapturedSchemas(t *testing.T) {
+	helperclauses.SetImplementation(mock.Implementation{})
+
+	// Returns the variables declared in the schema captured by the first clause matching the predicate
+	declaredBy := func(root *helperclauses.ClauseCapturer, predicate func(translator.Clause) bool) map[string]any {
+		s := findClause(root, predicate).GetCapturedSchema()
+		variables := map[string]any{}
+		for name, variable := range s.StructuralVariablesByName {
+			variables[name] = variable.Type
+		}
+		for name, variable := range s.PropertyVariablesByName {
+			variables[name] = variable.Type
+		}
+		return variables
+	}
+	isType := func(example translator.Clause) func(translator.Clause) bool {
+		return func(clause translator.Clause) bool {
+			return reflect.TypeOf(clause) == reflect.TypeOf(example)
+		}
+	}
+
+	parsed, err := Parse("MATCH (n)-[r*]->(m) WHERE all(z IN [1] WHERE z > 0) UNWIND [1] AS x WITH n, x AS y RETURN y")
+	if assert.NoError(t, err) {
+		assert.Empty(t, declaredBy(parsed[0], isType(&clauses.Match{})))
+		assert.Equal(t, map[string]any{
+			"n": schema.NODE,
+			"r": schema.RELATIONSHIP | schema.StructuralType(schema.ListMask),
+			"m": schema.NODE,
+		}, declaredBy(parsed[0], isType(&clauses.Unwind{})))
+		assert.Contains(t, declaredBy(parsed[0], isType(&clauses.With{})), "x")
+		assert.Equal(t, map[string]any{"n": schema.NODE, "y": schema.AnyType}, declaredBy(parsed[0], isType(&clauses.Return{})))
+	}
+
+	parsed, err = Parse("MATCH (a) CALL { WITH a MATCH (a)-->(b) RETURN b } FOREACH (x IN [1] | CREATE ()) RETURN *")
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]any{"a": schema.NODE}, declaredBy(parsed[0], isType(&clauses.CallSubquery{})))
+		assert.Equal(t, map[string]any{"a": schema.NODE, "b": schema.NODE}, declaredBy(parsed[0], isType(&clauses.Return{})))
+	}
+}
@@ -0,0 +1,224 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/Anon10214/dinkel/models/opencypher/clauses"
+	"github.com/Anon10214/dinkel/models/opencypher/lexer"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/translator"
+	"github.com/Anon10214/dinkel/translator/helperclauses"
+)
+
+// Parses the comma separated pattern parts of a MATCH
+func (p *parser) parseMatchPattern() *helperclauses.ClauseCapturer {
+	part := p.parsePatternPart()
+	if !p.acceptSymbol(",") {
+		return p.node(&clauses.MatchElementChain{IsBaseCase: true}, part)
+	}
+	return p.node(&clauses.MatchElementChain{}, part, p.parseMatchPattern())
+}
+
+// Parses a pattern part, which may be assigned to a path variable
+func (p *parser) parsePatternPart() *helperclauses.ClauseCapturer {
+	if p.peek().IsName() && p.peekAt(1).IsSymbol("=") {
+		variable := p.parseName()
+		p.next()
+		part := p.assemble("%s = %s", variable, p.parsePatternElement())
+		p.declareStructural(nameOf(variable), schema.PATH)
+		return part
+	}
+	return p.parsePatternElement()
+}
+
+// Parses a path, consisting of nodes connected by relationships
+func (p *parser) parsePatternElement() *helperclauses.ClauseCapturer {
+	switch {
+	case p.peek().IsName() && p.peekAt(1).IsSymbol("("):
+		// Functions returning paths, such as shortestPath
+		return p.parseExpression()
+	case p.isSymbol("(") && p.peekAt(1).IsSymbol("("):
+		p.next()
+		element := p.parsePatternElement()
+		p.expectSymbol(")")
+		return p.parseRelationships(p.assemble("(%s)", element))
+	}
+	return p.parseRelationships(p.parseNode())
+}
+
+// Parses the relationships following the passed node, if there are any.
+//
+// A path with relationships is held by an assembler holding its first node, the first relationship's details
+// and the path starting at the next node.
+func (p *parser) parseRelationships(node *helperclauses.ClauseCapturer) *helperclauses.ClauseCapturer {
+	if !p.isSymbol("-") && !(p.isSymbol("<") && p.peekAt(1).IsSymbol("-")) {
+		return node
+	}
+	template := "%s"
+	if p.acceptSymbol("<") {
+		template += "<"
+	}
+	p.expectSymbol("-")
+	template += "-"
+	subclauses := []*helperclauses.ClauseCapturer{node}
+	if p.isSymbol("[") {
+		template += "%s"
+		subclauses = append(subclauses, p.parseRelationshipDetail())
+	}
+	p.expectSymbol("-")
+	template += "-"
+	if p.acceptSymbol(">") {
+		template += ">"
+	}
+	template += "%s"
+
+	var next *helperclauses.ClauseCapturer
+	if p.isSymbol("(") && p.peekAt(1).IsSymbol("(") {
+		p.next()
+		element := p.parsePatternElement()
+		p.expectSymbol(")")
+		next = p.parseRelationships(p.assemble("(%s)", element))
+	} else {
+		next = p.parseRelationships(p.parseNode())
+	}
+	return p.assemble(template, append(subclauses, next)...)
+}
+
+func (p *parser) parseNode() *helperclauses.ClauseCapturer {
+	p.expectSymbol("(")
+	name := p.parseOptionalName()
+	labels := p.parseOptionalLabels(true)
+	properties := p.parseOptionalProperties()
+	p.expectSymbol(")")
+	node := p.node(&clauses.MatchNode{}, name, labels, properties)
+	p.declareStructural(nameOf(name), schema.NODE)
+	return node
+}
+
+// Parses the part of a relationship enclosed in brackets
+func (p *parser) parseRelationshipDetail() *helperclauses.ClauseCapturer {
+	p.expectSymbol("[")
+	name := p.parseOptionalName()
+	labels := p.parseOptionalLabels(true)
+	if !p.isSymbol("*") {
+		properties := p.parseOptionalProperties()
+		p.expectSymbol("]")
+		relationship := p.node(&clauses.MatchRelationship{}, name, labels, properties)
+		p.declareStructural(nameOf(name), schema.RELATIONSHIP)
+		return relationship
+	}
+
+	// The variable length of the relationship, as in *, *2, *1..3, *..3 or *2..
+	length := p.next().Text
+	if isNumber(p.peek()) {
+		length += p.next().Text
+	}
+	if p.acceptSymbol("..") {
+		length += ".."
+		if isNumber(p.peek()) {
+			length += p.next().Text
+		}
+	}
+	properties := p.parseOptionalProperties()
+	p.expectSymbol("]")
+	relationship := p.assemble("[%s%s%s%s]", name, labels, p.text(length), properties)
+	// Variable length relationships are bound to lists of relationships
+	p.declareStructural(nameOf(name), schema.RELATIONSHIP|schema.StructuralType(schema.ListMask))
+	return relationship
+}
+
+func (p *parser) parseOptionalName() *helperclauses.ClauseCapturer {
+	if p.peek().IsName() {
+		return p.parseName()
+	}
+	return p.node(&clauses.EmptyClause{})
+}
+
+func (p *parser) parseOptionalLabels(isPattern bool) *helperclauses.ClauseCapturer {
+	if !p.isSymbol(":") {
+		return p.node(&clauses.EmptyClause{})
+	}
+	return p.parseLabels(isPattern)
+}
+
+// Parses one or more labels, each preceded by a colon.
+//
+// Patterns may also hold label expressions, such as :A|B or :!(A&B).
+// Each of these is kept as is, as it isn't possible to restore the generated LabelMatch-es from them.
+func (p *parser) parseLabels(isPattern bool) *helperclauses.ClauseCapturer {
+	var labels []*helperclauses.ClauseCapturer
+	for p.acceptSymbol(":") {
+		var label string
+		if isPattern {
+			start := p.peek().Pos
+			p.parseLabelExpression()
+			previous := p.tokens[p.pos-1]
+			label = p.query[start : previous.Pos+len(previous.Text)]
+		} else {
+			if !p.peek().IsName() {
+				p.fail("a label")
+			}
+			label = p.next().Text
+		}
+		labels = append(labels, p.node(&clauses.Label{}, p.text(label)))
+	}
+	return p.assemble(strings.Repeat("%s", len(labels)), labels...)
+}
+
+// Skips over a label expression, such as A|:B or !(A&%)
+func (p *parser) parseLabelExpression() {
+	p.parseLabelConjunction()
+	for p.acceptSymbol("|") {
+		p.acceptSymbol(":")
+		p.parseLabelConjunction()
+	}
+}
+
+func (p *parser) parseLabelConjunction() {
+	p.parseLabelFactor()
+	for p.acceptSymbol("&") {
+		p.parseLabelFactor()
+	}
+}
+
+func (p *parser) parseLabelFactor() {
+	switch {
+	case p.acceptSymbol("!"):
+		p.parseLabelFactor()
+	case p.acceptSymbol("%"):
+	case p.acceptSymbol("("):
+		p.parseLabelExpression()
+		p.expectSymbol(")")
+	case p.peek().IsName():
+		p.next()
+	default:
+		p.fail("a label")
+	}
+}
+
+// Parses the properties of a node or relationship
+func (p *parser) parseOptionalProperties() *helperclauses.ClauseCapturer {
+	switch {
+	case p.peek().Kind == lexer.Parameter:
+		return p.text(p.next().Text)
+	case p.isSymbol("{"):
+		return p.parseMap(func() translator.Clause { return &clauses.PropertiesMatch{} })
+	}
+	return p.node(&clauses.EmptyClause{})
+}
+
+// Parses a map, such as {a: 1, b: 'x'}, using the passed function to create the clause holding its entries
+func (p *parser) parseMap(container func() translator.Clause) *helperclauses.ClauseCapturer {
+	p.expectSymbol("{")
+	if p.acceptSymbol("}") {
+		return p.text("{}")
+	}
+	var entries []*helperclauses.ClauseCapturer
+	for ok := true; ok; ok = p.acceptSymbol(",") {
+		key := p.parseName()
+		p.expectSymbol(":")
+		entries = append(entries, p.assemble("%s: %s", key, p.parseExpression()))
+	}
+	p.expectSymbol("}")
+	return p.node(container(), p.chain(entries, func() translator.Clause { return &clauses.PropertyChain{} }))
+}
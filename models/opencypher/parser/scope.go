@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/Anon10214/dinkel/models/opencypher/clauses"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/translator"
+	"github.com/Anon10214/dinkel/translator/helperclauses"
+)
+
+// Returns the name held by the passed name node, or an empty string if it doesn't hold one
+func nameOf(name *helperclauses.ClauseCapturer) string {
+	stringer, ok := name.GetCapturedClause().(*helperclauses.Stringer)
+	if !ok {
+		return ""
+	}
+	return strings.ReplaceAll(stringer.TemplateString(), "%%", "%")
+}
+
+// Returns true if a variable with the passed name is declared in the current scope
+func (p *parser) isDeclared(name string) bool {
+	_, isProperty := p.schema.PropertyVariablesByName[name]
+	_, isStructural := p.schema.StructuralVariablesByName[name]
+	return isProperty || isStructural
+}
+
+// Declares the passed structural variable in the current scope, unless it is already declared
+func (p *parser) declareStructural(name string, structuralType schema.StructuralType) {
+	if name != "" && !p.isDeclared(name) {
+		(*p.schema.UsedNames)[name] = true
+		p.schema.AddStructuralVariable(schema.StructuralVariable{Name: name, Type: structuralType})
+	}
+}
+
+// Declares the passed property variable in the current scope, unless it is already declared
+func (p *parser) declareProperty(name string, propertyType schema.PropertyType) {
+	if name != "" && !p.isDeclared(name) {
+		(*p.schema.UsedNames)[name] = true
+		p.schema.AddPropertyVariable(schema.PropertyVariable{Name: name, Type: propertyType})
+	}
+}
+
+// Declares the passed name in the current scope, with the type the passed variable has in the passed scope.
+// If the variable isn't declared in that scope, the name gets declared as a property variable of any type.
+func (p *parser) declareAs(name string, scope *schema.Schema, variable string) {
+	if structural, ok := scope.StructuralVariablesByName[variable]; ok {
+		p.declareStructural(name, structural.Type)
+	} else if property, ok := scope.PropertyVariablesByName[variable]; ok {
+		p.declareProperty(name, property.Type)
+	} else {
+		p.declareProperty(name, schema.AnyType)
+	}
+}
+
+// Declares all variables of the passed scope in the current one
+func (p *parser) declareAll(scope *schema.Schema) {
+	var names []string
+	for name := range scope.StructuralVariablesByName {
+		names = append(names, name)
+	}
+	for name := range scope.PropertyVariablesByName {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		p.declareAs(name, scope, name)
+	}
+}
+
+// Returns the name of the variable the passed expression references, if it consists of nothing but a declared variable
+func (p *parser) referencedVariable(expression *helperclauses.ClauseCapturer) string {
+	if _, ok := expression.GetCapturedClause().(*clauses.Expression); !ok {
+		return ""
+	}
+	if subclauses := expression.GetSubclauseClauseCapturers(); len(subclauses) == 1 {
+		if name := nameOf(subclauses[0]); p.isDeclared(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// Returns the AST node capturing the passed clause with the passed subclauses, generated in the passed scope.
+//
+// Clauses holding the clauses following them are only created once these are parsed.
+// They have to capture the scope from before their own variables got declared.
+func (p *parser) nodeIn(scope *schema.Schema, clause translator.Clause, subclauses ...*helperclauses.ClauseCapturer) *helperclauses.ClauseCapturer {
+	return helperclauses.CreateGeneratedClauseCapturer(clause, scope, subclauses...)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/Anon10214/dinkel/models/opencypher/clauses"
 	"github.com/Anon10214/dinkel/seed"
 	"github.com/Anon10214/dinkel/translator"
+	"github.com/Anon10214/dinkel/translator/helperclauses"
 
 	// Imports for docstring
 	"fmt"
@@ -22,6 +23,10 @@ import (
 // RootClause of OpenCypher represents the root of the AST.
 type RootClause clauses.ReadClause
 
+func init() {
+	helperclauses.RegisterClauses(&RootClause{})
+}
+
 // Generate subclauses for OpenCypherRootClause
 func (c *RootClause) Generate(seed *seed.Seed, s *schema.Schema) []translator.Clause {
 	if out := seed.GetByte(); out%5 == 0 {
@@ -0,0 +1,76 @@
+package schema
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Literal returns the Cypher literal evaluating to the passed value, as used for the values of properties.
+//
+// Booleans, int64s, finite float64s, strings and lists of these are rendered as literals.
+// All other values, including the minimum integer and non-finite floats, are rendered using the passed fallback,
+// allowing targets to express values they support, such as temporal values.
+// Returns the empty string if the value can't be expressed, or if the fallback is nil and the value isn't a plain literal.
+func Literal(value any, fallback func(any) string) string {
+	switch value := value.(type) {
+	case bool:
+		return strconv.FormatBool(value)
+	case int64:
+		// Negating the literal 9223372036854775808 overflows
+		if value != math.MinInt64 {
+			return strconv.FormatInt(value, 10)
+		}
+	case float64:
+		if !math.IsNaN(value) && !math.IsInf(value, 0) {
+			return FloatLiteral(value)
+		}
+	case string:
+		return StringLiteral(value)
+	case []any:
+		elems := make([]string, len(value))
+		for i, elem := range value {
+			if elems[i] = Literal(elem, fallback); elems[i] == "" {
+				return ""
+			}
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	}
+	if fallback == nil {
+		return ""
+	}
+	return fallback(value)
+}
+
+// FloatLiteral returns the shortest Cypher literal evaluating to exactly the passed finite float.
+func FloatLiteral(f float64) string {
+	// Cypher doesn't allow a plus sign in the exponent
+	literal := strings.Replace(strconv.FormatFloat(f, 'g', -1, 64), "e+", "e", 1)
+	if !strings.ContainsAny(literal, ".e") {
+		literal += ".0"
+	}
+	return literal
+}
+
+// StringLiteral returns the double quoted and escaped Cypher string literal for the passed string.
+func StringLiteral(s string) string {
+	var buf strings.Builder
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	// Encoding a string never fails
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// CompareProperties orders properties by their name, type and value.
+// Targets sort the properties they populate the schema with using it, making the schema deterministic.
+func CompareProperties(a, b Property) int {
+	if a.Name != b.Name {
+		return strings.Compare(a.Name, b.Name)
+	}
+	if a.Type != b.Type {
+		return int(a.Type) - int(b.Type)
+	}
+	return strings.Compare(a.Value, b.Value)
+}
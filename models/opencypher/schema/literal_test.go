@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiteral(t *testing.T) {
+	for _, tc := range []struct {
+		value    any
+		expected string
+	}{
+		{true, "true"},
+		{int64(-3), "-3"},
+		{float64(2), "2.0"},
+		{0.1, "0.1"},
+		{1.5e300, "1.5e300"},
+		{"a\"b\\c\n", `"a\"b\\c\n"`},
+		{"it's \x01<a>", `"it's \u0001<a>"`},
+		{[]any{int64(1), "x", []any{true}}, `[1, "x", [true]]`},
+		{math.NaN(), ""},
+		{math.Inf(-1), ""},
+		{int64(math.MinInt64), ""},
+		{[]any{int64(1), math.Inf(1)}, ""},
+		{map[string]any{"a": int64(1)}, ""},
+		{nil, ""},
+	} {
+		assert.Equal(t, tc.expected, Literal(tc.value, nil), tc.value)
+	}
+
+	fallback := func(value any) string {
+		if value == nil {
+			return "null"
+		}
+		return ""
+	}
+	assert.Equal(t, "[1, null]", Literal([]any{int64(1), nil}, fallback))
+	assert.Equal(t, "", Literal([]any{math.NaN(), nil}, fallback))
+}
+
+func TestCompareProperties(t *testing.T) {
+	properties := []Property{
+		{Name: "b", Type: Integer, Value: "1"},
+		{Name: "a", Type: String, Value: `"x"`},
+		{Name: "a", Type: Integer, Value: "2"},
+		{Name: "a", Type: Integer, Value: "1"},
+	}
+	for i := 1; i < len(properties); i++ {
+		assert.Positive(t, CompareProperties(properties[i-1], properties[i]), properties[i])
+		assert.Negative(t, CompareProperties(properties[i], properties[i-1]), properties[i])
+	}
+	assert.Zero(t, CompareProperties(properties[0], properties[0]))
+}
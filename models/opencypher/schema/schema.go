@@ -21,22 +21,22 @@ import (
 // The Schema used for stateful query generation
 type Schema struct {
 	// Lists the names of all properties used in a graph element
-	Properties         map[PropertyType][]Property
-	PropertyTypeByName map[string]PropertyType
+	Properties         map[PropertyType][]Property `ast:"properties"`
+	PropertyTypeByName map[string]PropertyType     `ast:"propertyTypeByName"`
 	// Lists all labels used for a graph element
-	Labels map[StructuralType][]string
+	Labels map[StructuralType][]string `ast:"labels"`
 	// Whether the statement already has an OPTIONAL MATCH clause (disallows further use of normal MATCH clauses)
 	// TODO: Not every DBMS needs this feature, some accept MATCH after OPTIONAL MATCH
-	HasOptionalMatch bool
+	HasOptionalMatch bool `ast:"hasOptionalMatch"`
 
 	// Added because of https://github.com/neo4j/neo4j/issues/13054
-	IsInSubquery bool
+	IsInSubquery bool `ast:"isInSubquery"`
 
 	// If this is set, the query will no longer generate any write clauses
-	DisallowWriteClauses bool
+	DisallowWriteClauses bool `ast:"disallowWriteClauses"`
 
 	// If this is set, no RETURN clause can be generated
-	CannotReturn bool
+	CannotReturn bool `ast:"cannotReturn"`
 
 	// There are two label match types in neo4j, the old one (only allowing ANDing labels by separating them with a colon)
 	// And the new one, allowing complex expressions with negation, ORing and ANDing labels, plus adding wildcards
@@ -45,43 +45,48 @@ type Schema struct {
 	// The RootClause and WriteQuery must reset this value to nil during generation
 	//
 	// [Neo4j Docs]: https://neo4j.com/docs/cypher-manual/current/syntax/expressions/#syntax-restrictions-label
-	UseNewLabelMatchType *bool
+	UseNewLabelMatchType *bool `ast:"useNewLabelMatchType"`
 
 	// If set, this decides whether the unions should be UNION ALL clauses or just UNION clauses
-	IsUnionAll *bool
+	IsUnionAll *bool `ast:"isUnionAll"`
 
 	// If true, expressions are not allowed to evaluate to NULL or contain subquery expressions (COUNT/EXISTS/COLLECT)
-	IsInMergeClause bool
+	IsInMergeClause bool `ast:"isInMergeClause"`
 
 	// If true, expressions are not allowed to contain aggregate functions
-	DisallowAggregateFunctions bool
+	DisallowAggregateFunctions bool `ast:"disallowAggregateFunctions"`
 	// If this is set, the clause `RETURN *` will never be generated
-	DisallowReturnAll bool
+	DisallowReturnAll bool `ast:"disallowReturnAll"`
 
 	// Map of all used names in the query, used to ensure their uniqueness
-	UsedNames *map[string]bool
+	UsedNames *map[string]bool `ast:"usedNames"`
 
 	// Map of all deleted edge/node variables
-	DeletedVars map[string]bool
+	DeletedVars map[string]bool `ast:"deletedVars"`
 
 	// Allows UNION clauses and having CALL subqueries return variables
-	MustReturn                  bool // If MustReturn is true, the statement has to terminate with a RETURN clause
-	PropertyVariablesToReturn   []PropertyVariable
-	StructuralVariablesToReturn []StructuralVariable
+	MustReturn                  bool                 `ast:"mustReturn"` // If MustReturn is true, the statement has to terminate with a RETURN clause
+	PropertyVariablesToReturn   []PropertyVariable   `ast:"propertyVariablesToReturn"`
+	StructuralVariablesToReturn []StructuralVariable `ast:"structuralVariablesToReturn"`
 
 	// Property variables (ints, floats, strings, etc) hold variables created using WITH or UNWIND statements and can be used everywhere
-	PropertyVariablesByName map[string]PropertyVariable         // Holds property variables, searchable via name
-	PropertyVariablesByType map[PropertyType][]PropertyVariable // Holds property variables, collected via type
+	PropertyVariablesByName map[string]PropertyVariable         `ast:"propertyVariablesByName"` // Holds property variables, searchable via name
+	PropertyVariablesByType map[PropertyType][]PropertyVariable `ast:"propertyVariablesByType"` // Holds property variables, collected via type
 
 	// Structural variables (nodes, relationships and paths) can only be used in RETURN and WHERE statements
-	StructuralVariablesByName map[string]StructuralVariable           // Holds structural variables, searchable via name
-	StructuralVariablesByType map[StructuralType][]StructuralVariable // Holds structural variables, collected via type
+	StructuralVariablesByName map[string]StructuralVariable           `ast:"structuralVariablesByName"` // Holds structural variables, searchable via name
+	StructuralVariablesByType map[StructuralType][]StructuralVariable `ast:"structuralVariablesByType"` // Holds structural variables, collected via type
 
 	// Holds structural variables that just got created in a CREATE clause, which can't be used in functions in the same clause
-	JustCreatedStructuralVariables []StructuralVariable
+	JustCreatedStructuralVariables []StructuralVariable `ast:"justCreatedStructuralVariables"`
 
 	// Names of created indexes
-	Indexes []string
+	Indexes []string `ast:"indexes"`
+
+	// How the rows returned by the statement are ordered, set by the statement's final RETURN
+	ReturnOrder RowOrder `ast:"returnOrder"`
+	// The amount of leading columns TruncatedTies rows got ordered by, 0 if they got ordered by other expressions
+	ReturnSortColumns int `ast:"returnSortColumns"`
 }
 
 // Reset sets the schema back to an initial state.
@@ -267,9 +272,9 @@ func (s *Schema) AddProperty(property Property) {
 
 // Property represents a node's or relationship's property
 type Property struct {
-	Name  string
-	Type  PropertyType
-	Value string
+	Name  string       `ast:"name"`
+	Type  PropertyType `ast:"type"`
+	Value string       `ast:"value"`
 }
 
 // StructuralType specifies the exact type of a structural variable.
@@ -283,20 +288,36 @@ const (
 	PATH
 )
 
+// RowOrder describes how the rows returned by a statement are ordered.
+type RowOrder int
+
+// Row orders
+const (
+	// Unordered rows may be returned in any order
+	Unordered RowOrder = iota
+	// TotallyOrdered rows got ordered by all returned columns, their order is fully determined.
+	// Rows tying in all columns are indistinguishable, so SKIP and LIMIT don't change that.
+	TotallyOrdered
+	// TruncatedTies rows got ordered by expressions not fully determining their order before being cut by SKIP or LIMIT.
+	// Which of the rows tying at the cut get returned is up to the target.
+	// If the rows got ordered by their leading columns, rows tying before the cut must all be returned.
+	TruncatedTies
+)
+
 // A PropertyVariable represents any variable evaluating to a property value.
 type PropertyVariable struct {
-	Name  string
-	Type  PropertyType
-	Value string
+	Name  string       `ast:"name"`
+	Type  PropertyType `ast:"type"`
+	Value string       `ast:"value"`
 }
 
 // A StructuralVariable represents any variable evaluating to a structural value.
 type StructuralVariable struct {
-	Name string
-	Type StructuralType
+	Name string         `ast:"name"`
+	Type StructuralType `ast:"type"`
 	// If this variable is likely to evaluate to nil.
 	// If unset, then the variable is guaranteed to be non nil.
-	LikelyNull bool
+	LikelyNull bool `ast:"likelyNull"`
 }
 
 // PropertyType represents a type for a Cypher expression
@@ -359,30 +380,30 @@ const (
 // The ExpressionConfig holds all options dictating how an expression gets generated.
 type ExpressionConfig struct {
 	// If this expression cannot evaluate to null
-	MustBeNonNull bool
+	MustBeNonNull bool `ast:"mustBeNonNull"`
 	// If this expression represents a list of the underlying type
-	IsList bool
+	IsList bool `ast:"isList"`
 	// The type of this expression
-	TargetType     ExpressionType
-	PropertyType   PropertyType   // Only relevant if targetType != STRUCTURAL
-	StructuralType StructuralType // Only relevant if targetType != PROPERTY
+	TargetType     ExpressionType `ast:"targetType"`
+	PropertyType   PropertyType   `ast:"propertyType"`   // Only relevant if targetType != STRUCTURAL
+	StructuralType StructuralType `ast:"structuralType"` // Only relevant if targetType != PROPERTY
 	// Constant expressions aren't allowed to contain variables
-	IsConstantExpression bool
+	IsConstantExpression bool `ast:"isConstantExpression"`
 	// If this is expression is allowed to contain aggregating functions, for example in a RETURN or WITH statement
-	CanContainAggregatingFunctions bool
+	CanContainAggregatingFunctions bool `ast:"canContainAggregatingFunctions"`
 	// If this expression is allowed to evaluate to a map
-	AllowMaps bool
+	AllowMaps bool `ast:"allowMaps"`
 	// If true, then whatever this expression will evaluate to, will be deleted from the graph
-	GetsDeleted bool
+	GetsDeleted bool `ast:"getsDeleted"`
 }
 
 // The Function struct represents a function callable in a cypher query.
 // Its return type gets defined in the implementation-specific OpenCypher config.
 type Function struct {
 	// The function's name
-	Name string
+	Name string `ast:"name"`
 	// The expression configs for the function arguments
-	InputTypes []ExpressionConfig
+	InputTypes []ExpressionConfig `ast:"inputTypes"`
 	// If true, this function can always return null even if arguments are all non null
-	CanAlwaysBeNull bool
+	CanAlwaysBeNull bool `ast:"canAlwaysBeNull"`
 }
@@ -0,0 +1,122 @@
+package scheduler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/seed"
+	"github.com/Anon10214/dinkel/translator"
+	"github.com/Anon10214/dinkel/translator/helperclauses"
+	"github.com/qdm12/reprint"
+)
+
+// A statementSnapshot holds everything needed to regenerate a statement's AST after it got generated.
+//
+// Generating statements using clause capturers is slow, so statements get generated without them while fuzzing.
+// Their ASTs only get captured by regenerating them from their snapshots once they trigger a bug.
+type statementSnapshot struct {
+	// The root clause before generating the statement
+	rootClause translator.Clause
+	// The schema before generating the statement
+	schema *schema.Schema
+	// The position of the seed before generating the statement
+	seedPosition int
+	// The generated statement
+	statement string
+}
+
+// takeSnapshot returns a snapshot of the statement about to be generated from the passed root clause, schema and seed.
+//
+// Taking a snapshot is part of generating every statement, so only the root clause itself gets copied instead of its subtree.
+// The root clauses returned by strategies are either freshly created, holding no subtree yet,
+// or hold clause capturers, which translate to the same statement when regenerated.
+func takeSnapshot(rootClause translator.Clause, s *schema.Schema, seed *seed.Seed) statementSnapshot {
+	snapshot := statementSnapshot{seedPosition: seed.Position()}
+	if capturer, ok := rootClause.(*helperclauses.ClauseCapturer); ok {
+		// Capturers hold their AST after generation, so they can be kept as is
+		snapshot.rootClause = capturer
+		return snapshot
+	}
+	snapshot.rootClause = rootClause
+	if v := reflect.ValueOf(rootClause); v.Kind() == reflect.Pointer {
+		// Generating the statement sets the fields of the root clause, so keep them as they are now
+		rootClauseCopy := reflect.New(v.Type().Elem())
+		rootClauseCopy.Elem().Set(v.Elem())
+		snapshot.rootClause = rootClauseCopy.Interface().(translator.Clause)
+	}
+	// Copy the schema directly, as the schema's Copy method keeps the used names shared
+	schemaCopy := reprint.This(*s).(schema.Schema)
+	snapshot.schema = &schemaCopy
+	return snapshot
+}
+
+// captureAST regenerates the snapshotted statements using clause capturers and returns their ASTs.
+//
+// The passed byte string has to hold all bytes the seed returned while generating the statements.
+// An error is returned if a regenerated statement differs from the snapshotted one.
+func captureAST(conf Config, snapshots []statementSnapshot, byteString []byte) ([]*helperclauses.ClauseCapturer, error) {
+	helperclauses.SetImplementation(conf.Implementation)
+	var ast []*helperclauses.ClauseCapturer
+	for i, snapshot := range snapshots {
+		rootClause := helperclauses.GetClauseCapturerForClause(snapshot.rootClause)
+		if snapshot.schema != nil {
+			seed := seed.GetPregeneratedByteString(byteString[snapshot.seedPosition:])
+			statement, err := translator.GenerateStatement(seed, snapshot.schema, rootClause, conf.Implementation, 0)
+			if err != nil {
+				return nil, err
+			}
+			if statement != snapshot.statement {
+				return nil, fmt.Errorf("regenerating statement #%d resulted in %q instead of %q", i, statement, snapshot.statement)
+			}
+		}
+		ast = append(ast, rootClause)
+	}
+	return ast, nil
+}
+
+// getRootClause returns the root clause of the statement at the passed index.
+//
+// If the config holds an AST, the strategy's root clause gets replaced by the AST's root clause at this index.
+// Strategies keeping track of the clauses they returned see this replacement, as the clause gets updated in place.
+func getRootClause(conf Config, s *schema.Schema, seed *seed.Seed, index int) translator.Clause {
+	rootClause := conf.Strategy.GetRootClause(conf.Implementation, s, seed)
+	if conf.AST == nil {
+		return rootClause
+	}
+	helperclauses.SetImplementation(conf.Implementation)
+	capturer := helperclauses.GetClauseCapturerForClause(rootClause)
+	capturer.UpdateClause(conf.AST[index])
+	return capturer
+}
+
+// isLastStatement returns true if no further statements should be generated after the one at the passed index.
+//
+// If the config holds an AST, exactly the AST's statements get run, regardless of the strategy's decision.
+func isLastStatement(conf Config, index int, discard bool) bool {
+	if conf.AST != nil {
+		return index == len(conf.AST)-1
+	}
+	return discard
+}
+
+// encodeAST returns the passed AST as stored in bug reports, which is its gzipped JSON encoding in base64.
+func encodeAST(ast []*helperclauses.ClauseCapturer) (string, error) {
+	data, err := helperclauses.MarshalAST(ast)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return "", errors.Join(errors.New("couldn't compress AST - "), err)
+	}
+	if err := w.Close(); err != nil {
+		return "", errors.Join(errors.New("couldn't compress AST - "), err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/opencypher/config"
 	"github.com/Anon10214/dinkel/scheduler/strategy/equivalencetransformation"
 	"github.com/Anon10214/dinkel/seed"
 	"github.com/Anon10214/dinkel/translator"
@@ -14,6 +15,7 @@ import (
 
 // Reduce takes in a conf for regenerating the bugreport and a string
 // holding the path where the reduced bugreport should be saved.
+// The bugreport's query gets regenerated from the config's AST if it holds one, or from its byte string otherwise.
 //
 // If fullReduction is set to true, the reduction will be repeated until no more changes to any of the statements occur.
 //
@@ -21,6 +23,8 @@ import (
 // example triggering the bug. However, some further manual reduction will almost
 // always be necessary afterwards.
 func Reduce(conf Config, newBugreportName string, fullReduction bool) error {
+	config.SetFloatTolerance(conf.FloatTolerance)
+	config.SetOrderedReturns(conf.OrderedReturns)
 	seed := seed.GetPregeneratedByteString(conf.ByteString)
 	// Generate the original queries
 	var origRootClauses []*helperclauses.ClauseCapturer
@@ -32,24 +36,24 @@ func Reduce(conf Config, newBugreportName string, fullReduction bool) error {
 		return err
 	}
 
-	if err := conf.DB.Reset(conf.DBOptions); err != nil {
+	if err := resetDB(conf); err != nil {
 		return err
 	}
 
 	conf.Strategy.Reset()
 
 	helperclauses.SetImplementation(conf.Implementation)
-	for {
+	for statementCount := 0; ; statementCount++ {
 		schema, err := conf.DB.GetSchema(conf.DBOptions)
 		if err != nil {
 			return err
 		}
 
-		rootClause := helperclauses.GetClauseCapturerForClause(conf.Strategy.GetRootClause(conf.Implementation, schema, seed))
+		rootClause := helperclauses.GetClauseCapturerForClause(getRootClause(conf, schema, seed, statementCount))
 		statement, _ := translator.GenerateStatement(seed, schema, rootClause, conf.Implementation, 0)
 		origRootClauses = append(origRootClauses, rootClause)
 
-		result, err := RunQuery(conf, statement)
+		result, err := RunQuery(conf, statement, schema.ReturnOrder, schema.ReturnSortColumns)
 		if err != nil {
 			return err
 		}
@@ -61,7 +65,7 @@ func Reduce(conf Config, newBugreportName string, fullReduction bool) error {
 			}
 		}
 
-		if conf.Strategy.DiscardQuery(result.Type, conf.DB, conf.DBOptions, result, seed) {
+		if isLastStatement(conf, statementCount, conf.Strategy.DiscardQuery(result.Type, conf.DB, conf.DBOptions, result, seed)) {
 			break
 		}
 	}
@@ -107,7 +111,7 @@ func Reduce(conf Config, newBugreportName string, fullReduction bool) error {
 	logrus.Info("Creating bugreport")
 
 	// Create bug report
-	if err := conf.DB.Reset(conf.DBOptions); err != nil {
+	if err := resetDB(conf); err != nil {
 		return err
 	}
 
@@ -121,7 +125,7 @@ func Reduce(conf Config, newBugreportName string, fullReduction bool) error {
 		statement, _ := translator.GenerateStatement(seed, schema, rootClause, conf.Implementation, 0)
 		query = append(query, statement)
 
-		lastResult, err = RunQuery(conf, statement)
+		lastResult, err = RunQuery(conf, statement, schema.ReturnOrder, schema.ReturnSortColumns)
 		if err != nil {
 			return err
 		}
@@ -131,7 +135,7 @@ func Reduce(conf Config, newBugreportName string, fullReduction bool) error {
 			}
 		}
 	}
-	WriteBugReport(conf, lastResult, query, "", seed, newBugreportName)
+	WriteBugReport(conf, lastResult, query, reducedClauses, "", seed, newBugreportName)
 
 	return nil
 }
@@ -150,7 +154,7 @@ func getQueryResults(conf Config, rootClauses []*helperclauses.ClauseCapturer) (
 
 	seed := seed.GetPregeneratedByteString(conf.ByteString)
 
-	if err := conf.DB.Reset(conf.DBOptions); err != nil {
+	if err := resetDB(conf); err != nil {
 		return nil, nil, err
 	}
 
@@ -158,7 +162,7 @@ func getQueryResults(conf Config, rootClauses []*helperclauses.ClauseCapturer) (
 	for statementCount := 0; statementCount < len(rootClauses); statementCount++ {
 		logrus.Debugf("Running statement #%d", statementCount)
 		if _, ok := conf.Strategy.(*equivalencetransformation.Strategy); ok && statementCount == len(rootClauses)/2 {
-			if err := conf.DB.Reset(conf.DBOptions); err != nil {
+			if err := resetDB(conf); err != nil {
 				return nil, nil, err
 			}
 		}
@@ -172,7 +176,7 @@ func getQueryResults(conf Config, rootClauses []*helperclauses.ClauseCapturer) (
 
 		statements = append(statements, statement)
 
-		result, err := RunQuery(conf, statement)
+		result, err := RunQuery(conf, statement, schema.ReturnOrder, schema.ReturnSortColumns)
 		if err != nil {
 			return nil, nil, err
 		}
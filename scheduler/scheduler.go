@@ -10,6 +10,7 @@ The scheduler is invoked through its [Run] function and can be configured using
 package scheduler
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -22,9 +23,12 @@ import (
 	"time"
 
 	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/opencypher/config"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
 	"github.com/Anon10214/dinkel/scheduler/strategy"
 	"github.com/Anon10214/dinkel/seed"
 	"github.com/Anon10214/dinkel/translator"
+	"github.com/Anon10214/dinkel/translator/helperclauses"
 	"github.com/Masterminds/sprig/v3"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/sirupsen/logrus"
@@ -41,6 +45,9 @@ type Config struct {
 	Strategy strategy.Strategy
 	// The given byte string to use instead of generating a new one
 	ByteString []byte
+	// The root clauses of the statements to run instead of generating new ones, e.g. loaded from a bug report.
+	// Queries consist of exactly these statements, the byte string only gets used by the strategy.
+	AST []*helperclauses.ClauseCapturer
 	// The seed to initialize the RNG to if no byte-string is given
 	InitialSeed int64
 	// How many nodes can be generated in the AST before aborting generation
@@ -57,6 +64,12 @@ type Config struct {
 	DisableKeybinds bool
 	// How many times to execute a fuzzing run by generating a query, -1 if unlimited
 	QueryLimit int
+	// If the DB's results get compared with a float tolerance, allowing the generation of float expressions prone to inaccuracies.
+	FloatTolerance bool
+	// If returns ordered by all returned elements get generated, allowing their rows to be compared in order.
+	OrderedReturns bool
+	// If DBs supporting snapshots should be restored to their snapshot before each query instead of being reset.
+	UseSnapshots bool
 	// The target DBMS. This only gets used for creating bug reports.
 	TargetDB string
 	// The target fuzzing strategy. This only gets used for creating bug reports.
@@ -66,6 +79,8 @@ type Config struct {
 	ErrorMessageRegex *dbms.ErrorMessageRegex
 	// BugReportTemplate holds the template used to create the bugreport when a bug is found.
 	BugReportTemplate *template.Template
+	// LogWatcher tails the DB's server log for bugs the DB only logs, nil if the target has no log source.
+	LogWatcher *dbms.LogWatcher
 }
 
 // Stats of a fuzzing run
@@ -79,9 +94,14 @@ type fuzzingStats struct {
 
 // Run runs the fuzzer with the given config
 func Run(conf Config) error {
+	config.SetFloatTolerance(conf.FloatTolerance)
+	config.SetOrderedReturns(conf.OrderedReturns)
 	if ok, err := ConnectToDB(conf); !ok {
 		return errors.Join(errors.New("failed to connect to database"), err)
 	}
+	if conf.LogWatcher != nil {
+		defer conf.LogWatcher.Close()
+	}
 
 	stats := fuzzingStats{
 		timestampStarted: time.Now(),
@@ -100,12 +120,14 @@ func Run(conf Config) error {
 			curSeed = seed.GetRandomByteString()
 		}
 
-		if err := conf.DB.Reset(conf.DBOptions); err != nil {
+		if err := resetDB(conf); err != nil {
 			return err
 		}
 
 		// The generated query
 		var query []string
+		// Snapshots of the generated statements, used for capturing the query's AST
+		var snapshots []statementSnapshot
 
 		conf.Strategy.Reset()
 
@@ -116,16 +138,19 @@ func Run(conf Config) error {
 				return err
 			}
 
-			rootClause := conf.Strategy.GetRootClause(conf.Implementation, schema, curSeed)
+			rootClause := getRootClause(conf, schema, curSeed, statementCount)
+			snapshot := takeSnapshot(rootClause, schema, curSeed)
 			statement, err := translator.GenerateStatement(curSeed, schema, rootClause, conf.Implementation, conf.MaxASTNodes)
 			if err != nil {
 				logrus.Warnf("Generation failed, max AST nodes reached. Continuing with new query")
 				break
 			}
 			query = append(query, statement)
+			snapshot.statement = statement
+			snapshots = append(snapshots, snapshot)
 			logrus.Debugf("Generated statement #%d:\n%s", statementCount, statement)
 
-			res, err := RunQuery(conf, statement)
+			res, err := RunQuery(conf, statement, schema.ReturnOrder, schema.ReturnSortColumns)
 			if err != nil {
 				return errors.Join(fmt.Errorf("couldn't run query %s", statement), err)
 			}
@@ -140,8 +165,12 @@ func Run(conf Config) error {
 			}
 
 			if (res.Type == dbms.Bug || res.Type == dbms.Crash) && !conf.SuppressBugreport {
+				ast, err := captureAST(conf, snapshots, curSeed.GetByteString())
+				if err != nil {
+					logrus.Warnf("Failed to capture the AST of the bug-triggering query, the bug report will only hold its byte string - %v", err)
+				}
 				query = conf.Strategy.PrepareQueryForBugreport(query)
-				GenerateBugReport(conf, res, query, "", curSeed)
+				GenerateBugReport(conf, res, query, ast, "", curSeed)
 			}
 
 			// Recover DBMS if this wasn't the last query
@@ -155,7 +184,7 @@ func Run(conf Config) error {
 			}
 
 			// Stop further generating query if DB decides it
-			if conf.Strategy.DiscardQuery(res.Type, conf.DB, conf.DBOptions, res, curSeed) {
+			if isLastStatement(conf, statementCount, conf.Strategy.DiscardQuery(res.Type, conf.DB, conf.DBOptions, res, curSeed)) {
 				break
 			}
 		}
@@ -168,28 +197,61 @@ func Run(conf Config) error {
 	return nil
 }
 
-func RunQuery(conf Config, query string) (dbms.QueryResult, error) {
-	// Timeout the query manually after double the specified timeout
+// How many timeouts RunQuery waits for a driver to return from a cancelled query it failed to abort,
+// before giving up on reestablishing the database connection.
+const abandonedQueryGracePeriod = 10
+
+// RunQuery runs the query against the target and evaluates its result using the strategy.
+//
+// The passed order and amount of sort columns state how the query's rows are ordered, as determined when generating it.
+func RunQuery(conf Config, query string, order schema.RowOrder, sortColumns int) (dbms.QueryResult, error) {
+	// Cancel the query after double the specified timeout
 	// Ensures queries terminate even if the driver of GDBMS have a bug causing
 	// them to run infinitely despite a specified timeout.
-	timeoutChan := time.After(2 * conf.DBOptions.Timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*conf.DBOptions.Timeout)
+	defer cancel()
+
+	// Only lines logged while running the query should be attributed to it
+	if conf.LogWatcher != nil {
+		if err := conf.LogWatcher.Skip(); err != nil {
+			logrus.Warnf("Failed to read the server log - %v", err)
+		}
+	}
+
 	resChan := make(chan dbms.QueryResult, 1)
 	go func(c chan dbms.QueryResult) {
-		c <- conf.DB.RunQuery(conf.DBOptions, query)
+		c <- conf.DB.RunQuery(ctx, conf.DBOptions, query)
 	}(resChan)
 
 	var res dbms.QueryResult
-	var forcedTimeOut bool
 	select {
-	case <-timeoutChan:
-		forcedTimeOut = true
 	case res = <-resChan:
-	}
-
-	if forcedTimeOut {
-		// Kill query if driver or GDBMS o not respect given timeout
-		logrus.Warnf("Had to kill query manually after it didn't terminate within double the specified timeout:\n%s", query)
+		res.Order, res.SortColumns = order, sortColumns
+	case <-ctx.Done():
+		logrus.Warnf("Had to cancel query after it didn't terminate within double the specified timeout:\n%s", query)
+		// Give the driver time to kill the query, else the next query may share a connection with it
+		select {
+		case <-resChan:
+		case <-time.After(conf.DBOptions.Timeout):
+			// The driver must never be used concurrently, so the connection can only be reestablished
+			// once the driver returned from the cancelled query
+			logrus.Errorf("Driver didn't abort the cancelled query, waiting up to %s for it to return", abandonedQueryGracePeriod*conf.DBOptions.Timeout)
+			select {
+			case <-resChan:
+			case <-time.After(abandonedQueryGracePeriod * conf.DBOptions.Timeout):
+				return res, errors.New("driver didn't return from the cancelled query, can't reestablish the database connection while it still uses it")
+			}
+			logrus.Info("Driver returned from the cancelled query, reestablishing the database connection")
+			if ok, err := ConnectToDB(conf); !ok {
+				return res, errors.Join(errors.New("couldn't reestablish database connection after cancelling query"), err)
+			}
+		}
 		res.Type = dbms.Timeout
+		// The server may have logged why the query hung, e.g. a failed assertion.
+		// The log has to be scanned now, as the next query skips everything logged before it.
+		if conf.LogWatcher != nil {
+			scanServerLog(conf.LogWatcher, &res)
+		}
 		return res, nil
 	}
 
@@ -200,9 +262,60 @@ func RunQuery(conf Config, query string) (dbms.QueryResult, error) {
 		res.Type = conf.Strategy.GetQueryResultType(conf.DB, conf.DBOptions, res, conf.ErrorMessageRegex)
 	}
 
+	if conf.LogWatcher != nil {
+		scanServerLog(conf.LogWatcher, &res)
+	}
+
 	return res, nil
 }
 
+// scanServerLog upgrades the result to a bug or crash if the server logged one while running the query,
+// attaching the matching log excerpt to the result.
+func scanServerLog(watcher *dbms.LogWatcher, res *dbms.QueryResult) {
+	logType, excerpt, err := watcher.Scan()
+	if err != nil {
+		logrus.Warnf("Failed to read the server log - %v", err)
+		return
+	}
+	if logType == dbms.None {
+		return
+	}
+
+	res.LogExcerpt = excerpt
+	// Never downgrade a crash to a bug
+	if res.Type != dbms.Crash {
+		logrus.Errorf("Server log indicates a %s:\n%s", logType.ToString(), excerpt)
+		res.Type = logType
+	}
+}
+
+// resetDB resets the DB before running a new query.
+//
+// If the config enables snapshots, DBs supporting them are restored to their snapshot instead,
+// which gets taken after resetting them if there is none. Falls back to resetting the DB if restoring it fails.
+func resetDB(conf Config) error {
+	snapshotter, ok := dbms.AsSnapshotter(conf.DB)
+	if !ok || !conf.UseSnapshots {
+		return conf.DB.Reset(conf.DBOptions)
+	}
+
+	err := snapshotter.Restore(conf.DBOptions)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, dbms.ErrNoSnapshot) {
+		logrus.Warnf("Failed to restore the DB's snapshot, resetting it instead - %v", err)
+	}
+
+	if err := conf.DB.Reset(conf.DBOptions); err != nil {
+		return err
+	}
+	if err := snapshotter.Snapshot(conf.DBOptions); err != nil {
+		logrus.Warnf("Failed to snapshot the DB, it will be reset before the next query - %v", err)
+	}
+	return nil
+}
+
 // Returns true if a connection to the DB has been established, else false.
 // Uses options from the passed config to adjust behavior.
 func ConnectToDB(conf Config) (bool, error) {
@@ -227,8 +340,8 @@ func ConnectToDB(conf Config) (bool, error) {
 }
 
 // Writes the bug report to the default location
-func GenerateBugReport(conf Config, res dbms.QueryResult, query []string, offendingCommit string, seed *seed.Seed) {
-	WriteBugReport(conf, res, query, offendingCommit, seed, fmt.Sprintf("report_%d", time.Now().UnixMicro()))
+func GenerateBugReport(conf Config, res dbms.QueryResult, query []string, ast []*helperclauses.ClauseCapturer, offendingCommit string, seed *seed.Seed) {
+	WriteBugReport(conf, res, query, ast, offendingCommit, seed, fmt.Sprintf("report_%d", time.Now().UnixMicro()))
 }
 
 // BugreportMarkdownData is the data passed to the bugreport template when writing a bugreport's markdown content
@@ -263,7 +376,10 @@ func checkBugReportDirectory(conf Config) {
 
 // WriteBugReport creates a bugreport with the passed name in the directory pointed to by the BugReportsDirectory specified in the passed [Config].
 // If the current target has a template for bug report markdowns, this function writes the markdown as well.
-func WriteBugReport(conf Config, res dbms.QueryResult, query []string, offendingCommit string, seed *seed.Seed, reportName string) {
+//
+// The passed AST holds the root clauses of the statements that were run, it is stored in the bugreport if it isn't nil.
+// Unlike the byte string, it still regenerates the same query after the generation of queries changes.
+func WriteBugReport(conf Config, res dbms.QueryResult, query []string, ast []*helperclauses.ClauseCapturer, offendingCommit string, seed *seed.Seed, reportName string) {
 	checkBugReportDirectory(conf)
 
 	type bugReport struct {
@@ -272,6 +388,7 @@ func WriteBugReport(conf Config, res dbms.QueryResult, query []string, offending
 		TimeFound       string
 		OffendingCommit string
 		ByteString      string
+		AST             string
 		ReportStatus    string
 		Query           []string
 	}
@@ -289,6 +406,14 @@ func WriteBugReport(conf Config, res dbms.QueryResult, query []string, offending
 		newBugReport.Query[i] = fmt.Sprintf("%q", el)
 	}
 
+	if ast != nil {
+		encodedAST, err := encodeAST(ast)
+		if err != nil {
+			logrus.Warnf("Failed to encode the AST of the bug-triggering query, the bug report will only hold its byte string - %v", err)
+		}
+		newBugReport.AST = encodedAST
+	}
+
 	templateString := `target: {{ .Target }}
 strategy: {{ .Strategy }}
 # When dinkel found this bug
@@ -299,6 +424,10 @@ offending_commit: "{{ .OffendingCommit }}"
 report_status: {{ .ReportStatus }}
 # The byte string that generates a query triggering this bug
 byte_string: "{{ .ByteString }}"
+{{- if .AST }}
+# The AST of the statements that were run, used instead of the byte string when regenerating the query
+ast: "{{ .AST }}"
+{{- end }}
 query: {{ range $index, $element := .Query }}
   - {{$element}}{{end}}
 `
@@ -0,0 +1,329 @@
+package scheduler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/inmemory"
+	"github.com/Anon10214/dinkel/models/mock"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/scheduler/strategy"
+	"github.com/Anon10214/dinkel/seed"
+	"github.com/Anon10214/dinkel/translator"
+	"github.com/Anon10214/dinkel/translator/helperclauses"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+// testConfig returns a config fuzzing the passed driver, writing bug reports into a temporary directory.
+func testConfig(t *testing.T, db *mock.ScriptedDriver) Config {
+	return Config{
+		DB:                  db,
+		DBOptions:           dbms.DBOptions{Timeout: 50 * time.Millisecond},
+		Implementation:      mock.Implementation{},
+		Strategy:            strategy.None.ToStrategy(),
+		TargetStrategy:      strategy.None,
+		QueryLimit:          1,
+		DisableKeybinds:     true,
+		BugReportsDirectory: t.TempDir(),
+		BugReportTemplate:   template.Must(template.New("").Parse("{{ .LastStatement }}")),
+		ErrorMessageRegex:   &dbms.ErrorMessageRegex{Ignored: regexp.MustCompile("^ignored")},
+	}
+}
+
+// bugReports returns the names of the bug reports written into the config's directory.
+func bugReports(t *testing.T, conf Config) []string {
+	reports, err := filepath.Glob(filepath.Join(conf.BugReportsDirectory, "*.yml"))
+	assert.NoError(t, err)
+	return reports
+}
+
+func TestConnectToDB(t *testing.T) {
+	db := &mock.ScriptedDriver{InitErrors: []error{errors.New("refused")}, ConnectivityFailures: 1}
+	conf := testConfig(t, db)
+	conf.DBConnectionRetries = 2
+
+	ok, err := ConnectToDB(conf)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, db.CallCount("Init"), "should retry both failed inits and failed connectivity checks")
+
+	initErr := errors.New("refused")
+	db = &mock.ScriptedDriver{InitErrors: []error{initErr, initErr, initErr}}
+	conf = testConfig(t, db)
+	conf.DBConnectionRetries = 1
+
+	ok, err = ConnectToDB(conf)
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, initErr)
+	assert.Equal(t, 2, db.CallCount("Init"))
+}
+
+func TestRunQuery(t *testing.T) {
+	t.Run("Results are classified by the strategy", func(t *testing.T) {
+		db := &mock.ScriptedDriver{Steps: []mock.Step{
+			{},
+			{Result: dbms.QueryResult{ProducedError: errors.New("ignored error")}},
+			{Result: dbms.QueryResult{ProducedError: errors.New("unexpected error")}},
+			{FailConnectivity: 1},
+		}}
+		conf := testConfig(t, db)
+
+		for _, expected := range []dbms.QueryResultType{dbms.Valid, dbms.Invalid, dbms.Bug, dbms.Crash} {
+			res, err := RunQuery(conf, "RETURN 1", schema.Unordered, 0)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, res.Type)
+		}
+	})
+
+	t.Run("Queries are cancelled after twice the timeout", func(t *testing.T) {
+		db := &mock.ScriptedDriver{Steps: []mock.Step{{Delay: time.Minute}}}
+		conf := testConfig(t, db)
+
+		res, err := RunQuery(conf, "RETURN 1", schema.Unordered, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, dbms.Timeout, res.Type)
+		assert.Zero(t, db.CallCount("Init"), "drivers aborting the query shouldn't be reconnected")
+	})
+
+	t.Run("Cancelled queries are reported if the server logged a bug", func(t *testing.T) {
+		logFile := filepath.Join(t.TempDir(), "server.log")
+		assert.NoError(t, os.WriteFile(logFile, nil, 0o644))
+		db := &mock.ScriptedDriver{Steps: []mock.Step{{Delay: time.Minute}}}
+		conf := testConfig(t, db)
+		conf.LogWatcher = &dbms.LogWatcher{File: logFile, BugPattern: regexp.MustCompile("Assertion .* failed")}
+		defer conf.LogWatcher.Close()
+
+		// Log the bug while the query hangs
+		time.AfterFunc(conf.DBOptions.Timeout/2, func() {
+			assert.NoError(t, os.WriteFile(logFile, []byte("Assertion `x' failed\n"), 0o644))
+		})
+		res, err := RunQuery(conf, "RETURN 1", schema.Unordered, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, dbms.Bug, res.Type)
+		assert.Equal(t, "Assertion `x' failed", res.LogExcerpt)
+	})
+
+	t.Run("Drivers not aborting queries are reconnected once they return", func(t *testing.T) {
+		db := &mock.ScriptedDriver{Steps: []mock.Step{{Delay: 300 * time.Millisecond, IgnoreCancellation: true}}}
+		conf := testConfig(t, db)
+
+		start := time.Now()
+		res, err := RunQuery(conf, "RETURN 1", schema.Unordered, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, dbms.Timeout, res.Type)
+		assert.Equal(t, 1, db.CallCount("Init"))
+		assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond, "the driver shouldn't be reinitialised while it still runs the query")
+
+		db = &mock.ScriptedDriver{
+			Steps:      []mock.Step{{Delay: 300 * time.Millisecond, IgnoreCancellation: true}},
+			InitErrors: []error{errors.New("refused")},
+		}
+		conf = testConfig(t, db)
+
+		_, err = RunQuery(conf, "RETURN 1", schema.Unordered, 0)
+		assert.Error(t, err, "should fail if the connection can't be reestablished")
+	})
+
+	t.Run("Drivers never returning from queries aren't reinitialised", func(t *testing.T) {
+		db := &mock.ScriptedDriver{Steps: []mock.Step{{Delay: time.Minute, IgnoreCancellation: true}}}
+		conf := testConfig(t, db)
+
+		_, err := RunQuery(conf, "RETURN 1", schema.Unordered, 0)
+		assert.Error(t, err)
+		assert.Zero(t, db.CallCount("Init"))
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Run("Bugs and crashes are reported", func(t *testing.T) {
+		db := &mock.ScriptedDriver{Steps: []mock.Step{
+			{Result: dbms.QueryResult{ProducedError: errors.New("ignored error")}},
+			{Result: dbms.QueryResult{ProducedError: errors.New("unexpected error")}},
+			{FailConnectivity: 1},
+			{},
+		}}
+		conf := testConfig(t, db)
+		conf.QueryLimit = 4
+
+		assert.NoError(t, Run(conf))
+		assert.Len(t, db.Queries(), 4)
+		assert.Equal(t, 4, db.CallCount("Reset"))
+		assert.Equal(t, 2, db.CallCount("Init"), "should reconnect after the crash")
+		assert.Len(t, bugReports(t, conf), 2)
+
+		markdowns, err := filepath.Glob(filepath.Join(conf.BugReportsDirectory, "*.md"))
+		assert.NoError(t, err)
+		if assert.Len(t, markdowns, 2) {
+			content, err := os.ReadFile(markdowns[0])
+			assert.NoError(t, err)
+			assert.Contains(t, db.Queries(), string(content), "the markdown should hold the last statement")
+		}
+	})
+
+	t.Run("Reports can be suppressed", func(t *testing.T) {
+		db := &mock.ScriptedDriver{Default: mock.Step{Result: dbms.QueryResult{ProducedError: errors.New("unexpected error")}}}
+		conf := testConfig(t, db)
+		conf.QueryLimit = 3
+		conf.SuppressBugreport = true
+
+		assert.NoError(t, Run(conf))
+		assert.Len(t, db.Queries(), 3)
+		assert.Empty(t, bugReports(t, conf))
+	})
+
+	t.Run("Queries are generated further unless discarded", func(t *testing.T) {
+		db := &mock.ScriptedDriver{Steps: []mock.Step{{Continue: true}, {Continue: true}, {}}}
+		conf := testConfig(t, db)
+
+		assert.NoError(t, Run(conf))
+		assert.Len(t, db.Queries(), 3)
+		assert.Equal(t, 1, db.CallCount("Reset"))
+	})
+
+	t.Run("Timeouts end the query", func(t *testing.T) {
+		db := &mock.ScriptedDriver{Steps: []mock.Step{{Delay: time.Minute, Continue: true}}}
+		conf := testConfig(t, db)
+
+		assert.NoError(t, Run(conf))
+		assert.Len(t, db.Queries(), 1)
+		assert.Empty(t, bugReports(t, conf))
+	})
+
+	t.Run("Failing DBs abort the run", func(t *testing.T) {
+		conf := testConfig(t, &mock.ScriptedDriver{ConnectivityFailures: 1})
+		assert.Error(t, Run(conf), "should fail if the DB can't be connected to")
+
+		conf = testConfig(t, &mock.ScriptedDriver{ResetErrors: []error{errors.New("reset failed")}})
+		assert.Error(t, Run(conf))
+
+		conf = testConfig(t, &mock.ScriptedDriver{SchemaError: errors.New("schema unavailable")})
+		assert.Error(t, Run(conf))
+
+		db := &mock.ScriptedDriver{Steps: []mock.Step{{FailConnectivity: 2}}}
+		conf = testConfig(t, db)
+		conf.QueryLimit = 2
+		assert.Error(t, Run(conf), "should fail if the DB can't be recovered after a crash")
+	})
+}
+
+// Every strategy should be able to fuzz the in-memory engine without finding bugs in it
+func TestRun_InMemory(t *testing.T) {
+	for _, fuzzingStrategy := range []strategy.FuzzingStrategy{strategy.None, strategy.EquivalenceTransformation, strategy.PredicatePartitioning, strategy.ProfileCardinality} {
+		t.Run(fuzzingStrategy.ToString(), func(t *testing.T) {
+			conf := Config{
+				DB:                  &inmemory.Driver{},
+				DBOptions:           dbms.DBOptions{Timeout: 10 * time.Second},
+				Implementation:      inmemory.Implementation{},
+				Strategy:            fuzzingStrategy.ToStrategy(),
+				TargetStrategy:      fuzzingStrategy,
+				QueryLimit:          15,
+				MaxASTNodes:         500,
+				DisableKeybinds:     true,
+				BugReportsDirectory: t.TempDir(),
+				BugReportTemplate:   template.Must(template.New("").Parse("{{ .LastStatement }}")),
+				// Same as the targets config, syntax and semantic errors are only ignored for queries the generator doesn't avoid yet
+				ErrorMessageRegex: &dbms.ErrorMessageRegex{
+					Ignored: regexp.MustCompile(`^SemanticError: (variable .* can't be accessed after DISTINCT or an aggregation|` +
+						`aggregating functions can't be used inside of expressions iterating over lists|` +
+						`the aggregating expression uses .*, which isn't a grouping key)$`),
+					IgnoredCodes: []string{inmemory.TypeError, inmemory.ArithmeticError, inmemory.ArgumentError, inmemory.Unsupported, inmemory.ResourceError},
+				},
+			}
+
+			assert.NoError(t, Run(conf))
+			assert.Empty(t, bugReports(t, conf))
+		})
+	}
+}
+
+// readAST reads the AST stored in the passed bug report.
+func readAST(t *testing.T, report string) []*helperclauses.ClauseCapturer {
+	data, err := os.ReadFile(report)
+	assert.NoError(t, err)
+	var bugReport struct {
+		AST string `yaml:"ast"`
+	}
+	assert.NoError(t, yaml.Unmarshal(data, &bugReport))
+	compressed, err := base64.StdEncoding.DecodeString(bugReport.AST)
+	assert.NoError(t, err)
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	encoded, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	ast, err := helperclauses.UnmarshalAST(encoded)
+	assert.NoError(t, err)
+	return ast
+}
+
+func TestRun_AST(t *testing.T) {
+	steps := []mock.Step{{Continue: true}, {Continue: true}, {Result: dbms.QueryResult{ProducedError: errors.New("unexpected error")}}}
+	db := &mock.ScriptedDriver{Steps: steps}
+	conf := testConfig(t, db)
+	assert.NoError(t, Run(conf))
+
+	reports := bugReports(t, conf)
+	if !assert.Len(t, reports, 1) {
+		return
+	}
+	conf.AST = readAST(t, reports[0])
+	assert.Len(t, conf.AST, 3, "the AST should hold all statements run")
+
+	// Regenerate the query from its AST
+	regeneratedDB := &mock.ScriptedDriver{Steps: steps}
+	conf.DB = regeneratedDB
+	conf.BugReportsDirectory = t.TempDir()
+	assert.NoError(t, Run(conf))
+	assert.Equal(t, db.Queries(), regeneratedDB.Queries())
+
+	reports = bugReports(t, conf)
+	if assert.Len(t, reports, 1) {
+		assert.Equal(t, conf.AST, readAST(t, reports[0]), "the regenerated query's report should hold the same AST")
+	}
+
+	// Exactly the AST's statements get run, even if the strategy would generate further statements
+	regeneratedDB = &mock.ScriptedDriver{Default: mock.Step{Continue: true}}
+	conf.DB = regeneratedDB
+	conf.SuppressBugreport = true
+	assert.NoError(t, Run(conf))
+	assert.Equal(t, db.Queries(), regeneratedDB.Queries())
+}
+
+// Regenerating snapshotted statements should result in the same statements for every strategy
+func TestCaptureAST(t *testing.T) {
+	for _, fuzzingStrategy := range []strategy.FuzzingStrategy{strategy.None, strategy.EquivalenceTransformation, strategy.PredicatePartitioning, strategy.ProfileCardinality} {
+		conf := testConfig(t, &mock.ScriptedDriver{})
+		conf.Strategy = fuzzingStrategy.ToStrategy()
+		for i := int64(0); i < 10; i++ {
+			curSeed := seed.GetRandomByteStringWithSource(*rand.New(rand.NewSource(i)))
+			conf.Strategy.Reset()
+			var snapshots []statementSnapshot
+			for j := 0; j < 3; j++ {
+				s, err := conf.DB.GetSchema(conf.DBOptions)
+				assert.NoError(t, err)
+				rootClause := getRootClause(conf, s, curSeed, j)
+				snapshot := takeSnapshot(rootClause, s, curSeed)
+				snapshot.statement, _ = translator.GenerateStatement(curSeed, s, rootClause, conf.Implementation, 0)
+				snapshots = append(snapshots, snapshot)
+			}
+
+			ast, err := captureAST(conf, snapshots, curSeed.GetByteString())
+			if assert.NoError(t, err, "strategy %s, seed %d", fuzzingStrategy.ToString(), i) {
+				assert.Len(t, ast, 3)
+			}
+		}
+	}
+}
@@ -194,9 +194,13 @@ func equivalenceTransform(impl translator.Implementation, clause *helperclauses.
 // Represents a transformed clause.
 // Which clause will be generated can be changed by setting useTransformed.
 type TransformedClause struct {
-	UseTransformed    bool
-	origClause        *helperclauses.ClauseCapturer
-	transformedClause *helperclauses.ClauseCapturer
+	UseTransformed    bool                          `ast:"useTransformed"`
+	origClause        *helperclauses.ClauseCapturer `ast:"origClause"`
+	transformedClause *helperclauses.ClauseCapturer `ast:"transformedClause"`
+}
+
+func init() {
+	helperclauses.RegisterClauses(&TransformedClause{})
 }
 
 func (c TransformedClause) getSelectedClause() *helperclauses.ClauseCapturer {
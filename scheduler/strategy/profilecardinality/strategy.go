@@ -0,0 +1,128 @@
+package profilecardinality
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/Anon10214/dinkel/models/opencypher/clauses"
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/scheduler/strategy/none"
+	"github.com/Anon10214/dinkel/seed"
+	"github.com/Anon10214/dinkel/translator"
+	"github.com/Anon10214/dinkel/translator/helperclauses"
+	"github.com/sirupsen/logrus"
+)
+
+// Strategy generates a graph using write clauses and then profiles a read query on it,
+// reporting a bug if the row counts of the profiled plan's operators are inconsistent.
+type Strategy struct {
+	generatedSchema        bool // If done generating the schema to be queried by the profiled query
+	generatedProfiledQuery bool // If done generating the profiled read query. Query can be discarded once this is true
+
+	// Reduction works the same as without a strategy, only the validation differs
+	reducer none.Strategy
+}
+
+// Reset the strategy to generate a new graph for the next query.
+func (s *Strategy) Reset() {
+	*s = Strategy{}
+}
+
+// GetRootClause returns write clauses until the schema is done being generated,
+// after which the root clause of the profiled read query gets returned.
+func (s *Strategy) GetRootClause(impl translator.Implementation, sc *schema.Schema, seed *seed.Seed) translator.Clause {
+	helperclauses.SetImplementation(impl)
+	if !s.generatedSchema {
+		if out := seed.GetByte(); out%5 == 0 {
+			s.generatedSchema = true
+		}
+		if out := seed.GetByte(); out%5 == 0 {
+			return &clauses.Index{}
+		}
+		return &clauses.WriteClause{}
+	}
+	s.generatedProfiledQuery = true
+
+	// Only the rows of read queries are determined by the graph generated beforehand
+	sc.DisallowWriteClauses = true
+	return helperclauses.CreateAssembler(dbms.ProfilePrefix+"%s", &clauses.ReadClause{})
+}
+
+// GetQueryResultType returns [dbms.Bug] if the profiled plan violates a cardinality invariant.
+func (s *Strategy) GetQueryResultType(db dbms.DB, dbOpts dbms.DBOptions, res dbms.QueryResult, errorMessageRegex *dbms.ErrorMessageRegex) dbms.QueryResultType {
+	if resType := db.GetQueryResultType(res, errorMessageRegex); resType != dbms.Valid {
+		return resType
+	}
+
+	if res.Profile == nil {
+		return dbms.Valid
+	}
+
+	if err := checkCardinalities(res.Profile); err != nil {
+		logrus.Warnf("Profiled plan violates cardinality invariant - %v", err)
+		return dbms.Bug
+	}
+	return dbms.Valid
+}
+
+// DiscardQuery discards the query once the profiled query got generated, as every query only profiles one read query.
+func (s *Strategy) DiscardQuery(resultType dbms.QueryResultType, db dbms.DB, dbOpts dbms.DBOptions, res dbms.QueryResult, seed *seed.Seed) bool {
+	return db.DiscardQuery(res, seed) || s.generatedProfiledQuery
+}
+
+// checkCardinalities verifies that the rows produced by the operators of a profiled plan are consistent.
+//
+// The root operator has to produce exactly as many rows as the profiled query returned
+// and a filter can never produce more rows than its input.
+//
+// Returns an error describing the first violated invariant, or nil if none got violated.
+func checkCardinalities(profile *dbms.ProfiledOperator) error {
+	if profile.Rows != profile.ReturnedRows {
+		return fmt.Errorf("root operator %s produced %d rows, but %d rows were returned", profile.Name, profile.Rows, profile.ReturnedRows)
+	}
+	return checkFilters(profile)
+}
+
+// checkFilters ensures that no filter in the subtree with the passed operator as root produces more rows than its input.
+func checkFilters(op *dbms.ProfiledOperator) error {
+	if op.Name == "Filter" && len(op.Children) == 1 && op.Rows > op.Children[0].Rows {
+		return fmt.Errorf("filter produced %d rows from %d input rows", op.Rows, op.Children[0].Rows)
+	}
+	for _, child := range op.Children {
+		if err := checkFilters(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Strategy) ReduceStep(ctx context.Context, rootClauses []*helperclauses.ClauseCapturer) (context.Context, []*helperclauses.ClauseCapturer, bool) {
+	return s.reducer.ReduceStep(ctx, rootClauses)
+}
+
+// ValidateReductionResult returns true if the last statement's profiled plan still violates a cardinality invariant.
+func (s *Strategy) ValidateReductionResult(db dbms.DB, orig []dbms.QueryResult, new []dbms.QueryResult) bool {
+	last := new[len(new)-1]
+	if last.ProducedError != nil || last.Profile == nil {
+		return false
+	}
+	return checkCardinalities(last.Profile) != nil
+}
+
+func (s *Strategy) PrepareQueryForBugreport(query []string) []string {
+	return query
+}
+
+func (s *Strategy) RerunQuery(statements []string, db dbms.DB, dbOpts dbms.DBOptions, runNext func() (dbms.QueryResult, error)) (dbms.QueryResultType, error) {
+	for range statements {
+		res, err := runNext()
+		if err != nil {
+			return dbms.Invalid, err
+		}
+		if res.Type != dbms.Valid {
+			return res.Type, nil
+		}
+	}
+	return dbms.Valid, nil
+}
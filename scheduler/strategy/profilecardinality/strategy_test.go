@@ -0,0 +1,37 @@
+package profilecardinality
+
+import (
+	"testing"
+
+	"github.com/Anon10214/dinkel/dbms"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCardinalities(t *testing.T) {
+	scan := &dbms.ProfiledOperator{Name: "AllNodesScan", Rows: 3}
+	filter := &dbms.ProfiledOperator{Name: "Filter", Rows: 2, Children: []*dbms.ProfiledOperator{scan}}
+	root := &dbms.ProfiledOperator{Name: "ProduceResults", Rows: 2, Children: []*dbms.ProfiledOperator{filter}, ReturnedRows: 2}
+	assert.NoError(t, checkCardinalities(root))
+
+	root.ReturnedRows = 1
+	assert.Error(t, checkCardinalities(root), "root operator producing more rows than returned")
+
+	root.ReturnedRows = 3
+	assert.Error(t, checkCardinalities(root), "root operator producing fewer rows than returned")
+
+	root.ReturnedRows = 2
+	filter.Rows = 4
+	assert.Error(t, checkCardinalities(root), "filter producing more rows than its input")
+
+	// Filters with multiple inputs aren't checked
+	filter.Children = append(filter.Children, &dbms.ProfiledOperator{Name: "Argument", Rows: 1})
+	assert.NoError(t, checkCardinalities(root))
+}
+
+func TestCheckCardinalities_Nested(t *testing.T) {
+	inner := &dbms.ProfiledOperator{Name: "Filter", Rows: 5, Children: []*dbms.ProfiledOperator{{Name: "NodeByLabelScan", Rows: 1}}}
+	root := &dbms.ProfiledOperator{Name: "Results", Rows: 0, Children: []*dbms.ProfiledOperator{
+		{Name: "Limit", Rows: 0, Children: []*dbms.ProfiledOperator{inner}},
+	}}
+	assert.Error(t, checkCardinalities(root), "nested filters should be checked")
+}
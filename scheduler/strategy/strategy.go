@@ -11,6 +11,7 @@ import (
 	"github.com/Anon10214/dinkel/scheduler/strategy/equivalencetransformation"
 	"github.com/Anon10214/dinkel/scheduler/strategy/none"
 	"github.com/Anon10214/dinkel/scheduler/strategy/predicatepartitioning"
+	"github.com/Anon10214/dinkel/scheduler/strategy/profilecardinality"
 	"github.com/Anon10214/dinkel/seed"
 	"github.com/Anon10214/dinkel/translator"
 	"github.com/Anon10214/dinkel/translator/helperclauses"
@@ -42,6 +43,10 @@ const (
 	//	 UNION ALL
 	//	MATCH (..) WHERE x IS NULL RETURN ..
 	PredicatePartitioning
+	// ProfileCardinality first generates write clauses, then runs a read query under PROFILE.
+	// The row counts of the profiled plan's operators have to be consistent with each other
+	// and with the amount of rows returned.
+	ProfileCardinality
 )
 
 // ToStrategy returns the concrete [Strategy] associated with a [FuzzingStrategy].
@@ -53,6 +58,8 @@ func (s FuzzingStrategy) ToStrategy() Strategy {
 		return &equivalencetransformation.Strategy{}
 	case PredicatePartitioning:
 		return &predicatepartitioning.Strategy{}
+	case ProfileCardinality:
+		return &profilecardinality.Strategy{}
 	}
 	logrus.Panicf("Invalid Fuzzing strategy encountered: %d", s)
 	return nil
@@ -67,6 +74,8 @@ func (s FuzzingStrategy) ToString() string {
 		return "EQUIVALENCE TRANSFORM"
 	case PredicatePartitioning:
 		return "PREDICATE PARTITIONING"
+	case ProfileCardinality:
+		return "PROFILE CARDINALITY"
 	}
 	return "INVALID FUZZING STRATEGY"
 }
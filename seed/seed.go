@@ -28,10 +28,19 @@ func (s *Seed) GetByteString() []byte {
 	return s.underlyingSeedSource.GetByteString()
 }
 
+// Position returns how many bytes the seed has returned so far.
+//
+// Passing the byte string from this position onwards to [GetPregeneratedByteString]
+// results in a seed returning the same bytes as this seed does from now on.
+func (s *Seed) Position() int {
+	return s.underlyingSeedSource.Position()
+}
+
 // A Source is used by a seed to get bytes
 type source interface {
 	GetByteString() []byte
 	GetByte() byte
+	Position() int
 }
 
 // wrapSeedSource takes in a source and returns a seed sourcing it
@@ -55,6 +64,10 @@ func (r randomByteString) GetByteString() []byte {
 	return r.generatedString
 }
 
+func (r randomByteString) Position() int {
+	return len(r.generatedString)
+}
+
 // GetRandomByteString returns a seed returning randomly generated bytes.
 func GetRandomByteString() *Seed {
 	return wrapSeedSource(&randomByteString{
@@ -101,6 +114,11 @@ func (p pregeneratedByteString) GetByteString() []byte {
 	return append(p.generatedString, p.overflow.GetByteString()...)
 }
 
+func (p pregeneratedByteString) Position() int {
+	// The index exceeds the byte string by one once bytes get generated randomly
+	return min(p.index, len(p.generatedString)) + p.overflow.Position()
+}
+
 // GetPregeneratedByteString returns a seed which returns the bytes passed in the slice of bytes in order.
 // If the passed slice is too short, it starts randomly generating future bytes after exhausting the slice of bytes.
 func GetPregeneratedByteString(byteString []byte) *Seed {
@@ -33,3 +33,27 @@ func TestPregeneratedByteString_RunningOut(t *testing.T) {
 
 	assert.Equal(t, byteString, seed.GetByteString(), "Pregenerated Byte String underlying byte string mismatch")
 }
+
+// Ensure that the byte string starting at a seed's position results in the same bytes
+func TestPosition(t *testing.T) {
+	for _, s := range []*seed.Seed{
+		seed.GetRandomByteString(),
+		seed.GetPregeneratedByteString([]byte{1, 2, 3}),
+	} {
+		s.GetByte()
+		s.GetByte()
+		position := s.Position()
+		assert.Equal(t, 2, position)
+
+		var expected []byte
+		for i := 0; i < 5; i++ {
+			expected = append(expected, s.GetByte())
+		}
+		assert.Equal(t, 7, s.Position())
+
+		replayed := seed.GetPregeneratedByteString(s.GetByteString()[position:])
+		for _, b := range expected {
+			assert.Equal(t, b, replayed.GetByte())
+		}
+	}
+}
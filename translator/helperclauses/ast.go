@@ -0,0 +1,740 @@
+package helperclauses
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/Anon10214/dinkel/models/opencypher/schema"
+	"github.com/Anon10214/dinkel/translator"
+)
+
+// The version of the AST encoding, increased on incompatible changes to it,
+// such as adding, removing or renaming a field tagged with `ast` or registering a clause under a different name.
+const astVersion = 3
+
+var (
+	clauseTypesMutex sync.RWMutex
+	clauseTypes      = make(map[string]reflect.Type)
+)
+
+// RegisterClauses registers the types of the passed clauses, allowing ASTs containing them to be unmarshalled.
+//
+// Both the type of a clause and the pointer to it get registered, regardless of which of these gets passed.
+// Packages defining clauses should register all of them in an init function.
+//
+// Clauses opt in to having their state encoded by tagging their fields with `ast:"name"`, storing the field under the name.
+// Fields tagged with `ast:"-"` aren't encoded, and encoding a clause with an untagged field fails.
+// The same applies to the structs held by the clauses, such as the schema and expression configs.
+func RegisterClauses(clauses ...translator.Clause) {
+	clauseTypesMutex.Lock()
+	defer clauseTypesMutex.Unlock()
+	for _, clause := range clauses {
+		t := reflect.TypeOf(clause)
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		clauseTypes[typeName(t)] = t
+		clauseTypes[typeName(reflect.PointerTo(t))] = reflect.PointerTo(t)
+	}
+}
+
+func init() {
+	RegisterClauses(&ClauseCapturer{}, &EmptyClause{}, &Stringer{}, &Assembler{})
+}
+
+// typeName returns the name under which a type gets stored, consisting of its package's import path and its name.
+func typeName(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		return "*" + typeName(t.Elem())
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+// The encoded AST of a query
+type encodedAST struct {
+	Version int `json:"version"`
+	// The schemas captured by the clause capturers, which reference them by their index.
+	// Many clauses don't modify the schema, so storing each distinct schema once keeps the encoding small.
+	Schemas    []any `json:"schemas"`
+	Statements []any `json:"statements"`
+}
+
+// MarshalAST returns the JSON encoding of the passed statements' ASTs, which can be loaded again using [UnmarshalAST].
+//
+// The ASTs have to be fully generated, e.g. by [ClauseCapturer.GenerateAST] or by the translator.
+// The encoding holds every captured clause's type and its fields tagged with `ast`, including unexported ones,
+// as well as the schemas captured during generation.
+// Values referenced multiple times in the ASTs, such as the used names shared by all schemas, remain shared when loaded.
+func MarshalAST(rootClauses []*ClauseCapturer) ([]byte, error) {
+	e := astEncoder{
+		ids:           make(map[pointerKey]int),
+		schemaIndices: make(map[string]int),
+	}
+	ast := encodedAST{Version: astVersion}
+	for i, rootClause := range rootClauses {
+		encoded, err := e.encode(reflect.ValueOf(rootClause))
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("couldn't encode statement #%d - ", i), err)
+		}
+		ast.Statements = append(ast.Statements, encoded)
+	}
+	ast.Schemas = e.schemas
+	return json.Marshal(ast)
+}
+
+// UnmarshalAST loads ASTs encoded by [MarshalAST], returning their root clauses.
+//
+// The returned clause capturers are marked as generated, so translating them results in the same statements as the original ASTs did.
+// Every clause type contained in the ASTs has to be registered using [RegisterClauses].
+// If an implementation was set using [SetImplementation], its drop ins get used when regenerating clauses of the returned ASTs.
+func UnmarshalAST(data []byte) ([]*ClauseCapturer, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// Keep integers precise
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+
+	var ast encodedAST
+	if err := decoder.Decode(&ast); err != nil {
+		return nil, errors.Join(errors.New("invalid AST encoding - "), err)
+	}
+	if ast.Version != astVersion {
+		return nil, fmt.Errorf("unsupported AST encoding version %d, expected version %d", ast.Version, astVersion)
+	}
+
+	d := astDecoder{
+		pointers: make(map[int]reflect.Value),
+		schemas:  ast.Schemas,
+	}
+	if implementation != nil {
+		d.dropIns = implementation.GetDropIns()
+	}
+	var rootClauses []*ClauseCapturer
+	for i, statement := range ast.Statements {
+		var rootClause *ClauseCapturer
+		if err := d.decode(statement, reflect.ValueOf(&rootClause).Elem()); err != nil {
+			return nil, errors.Join(fmt.Errorf("couldn't decode statement #%d - ", i), err)
+		}
+		if rootClause == nil {
+			return nil, fmt.Errorf("statement #%d holds no AST", i)
+		}
+		rootClauses = append(rootClauses, rootClause)
+	}
+	return rootClauses, nil
+}
+
+var clauseCapturerType = reflect.TypeOf(ClauseCapturer{})
+
+// encodedClauseCapturer holds the fields of a [ClauseCapturer] relevant for regenerating it.
+type encodedClauseCapturer struct {
+	Generated      bool `json:"generated"`
+	Regenerated    bool `json:"regenerated"`
+	Schema         any  `json:"schema"`
+	ModifiedSchema any  `json:"modifiedSchema"`
+	Clause         any  `json:"clause"`
+	Subclauses     any  `json:"subclauses"`
+}
+
+// pointerKey identifies the value a pointer points to.
+// The type is needed, as a pointer to a struct has the same address as a pointer to its first field.
+type pointerKey struct {
+	address uintptr
+	t       reflect.Type
+}
+
+type astEncoder struct {
+	// The IDs of already encoded pointers, later occurrences reference them
+	ids map[pointerKey]int
+	// The distinct schemas encoded so far
+	schemas []any
+	// The indices of the encoded schemas, by their JSON encoding
+	schemaIndices map[string]int
+}
+
+// encode returns the passed value's representation, which can be marshalled to JSON.
+//
+// Pointers are encoded as {"id": n, "value": v} when first encountered and as {"ref": n} afterwards,
+// except for pointers to booleans, numbers and strings, which are encoded as the value they point to.
+// Interfaces are encoded as {"type": name, "value": v}, structs as objects holding all of their fields,
+// and maps as objects, requiring their keys to be strings or integers.
+func (e *astEncoder) encode(v reflect.Value) (any, error) {
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		// JSON has no representation for these values
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return strconv.FormatFloat(f, 'g', -1, 64), nil
+		}
+		return f, nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		fallthrough
+	case reflect.Array:
+		elements := make([]any, v.Len())
+		for i := range elements {
+			element, err := e.encode(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = element
+		}
+		return elements, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		entries := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := encodeMapKey(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			if entries[key], err = e.encode(iter.Value()); err != nil {
+				return nil, err
+			}
+		}
+		return entries, nil
+	case reflect.Struct:
+		if v.Type() == clauseCapturerType {
+			return e.encodeClauseCapturer(v.Addr().Interface().(*ClauseCapturer))
+		}
+		fields, err := taggedFields(v.Type())
+		if err != nil {
+			return nil, err
+		}
+		encoded := make(map[string]any, len(fields))
+		for _, field := range fields {
+			value, err := e.encode(v.FieldByIndex(field.Index))
+			if err != nil {
+				return nil, errors.Join(fmt.Errorf("couldn't encode field %s of %s - ", field.Name, v.Type()), err)
+			}
+			encoded[field.Tag.Get("ast")] = value
+		}
+		return encoded, nil
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if !isTracked(v.Type()) {
+			return e.encode(v.Elem())
+		}
+		key := pointerKey{v.Pointer(), v.Type()}
+		if id, ok := e.ids[key]; ok {
+			return map[string]any{"ref": id}, nil
+		}
+		id := len(e.ids)
+		e.ids[key] = id
+		value, err := e.encode(addressable(v.Elem()))
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"id": id, "value": value}, nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		elem := v.Elem()
+		if !isRegistered(elem.Type()) {
+			return nil, fmt.Errorf("type %s is not registered, register it using helperclauses.RegisterClauses", elem.Type())
+		}
+		value, err := e.encode(elem)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": typeName(elem.Type()), "value": value}, nil
+	default:
+		if v.IsZero() {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("values of kind %s can't be encoded", v.Kind())
+	}
+}
+
+func (e *astEncoder) encodeClauseCapturer(c *ClauseCapturer) (any, error) {
+	var err error
+	encoded := encodedClauseCapturer{
+		Generated:   c.generated,
+		Regenerated: c.regenerated,
+	}
+	if encoded.Schema, err = e.encodeSchema(c.capturedSchema); err != nil {
+		return nil, err
+	}
+	if encoded.ModifiedSchema, err = e.encodeSchema(c.capturedModifiedSchema); err != nil {
+		return nil, err
+	}
+	if encoded.Clause, err = e.encode(reflect.ValueOf(&c.capturedClause).Elem()); err != nil {
+		return nil, err
+	}
+	if encoded.Subclauses, err = e.encode(reflect.ValueOf(c.subclauses)); err != nil {
+		return nil, err
+	}
+	return encoded, nil
+}
+
+// encodeSchema returns the index of the passed schema in the encoded schemas, or nil if the schema is nil.
+func (e *astEncoder) encodeSchema(s *schema.Schema) (any, error) {
+	if s == nil {
+		return nil, nil
+	}
+	encoded, err := e.encode(reflect.ValueOf(s).Elem())
+	if err != nil {
+		return nil, errors.Join(errors.New("couldn't encode schema - "), err)
+	}
+	key, err := json.Marshal(encoded)
+	if err != nil {
+		return nil, err
+	}
+	index, ok := e.schemaIndices[string(key)]
+	if !ok {
+		index = len(e.schemas)
+		e.schemas = append(e.schemas, encoded)
+		e.schemaIndices[string(key)] = index
+	}
+	return index, nil
+}
+
+func encodeMapKey(key reflect.Value) (string, error) {
+	switch key.Kind() {
+	case reflect.String:
+		return key.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("map keys of type %s can't be encoded", key.Type())
+}
+
+type astDecoder struct {
+	// The values of the pointers decoded so far, by their ID
+	pointers map[int]reflect.Value
+	// The encoded schemas referenced by the clause capturers
+	schemas []any
+	// The drop ins assigned to the decoded clause capturers
+	dropIns translator.DropIns
+}
+
+// decode sets the passed value, which has to be addressable, to the value described by the data.
+// The data is the JSON encoding of a value as returned by [astEncoder.encode], decoded using json.Number for numbers.
+//
+// Struct fields tagged with `ast` but not stored in the data and stored fields not tagged in the struct result in an error,
+// as the clause's state changed since the AST was encoded and the AST would no longer translate to the same statement.
+func (d *astDecoder) decode(data any, v reflect.Value) error {
+	v = addressable(v)
+	if data == nil {
+		v.SetZero()
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b, ok := data.(bool)
+		if !ok {
+			return fmt.Errorf("expected a boolean for %s, got %v", v.Type(), data)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := data.(json.Number)
+		if !ok {
+			return fmt.Errorf("expected an integer for %s, got %v", v.Type(), data)
+		}
+		i, err := strconv.ParseInt(n.String(), 10, v.Type().Bits())
+		if err != nil {
+			return errors.Join(fmt.Errorf("invalid integer for %s - ", v.Type()), err)
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := data.(json.Number)
+		if !ok {
+			return fmt.Errorf("expected an integer for %s, got %v", v.Type(), data)
+		}
+		u, err := strconv.ParseUint(n.String(), 10, v.Type().Bits())
+		if err != nil {
+			return errors.Join(fmt.Errorf("invalid integer for %s - ", v.Type()), err)
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var s string
+		switch f := data.(type) {
+		case json.Number:
+			s = f.String()
+		case string:
+			s = f
+		default:
+			return fmt.Errorf("expected a number for %s, got %v", v.Type(), data)
+		}
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return errors.Join(fmt.Errorf("invalid number for %s - ", v.Type()), err)
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		s, ok := data.(string)
+		if !ok {
+			return fmt.Errorf("expected a string for %s, got %v", v.Type(), data)
+		}
+		v.SetString(s)
+	case reflect.Slice, reflect.Array:
+		elements, ok := data.([]any)
+		if !ok {
+			return fmt.Errorf("expected a list for %s, got %v", v.Type(), data)
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(elements), len(elements)))
+		} else if len(elements) != v.Len() {
+			return fmt.Errorf("expected %d elements for %s, got %d", v.Len(), v.Type(), len(elements))
+		}
+		for i, element := range elements {
+			if err := d.decode(element, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		entries, ok := data.(map[string]any)
+		if !ok {
+			return fmt.Errorf("expected an object for %s, got %v", v.Type(), data)
+		}
+		v.Set(reflect.MakeMapWithSize(v.Type(), len(entries)))
+		for key, entry := range entries {
+			decodedKey := reflect.New(v.Type().Key()).Elem()
+			if err := decodeMapKey(key, decodedKey); err != nil {
+				return err
+			}
+			decodedEntry := reflect.New(v.Type().Elem()).Elem()
+			if err := d.decode(entry, decodedEntry); err != nil {
+				return err
+			}
+			v.SetMapIndex(decodedKey, decodedEntry)
+		}
+	case reflect.Struct:
+		if v.Type() == clauseCapturerType {
+			return d.decodeClauseCapturer(data, v.Addr().Interface().(*ClauseCapturer))
+		}
+		encoded, ok := data.(map[string]any)
+		if !ok {
+			return fmt.Errorf("expected an object for %s, got %v", v.Type(), data)
+		}
+		fields, err := taggedFields(v.Type())
+		if err != nil {
+			return err
+		}
+		names := make([]string, len(fields))
+		for i, field := range fields {
+			names[i] = field.Tag.Get("ast")
+		}
+		if err := checkFields(encoded, names, v.Type().String()); err != nil {
+			return err
+		}
+		v.SetZero()
+		for i, field := range fields {
+			if err := d.decode(encoded[names[i]], v.FieldByIndex(field.Index)); err != nil {
+				return errors.Join(fmt.Errorf("couldn't decode field %s of %s - ", field.Name, v.Type()), err)
+			}
+		}
+	case reflect.Pointer:
+		if !isTracked(v.Type()) {
+			v.Set(reflect.New(v.Type().Elem()))
+			return d.decode(data, v.Elem())
+		}
+		pointer, ok := data.(map[string]any)
+		if !ok {
+			return fmt.Errorf("expected an object for %s, got %v", v.Type(), data)
+		}
+		if ref, ok := pointer["ref"]; ok {
+			id, err := decodeID(ref)
+			if err != nil {
+				return err
+			}
+			referenced, ok := d.pointers[id]
+			if !ok {
+				return fmt.Errorf("reference to unknown pointer %d", id)
+			}
+			if referenced.Type() != v.Type() {
+				return fmt.Errorf("pointer %d is of type %s, expected %s", id, referenced.Type(), v.Type())
+			}
+			v.Set(referenced)
+			return nil
+		}
+		id, err := decodeID(pointer["id"])
+		if err != nil {
+			return err
+		}
+		if decoded, ok := d.pointers[id]; ok && decoded.Type() == v.Type() {
+			// Schemas get decoded once for every clause capturer referencing them, so pointers they hold may already be decoded
+			v.Set(decoded)
+			return nil
+		}
+		// Store the pointer before decoding its value, as the value may reference it again
+		v.Set(reflect.New(v.Type().Elem()))
+		d.pointers[id] = v
+		return d.decode(pointer["value"], v.Elem())
+	case reflect.Interface:
+		value, ok := data.(map[string]any)
+		if !ok {
+			return fmt.Errorf("expected an object for %s, got %v", v.Type(), data)
+		}
+		name, _ := value["type"].(string)
+		clauseTypesMutex.RLock()
+		t, ok := clauseTypes[name]
+		clauseTypesMutex.RUnlock()
+		if !ok {
+			return fmt.Errorf("unknown type %q, register it using helperclauses.RegisterClauses", name)
+		}
+		if !t.Implements(v.Type()) {
+			return fmt.Errorf("type %s doesn't implement %s", t, v.Type())
+		}
+		decoded := reflect.New(t).Elem()
+		if err := d.decode(value["value"], decoded); err != nil {
+			return err
+		}
+		v.Set(decoded)
+	default:
+		return fmt.Errorf("values of kind %s can't be decoded", v.Kind())
+	}
+	return nil
+}
+
+func (d *astDecoder) decodeClauseCapturer(data any, c *ClauseCapturer) error {
+	fields, ok := data.(map[string]any)
+	if !ok {
+		return fmt.Errorf("expected an object for a clause capturer, got %v", data)
+	}
+	if err := checkFields(fields, []string{"generated", "regenerated", "schema", "modifiedSchema", "clause", "subclauses"}, "a clause capturer"); err != nil {
+		return err
+	}
+
+	*c = ClauseCapturer{dropIns: d.dropIns}
+	var err error
+	if c.capturedSchema, err = d.decodeSchema(fields["schema"]); err != nil {
+		return err
+	}
+	if c.capturedModifiedSchema, err = d.decodeSchema(fields["modifiedSchema"]); err != nil {
+		return err
+	}
+	for _, field := range []struct {
+		name  string
+		value any
+	}{
+		{"generated", &c.generated},
+		{"regenerated", &c.regenerated},
+		{"clause", &c.capturedClause},
+		{"subclauses", &c.subclauses},
+	} {
+		if err := d.decode(fields[field.name], reflect.ValueOf(field.value).Elem()); err != nil {
+			return errors.Join(fmt.Errorf("couldn't decode field %s of a clause capturer - ", field.name), err)
+		}
+	}
+	if c.capturedClause == nil {
+		return errors.New("clause capturer holds no clause")
+	}
+	return nil
+}
+
+// decodeSchema returns a new schema decoded from the encoded schema with the index held by the passed data.
+//
+// Every clause capturer gets its own schema, as regenerating a clause modifies the maps of its captured schema.
+func (d *astDecoder) decodeSchema(data any) (*schema.Schema, error) {
+	if data == nil {
+		return nil, nil
+	}
+	index, err := decodeID(data)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(d.schemas) {
+		return nil, fmt.Errorf("reference to unknown schema %d", index)
+	}
+	s := &schema.Schema{}
+	if err := d.decode(d.schemas[index], reflect.ValueOf(s).Elem()); err != nil {
+		return nil, errors.Join(errors.New("couldn't decode schema - "), err)
+	}
+	return s, nil
+}
+
+// taggedFields returns the fields of the passed struct type tagged with `ast`, excluding those tagged with `ast:"-"`.
+// An error is returned if a field isn't tagged or multiple fields are stored under the same name.
+func taggedFields(t reflect.Type) ([]reflect.StructField, error) {
+	var fields []reflect.StructField
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := field.Tag.Lookup("ast")
+		if !ok {
+			return nil, fmt.Errorf("field %s of %s isn't tagged, tag it with `ast:\"name\"` or `ast:\"-\"` if it holds no state", field.Name, t)
+		}
+		if name == "-" {
+			continue
+		}
+		if name == "" || names[name] {
+			return nil, fmt.Errorf("field %s of %s has an empty or duplicate ast tag %q", field.Name, t, name)
+		}
+		names[name] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// checkFields returns an error if the encoded struct described by what doesn't store exactly the fields with the passed names.
+func checkFields(encoded map[string]any, names []string, what string) error {
+	for _, name := range names {
+		if _, ok := encoded[name]; !ok {
+			return fmt.Errorf("field %q of %s is missing, the AST was encoded by an incompatible version of dinkel", name, what)
+		}
+	}
+	for name := range encoded {
+		if !slices.Contains(names, name) {
+			return fmt.Errorf("unknown field %q of %s, the AST was encoded by an incompatible version of dinkel", name, what)
+		}
+	}
+	return nil
+}
+
+func decodeMapKey(key string, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(key)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(key, 10, v.Type().Bits())
+		if err != nil {
+			return errors.Join(fmt.Errorf("invalid map key for %s - ", v.Type()), err)
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(key, 10, v.Type().Bits())
+		if err != nil {
+			return errors.Join(fmt.Errorf("invalid map key for %s - ", v.Type()), err)
+		}
+		v.SetUint(u)
+	default:
+		return fmt.Errorf("map keys of type %s can't be decoded", v.Type())
+	}
+	return nil
+}
+
+func decodeID(data any) (int, error) {
+	n, ok := data.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("invalid pointer ID %v", data)
+	}
+	id, err := strconv.Atoi(n.String())
+	if err != nil {
+		return 0, errors.Join(fmt.Errorf("invalid pointer ID %v - ", data), err)
+	}
+	return id, nil
+}
+
+// isTracked returns true if the identity of the values pointed to by pointers of the passed type is preserved.
+//
+// The identity of booleans, numbers and strings is irrelevant, so encoding them by value keeps the encoding of equal schemas equal.
+func isTracked(t reflect.Type) bool {
+	switch t.Elem().Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return false
+	}
+	return true
+}
+
+func isRegistered(t reflect.Type) bool {
+	clauseTypesMutex.RLock()
+	defer clauseTypesMutex.RUnlock()
+	return clauseTypes[typeName(t)] == t
+}
+
+// addressable returns a value that can be read and set even if it was obtained using unexported struct fields.
+// The generated state of clauses is mostly held in unexported fields, which thus need to be accessed.
+func addressable(v reflect.Value) reflect.Value {
+	if !v.CanAddr() {
+		// Values not stemming from pointers can't hold unexported fields that were accessed
+		return v
+	}
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// astLayout describes the names of the registered clauses and the encoded fields of the structs they and the schema hold,
+// one struct per line. A change to the layout is an incompatible change to the encoding, requiring astVersion to be increased.
+func astLayout() (string, error) {
+	clauseTypesMutex.RLock()
+	names := make([]string, 0, len(clauseTypes))
+	roots := []reflect.Type{reflect.TypeOf(schema.Schema{})}
+	for name, t := range clauseTypes {
+		if t.Kind() != reflect.Pointer {
+			names = append(names, "clause "+name)
+			roots = append(roots, t)
+		}
+	}
+	clauseTypesMutex.RUnlock()
+
+	lines := make(map[string]bool)
+	var describe func(t reflect.Type) error
+	describe = func(t reflect.Type) error {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array:
+			return describe(t.Elem())
+		case reflect.Map:
+			if err := describe(t.Key()); err != nil {
+				return err
+			}
+			return describe(t.Elem())
+		case reflect.Struct:
+			if t == clauseCapturerType {
+				// Clause capturers are encoded by encodedClauseCapturer
+				return nil
+			}
+		default:
+			return nil
+		}
+		fields, err := taggedFields(t)
+		if err != nil {
+			return err
+		}
+		encodedFields := make([]string, len(fields))
+		for i, field := range fields {
+			encodedFields[i] = fmt.Sprintf("%s %s", field.Tag.Get("ast"), field.Type)
+		}
+		line := fmt.Sprintf("%s {%s}", typeName(t), strings.Join(encodedFields, ", "))
+		if lines[line] {
+			return nil
+		}
+		lines[line] = true
+		for _, field := range fields {
+			if err := describe(field.Type); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, t := range roots {
+		if err := describe(t); err != nil {
+			return "", err
+		}
+	}
+
+	layout := names
+	for line := range lines {
+		layout = append(layout, line)
+	}
+	slices.Sort(layout)
+	return strings.Join(layout, "\n") + "\n", nil
+}
@@ -0,0 +1,155 @@
+package helperclauses_test
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Anon10214/dinkel/models/mock"
+	"github.com/Anon10214/dinkel/models/opencypher"
+	"github.com/Anon10214/dinkel/seed"
+	"github.com/Anon10214/dinkel/translator/helperclauses"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	// Register the clauses of all models, making them part of the checked layout
+	_ "github.com/Anon10214/dinkel/models/apacheage/clauses"
+	_ "github.com/Anon10214/dinkel/models/kuzu/clauses"
+	_ "github.com/Anon10214/dinkel/models/memgraph/clauses"
+	_ "github.com/Anon10214/dinkel/models/neo4j/clauses"
+	_ "github.com/Anon10214/dinkel/scheduler/strategy/equivalencetransformation"
+)
+
+// Ensure that loading a marshalled AST results in the same AST, translating to the same statement
+func TestMarshalAST(t *testing.T) {
+	helperclauses.SetImplementation(mock.Implementation{})
+
+	for i := int64(0); i < 20; i++ {
+		var rootClauses []*helperclauses.ClauseCapturer
+		var statements []string
+		seed := seed.GetRandomByteStringWithSource(*rand.New(rand.NewSource(i)))
+		for j := 0; j < 2; j++ {
+			rootClause := helperclauses.GetClauseCapturerForClause(&opencypher.RootClause{})
+			statements = append(statements, generateMockClauseWithSeed(rootClause, seed))
+			rootClauses = append(rootClauses, rootClause)
+		}
+
+		data, err := helperclauses.MarshalAST(rootClauses)
+		if !assert.NoError(t, err) {
+			return
+		}
+		loaded, err := helperclauses.UnmarshalAST(data)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, rootClauses, loaded)
+		for j, rootClause := range loaded {
+			assert.Equal(t, statements[j], generateMockClauseWithSeed(rootClause, nil), "the loaded AST should translate to the same statement")
+		}
+	}
+}
+
+// Values referenced by multiple clauses should still be shared after loading the AST
+func TestMarshalAST_SharedValues(t *testing.T) {
+	helperclauses.SetImplementation(mock.Implementation{})
+	stringer := helperclauses.CreateStringer("a")
+	rootClause := helperclauses.GetClauseCapturerForClause(helperclauses.CreateAssembler("%s %s", stringer, stringer))
+	generateMockClauseWithSeed(rootClause, nil)
+
+	data, err := helperclauses.MarshalAST([]*helperclauses.ClauseCapturer{rootClause})
+	assert.NoError(t, err)
+	loaded, err := helperclauses.UnmarshalAST(data)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	subclauses := loaded[0].GetSubclauseClauseCapturers()
+	assert.Same(t, subclauses[0].GetCapturedClause(), subclauses[1].GetCapturedClause())
+	assert.Same(t, loaded[0].GetCapturedSchema().UsedNames, subclauses[0].GetCapturedSchema().UsedNames)
+	assert.Equal(t, "a a", generateMockClauseWithSeed(loaded[0], nil))
+}
+
+func TestMarshalAST_Errors(t *testing.T) {
+	helperclauses.SetImplementation(mock.Implementation{})
+	rootClause := helperclauses.GetClauseCapturerForClause(&helperclauses.HookClause{})
+	generateMockClauseWithSeed(rootClause, nil)
+	_, err := helperclauses.MarshalAST([]*helperclauses.ClauseCapturer{rootClause})
+	assert.ErrorContains(t, err, "not registered", "unregistered clauses shouldn't be encoded")
+
+	rootClause = helperclauses.GetClauseCapturerForClause(&helperclauses.EmptyClause{})
+	generateMockClauseWithSeed(rootClause, nil)
+	data, err := helperclauses.MarshalAST([]*helperclauses.ClauseCapturer{rootClause})
+	assert.NoError(t, err)
+
+	_, err = helperclauses.UnmarshalAST([]byte(strings.Replace(string(data), "EmptyClause", "RemovedClause", 1)))
+	assert.ErrorContains(t, err, "unknown type")
+
+	_, err = helperclauses.UnmarshalAST([]byte(strings.Replace(string(data), fmt.Sprintf(`"version":%d`, helperclauses.ASTVersion), `"version":1`, 1)))
+	assert.ErrorContains(t, err, "unsupported AST encoding version")
+
+	_, err = helperclauses.UnmarshalAST([]byte("{"))
+	assert.Error(t, err)
+}
+
+// Changes to the state of clauses should be detected instead of silently resulting in a different statement
+func TestMarshalAST_ChangedFields(t *testing.T) {
+	helperclauses.SetImplementation(mock.Implementation{})
+	_, err := helperclauses.TaggedFields(reflect.TypeOf(struct {
+		tagged   bool `ast:"tagged"`
+		untagged bool
+	}{}))
+	assert.ErrorContains(t, err, "field untagged of struct { tagged bool \"ast:\\\"tagged\\\"\"; untagged bool } isn't tagged")
+
+	rootClause := helperclauses.GetClauseCapturerForClause(helperclauses.CreateStringer("a"))
+	generateMockClauseWithSeed(rootClause, nil)
+	data, err := helperclauses.MarshalAST([]*helperclauses.ClauseCapturer{rootClause})
+	if !assert.NoError(t, err) {
+		return
+	}
+	encoded := string(data)
+	assert.Contains(t, encoded, `{"value":"a"}`)
+
+	_, err = helperclauses.UnmarshalAST([]byte(strings.Replace(encoded, `{"value":"a"}`, `{"renamed":"a"}`, 1)))
+	assert.ErrorContains(t, err, `field "value" of helperclauses.Stringer is missing`)
+
+	_, err = helperclauses.UnmarshalAST([]byte(strings.Replace(encoded, `{"value":"a"}`, `{"value":"a","removed":1}`, 1)))
+	assert.ErrorContains(t, err, `unknown field "removed" of helperclauses.Stringer`)
+
+	_, err = helperclauses.UnmarshalAST([]byte(strings.Replace(encoded, `"regenerated":false,`, "", 1)))
+	assert.ErrorContains(t, err, `field "regenerated" of a clause capturer is missing`)
+}
+
+var updateLayout = flag.Bool("update-layout", false, "update the expected layout of the AST encoding")
+
+// Changing the encoded fields of clauses has to come with an increased AST encoding version,
+// so bug reports holding ASTs of an earlier version get rejected instead of being loaded incorrectly.
+//
+// After increasing the version, update the expected layout using
+//
+//	go test ./translator/helperclauses -run TestASTLayout -update-layout
+func TestASTLayout(t *testing.T) {
+	layout, err := helperclauses.ASTLayout()
+	require.NoError(t, err)
+	version := fmt.Sprintf("version %d\n", helperclauses.ASTVersion)
+
+	path := filepath.Join("testdata", "ast_layout.txt")
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err)
+	expectedVersion, expectedLayout, _ := strings.Cut(string(expected), "\n")
+	if expectedVersion+"\n" == version && expectedLayout != layout {
+		assert.Fail(t, "the encoded fields of the clauses changed without increasing astVersion")
+		return
+	}
+
+	if *updateLayout {
+		require.NoError(t, os.WriteFile(path, []byte(version+layout), 0o644))
+		return
+	}
+	assert.Equal(t, string(expected), version+layout, "the expected layout is outdated, update it using -update-layout")
+}
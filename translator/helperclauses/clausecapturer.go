@@ -71,6 +71,27 @@ func getClauseCapturerForClause(clause translator.Clause, dropIns translator.Dro
 	}
 }
 
+// CreateGeneratedClauseCapturer returns a clause capturer which behaves as if it had already generated
+// the passed clause, returning the passed subclauses.
+// The passed schema is used both as the schema at the time of generation and as the one after the modifications.
+//
+// This allows building ASTs from clauses which never got generated, for example ones parsed from a query.
+// The captured clause only gets generated if it gets updated.
+func CreateGeneratedClauseCapturer(clause translator.Clause, s *schema.Schema, subclauses ...*ClauseCapturer) *ClauseCapturer {
+	var dropIns translator.DropIns
+	if implementation != nil {
+		dropIns = implementation.GetDropIns()
+	}
+	return &ClauseCapturer{
+		generated:              true,
+		capturedSchema:         s.Copy(),
+		capturedModifiedSchema: s.Copy(),
+		capturedClause:         clause,
+		subclauses:             subclauses,
+		dropIns:                dropIns,
+	}
+}
+
 // GetCapturedClause returns the underlying captured clause
 func (c *ClauseCapturer) GetCapturedClause() translator.Clause {
 	return c.capturedClause
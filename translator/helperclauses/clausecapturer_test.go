@@ -232,3 +232,31 @@ func TestCapturedSchema(t *testing.T) {
 	assert.Equal(t, schemaAtGeneration, capturedSchema, "Captured schema doesn't match schema at generation")
 	assert.NotSame(t, schemaAtGeneration, capturedSchema, "Captured schema doesn't point to separate memory address")
 }
+
+// A capturer created as generated shouldn't generate its clause, unless the clause gets updated
+func TestCreateGeneratedClauseCapturer(t *testing.T) {
+	helperclauses.SetImplementation(mock.Implementation{})
+	s := &schema.Schema{}
+	s.Reset()
+	s.Labels[schema.NODE] = []string{"L"}
+
+	hookClause := helperclauses.HookClause{
+		GenerateHook: func(*seed.Seed, *schema.Schema) []translator.Clause {
+			t.Error("Generated clause capturer generated its clause")
+			return nil
+		},
+		TemplateStringHook: func() string { return "%s-%s" },
+	}
+	clause := helperclauses.CreateGeneratedClauseCapturer(hookClause, s,
+		helperclauses.CreateGeneratedClauseCapturer(helperclauses.CreateStringer("a"), s),
+		helperclauses.CreateGeneratedClauseCapturer(helperclauses.CreateStringer("b"), s),
+	)
+
+	assert.Equal(t, "a-b", generateMockClause(clause))
+	assert.Equal(t, s, clause.GetCapturedSchema())
+	assert.NotSame(t, s, clause.GetCapturedSchema(), "Captured schema doesn't point to separate memory address")
+
+	// Updated clauses get generated with the passed schema
+	clause.GetSubclauseClauseCapturers()[1].UpdateClause(&clauses.ExistingLabel{LabelType: schema.NODE})
+	assert.Equal(t, "a-L", generateMockClause(clause))
+}
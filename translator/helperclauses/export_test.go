@@ -0,0 +1,8 @@
+package helperclauses
+
+// Exported for the tests of the package helperclauses_test, which can import the packages registering clauses.
+const ASTVersion = astVersion
+
+var ASTLayout = astLayout
+
+var TaggedFields = taggedFields
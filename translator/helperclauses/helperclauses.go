@@ -26,7 +26,7 @@ func (c *EmptyClause) Generate(seed *seed.Seed, s *schema.Schema) []translator.C
 // A Stringer has no subclauses and does not modify the schema or seed.
 // It simply returns the saved string as its template string.
 type Stringer struct {
-	value string
+	value string `ast:"value"`
 }
 
 // CreateStringer returns a stringer generating the passed string.
@@ -48,8 +48,8 @@ func (c Stringer) TemplateString() string {
 // An Assembler returns the provided subclauses during generation and the provided template string when prompted.
 // The assembler itself does not modify the schema or seed, though the subclauses might.
 type Assembler struct {
-	subclauses     []translator.Clause
-	templateString string
+	subclauses     []translator.Clause `ast:"subclauses"`
+	templateString string              `ast:"templateString"`
 }
 
 // CreateAssembler returns an assembler given the passed subclauses and template string
@@ -2,13 +2,16 @@ package translator_test
 
 import (
 	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/Anon10214/dinkel/models/mock"
 	"github.com/Anon10214/dinkel/models/opencypher"
+	"github.com/Anon10214/dinkel/models/opencypher/config"
 	"github.com/Anon10214/dinkel/models/opencypher/schema"
 	"github.com/Anon10214/dinkel/seed"
 	"github.com/Anon10214/dinkel/translator"
+	"github.com/stretchr/testify/assert"
 )
 
 const (
@@ -30,3 +33,67 @@ func TestGeneration(t *testing.T) {
 		translator.GenerateStatement(seed, schema, &opencypher.RootClause{}, mock.Implementation{}, 0)
 	}
 }
+
+// Statements marked as totally ordered or cut through ties of known sort keys have to end in an ORDER BY,
+// optionally followed by SKIP and LIMIT.
+func TestGeneration_ReturnOrder(t *testing.T) {
+	config.SetOrderedReturns(true)
+	t.Cleanup(func() { config.SetOrderedReturns(false) })
+
+	var ordered, cut int
+	for i := int64(0); i < int64(samples); i++ {
+		seed := seed.GetRandomByteStringWithSource(*rand.New(rand.NewSource(i)))
+
+		s := &schema.Schema{}
+		s.Reset()
+
+		statement, _ := translator.GenerateStatement(seed, s, &opencypher.RootClause{}, mock.Implementation{}, 0)
+		if s.ReturnOrder == schema.TotallyOrdered {
+			ordered++
+			assert.Contains(t, statement[strings.LastIndex(statement, "RETURN"):], "ORDER BY", "Totally ordered statement without ORDER BY")
+		}
+		if s.ReturnSortColumns != 0 {
+			cut++
+			assert.Equal(t, schema.TruncatedTies, s.ReturnOrder, "Sort columns set for rows not cut through ties")
+			assert.Contains(t, statement[strings.LastIndex(statement, "RETURN"):], "ORDER BY", "Statement cut through ties of known sort keys without ORDER BY")
+		}
+	}
+	assert.NotZero(t, ordered, "No totally ordered statement generated")
+	assert.NotZero(t, cut, "No statement cut through ties of known sort keys generated")
+}
+
+// Values of existing properties are inserted as is, even if they contain percent signs.
+func TestGeneration_ExistingPropertyValues(t *testing.T) {
+	var used int
+	for i := int64(0); i < int64(samples); i++ {
+		seed := seed.GetRandomByteStringWithSource(*rand.New(rand.NewSource(i)))
+
+		s := &schema.Schema{}
+		s.Reset()
+		s.AddProperty(schema.Property{Name: "p", Type: schema.String, Value: `"100%"`})
+
+		statement, _ := translator.GenerateStatement(seed, s, &opencypher.RootClause{}, mock.Implementation{}, 0)
+		if !assert.NotContains(t, statement, `"100%!`, "Percent sign of the value got interpreted as a verb") {
+			return
+		}
+		if strings.Contains(statement, `p:"100%"`) {
+			used++
+		}
+	}
+	assert.NotZero(t, used, "Value of the existing property never used")
+}
+
+// Byte strings have to generate the same statements as before ordered returns were added unless they are enabled.
+func TestGeneration_OrderedReturnsDisabled(t *testing.T) {
+	for i := int64(0); i < int64(samples); i++ {
+		seed := seed.GetRandomByteStringWithSource(*rand.New(rand.NewSource(i)))
+
+		s := &schema.Schema{}
+		s.Reset()
+
+		translator.GenerateStatement(seed, s, &opencypher.RootClause{}, mock.Implementation{}, 0)
+		if !assert.NotEqual(t, schema.TotallyOrdered, s.ReturnOrder, "Totally ordered statement generated with ordered returns disabled") {
+			return
+		}
+	}
+}